@@ -58,7 +58,7 @@ const Chat = () => {
     }
 
     // WebSocket接続を開始
-    ws.current = new WebSocket(`ws://localhost:8080/ws?token=${token}`);
+    ws.current = new WebSocket(`ws://localhost:8080/ws?token=${token}&workspace_id=${workspaceID}`);
 
     ws.current.onopen = () => {
       console.log("WebSocket connection established");
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/cors"
@@ -11,14 +10,16 @@ import (
 	"go.uber.org/dig"
 
 	"github.com/tusmasoma/go-chat-app/config"
-	"github.com/tusmasoma/go-chat-app/entity"
 	"github.com/tusmasoma/go-chat-app/interfaces/handler"
 	"github.com/tusmasoma/go-chat-app/interfaces/middleware"
 	"github.com/tusmasoma/go-chat-app/interfaces/websocket"
 	"github.com/tusmasoma/go-chat-app/repository"
 	"github.com/tusmasoma/go-chat-app/repository/auth"
+	"github.com/tusmasoma/go-chat-app/repository/filesystem"
+	"github.com/tusmasoma/go-chat-app/repository/mail"
 	"github.com/tusmasoma/go-chat-app/repository/mysql"
 	"github.com/tusmasoma/go-chat-app/repository/redis"
+	"github.com/tusmasoma/go-chat-app/repository/s3"
 	"github.com/tusmasoma/go-chat-app/usecase"
 )
 
@@ -36,25 +37,71 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) {
 		config.NewServerConfig,
 		config.NewCacheConfig,
 		config.NewDBConfig,
+		config.NewStorageConfig,
+		config.NewAuthConfig,
+		config.NewMailConfig,
+		config.NewOIDCConfig,
+		config.NewWorkspaceConfig,
 		mysql.NewMySQLDB,
 		mysql.NewTransactionRepository,
 		mysql.NewMessageRepository,
 		mysql.NewUserRepository,
+		mysql.NewUserIdentityRepository,
+		mysql.NewRecoveryCodeRepository,
 		mysql.NewMembershipRepository,
+		mysql.NewChannelRepository,
+		mysql.NewWorkspaceRepository,
+		mysql.NewMembershipChannelRepository,
+		mysql.NewReactionRepository,
+		mysql.NewMessageRevisionRepository,
+		mysql.NewReadReceiptRepository,
+		mysql.NewMentionRepository,
+		mysql.NewAttachmentRepository,
 		auth.NewAuthRepository,
+		auth.NewOIDCProvider,
 		redis.NewRedisClient,
 		redis.NewPubSubRepository,
+		redis.NewPresenceRepository,
+		redis.NewTokenRepository,
+		generateBlobStore,
+		generateMailer,
 		usecase.NewMessageUseCase,
 		usecase.NewUserUseCase,
-		generateHubManager,
+		usecase.NewChannelUseCase,
+		usecase.NewWorkspaceUseCase,
+		usecase.NewReactionUseCase,
+		usecase.NewPresenceUseCase,
+		usecase.NewReadReceiptUseCase,
+		usecase.NewMentionUseCase,
+		usecase.NewSearchUseCase,
+		usecase.NewAttachmentUseCase,
+		generateHubManagerRegistry,
 		handler.NewWebsocketHandler,
 		handler.NewUserHandler,
+		handler.NewChannelHandler,
+		handler.NewWorkspaceHandler,
+		handler.NewMessageHandler,
+		handler.NewDirectMessageHandler,
+		handler.NewPresenceHandler,
+		handler.NewMentionHandler,
+		handler.NewSearchHandler,
+		handler.NewAttachmentHandler,
 		middleware.NewAuthMiddleware,
+		middleware.NewMembershipMiddleware,
 		func(
 			serverConfig *config.ServerConfig,
 			wsHandler *handler.WebsocketHandler,
 			userHandler handler.UserHandler,
+			channelHandler handler.ChannelHandler,
+			workspaceHandler handler.WorkspaceHandler,
+			messageHandler handler.MessageHandler,
+			directMessageHandler handler.DirectMessageHandler,
+			presenceHandler handler.PresenceHandler,
+			mentionHandler handler.MentionHandler,
+			searchHandler handler.SearchHandler,
+			attachmentHandler handler.AttachmentHandler,
 			authMiddleware middleware.AuthMiddleware,
+			membershipMiddleware middleware.MembershipMiddleware,
 		) *chi.Mux {
 			r := chi.NewRouter()
 			r.Use(cors.Handler(cors.Options{
@@ -68,17 +115,73 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) {
 
 			r.Group(func(r chi.Router) {
 				r.Use(authMiddleware.Authenticate)
+				r.Use(membershipMiddleware.Authorize)
 				r.Get("/ws", wsHandler.WebSocket)
 			})
 
+			r.Get("/.well-known/jwks.json", userHandler.JWKS)
+
 			r.Route("/api", func(r chi.Router) {
 				r.Route("/user", func(r chi.Router) {
 					r.Post("/signup", userHandler.SignUp)
 					r.Post("/login", userHandler.Login)
-					// r.Group(func(r chi.Router) {
-					// 	r.Use(authMiddleware.Authenticate)
-					// 	r.Get("/logout", userHandler.Logout)
-					// })
+					r.Post("/login/2fa", userHandler.LoginTwoFactor)
+					r.Post("/refresh", userHandler.Refresh)
+					r.Post("/verify", userHandler.VerifyEmail)
+					r.Post("/verify/resend", userHandler.ResendVerificationEmail)
+					r.Post("/password/forgot", userHandler.ForgotPassword)
+					r.Post("/password/reset", userHandler.ResetPassword)
+					r.Get("/oidc/login", userHandler.OIDCLogin)
+					r.Get("/oidc/callback", userHandler.OIDCCallback)
+					r.Group(func(r chi.Router) {
+						r.Use(authMiddleware.Authenticate)
+						r.Post("/logout", userHandler.Logout)
+						r.Post("/2fa/enroll", userHandler.EnrollTwoFactor)
+						r.Post("/2fa/activate", userHandler.ActivateTwoFactor)
+						r.Post("/2fa/disable", userHandler.DisableTwoFactor)
+					})
+				})
+				r.Route("/me", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Get("/mentions", mentionHandler.ListMentions)
+				})
+				r.Route("/search", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Get("/messages", searchHandler.SearchMessages)
+				})
+				r.Route("/attachments", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Get("/{attachmentID}", attachmentHandler.DownloadAttachment)
+				})
+				r.Route("/workspaces", func(r chi.Router) {
+					r.Use(authMiddleware.Authenticate)
+					r.Get("/", workspaceHandler.ListWorkspaces)
+					r.Post("/", workspaceHandler.CreateWorkspace)
+					r.Route("/{workspaceID}", func(r chi.Router) {
+						r.Use(membershipMiddleware.Authorize)
+						r.Route("/channels", func(r chi.Router) {
+							r.Get("/", channelHandler.ListChannels)
+							r.Post("/", channelHandler.CreateChannel)
+							r.Route("/{channelID}", func(r chi.Router) {
+								r.Get("/", channelHandler.GetChannel)
+								r.Put("/", channelHandler.UpdateChannel)
+								r.Delete("/", channelHandler.DeleteChannel)
+								r.Get("/messages", messageHandler.ListMessages)
+								r.Get("/messages/{messageID}/replies", messageHandler.ListThreadMessages)
+								r.Post("/attachments", attachmentHandler.UploadAttachment)
+								r.Route("/members", func(r chi.Router) {
+									r.Post("/", channelHandler.InviteMember)
+									r.Delete("/{userID}", channelHandler.RemoveMember)
+								})
+							})
+						})
+						r.Get("/messages/{messageID}/revisions", messageHandler.ListMessageRevisions)
+						r.Route("/dms", func(r chi.Router) {
+							r.Get("/", directMessageHandler.ListDirectMessages)
+							r.Post("/", directMessageHandler.OpenDirectMessage)
+						})
+						r.Get("/presence", presenceHandler.ListPresences)
+					})
 				})
 			})
 
@@ -97,44 +200,62 @@ func BuildContainer(ctx context.Context) (*dig.Container, error) {
 	return container, nil
 }
 
-func generateHubManager(ctx context.Context, psr repository.PubSubRepository) *websocket.HubManager {
-	//  現状、Workspaceは一つの為、containerにてHubManagerを生成して、DIする
-	//  同様に、ChannelManagerも生成してDIする
-	workspaceID := os.Getenv("WORKSPACE_ID")
-	if workspaceID == "" {
-		log.Critical("Failed to get workspace ID")
-		return nil
-	}
-	hub, err := entity.NewHub(workspaceID, "DefaultWorkspace")
-	if err != nil {
-		log.Critical("Failed to create new hub", log.Ferror(err))
-		return nil
-	}
-	hm := websocket.NewHubManager(hub)
+func generateHubManagerRegistry(
+	ctx context.Context,
+	psr repository.PubSubRepository,
+	wr repository.WorkspaceRepository,
+	cr repository.ChannelRepository,
+) (*websocket.HubManagerRegistry, error) {
+	// DBに登録されている全Workspace分のHubManagerを生成し、Registryに登録する
+	hmr := websocket.NewHubManagerRegistry(psr)
 
-	go hm.Run()
+	// 起動中に他のサーバで作成されたWorkspaceを取りこぼさないよう、一覧の取得より先に購読を開始する
+	go hmr.SubscribeToWorkspaces(ctx)
 
-	channelID := os.Getenv("CHANNEL_ID")
-	if channelID == "" {
-		log.Critical("Failed to get channel ID")
-		return nil
-	}
-	channel, err := entity.NewChannel(channelID, "DefaultChannel", false)
+	workspaces, err := wr.List(ctx)
 	if err != nil {
-		log.Critical("Failed to create new channel", log.Ferror(err))
-		return nil
+		log.Critical("Failed to list workspaces", log.Ferror(err))
+		return nil, err
 	}
-
-	cm := websocket.NewChannelManager(channel, psr)
-	if cm == nil {
-		log.Critical("Failed to create new channel manager")
-		return nil
+	for i := range workspaces {
+		channels, err := cr.List(ctx, workspaces[i].ID) //nolint:govet // err shadowing
+		if err != nil {
+			log.Critical("Failed to list channels", log.Fstring("workspaceID", workspaces[i].ID), log.Ferror(err))
+			return nil, err
+		}
+		if _, err = hmr.RegisterWorkspace(ctx, &workspaces[i], channels); err != nil {
+			log.Critical("Failed to register workspace", log.Fstring("workspaceID", workspaces[i].ID), log.Ferror(err))
+			return nil, err
+		}
 	}
 
-	go cm.Run(ctx)
-	hm.RegisterChannelManager(cm)
+	log.Info("HubManagerRegistry created successfully", log.Fint("workspaces", len(workspaces)))
 
-	log.Info("HubManager created successfully")
+	return hmr, nil
+}
 
-	return &hm
+// generateBlobStore は設定に応じて添付ファイルの保存先を選択する
+func generateBlobStore(ctx context.Context, conf *config.StorageConfig) (repository.BlobStore, error) {
+	switch conf.Driver {
+	case "local":
+		return filesystem.NewBlobStore(conf.LocalDir)
+	case "s3":
+		return s3.NewBlobStore(ctx, conf)
+	default:
+		log.Critical("Unknown storage driver", log.Fstring("driver", conf.Driver))
+		return nil, fmt.Errorf("unknown storage driver: %s", conf.Driver)
+	}
+}
+
+// generateMailer は設定に応じてメールの送信方法を選択する
+func generateMailer(conf *config.MailConfig) (repository.Mailer, error) {
+	switch conf.Driver {
+	case "log":
+		return mail.NewLogMailer(), nil
+	case "smtp":
+		return mail.NewSMTPMailer(conf), nil
+	default:
+		log.Critical("Unknown mail driver", log.Fstring("driver", conf.Driver))
+		return nil, fmt.Errorf("unknown mail driver: %s", conf.Driver)
+	}
 }
@@ -10,9 +10,14 @@ import (
 )
 
 const (
-	serverPrefix = "SERVER_"
-	dbPrefix     = "MYSQL_"
-	cachePrefix  = "REDIS_"
+	serverPrefix    = "SERVER_"
+	dbPrefix        = "MYSQL_"
+	cachePrefix     = "REDIS_"
+	storagePrefix   = "STORAGE_"
+	authPrefix      = "AUTH_"
+	mailPrefix      = "MAIL_"
+	oidcPrefix      = "OIDC_"
+	workspacePrefix = "WORKSPACE_"
 )
 
 type DBConfig struct {
@@ -37,6 +42,77 @@ type ServerConfig struct {
 	PreflightCacheDurationSec int           `env:"PREFLIGHT_CACHE_DURATION_SEC,default=300"`
 }
 
+// StorageConfig は添付ファイルの保存先と制限の設定
+// Driverがlocalの場合はLocalDirに、s3の場合はS3互換のストレージ(MinIOなど)に保存する
+// AllowedContentTypesには"image/*"のようにサブタイプを省略した指定もできる
+type StorageConfig struct {
+	Driver              string   `env:"DRIVER,default=local"`
+	LocalDir            string   `env:"LOCAL_DIR,default=./storage"`
+	S3Endpoint          string   `env:"S3_ENDPOINT"`
+	S3Region            string   `env:"S3_REGION,default=us-east-1"`
+	S3Bucket            string   `env:"S3_BUCKET"`
+	S3AccessKeyID       string   `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey   string   `env:"S3_SECRET_ACCESS_KEY"`
+	S3UseSSL            bool     `env:"S3_USE_SSL,default=false"`
+	MaxUploadSize       int64    `env:"MAX_UPLOAD_SIZE,default=10485760"`
+	AllowedContentTypes []string `env:"ALLOWED_CONTENT_TYPES,default=image/*,application/pdf,text/plain,application/zip"`
+}
+
+// AuthConfig はトークンの発行者・有効期限と署名鍵の設定
+// 署名鍵はPEM形式でSigningKeyFileかSigningKeyに指定する。鍵の種類によりRS256/ES256(P-256)/EdDSA(Ed25519)で署名する
+// 鍵のローテーション中は、以前の鍵(公開鍵でも可)をVerificationKeyFilesに指定すると発行済みのトークンを検証できる
+// 署名鍵が未指定の場合は起動に失敗する。開発時のみAllowEphemeralSigningKeyを有効にすると、起動毎に一時的な鍵を生成する
+// 一時的な鍵はサーバ毎に異なり再起動で失われる為、複数台構成や本番環境では使えない
+// RequireEmailVerificationを有効にすると、メールアドレスを確認するまでログインできない
+// 二要素認証が有効なユーザは、ログイン後TwoFactorChallengeTTLの間に認証アプリのコードを送信する必要がある
+type AuthConfig struct {
+	Issuer                    string        `env:"ISSUER,default=go-chat-app"`
+	AccessTokenTTL            time.Duration `env:"ACCESS_TOKEN_TTL,default=15m"`
+	RefreshTokenTTL           time.Duration `env:"REFRESH_TOKEN_TTL,default=720h"`
+	SigningKeyFile            string        `env:"SIGNING_KEY_FILE"`
+	SigningKey                string        `env:"SIGNING_KEY"`
+	AllowEphemeralSigningKey  bool          `env:"ALLOW_EPHEMERAL_SIGNING_KEY,default=false"`
+	VerificationKeyFiles      []string      `env:"VERIFICATION_KEY_FILES"`
+	EmailVerificationTokenTTL time.Duration `env:"EMAIL_VERIFICATION_TOKEN_TTL,default=24h"`
+	PasswordResetTokenTTL     time.Duration `env:"PASSWORD_RESET_TOKEN_TTL,default=1h"`
+	RequireEmailVerification  bool          `env:"REQUIRE_EMAIL_VERIFICATION,default=false"`
+	TwoFactorChallengeTTL     time.Duration `env:"TWO_FACTOR_CHALLENGE_TTL,default=5m"`
+}
+
+// MailConfig はメールの送信方法の設定
+// Driverがlogの場合は送信せずにログに出力し(開発用)、smtpの場合はSMTPサーバから送信する
+// LinkBaseURLはメールに記載する確認・再設定ページのURLの先頭部分
+type MailConfig struct {
+	Driver       string `env:"DRIVER,default=log"`
+	From         string `env:"FROM,default=no-reply@localhost"`
+	SMTPHost     string `env:"SMTP_HOST,default=localhost"`
+	SMTPPort     int    `env:"SMTP_PORT,default=587"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+	LinkBaseURL  string `env:"LINK_BASE_URL,default=http://localhost:3000"`
+}
+
+// OIDCConfig はSSO(OpenID Connect)でログインする際のプロバイダの設定
+// Issuerからディスカバリ(/.well-known/openid-configuration)でエンドポイントを取得する。Issuerが空の場合はOIDCログインを無効にする
+// RedirectURLにはプロバイダに登録したコールバックのURLを指定する
+type OIDCConfig struct {
+	Issuer         string        `env:"ISSUER"`
+	ClientID       string        `env:"CLIENT_ID"`
+	ClientSecret   string        `env:"CLIENT_SECRET"`
+	RedirectURL    string        `env:"REDIRECT_URL,default=http://localhost:8080/api/user/oidc/callback"`
+	Scopes         []string      `env:"SCOPES,default=openid,email,profile"`
+	AuthRequestTTL time.Duration `env:"AUTH_REQUEST_TTL,default=10m"`
+}
+
+// WorkspaceConfig はサインアップしたユーザが参加するWorkspaceの設定
+// DefaultIDを設定すると、サインアップ(OIDCでの初回ログインを含む)したユーザをそのWorkspaceのメンバーとして登録する
+// 未設定の場合はどのWorkspaceにも参加せず、Workspaceの作成などで参加する
+// DefaultChannelsには、メンバーとして登録したユーザを同時に参加させる公開Channelの名前を指定する
+type WorkspaceConfig struct {
+	DefaultID       string   `env:"DEFAULT_ID"`
+	DefaultChannels []string `env:"DEFAULT_CHANNELS,default=DefaultChannel"`
+}
+
 func NewDBConfig(ctx context.Context) (*DBConfig, error) {
 	conf := &DBConfig{}
 	pl := envconfig.PrefixLookuper(dbPrefix, envconfig.OsLookuper())
@@ -66,3 +142,53 @@ func NewServerConfig(ctx context.Context) (*ServerConfig, error) {
 	}
 	return conf, nil
 }
+
+func NewStorageConfig(ctx context.Context) (*StorageConfig, error) {
+	conf := &StorageConfig{}
+	pl := envconfig.PrefixLookuper(storagePrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load storage config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewAuthConfig(ctx context.Context) (*AuthConfig, error) {
+	conf := &AuthConfig{}
+	pl := envconfig.PrefixLookuper(authPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load auth config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewMailConfig(ctx context.Context) (*MailConfig, error) {
+	conf := &MailConfig{}
+	pl := envconfig.PrefixLookuper(mailPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load mail config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewOIDCConfig(ctx context.Context) (*OIDCConfig, error) {
+	conf := &OIDCConfig{}
+	pl := envconfig.PrefixLookuper(oidcPrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load oidc config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
+
+func NewWorkspaceConfig(ctx context.Context) (*WorkspaceConfig, error) {
+	conf := &WorkspaceConfig{}
+	pl := envconfig.PrefixLookuper(workspacePrefix, envconfig.OsLookuper())
+	if err := envconfig.ProcessWith(ctx, conf, pl); err != nil {
+		log.Error("Failed to load workspace config", log.Ferror(err))
+		return nil, err
+	}
+	return conf, nil
+}
@@ -161,3 +161,279 @@ func Test_NewServerConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewStorageConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *StorageConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &StorageConfig{
+				Driver:              "local",
+				LocalDir:            "./storage",
+				S3Region:            "us-east-1",
+				MaxUploadSize:       10485760,
+				AllowedContentTypes: []string{"image/*", "application/pdf", "text/plain", "application/zip"},
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("STORAGE_DRIVER", "s3")
+				t.Setenv("STORAGE_S3_ENDPOINT", "localhost:9000")
+				t.Setenv("STORAGE_S3_BUCKET", "attachments")
+				t.Setenv("STORAGE_S3_ACCESS_KEY_ID", "minioadmin")
+				t.Setenv("STORAGE_S3_SECRET_ACCESS_KEY", "minioadmin")
+				t.Setenv("STORAGE_MAX_UPLOAD_SIZE", "1024")
+				t.Setenv("STORAGE_ALLOWED_CONTENT_TYPES", "image/png")
+			},
+			want: &StorageConfig{
+				Driver:              "s3",
+				LocalDir:            "./storage",
+				S3Endpoint:          "localhost:9000",
+				S3Region:            "us-east-1",
+				S3Bucket:            "attachments",
+				S3AccessKeyID:       "minioadmin",
+				S3SecretAccessKey:   "minioadmin",
+				MaxUploadSize:       1024,
+				AllowedContentTypes: []string{"image/png"},
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewStorageConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewAuthConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *AuthConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &AuthConfig{
+				Issuer:                    "go-chat-app",
+				AccessTokenTTL:            15 * time.Minute,
+				RefreshTokenTTL:           720 * time.Hour,
+				EmailVerificationTokenTTL: 24 * time.Hour,
+				PasswordResetTokenTTL:     time.Hour,
+				TwoFactorChallengeTTL:     5 * time.Minute,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("AUTH_ISSUER", "https://chat.example.com")
+				t.Setenv("AUTH_ACCESS_TOKEN_TTL", "5m")
+				t.Setenv("AUTH_REFRESH_TOKEN_TTL", "24h")
+				t.Setenv("AUTH_SIGNING_KEY_FILE", "/etc/go-chat-app/signing.pem")
+				t.Setenv("AUTH_ALLOW_EPHEMERAL_SIGNING_KEY", "true")
+				t.Setenv("AUTH_VERIFICATION_KEY_FILES", "/etc/go-chat-app/old1.pem,/etc/go-chat-app/old2.pem")
+				t.Setenv("AUTH_EMAIL_VERIFICATION_TOKEN_TTL", "48h")
+				t.Setenv("AUTH_PASSWORD_RESET_TOKEN_TTL", "30m")
+				t.Setenv("AUTH_REQUIRE_EMAIL_VERIFICATION", "true")
+				t.Setenv("AUTH_TWO_FACTOR_CHALLENGE_TTL", "3m")
+			},
+			want: &AuthConfig{
+				Issuer:                    "https://chat.example.com",
+				AccessTokenTTL:            5 * time.Minute,
+				RefreshTokenTTL:           24 * time.Hour,
+				SigningKeyFile:            "/etc/go-chat-app/signing.pem",
+				AllowEphemeralSigningKey:  true,
+				VerificationKeyFiles:      []string{"/etc/go-chat-app/old1.pem", "/etc/go-chat-app/old2.pem"},
+				EmailVerificationTokenTTL: 48 * time.Hour,
+				PasswordResetTokenTTL:     30 * time.Minute,
+				RequireEmailVerification:  true,
+				TwoFactorChallengeTTL:     3 * time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewAuthConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewMailConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *MailConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &MailConfig{
+				Driver:      "log",
+				From:        "no-reply@localhost",
+				SMTPHost:    "localhost",
+				SMTPPort:    587,
+				LinkBaseURL: "http://localhost:3000",
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("MAIL_DRIVER", "smtp")
+				t.Setenv("MAIL_FROM", "no-reply@chat.example.com")
+				t.Setenv("MAIL_SMTP_HOST", "smtp.example.com")
+				t.Setenv("MAIL_SMTP_PORT", "2525")
+				t.Setenv("MAIL_SMTP_USERNAME", "user")
+				t.Setenv("MAIL_SMTP_PASSWORD", "password")
+				t.Setenv("MAIL_LINK_BASE_URL", "https://chat.example.com")
+			},
+			want: &MailConfig{
+				Driver:       "smtp",
+				From:         "no-reply@chat.example.com",
+				SMTPHost:     "smtp.example.com",
+				SMTPPort:     2525,
+				SMTPUsername: "user",
+				SMTPPassword: "password",
+				LinkBaseURL:  "https://chat.example.com",
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewMailConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewOIDCConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *OIDCConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &OIDCConfig{
+				RedirectURL:    "http://localhost:8080/api/user/oidc/callback",
+				Scopes:         []string{"openid", "email", "profile"},
+				AuthRequestTTL: 10 * time.Minute,
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("OIDC_ISSUER", "https://sso.example.com")
+				t.Setenv("OIDC_CLIENT_ID", "chat")
+				t.Setenv("OIDC_CLIENT_SECRET", "secret")
+				t.Setenv("OIDC_REDIRECT_URL", "https://chat.example.com/api/user/oidc/callback")
+				t.Setenv("OIDC_SCOPES", "openid,email")
+				t.Setenv("OIDC_AUTH_REQUEST_TTL", "5m")
+			},
+			want: &OIDCConfig{
+				Issuer:         "https://sso.example.com",
+				ClientID:       "chat",
+				ClientSecret:   "secret",
+				RedirectURL:    "https://chat.example.com/api/user/oidc/callback",
+				Scopes:         []string{"openid", "email"},
+				AuthRequestTTL: 5 * time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewOIDCConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NewWorkspaceConfig(t *testing.T) {
+	ctx := context.Background()
+
+	patterns := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  *WorkspaceConfig
+	}{
+		{
+			name: "default",
+			setup: func(t *testing.T) {
+				t.Helper()
+			},
+			want: &WorkspaceConfig{
+				DefaultChannels: []string{"DefaultChannel"},
+			},
+		},
+		{
+			name: "set env",
+			setup: func(t *testing.T) {
+				t.Helper()
+				t.Setenv("WORKSPACE_DEFAULT_ID", "550e8400-e29b-41d4-a716-446655440000")
+				t.Setenv("WORKSPACE_DEFAULT_CHANNELS", "general,random")
+			},
+			want: &WorkspaceConfig{
+				DefaultID:       "550e8400-e29b-41d4-a716-446655440000",
+				DefaultChannels: []string{"general", "random"},
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup(t)
+
+			got, err := NewWorkspaceConfig(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
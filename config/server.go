@@ -4,7 +4,11 @@ import "time"
 
 type ContextKey string
 
-const ContextUserIDKey ContextKey = "userID"
+const (
+	ContextUserIDKey      ContextKey = "userID"
+	ContextWorkspaceIDKey ContextKey = "workspaceID"
+	ContextTokenIDKey     ContextKey = "tokenID"
+)
 
 const (
 	// Max wait time when writing a message to the peer.
@@ -17,6 +21,16 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	PingPeriod = (PongWait * PingMultiplier) / 10
 
+	// PresenceTTL is how long a connection stays online without a pong.
+	// It must be longer than PingPeriod so that each pong refreshes it before it expires.
+	PresenceTTL = 2 * PingPeriod
+
+	// TypingTimeout is how long a typing indicator lasts without another TYPING_START.
+	TypingTimeout = 5 * time.Second
+
+	// TypingThrottleInterval is the minimum interval between TYPING_START broadcasts per client and channel.
+	TypingThrottleInterval = 2 * time.Second
+
 	// Max message size allowed from peer.
 	MaxMessageSize = 10000
 
@@ -29,6 +43,12 @@ const (
 	// PubSubGeneralChannel is the general channel for pubsub.
 	PubSubGeneralChannel = "general"
 
+	// DefaultMessageListLimit is the number of messages returned per page of history.
+	DefaultMessageListLimit = 50
+
+	// MaxMessageListLimit is the maximum number of messages returned per page of history.
+	MaxMessageListLimit = 100
+
 	// PubSubChannelPrefix is the prefix for the channel channel.
 	WelcomeMessage = "%s joined the channel"
 	GoodbyeMessage = "%s left the channel"
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+const (
+	// MaxAttachmentNameLength はファイル名の最大文字数
+	MaxAttachmentNameLength = 255
+
+	// MaxAttachmentsPerMessage は1つのメッセージに添付できるファイルの最大数
+	MaxAttachmentsPerMessage = 10
+
+	// AttachmentURLFormat は添付ファイルのダウンロードURL
+	AttachmentURLFormat = "/api/attachments/%s"
+)
+
+// Attachment はChannelにアップロードされたファイル。MessageIDはメッセージに添付されるまで空
+type Attachment struct {
+	ID          string    `json:"id"`
+	MessageID   string    `json:"message_id,omitempty"`
+	UserID      string    `json:"user_id"`
+	WorkspaceID string    `json:"workspace_id"`
+	ChannelID   string    `json:"channel_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewAttachment(id, messageID, userID, workspaceID, channelID, name, contentType string, size int64, createdAt time.Time) (*Attachment, error) {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if workspaceID == "" {
+		log.Error("WorkspaceID is required", log.Fstring("workspaceID", workspaceID))
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if channelID == "" {
+		log.Error("ChannelID is required", log.Fstring("channelID", channelID))
+		return nil, fmt.Errorf("channelID is required")
+	}
+	name = NormalizeAttachmentName(name)
+	if name == "" {
+		log.Error("Name is required")
+		return nil, fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(name) > MaxAttachmentNameLength {
+		log.Error("Name is too long", log.Fint("length", utf8.RuneCountInString(name)))
+		return nil, fmt.Errorf("name is too long")
+	}
+	if contentType == "" {
+		log.Error("ContentType is required")
+		return nil, fmt.Errorf("contentType is required")
+	}
+	if size < 0 {
+		log.Error("Size is invalid", log.Fint("size", int(size)))
+		return nil, fmt.Errorf("size is invalid")
+	}
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &Attachment{
+		ID:          id,
+		MessageID:   messageID,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		ChannelID:   channelID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		URL:         fmt.Sprintf(AttachmentURLFormat, id),
+		CreatedAt:   createdAt,
+	}, nil
+}
+
+// NormalizeAttachmentName はクライアントから送られたファイル名からディレクトリを取り除く
+func NormalizeAttachmentName(name string) string {
+	name = strings.TrimSpace(strings.ReplaceAll(name, `\`, "/"))
+	if name == "" {
+		return ""
+	}
+	name = path.Base(name)
+	if name == "." || name == "/" || name == ".." {
+		return ""
+	}
+	return name
+}
+
+// StorageKey はBlobStoreに保存する際のキー
+func (a *Attachment) StorageKey() string {
+	return path.Join(a.WorkspaceID, a.ChannelID, a.ID)
+}
+
+// IsAttached はメッセージに添付済みであるかを返す
+func (a *Attachment) IsAttached() bool {
+	return a.MessageID != ""
+}
+
+// GroupAttachmentsByMessageID は添付ファイルをメッセージIDごとにまとめる
+func GroupAttachmentsByMessageID(attachments []Attachment) map[string][]Attachment {
+	groups := make(map[string][]Attachment)
+	for _, attachment := range attachments {
+		groups[attachment.MessageID] = append(groups[attachment.MessageID], attachment)
+	}
+	return groups
+}
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewAttachment(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name     string
+		fileName string
+		size     int64
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "Success",
+			fileName: "report.pdf",
+			size:     1024,
+			wantName: "report.pdf",
+		},
+		{
+			name:     "Success: directories are removed",
+			fileName: `C:\Users\alice\..\photo.png`,
+			size:     1024,
+			wantName: "photo.png",
+		},
+		{
+			name:     "Fail: name is required",
+			fileName: "../",
+			size:     1024,
+			wantErr:  errors.New("name is required"),
+		},
+		{
+			name:     "Fail: name is too long",
+			fileName: strings.Repeat("a", MaxAttachmentNameLength+1),
+			size:     1024,
+			wantErr:  errors.New("name is too long"),
+		},
+		{
+			name:     "Fail: size is invalid",
+			fileName: "report.pdf",
+			size:     -1,
+			wantErr:  errors.New("size is invalid"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewAttachment("", "", userID, workspaceID, channelID, tt.fileName, "application/pdf", tt.size, time.Time{})
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("NewAttachment() name = %q, want %q", got.Name, tt.wantName)
+			}
+			if got.URL != "/api/attachments/"+got.ID {
+				t.Errorf("NewAttachment() url = %q", got.URL)
+			}
+			if got.StorageKey() != workspaceID+"/"+channelID+"/"+got.ID {
+				t.Errorf("StorageKey() = %q", got.StorageKey())
+			}
+		})
+	}
+}
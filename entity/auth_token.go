@@ -0,0 +1,142 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+// TokenTypeBearer はAuthorizationヘッダに指定するトークンの種類
+const TokenTypeBearer = "Bearer"
+
+// opaqueTokenBytes はリフレッシュトークン・ワンタイムトークンの乱数のバイト数
+const opaqueTokenBytes = 32
+
+const (
+	OneTimeTokenPurposeEmailVerification = "email_verification"
+	OneTimeTokenPurposePasswordReset     = "password_reset"
+	// パスワードを確認済みで、二要素認証のコードを待っているログイン
+	OneTimeTokenPurposeTwoFactorChallenge = "two_factor_challenge"
+)
+
+// AuthTokens はログイン・トークンの更新時にクライアントへ返すトークン
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // アクセストークンの有効期間(秒)
+}
+
+func NewAuthTokens(accessToken, refreshToken string, expiresIn time.Duration) *AuthTokens {
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    TokenTypeBearer,
+		ExpiresIn:    int(expiresIn.Seconds()),
+	}
+}
+
+// RefreshToken はサーバ側で保持するリフレッシュトークン。トークンそのものは保存せず、ハッシュ値で照合する
+// 使用する度に同じFamilyIDの新しいトークンに置き換え、使用済みのトークンが再利用された場合はFamily全体を無効にする
+type RefreshToken struct {
+	Token     string    `json:"-"` // 発行時のみ設定される
+	TokenHash string    `json:"token_hash"`
+	UserID    string    `json:"user_id"`
+	FamilyID  string    `json:"family_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewRefreshToken は新しいリフレッシュトークンを発行する。familyIDが空の場合は新しいFamilyを作る
+func NewRefreshToken(userID, familyID string, ttl time.Duration) (*RefreshToken, error) {
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if ttl <= 0 {
+		log.Error("TTL must be positive")
+		return nil, fmt.Errorf("ttl must be positive")
+	}
+	if familyID == "" {
+		familyID = uuid.New().String()
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		log.Error("Failed to generate refresh token", log.Ferror(err))
+		return nil, err
+	}
+
+	return &RefreshToken{
+		Token:     token,
+		TokenHash: HashToken(token),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// HashToken はリフレッシュトークン・ワンタイムトークンを保存・照合する為のハッシュ値を返す
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// IsExpired はリフレッシュトークンの有効期限が切れているかを返す
+func (rt *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(rt.ExpiresAt)
+}
+
+// OneTimeToken はメールアドレスの確認・パスワードの再設定・二要素認証のログインで使う一度だけ使えるトークン
+// リフレッシュトークンと同様に、トークンそのものは保存せずハッシュ値で照合する
+type OneTimeToken struct {
+	Token     string // 発行時のみ設定される
+	TokenHash string
+	Purpose   string
+	UserID    string
+	TTL       time.Duration
+}
+
+func NewOneTimeToken(purpose, userID string, ttl time.Duration) (*OneTimeToken, error) {
+	switch purpose {
+	case OneTimeTokenPurposeEmailVerification, OneTimeTokenPurposePasswordReset, OneTimeTokenPurposeTwoFactorChallenge:
+	default:
+		log.Error("Invalid purpose", log.Fstring("purpose", purpose))
+		return nil, fmt.Errorf("invalid purpose")
+	}
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if ttl <= 0 {
+		log.Error("TTL must be positive")
+		return nil, fmt.Errorf("ttl must be positive")
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		log.Error("Failed to generate one-time token", log.Ferror(err))
+		return nil, err
+	}
+
+	return &OneTimeToken{
+		Token:     token,
+		TokenHash: HashToken(token),
+		Purpose:   purpose,
+		UserID:    userID,
+		TTL:       ttl,
+	}, nil
+}
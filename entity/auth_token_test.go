@@ -0,0 +1,147 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	familyID := uuid.New().String()
+
+	patterns := []struct {
+		name     string
+		userID   string
+		familyID string
+		ttl      time.Duration
+		wantErr  error
+	}{
+		{
+			name:   "Success: new family",
+			userID: userID,
+			ttl:    time.Hour,
+		},
+		{
+			name:     "Success: rotated in the same family",
+			userID:   userID,
+			familyID: familyID,
+			ttl:      time.Hour,
+		},
+		{
+			name:    "Fail: userID is required",
+			ttl:     time.Hour,
+			wantErr: errors.New("userID is required"),
+		},
+		{
+			name:    "Fail: ttl must be positive",
+			userID:  userID,
+			wantErr: errors.New("ttl must be positive"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewRefreshToken(tt.userID, tt.familyID, tt.ttl)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewRefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewRefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Token == "" || got.TokenHash != HashToken(got.Token) || got.TokenHash == got.Token {
+				t.Errorf("NewRefreshToken() token = %q, hash = %q", got.Token, got.TokenHash)
+			}
+			if tt.familyID != "" && got.FamilyID != tt.familyID {
+				t.Errorf("NewRefreshToken() familyID = %q, want %q", got.FamilyID, tt.familyID)
+			}
+			if tt.familyID == "" && got.FamilyID == "" {
+				t.Error("NewRefreshToken() familyID is empty")
+			}
+			if got.IsExpired(time.Now()) || !got.IsExpired(time.Now().Add(tt.ttl)) {
+				t.Errorf("NewRefreshToken() expiresAt = %v", got.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestEntity_NewOneTimeToken(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name    string
+		purpose string
+		userID  string
+		ttl     time.Duration
+		wantErr error
+	}{
+		{
+			name:    "Success: email verification",
+			purpose: OneTimeTokenPurposeEmailVerification,
+			userID:  userID,
+			ttl:     time.Hour,
+		},
+		{
+			name:    "Success: password reset",
+			purpose: OneTimeTokenPurposePasswordReset,
+			userID:  userID,
+			ttl:     time.Hour,
+		},
+		{
+			name:    "Success: two factor challenge",
+			purpose: OneTimeTokenPurposeTwoFactorChallenge,
+			userID:  userID,
+			ttl:     5 * time.Minute,
+		},
+		{
+			name:    "Fail: invalid purpose",
+			purpose: "login",
+			userID:  userID,
+			ttl:     time.Hour,
+			wantErr: errors.New("invalid purpose"),
+		},
+		{
+			name:    "Fail: userID is required",
+			purpose: OneTimeTokenPurposePasswordReset,
+			ttl:     time.Hour,
+			wantErr: errors.New("userID is required"),
+		},
+		{
+			name:    "Fail: ttl must be positive",
+			purpose: OneTimeTokenPurposePasswordReset,
+			userID:  userID,
+			wantErr: errors.New("ttl must be positive"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewOneTimeToken(tt.purpose, tt.userID, tt.ttl)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewOneTimeToken() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewOneTimeToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Token == "" || got.TokenHash != HashToken(got.Token) || got.Purpose != tt.purpose || got.UserID != tt.userID {
+				t.Errorf("NewOneTimeToken() = %+v", got)
+			}
+		})
+	}
+}
@@ -1,35 +1,112 @@
 package entity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
 )
 
+const (
+	ChannelKindChannel       = "channel"
+	ChannelKindDirectMessage = "direct_message"
+)
+
+// MaxDirectMessageParticipants はグループDMに参加できる最大人数
+const MaxDirectMessageParticipants = 9
+
 type Channel struct {
-	ID      string
-	Name    string
-	Private bool
-	Clients map[*Client]bool
+	ID              string
+	WorkspaceID     string
+	Name            string
+	Private         bool
+	Kind            string
+	ConversationKey string // ConversationKey identifies a direct message by its participants
+	Clients         map[*Client]bool
+}
+
+// DirectMessage はDMのChannelとその参加者
+type DirectMessage struct {
+	Channel *Channel
+	UserIDs []string
 }
 
-func NewChannel(id, name string, private bool) (*Channel, error) {
+func NewChannel(id, workspaceID, name string, private bool) (*Channel, error) {
 	if id == "" {
 		id = uuid.New().String()
 	}
+	if workspaceID == "" {
+		log.Error("workspaceID is required")
+		return nil, errors.New("workspaceID is required")
+	}
 	if name == "" {
 		log.Error("name is required")
 		return nil, errors.New("name is required")
 	}
 	return &Channel{
-		ID:      id,
-		Name:    name,
-		Private: private,
-		Clients: make(map[*Client]bool),
+		ID:          id,
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Private:     private,
+		Kind:        ChannelKindChannel,
+		Clients:     make(map[*Client]bool),
 	}, nil
 }
 
+// NewDirectMessageChannel は参加者の組み合わせ毎に一意となるDMのChannelを生成する
+// DMは参加者のみが閲覧できるプライベートChannelとして扱う
+func NewDirectMessageChannel(id, workspaceID string, userIDs []string) (*Channel, error) {
+	participants, err := NewParticipants(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	key := conversationKey(participants)
+	channel, err := NewChannel(id, workspaceID, "dm-"+key[:47], true)
+	if err != nil {
+		return nil, err
+	}
+	channel.Kind = ChannelKindDirectMessage
+	channel.ConversationKey = key
+	return channel, nil
+}
+
+// NewParticipants はDMの参加者を重複を除いて並べ替え、人数を検証する
+func NewParticipants(userIDs []string) ([]string, error) {
+	seen := make(map[string]bool, len(userIDs))
+	participants := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		participants = append(participants, userID)
+	}
+	if len(participants) < 2 {
+		log.Error("at least two participants are required")
+		return nil, errors.New("at least two participants are required")
+	}
+	if len(participants) > MaxDirectMessageParticipants {
+		log.Error("too many participants")
+		return nil, errors.New("too many participants")
+	}
+	sort.Strings(participants)
+	return participants, nil
+}
+
+func conversationKey(participants []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(participants, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsDirectMessage はChannelがDMであるかを返す
+func (c *Channel) IsDirectMessage() bool {
+	return c.Kind == ChannelKindDirectMessage
+}
+
 func (c *Channel) RegisterClientInChannel(client *Client) {
 	if client == nil {
 		return
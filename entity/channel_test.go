@@ -13,13 +13,15 @@ func TestEntity_NewChannel(t *testing.T) {
 	t.Parallel()
 
 	id := uuid.New().String()
+	workspaceID := uuid.New().String()
 
 	patterns := []struct {
 		name string
 		arg  struct {
-			id      string
-			name    string
-			private bool
+			id          string
+			workspaceID string
+			name        string
+			private     bool
 		}
 		want struct {
 			channel *Channel
@@ -29,23 +31,27 @@ func TestEntity_NewChannel(t *testing.T) {
 		{
 			name: "Success: id is not empty",
 			arg: struct {
-				id      string
-				name    string
-				private bool
+				id          string
+				workspaceID string
+				name        string
+				private     bool
 			}{
-				id:      id,
-				name:    "channel",
-				private: false,
+				id:          id,
+				workspaceID: workspaceID,
+				name:        "channel",
+				private:     false,
 			},
 			want: struct {
 				channel *Channel
 				err     error
 			}{
 				channel: &Channel{
-					ID:      id,
-					Name:    "channel",
-					Private: false,
-					Clients: make(map[*Client]bool),
+					ID:          id,
+					WorkspaceID: workspaceID,
+					Name:        "channel",
+					Private:     false,
+					Kind:        ChannelKindChannel,
+					Clients:     make(map[*Client]bool),
 				},
 				err: nil,
 			},
@@ -53,36 +59,63 @@ func TestEntity_NewChannel(t *testing.T) {
 		{
 			name: "Success: id is empty",
 			arg: struct {
-				id      string
-				name    string
-				private bool
+				id          string
+				workspaceID string
+				name        string
+				private     bool
 			}{
-				id:      "",
-				name:    "channel",
-				private: false,
+				id:          "",
+				workspaceID: workspaceID,
+				name:        "channel",
+				private:     false,
 			},
 			want: struct {
 				channel *Channel
 				err     error
 			}{
 				channel: &Channel{
-					Name:    "channel",
-					Private: false,
-					Clients: make(map[*Client]bool),
+					WorkspaceID: workspaceID,
+					Name:        "channel",
+					Private:     false,
+					Kind:        ChannelKindChannel,
+					Clients:     make(map[*Client]bool),
 				},
 				err: nil,
 			},
 		},
+		{
+			name: "Fail: workspaceID is empty",
+			arg: struct {
+				id          string
+				workspaceID string
+				name        string
+				private     bool
+			}{
+				id:          id,
+				workspaceID: "",
+				name:        "channel",
+				private:     false,
+			},
+			want: struct {
+				channel *Channel
+				err     error
+			}{
+				channel: nil,
+				err:     errors.New("workspaceID is required"),
+			},
+		},
 		{
 			name: "Fail: name is empty",
 			arg: struct {
-				id      string
-				name    string
-				private bool
+				id          string
+				workspaceID string
+				name        string
+				private     bool
 			}{
-				id:      id,
-				name:    "",
-				private: false,
+				id:          id,
+				workspaceID: workspaceID,
+				name:        "",
+				private:     false,
 			},
 			want: struct {
 				channel *Channel
@@ -99,7 +132,7 @@ func TestEntity_NewChannel(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			channel, err := NewChannel(tt.arg.id, tt.arg.name, tt.arg.private)
+			channel, err := NewChannel(tt.arg.id, tt.arg.workspaceID, tt.arg.name, tt.arg.private)
 
 			if (err != nil) != (tt.want.err != nil) {
 				t.Errorf("NewChannel() error = %v, wantErr %v", err, tt.want.err)
@@ -114,6 +147,75 @@ func TestEntity_NewChannel(t *testing.T) {
 	}
 }
 
+func TestEntity_NewDirectMessageChannel(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID1 := uuid.New().String()
+	userID2 := uuid.New().String()
+	userID3 := uuid.New().String()
+
+	tooMany := make([]string, MaxDirectMessageParticipants+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New().String()
+	}
+
+	patterns := []struct {
+		name    string
+		userIDs []string
+		wantErr error
+	}{
+		{
+			name:    "Success: 1:1",
+			userIDs: []string{userID1, userID2},
+			wantErr: nil,
+		},
+		{
+			name:    "Success: group",
+			userIDs: []string{userID3, userID1, userID2, userID1},
+			wantErr: nil,
+		},
+		{
+			name:    "Fail: only one participant",
+			userIDs: []string{userID1, userID1},
+			wantErr: errors.New("at least two participants are required"),
+		},
+		{
+			name:    "Fail: too many participants",
+			userIDs: tooMany,
+			wantErr: errors.New("too many participants"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			channel, err := NewDirectMessageChannel("", workspaceID, tt.userIDs)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewDirectMessageChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewDirectMessageChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !channel.IsDirectMessage() || !channel.Private || len(channel.Name) > 50 {
+				t.Errorf("NewDirectMessageChannel() got unexpected channel: %+v", channel)
+			}
+		})
+	}
+
+	// 参加者の順序に関わらず同じDMとなる
+	a, _ := NewDirectMessageChannel("", workspaceID, []string{userID1, userID2, userID3})
+	b, _ := NewDirectMessageChannel("", workspaceID, []string{userID3, userID2, userID1})
+	if a.ConversationKey != b.ConversationKey || a.Name != b.Name {
+		t.Errorf("ConversationKey differs: %v, %v", a.ConversationKey, b.ConversationKey)
+	}
+}
+
 func TestEntity_Channel_RegisterClientInChannel(t *testing.T) {
 	t.Parallel()
 
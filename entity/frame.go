@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+const (
+	AckAction   = "ACK"
+	ErrorAction = "ERROR"
+)
+
+// ErrorFrameのCodeに設定する、クライアントが判別可能なエラーコード
+const (
+	ErrorCodeInvalidMessage = "INVALID_MESSAGE"
+	ErrorCodeUnknownAction  = "UNKNOWN_ACTION"
+	ErrorCodeNotFound       = "NOT_FOUND"
+	ErrorCodeForbidden      = "FORBIDDEN"
+	ErrorCodeInternal       = "INTERNAL_ERROR"
+)
+
+// AckFrame はクライアントのリクエストが処理されたことを、リクエストしたクライアントにのみ通知する
+type AckFrame struct {
+	Action    string    `json:"action"`
+	RequestID string    `json:"request_id,omitempty"`
+	MessageID string    `json:"message_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewAckFrame(requestID, messageID string, createdAt time.Time) *AckFrame {
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &AckFrame{
+		Action:    AckAction,
+		RequestID: requestID,
+		MessageID: messageID,
+		CreatedAt: createdAt,
+	}
+}
+
+func (af *AckFrame) Encode() ([]byte, error) {
+	json, err := json.Marshal(af)
+	if err != nil {
+		log.Error("Failed to encode ack frame", log.Ferror(err))
+		return nil, err
+	}
+	return json, nil
+}
+
+// ErrorFrame はクライアントのリクエストが失敗したことを、リクエストしたクライアントにのみ通知する
+type ErrorFrame struct {
+	Action    string `json:"action"`
+	RequestID string `json:"request_id,omitempty"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+}
+
+func NewErrorFrame(requestID, code, message string) (*ErrorFrame, error) {
+	if code == "" {
+		log.Error("code is required")
+		return nil, errors.New("code is required")
+	}
+	return &ErrorFrame{
+		Action:    ErrorAction,
+		RequestID: requestID,
+		Code:      code,
+		Message:   message,
+	}, nil
+}
+
+func (ef *ErrorFrame) Encode() ([]byte, error) {
+	json, err := json.Marshal(ef)
+	if err != nil {
+		log.Error("Failed to encode error frame", log.Ferror(err))
+		return nil, err
+	}
+	return json, nil
+}
@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewAckFrame(t *testing.T) {
+	t.Parallel()
+
+	requestID := uuid.New().String()
+	msgID := uuid.New().String()
+	createdAt := time.Now()
+
+	ack := NewAckFrame(requestID, msgID, createdAt)
+	want := &AckFrame{
+		Action:    AckAction,
+		RequestID: requestID,
+		MessageID: msgID,
+		CreatedAt: createdAt,
+	}
+	if d := cmp.Diff(want, ack); len(d) != 0 {
+		t.Errorf("NewAckFrame() mismatch (-want +got):\n%s", d)
+	}
+
+	ack = NewAckFrame(requestID, msgID, time.Time{})
+	if ack.CreatedAt.IsZero() {
+		t.Error("NewAckFrame() CreatedAt should be set")
+	}
+}
+
+func TestEntity_NewErrorFrame(t *testing.T) {
+	t.Parallel()
+
+	requestID := uuid.New().String()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			requestID string
+			code      string
+			message   string
+		}
+		want struct {
+			frame *ErrorFrame
+			err   error
+		}
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				requestID string
+				code      string
+				message   string
+			}{
+				requestID: requestID,
+				code:      ErrorCodeNotFound,
+				message:   "channel not found",
+			},
+			want: struct {
+				frame *ErrorFrame
+				err   error
+			}{
+				frame: &ErrorFrame{
+					Action:    ErrorAction,
+					RequestID: requestID,
+					Code:      ErrorCodeNotFound,
+					Message:   "channel not found",
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "Fail: code is empty",
+			arg: struct {
+				requestID string
+				code      string
+				message   string
+			}{
+				requestID: requestID,
+				code:      "",
+				message:   "channel not found",
+			},
+			want: struct {
+				frame *ErrorFrame
+				err   error
+			}{
+				frame: nil,
+				err:   errors.New("code is required"),
+			},
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			frame, err := NewErrorFrame(tt.arg.requestID, tt.arg.code, tt.arg.message)
+			if (err != nil) != (tt.want.err != nil) {
+				t.Errorf("NewErrorFrame() error = %v, wantErr %v", err, tt.want.err)
+			} else if err != nil && tt.want.err != nil && err.Error() != tt.want.err.Error() {
+				t.Errorf("NewErrorFrame() error = %v, wantErr %v", err, tt.want.err)
+			}
+
+			if d := cmp.Diff(tt.want.frame, frame, cmpopts.EquateEmpty()); len(d) != 0 {
+				t.Errorf("NewErrorFrame() mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -259,6 +259,7 @@ func TestEntity_Hub_FindChannelByID(t *testing.T) {
 	channelID := uuid.New().String()
 	channel1, _ := NewChannel(
 		channelID,
+		uuid.New().String(),
 		"name",
 		false,
 	)
@@ -326,6 +327,7 @@ func TestEntity_Hub_FindChannelByName(t *testing.T) {
 	channelID := uuid.New().String()
 	channel1, _ := NewChannel(
 		channelID,
+		uuid.New().String(),
 		"channel1",
 		false,
 	)
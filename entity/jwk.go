@@ -0,0 +1,20 @@
+package entity
+
+// JWK はトークンの検証に使う公開鍵(RFC 7517)
+// Ktyにより使われるフィールドが異なる(RSA: N/E, EC: Crv/X/Y, OKP: Crv/X)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet は/.well-known/jwks.jsonで公開する鍵の一覧
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
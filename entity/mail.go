@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+// Mail はユーザに送信するテキスト形式のメール
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+func NewMail(to, subject, body string) (*Mail, error) {
+	if to == "" {
+		log.Error("To is required", log.Fstring("to", to))
+		return nil, fmt.Errorf("to is required")
+	}
+	if subject == "" {
+		log.Error("Subject is required", log.Fstring("to", to))
+		return nil, fmt.Errorf("subject is required")
+	}
+	// ヘッダに改行を含めると任意のヘッダを追加できてしまう
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		log.Error("Mail header contains line breaks", log.Fstring("to", to))
+		return nil, fmt.Errorf("mail header must not contain line breaks")
+	}
+	return &Mail{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
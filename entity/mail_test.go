@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEntity_NewMail(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		to      string
+		subject string
+		wantErr error
+	}{
+		{
+			name:    "Success",
+			to:      "test@gmail.com",
+			subject: "Verify your email address",
+		},
+		{
+			name:    "Fail: to is required",
+			subject: "Verify your email address",
+			wantErr: errors.New("to is required"),
+		},
+		{
+			name:    "Fail: subject is required",
+			to:      "test@gmail.com",
+			wantErr: errors.New("subject is required"),
+		},
+		{
+			name:    "Fail: header injection",
+			to:      "test@gmail.com\r\nBcc: attacker@example.com",
+			subject: "Verify your email address",
+			wantErr: errors.New("mail header must not contain line breaks"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewMail(tt.to, tt.subject, "body")
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewMail() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewMail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"fmt"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+type MembershipChannel struct {
+	UserID      string
+	WorkspaceID string
+	ChannelID   string
+	IsOwner     bool
+}
+
+func NewMembershipChannel(userID, workspaceID, channelID string, isOwner bool) (*MembershipChannel, error) {
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if workspaceID == "" {
+		log.Error("WorkspaceID is required", log.Fstring("workspaceID", workspaceID))
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if channelID == "" {
+		log.Error("ChannelID is required", log.Fstring("channelID", channelID))
+		return nil, fmt.Errorf("channelID is required")
+	}
+	return &MembershipChannel{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		ChannelID:   channelID,
+		IsOwner:     isOwner,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewMembershipChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			userID      string
+			workspaceID string
+			channelID   string
+			isOwner     bool
+		}
+		wantErr error
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				userID      string
+				workspaceID string
+				channelID   string
+				isOwner     bool
+			}{
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+				isOwner:     true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: userID is required",
+			arg: struct {
+				userID      string
+				workspaceID string
+				channelID   string
+				isOwner     bool
+			}{
+				userID:      "",
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: fmt.Errorf("userID is required"),
+		},
+		{
+			name: "Fail: workspaceID is required",
+			arg: struct {
+				userID      string
+				workspaceID string
+				channelID   string
+				isOwner     bool
+			}{
+				userID:      userID,
+				workspaceID: "",
+				channelID:   channelID,
+			},
+			wantErr: fmt.Errorf("workspaceID is required"),
+		},
+		{
+			name: "Fail: channelID is required",
+			arg: struct {
+				userID      string
+				workspaceID string
+				channelID   string
+				isOwner     bool
+			}{
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   "",
+			},
+			wantErr: fmt.Errorf("channelID is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewMembershipChannel(tt.arg.userID, tt.arg.workspaceID, tt.arg.channelID, tt.arg.isOwner)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewMembershipChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewMembershipChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+const MentionAction = "MENTION"
+
+const (
+	MentionKindUser    = "user"
+	MentionKindChannel = "channel"
+	MentionKindHere    = "here"
+)
+
+// mentionPattern は行頭または空白の直後の「@」から次の空白までをメンションとして扱う
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// Mention はメッセージでメンションされたユーザ
+type Mention struct {
+	MessageID   string
+	UserID      string // UserID is the ID of the mentioned user
+	WorkspaceID string
+	ChannelID   string
+	Kind        string
+	CreatedAt   time.Time
+}
+
+func NewMention(messageID, userID, workspaceID, channelID, kind string, createdAt time.Time) (*Mention, error) {
+	if messageID == "" {
+		log.Error("MessageID is required", log.Fstring("messageID", messageID))
+		return nil, fmt.Errorf("messageID is required")
+	}
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if workspaceID == "" {
+		log.Error("WorkspaceID is required", log.Fstring("workspaceID", workspaceID))
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if channelID == "" {
+		log.Error("ChannelID is required", log.Fstring("channelID", channelID))
+		return nil, fmt.Errorf("channelID is required")
+	}
+	if kind != MentionKindUser && kind != MentionKindChannel && kind != MentionKindHere {
+		log.Error("Invalid mention kind", log.Fstring("kind", kind))
+		return nil, fmt.Errorf("invalid mention kind")
+	}
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &Mention{
+		MessageID:   messageID,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		ChannelID:   channelID,
+		Kind:        kind,
+		CreatedAt:   createdAt,
+	}, nil
+}
+
+// MentionTargets はメッセージ本文から抽出したメンションの宛先
+type MentionTargets struct {
+	Names   []string
+	Channel bool
+	Here    bool
+}
+
+func (mt MentionTargets) IsEmpty() bool {
+	return len(mt.Names) == 0 && !mt.Channel && !mt.Here
+}
+
+// ParseMentions は本文から@name, @channel, @hereを抽出する
+// 名前の末尾の句読点は取り除き、同じ名前は一度だけ含める
+func ParseMentions(text string) MentionTargets {
+	var targets MentionTargets
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(match[1], ".,!?:;)]}\"'")
+		switch {
+		case name == "":
+			continue
+		case name == MentionKindChannel:
+			targets.Channel = true
+		case name == MentionKindHere:
+			targets.Here = true
+		case !seen[name]:
+			seen[name] = true
+			targets.Names = append(targets.Names, name)
+		}
+	}
+	return targets
+}
+
+// MentionedMessage はメンションの種類とメンションを含むメッセージ
+type MentionedMessage struct {
+	Kind    string   `json:"kind"`
+	Message *Message `json:"message"`
+}
+
+// MentionEvent はメンションされたユーザの全ての接続に送信するイベント
+type MentionEvent struct {
+	Action string `json:"action"`
+	MentionedMessage
+}
+
+func NewMentionEvent(kind string, message *Message) *MentionEvent {
+	return &MentionEvent{
+		Action:           MentionAction,
+		MentionedMessage: MentionedMessage{Kind: kind, Message: message},
+	}
+}
+
+func (me *MentionEvent) Encode() ([]byte, error) {
+	json, err := json.Marshal(me)
+	if err != nil {
+		log.Error("Failed to encode mention event", log.Ferror(err))
+		return nil, err
+	}
+	return json, nil
+}
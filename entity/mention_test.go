@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEntity_ParseMentions(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name string
+		text string
+		want MentionTargets
+	}{
+		{
+			name: "no mentions",
+			text: "Hello, World!",
+			want: MentionTargets{},
+		},
+		{
+			name: "names",
+			text: "@alice and @bob, please review. cc @alice",
+			want: MentionTargets{Names: []string{"alice", "bob"}},
+		},
+		{
+			name: "channel and here",
+			text: "@channel meeting now @here",
+			want: MentionTargets{Channel: true, Here: true},
+		},
+		{
+			name: "email address is not a mention",
+			text: "mail me at alice@example.com",
+			want: MentionTargets{},
+		},
+		{
+			name: "mention at line start",
+			text: "line\n@carol!",
+			want: MentionTargets{Names: []string{"carol"}},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ParseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
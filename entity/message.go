@@ -3,7 +3,9 @@ package entity
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
@@ -17,18 +19,35 @@ const (
 	CreateMessageAction       = "CREATE_MESSAGE"
 	DeleteMessageAction       = "DELETE_MESSAGE"
 	UpdateMessageAction       = "UPDATE_MESSAGE"
+	ReplyMessageAction        = "REPLY_MESSAGE"
+	ListThreadMessagesAction  = "LIST_THREAD_MESSAGES"
+	UpdateThreadAction        = "UPDATE_THREAD" // UpdateThreadAction notifies the updated reply summary of a thread's parent message
+	AddReactionAction         = "ADD_REACTION"
+	RemoveReactionAction      = "REMOVE_REACTION"
+	TypingStartAction         = "TYPING_START"
+	TypingStopAction          = "TYPING_STOP"
 	CreatePublicChannelAction = "CREATE_PUBLIC_CHANNEL"
 	JoinPublicChannelAction   = "JOIN_PUBLIC_CHANNEL"
 	LeavePublicChannelAction  = "LEAVE_PUBLIC_CHANNEL"
 	NoneAction                = "NONE"
 )
 
+// MaxMessageTextLength はメッセージ本文の最大文字数
+const MaxMessageTextLength = 4000
+
+// DeletedMessageText は削除されたメッセージの代わりに表示する本文
+const DeletedMessageText = "message deleted"
+
 var validActions = map[string]bool{
 	GetMessagesAction:         true,
 	ListMessagesAction:        true,
 	CreateMessageAction:       true,
 	DeleteMessageAction:       true,
 	UpdateMessageAction:       true,
+	ReplyMessageAction:        true,
+	ListThreadMessagesAction:  true,
+	AddReactionAction:         true,
+	RemoveReactionAction:      true,
 	CreatePublicChannelAction: true,
 	JoinPublicChannelAction:   true,
 	LeavePublicChannelAction:  true,
@@ -42,7 +61,23 @@ type Message struct {
 	Text        string    `json:"text"`
 	CreatedAt   time.Time `json:"created_at"`
 	Action      string    `json:"action"`
-	TargetID    string    `json:"target_id"` // TargetID is the ID of the channel or user the message is intended for
+	TargetID    string    `json:"target_id"`            // TargetID is the ID of the channel or direct message the message is intended for
+	RequestID   string    `json:"request_id,omitempty"` // RequestID is the client-generated ID echoed back in ACK/ERROR frames
+	// スレッド
+	ParentID          string     `json:"parent_id,omitempty"`            // ParentID is the ID of the message this message replies to
+	AlsoSendToChannel bool       `json:"also_send_to_channel,omitempty"` // AlsoSendToChannel shows the reply in the channel as well as the thread
+	ReplyCount        int        `json:"reply_count"`
+	LastReplyAt       *time.Time `json:"last_reply_at,omitempty"`
+	// リアクション
+	Reactions []ReactionCount `json:"reactions,omitempty"`
+	// 添付ファイル
+	AttachmentIDs []string     `json:"attachment_ids,omitempty"` // AttachmentIDs are the IDs of uploaded files to attach when creating the message
+	Attachments   []Attachment `json:"attachments,omitempty"`
+	// 編集・削除
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// メンション
+	Mentions []Mention `json:"-"` // Mentions are the mentions saved with the message, used to notify the mentioned users
 	// SenderID  string    `json:"sender_id"` // SenderID is the ID of the user who sent the message
 }
 
@@ -58,11 +93,14 @@ func NewMessage(id, userID, workspaceID, text, action, targetID string, createdA
 		log.Error("workspaceID is required")
 		return nil, errors.New("workspaceID is required")
 	}
-	if text == "" {
-		// TODO: textの長さ制限を設ける
+	if strings.TrimSpace(text) == "" {
 		log.Error("text is required")
 		return nil, errors.New("text is required")
 	}
+	if utf8.RuneCountInString(text) > MaxMessageTextLength {
+		log.Error("text is too long", log.Fint("length", utf8.RuneCountInString(text)))
+		return nil, errors.New("text is too long")
+	}
 	if !validActions[action] {
 		log.Error("invalid action")
 		return nil, errors.New("invalid action")
@@ -72,9 +110,10 @@ func NewMessage(id, userID, workspaceID, text, action, targetID string, createdA
 		return nil, errors.New("targetID is required")
 	}
 	if createdAt.IsZero() {
-		now := time.Now()
-		createdAt = now
+		createdAt = time.Now()
 	}
+	// DBにはマイクロ秒まで保存される為、カーソルに使う投稿日時を保存値と揃える
+	createdAt = createdAt.Truncate(time.Microsecond)
 	return &Message{
 		ID:          id,
 		UserID:      userID,
@@ -86,6 +125,41 @@ func NewMessage(id, userID, workspaceID, text, action, targetID string, createdA
 	}, nil
 }
 
+// NewReplyMessage はスレッドへの返信メッセージを生成する
+func NewReplyMessage(id, userID, workspaceID, text, targetID, parentID string, alsoSendToChannel bool, createdAt time.Time) (*Message, error) {
+	if parentID == "" {
+		log.Error("parentID is required")
+		return nil, errors.New("parentID is required")
+	}
+	message, err := NewMessage(id, userID, workspaceID, text, ReplyMessageAction, targetID, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	message.ParentID = parentID
+	message.AlsoSendToChannel = alsoSendToChannel
+	return message, nil
+}
+
+// IsReply はメッセージがスレッドへの返信であるかを返す
+func (m *Message) IsReply() bool {
+	return m.ParentID != ""
+}
+
+// IsDeleted はメッセージが削除済みであるかを返す
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// Redact は削除済みのメッセージの本文とリアクション、添付ファイルを隠す
+func (m *Message) Redact() {
+	if !m.IsDeleted() {
+		return
+	}
+	m.Text = DeletedMessageText
+	m.Reactions = nil
+	m.Attachments = nil
+}
+
 func (m *Message) Encode() ([]byte, error) {
 	json, err := json.Marshal(m)
 	if err != nil {
@@ -98,7 +172,8 @@ func (m *Message) Encode() ([]byte, error) {
 type Messages struct {
 	Messages []*Message `json:"messages"`
 	Action   string     `json:"action"`
-	TargetID string     `json:"target_id"` // TargetID is the ID of the channel or user the message is intended for
+	TargetID string     `json:"target_id"`           // TargetID is the ID of the channel or direct message the message is intended for
+	ParentID string     `json:"parent_id,omitempty"` // ParentID is the ID of the thread's parent message when listing a thread
 	// SenderID  string    `json:"sender_id"` // SenderID is the ID of the user who sent the message
 }
 
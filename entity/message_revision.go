@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+// MessageRevision は編集される前のメッセージ本文
+type MessageRevision struct {
+	ID        string    `json:"id"`
+	MessageID string    `json:"message_id"`
+	UserID    string    `json:"user_id"` // UserID is the ID of the user who edited the message
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewMessageRevision(id, messageID, userID, text string, createdAt time.Time) (*MessageRevision, error) {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	if messageID == "" {
+		log.Error("MessageID is required", log.Fstring("messageID", messageID))
+		return nil, fmt.Errorf("messageID is required")
+	}
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &MessageRevision{
+		ID:        id,
+		MessageID: messageID,
+		UserID:    userID,
+		Text:      text,
+		CreatedAt: createdAt,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewMessageRevision(t *testing.T) {
+	t.Parallel()
+
+	messageID := uuid.New().String()
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			messageID string
+			userID    string
+		}
+		wantErr error
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				messageID string
+				userID    string
+			}{
+				messageID: messageID,
+				userID:    userID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: messageID is required",
+			arg: struct {
+				messageID string
+				userID    string
+			}{
+				messageID: "",
+				userID:    userID,
+			},
+			wantErr: errors.New("messageID is required"),
+		},
+		{
+			name: "Fail: userID is required",
+			arg: struct {
+				messageID string
+				userID    string
+			}{
+				messageID: messageID,
+				userID:    "",
+			},
+			wantErr: errors.New("userID is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			revision, err := NewMessageRevision("", tt.arg.messageID, tt.arg.userID, "text", time.Time{})
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewMessageRevision() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewMessageRevision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (revision.ID == "" || revision.CreatedAt.IsZero()) {
+				t.Errorf("NewMessageRevision() got = %v, want ID and CreatedAt to be set", revision)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -182,6 +183,33 @@ func TestEntity_NewMessage(t *testing.T) {
 				err:     errors.New("text is required"),
 			},
 		},
+		{
+			name: "Fail: text is too long",
+			arg: struct {
+				id          string
+				userID      string
+				workspaceID string
+				text        string
+				action      string
+				targetID    string
+				createdAt   time.Time
+			}{
+				id:          msgID,
+				userID:      userID,
+				workspaceID: workspaceID,
+				text:        strings.Repeat("あ", MaxMessageTextLength+1),
+				action:      CreateMessageAction,
+				targetID:    channelID,
+				createdAt:   time.Time{},
+			},
+			want: struct {
+				message *Message
+				err     error
+			}{
+				message: nil,
+				err:     errors.New("text is too long"),
+			},
+		},
 		{
 			name: "Fail: action is invalid",
 			arg: struct {
@@ -258,6 +286,118 @@ func TestEntity_NewMessage(t *testing.T) {
 	}
 }
 
+// 投稿日時はDBに保存される精度(マイクロ秒)に揃える
+func TestEntity_NewMessage_CreatedAtPrecision(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	message, err := NewMessage("", uuid.New().String(), uuid.New().String(), "text", CreateMessageAction, uuid.New().String(), createdAt)
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 123456000, time.UTC); !message.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt got: %v, want: %v", message.CreatedAt, want)
+	}
+
+	message, err = NewMessage("", uuid.New().String(), uuid.New().String(), "text", CreateMessageAction, uuid.New().String(), time.Time{})
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if message.CreatedAt.Nanosecond()%int(time.Microsecond) != 0 {
+		t.Errorf("CreatedAt is not truncated to microseconds: %v", message.CreatedAt)
+	}
+}
+
+func TestEntity_NewReplyMessage(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	parentID := uuid.New().String()
+
+	patterns := []struct {
+		name     string
+		parentID string
+		want     struct {
+			message *Message
+			err     error
+		}
+	}{
+		{
+			name:     "Success",
+			parentID: parentID,
+			want: struct {
+				message *Message
+				err     error
+			}{
+				message: &Message{
+					UserID:            userID,
+					WorkspaceID:       workspaceID,
+					Text:              "reply",
+					Action:            ReplyMessageAction,
+					TargetID:          channelID,
+					ParentID:          parentID,
+					AlsoSendToChannel: true,
+				},
+				err: nil,
+			},
+		},
+		{
+			name:     "Fail: parentID is empty",
+			parentID: "",
+			want: struct {
+				message *Message
+				err     error
+			}{
+				message: nil,
+				err:     errors.New("parentID is required"),
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			message, err := NewReplyMessage("", userID, workspaceID, "reply", channelID, tt.parentID, true, time.Time{})
+
+			if (err != nil) != (tt.want.err != nil) {
+				t.Errorf("NewReplyMessage() error = %v, wantErr %v", err, tt.want.err)
+			} else if err != nil && tt.want.err != nil && err.Error() != tt.want.err.Error() {
+				t.Errorf("NewReplyMessage() error = %v, wantErr %v", err, tt.want.err)
+			}
+
+			if d := cmp.Diff(message, tt.want.message, cmpopts.IgnoreFields(Message{}, "ID", "CreatedAt")); len(d) != 0 {
+				t.Errorf("NewReplyMessage() mismatch (-got +want):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestEntity_Message_Redact(t *testing.T) {
+	t.Parallel()
+
+	deletedAt := time.Now()
+	message := &Message{
+		Text:        "text",
+		Reactions:   []ReactionCount{{Emoji: "👍", Count: 1}},
+		Attachments: []Attachment{{ID: uuid.New().String(), Name: "photo.png"}},
+		DeletedAt:   &deletedAt,
+	}
+	message.Redact()
+	if message.Text != DeletedMessageText || message.Reactions != nil || message.Attachments != nil {
+		t.Errorf("Redact() got = %v, want text %q and no reactions or attachments", message, DeletedMessageText)
+	}
+
+	message = &Message{Text: "text"}
+	message.Redact()
+	if message.Text != "text" {
+		t.Errorf("Redact() changed a message that is not deleted: %v", message)
+	}
+}
+
 func TestEntity_Message_Encode(t *testing.T) {
 	t.Parallel()
 
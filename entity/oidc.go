@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+// OIDCAuthRequest はOIDCプロバイダへリダイレクトしてからコールバックされるまで保持する認可リクエストの情報
+// Stateでコールバックと照合し、NonceはIDトークンのリプレイ、CodeVerifier(PKCE)は認可コードの横取りを防ぐ
+type OIDCAuthRequest struct {
+	State        string        `json:"state"`
+	Nonce        string        `json:"nonce"`
+	CodeVerifier string        `json:"code_verifier"`
+	TTL          time.Duration `json:"-"`
+}
+
+func NewOIDCAuthRequest(ttl time.Duration) (*OIDCAuthRequest, error) {
+	if ttl <= 0 {
+		log.Error("TTL must be positive")
+		return nil, fmt.Errorf("ttl must be positive")
+	}
+
+	// code_verifierは43文字以上(RFC 7636)。32バイトの乱数をBase64Urlエンコードすると43文字になる
+	r := &OIDCAuthRequest{TTL: ttl}
+	for _, v := range []*string{&r.State, &r.Nonce, &r.CodeVerifier} {
+		token, err := generateOpaqueToken()
+		if err != nil {
+			log.Error("Failed to generate oidc auth request", log.Ferror(err))
+			return nil, err
+		}
+		*v = token
+	}
+	return r, nil
+}
+
+// CodeChallenge はCodeVerifierのSHA-256ハッシュをBase64Urlエンコードしたもの(code_challenge_method=S256)
+func (r *OIDCAuthRequest) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(r.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// OIDCIdentity はIDトークンから取り出した外部アカウントの情報
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// UserIdentity は外部アカウント(IssuerとSubjectの組)とユーザの紐付け
+type UserIdentity struct {
+	Issuer    string
+	Subject   string
+	UserID    string
+	CreatedAt time.Time
+}
+
+func NewUserIdentity(issuer, subject, userID string) (*UserIdentity, error) {
+	if issuer == "" {
+		log.Error("Issuer is required", log.Fstring("issuer", issuer))
+		return nil, fmt.Errorf("issuer is required")
+	}
+	if subject == "" {
+		log.Error("Subject is required", log.Fstring("subject", subject))
+		return nil, fmt.Errorf("subject is required")
+	}
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	return &UserIdentity{
+		Issuer:    issuer,
+		Subject:   subject,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
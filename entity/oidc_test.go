@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewOIDCAuthRequest(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		ttl     time.Duration
+		wantErr error
+	}{
+		{
+			name: "Success",
+			ttl:  10 * time.Minute,
+		},
+		{
+			name:    "Fail: ttl must be positive",
+			wantErr: errors.New("ttl must be positive"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewOIDCAuthRequest(tt.ttl)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewOIDCAuthRequest() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewOIDCAuthRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.State == "" || got.Nonce == "" || got.State == got.Nonce || got.Nonce == got.CodeVerifier {
+				t.Errorf("NewOIDCAuthRequest() state = %q, nonce = %q, codeVerifier = %q", got.State, got.Nonce, got.CodeVerifier)
+			}
+			if len(got.CodeVerifier) < 43 {
+				t.Errorf("NewOIDCAuthRequest() codeVerifier is too short: %q", got.CodeVerifier)
+			}
+		})
+	}
+}
+
+func TestEntity_OIDCAuthRequest_CodeChallenge(t *testing.T) {
+	t.Parallel()
+
+	// RFC 7636 Appendix B
+	r := OIDCAuthRequest{CodeVerifier: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"}
+	if got, want := r.CodeChallenge(), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallenge() = %q, want %q", got, want)
+	}
+}
+
+func TestEntity_NewUserIdentity(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name    string
+		issuer  string
+		subject string
+		userID  string
+		wantErr error
+	}{
+		{
+			name:    "Success",
+			issuer:  "https://sso.example.com",
+			subject: "248289761001",
+			userID:  userID,
+		},
+		{
+			name:    "Fail: issuer is required",
+			subject: "248289761001",
+			userID:  userID,
+			wantErr: errors.New("issuer is required"),
+		},
+		{
+			name:    "Fail: subject is required",
+			issuer:  "https://sso.example.com",
+			userID:  userID,
+			wantErr: errors.New("subject is required"),
+		},
+		{
+			name:    "Fail: userID is required",
+			issuer:  "https://sso.example.com",
+			subject: "248289761001",
+			wantErr: errors.New("userID is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewUserIdentity(tt.issuer, tt.subject, tt.userID)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewUserIdentity() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewUserIdentity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Issuer != tt.issuer || got.Subject != tt.subject || got.UserID != tt.userID || got.CreatedAt.IsZero() {
+				t.Errorf("NewUserIdentity() = %+v", got)
+			}
+		})
+	}
+}
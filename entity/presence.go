@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+const (
+	PresenceAction    = "PRESENCE"
+	SetPresenceAction = "SET_PRESENCE"
+)
+
+// Presence はWorkspaceにおけるユーザの接続状態
+// ユーザが複数の接続を持つ場合、いずれかがonlineであればonline、全てawayであればawayとなる
+type Presence struct {
+	Action      string `json:"action"`
+	UserID      string `json:"user_id"`
+	WorkspaceID string `json:"workspace_id"`
+	Status      string `json:"status"`
+	Connections int    `json:"connections"`
+}
+
+// IsValidPresenceStatus は接続毎に設定できる状態であるかを返す
+func IsValidPresenceStatus(status string) bool {
+	return status == PresenceOnline || status == PresenceAway
+}
+
+// NewPresence は接続毎の状態からユーザの状態を集計する
+func NewPresence(workspaceID, userID string, statuses []string) (*Presence, error) {
+	if workspaceID == "" {
+		log.Error("workspaceID is required")
+		return nil, errors.New("workspaceID is required")
+	}
+	if userID == "" {
+		log.Error("userID is required")
+		return nil, errors.New("userID is required")
+	}
+
+	status := PresenceOffline
+	for _, s := range statuses {
+		if s == PresenceOnline {
+			status = PresenceOnline
+			break
+		}
+		if s == PresenceAway {
+			status = PresenceAway
+		}
+	}
+	return &Presence{
+		Action:      PresenceAction,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Status:      status,
+		Connections: len(statuses),
+	}, nil
+}
+
+func (p *Presence) Encode() ([]byte, error) {
+	json, err := json.Marshal(p)
+	if err != nil {
+		log.Error("Failed to encode presence", log.Ferror(err))
+		return nil, err
+	}
+	return json, nil
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewPresence(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name     string
+		userID   string
+		statuses []string
+		want     struct {
+			status      string
+			connections int
+		}
+		wantErr error
+	}{
+		{
+			name:     "Success: offline",
+			userID:   userID,
+			statuses: nil,
+			want: struct {
+				status      string
+				connections int
+			}{status: PresenceOffline, connections: 0},
+		},
+		{
+			name:     "Success: online if any connection is online",
+			userID:   userID,
+			statuses: []string{PresenceAway, PresenceOnline},
+			want: struct {
+				status      string
+				connections int
+			}{status: PresenceOnline, connections: 2},
+		},
+		{
+			name:     "Success: away if all connections are away",
+			userID:   userID,
+			statuses: []string{PresenceAway, PresenceAway},
+			want: struct {
+				status      string
+				connections int
+			}{status: PresenceAway, connections: 2},
+		},
+		{
+			name:    "Fail: userID is required",
+			userID:  "",
+			wantErr: errors.New("userID is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			presence, err := NewPresence(workspaceID, tt.userID, tt.statuses)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewPresence() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewPresence() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if presence.Status != tt.want.status || presence.Connections != tt.want.connections {
+				t.Errorf("NewPresence() got = %+v, want %+v", presence, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+const MaxReactionEmojiLength = 64
+
+type Reaction struct {
+	MessageID string
+	UserID    string
+	Emoji     string
+	CreatedAt time.Time
+}
+
+func NewReaction(messageID, userID, emoji string, createdAt time.Time) (*Reaction, error) {
+	if messageID == "" {
+		log.Error("MessageID is required", log.Fstring("messageID", messageID))
+		return nil, fmt.Errorf("messageID is required")
+	}
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if strings.TrimSpace(emoji) == "" {
+		log.Error("Emoji is required", log.Fstring("emoji", emoji))
+		return nil, fmt.Errorf("emoji is required")
+	}
+	if utf8.RuneCountInString(emoji) > MaxReactionEmojiLength {
+		log.Error("Emoji is too long", log.Fint("length", utf8.RuneCountInString(emoji)))
+		return nil, fmt.Errorf("emoji is too long")
+	}
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &Reaction{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// ReactionCount はメッセージに付けられたリアクションを絵文字ごとに集計したもの
+type ReactionCount struct {
+	Emoji   string   `json:"emoji"`
+	Count   int      `json:"count"`
+	UserIDs []string `json:"user_ids"`
+}
+
+// NewReactionCounts はリアクションをメッセージIDごと、絵文字ごとに集計する
+// 絵文字は最初にリアクションされた順に並ぶ為、reactionsは作成日時の昇順で渡すこと
+func NewReactionCounts(reactions []Reaction) map[string][]ReactionCount {
+	counts := make(map[string][]ReactionCount)
+	for _, reaction := range reactions {
+		rcs := counts[reaction.MessageID]
+		found := false
+		for i := range rcs {
+			if rcs[i].Emoji == reaction.Emoji {
+				rcs[i].Count++
+				rcs[i].UserIDs = append(rcs[i].UserIDs, reaction.UserID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			rcs = append(rcs, ReactionCount{
+				Emoji:   reaction.Emoji,
+				Count:   1,
+				UserIDs: []string{reaction.UserID},
+			})
+		}
+		counts[reaction.MessageID] = rcs
+	}
+	return counts
+}
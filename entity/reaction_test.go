@@ -0,0 +1,129 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewReaction(t *testing.T) {
+	t.Parallel()
+
+	messageID := uuid.New().String()
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			messageID string
+			userID    string
+			emoji     string
+		}
+		wantErr error
+	}{
+		{
+			name: "Success",
+			arg: struct {
+				messageID string
+				userID    string
+				emoji     string
+			}{
+				messageID: messageID,
+				userID:    userID,
+				emoji:     "👍",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: emoji is required",
+			arg: struct {
+				messageID string
+				userID    string
+				emoji     string
+			}{
+				messageID: messageID,
+				userID:    userID,
+				emoji:     " ",
+			},
+			wantErr: errors.New("emoji is required"),
+		},
+		{
+			name: "Fail: emoji is too long",
+			arg: struct {
+				messageID string
+				userID    string
+				emoji     string
+			}{
+				messageID: messageID,
+				userID:    userID,
+				emoji:     strings.Repeat("a", MaxReactionEmojiLength+1),
+			},
+			wantErr: errors.New("emoji is too long"),
+		},
+		{
+			name: "Fail: messageID is required",
+			arg: struct {
+				messageID string
+				userID    string
+				emoji     string
+			}{
+				messageID: "",
+				userID:    userID,
+				emoji:     "👍",
+			},
+			wantErr: errors.New("messageID is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reaction, err := NewReaction(tt.arg.messageID, tt.arg.userID, tt.arg.emoji, time.Time{})
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewReaction() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewReaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && reaction.CreatedAt.IsZero() {
+				t.Errorf("NewReaction() CreatedAt is zero")
+			}
+		})
+	}
+}
+
+func TestEntity_NewReactionCounts(t *testing.T) {
+	t.Parallel()
+
+	messageID1 := uuid.New().String()
+	messageID2 := uuid.New().String()
+	userID1 := uuid.New().String()
+	userID2 := uuid.New().String()
+
+	reactions := []Reaction{
+		{MessageID: messageID1, UserID: userID1, Emoji: "👍"},
+		{MessageID: messageID1, UserID: userID1, Emoji: "🎉"},
+		{MessageID: messageID1, UserID: userID2, Emoji: "👍"},
+		{MessageID: messageID2, UserID: userID2, Emoji: "👀"},
+	}
+
+	want := map[string][]ReactionCount{
+		messageID1: {
+			{Emoji: "👍", Count: 2, UserIDs: []string{userID1, userID2}},
+			{Emoji: "🎉", Count: 1, UserIDs: []string{userID1}},
+		},
+		messageID2: {
+			{Emoji: "👀", Count: 1, UserIDs: []string{userID2}},
+		},
+	}
+
+	if d := cmp.Diff(NewReactionCounts(reactions), want); len(d) != 0 {
+		t.Errorf("NewReactionCounts() mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+const (
+	MarkReadAction     = "MARK_READ"
+	UnreadCountsAction = "UNREAD_COUNTS"
+)
+
+// ReadReceipt はユーザがChannelで最後に読んだメッセージ
+// ReadAtには読んだメッセージの投稿日時を保持し、(ReadAt, MessageID)で既読位置を比較する
+type ReadReceipt struct {
+	UserID      string
+	WorkspaceID string
+	ChannelID   string
+	MessageID   string
+	ReadAt      time.Time
+}
+
+func NewReadReceipt(userID, workspaceID, channelID, messageID string, readAt time.Time) (*ReadReceipt, error) {
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+	if workspaceID == "" {
+		log.Error("WorkspaceID is required", log.Fstring("workspaceID", workspaceID))
+		return nil, fmt.Errorf("workspaceID is required")
+	}
+	if channelID == "" {
+		log.Error("ChannelID is required", log.Fstring("channelID", channelID))
+		return nil, fmt.Errorf("channelID is required")
+	}
+	if messageID == "" {
+		log.Error("MessageID is required", log.Fstring("messageID", messageID))
+		return nil, fmt.Errorf("messageID is required")
+	}
+	if readAt.IsZero() {
+		log.Error("ReadAt is required")
+		return nil, fmt.Errorf("readAt is required")
+	}
+	return &ReadReceipt{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		ChannelID:   channelID,
+		MessageID:   messageID,
+		ReadAt:      readAt,
+	}, nil
+}
+
+// IsAfter は既読位置がotherより新しいかを返す。otherがnilの場合は常にtrue
+func (r *ReadReceipt) IsAfter(other *ReadReceipt) bool {
+	if other == nil {
+		return true
+	}
+	if !r.ReadAt.Equal(other.ReadAt) {
+		return r.ReadAt.After(other.ReadAt)
+	}
+	return r.MessageID > other.MessageID
+}
+
+// UnreadCount はChannelの未読メッセージ数とそのうち自分宛てのメンションを含む数
+type UnreadCount struct {
+	ChannelID         string `json:"channel_id"`
+	LastReadMessageID string `json:"last_read_message_id,omitempty"`
+	UnreadCount       int    `json:"unread_count"`
+	MentionCount      int    `json:"mention_count"`
+}
+
+// UnreadCounts は接続時にClientへ送信するChannel毎の未読数
+type UnreadCounts struct {
+	Action       string        `json:"action"`
+	UnreadCounts []UnreadCount `json:"unread_counts"`
+}
+
+func NewUnreadCounts(counts []UnreadCount) *UnreadCounts {
+	if counts == nil {
+		counts = []UnreadCount{}
+	}
+	return &UnreadCounts{
+		Action:       UnreadCountsAction,
+		UnreadCounts: counts,
+	}
+}
+
+func (uc *UnreadCounts) Encode() ([]byte, error) {
+	json, err := json.Marshal(uc)
+	if err != nil {
+		log.Error("Failed to encode unread counts", log.Ferror(err))
+		return nil, err
+	}
+	return json, nil
+}
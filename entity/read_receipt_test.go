@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEntity_ReadReceipt_IsAfter(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	now := time.Now()
+
+	receipt := func(messageID string, readAt time.Time) *ReadReceipt {
+		r, err := NewReadReceipt(userID, workspaceID, channelID, messageID, readAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	patterns := []struct {
+		name    string
+		receipt *ReadReceipt
+		other   *ReadReceipt
+		want    bool
+	}{
+		{
+			name:    "no previous receipt",
+			receipt: receipt("b", now),
+			other:   nil,
+			want:    true,
+		},
+		{
+			name:    "newer message",
+			receipt: receipt("a", now.Add(time.Second)),
+			other:   receipt("b", now),
+			want:    true,
+		},
+		{
+			name:    "older message",
+			receipt: receipt("b", now),
+			other:   receipt("a", now.Add(time.Second)),
+			want:    false,
+		},
+		{
+			name:    "same time is ordered by id",
+			receipt: receipt("b", now),
+			other:   receipt("a", now),
+			want:    true,
+		},
+		{
+			name:    "same message",
+			receipt: receipt("a", now),
+			other:   receipt("a", now),
+			want:    false,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.receipt.IsAfter(tt.other); got != tt.want {
+				t.Errorf("IsAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
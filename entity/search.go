@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchSnippetRadius は抜粋に含める、最初に一致した語の前後の文字数
+const SearchSnippetRadius = 40
+
+// Highlight は抜粋中で検索語に一致した範囲。Start/Endは抜粋の先頭からの文字(rune)単位の位置
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SearchResult は検索に一致したメッセージとその抜粋
+type SearchResult struct {
+	Message    *Message    `json:"message"`
+	Snippet    string      `json:"snippet"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// SearchResults は検索結果の1ページ。NextCursorが空の場合は次のページは無い
+type SearchResults struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// ParseSearchTerms は検索文字列を空白で区切った検索語を返す
+// 検索語の引用符は取り除き、同じ語は一度だけ含める
+func ParseSearchTerms(q string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Fields(q) {
+		term := strings.ReplaceAll(field, `"`, "")
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// NewSearchResult はメッセージ本文から最初に一致した検索語の前後を抜粋し、一致した範囲を返す
+// 大文字と小文字は区別しない
+func NewSearchResult(message *Message, terms []string) SearchResult {
+	text := []rune(message.Text)
+	lower := []rune(strings.ToLower(message.Text))
+	if len(lower) != len(text) {
+		// 小文字化で文字数が変わる場合は位置を対応付けられない為、runeごとに小文字化する
+		lower = make([]rune, len(text))
+		for i, r := range text {
+			lower[i] = unicode.ToLower(r)
+		}
+	}
+
+	lowerTerms := make([][]rune, 0, len(terms))
+	for _, term := range terms {
+		if t := []rune(strings.ToLower(term)); len(t) > 0 {
+			lowerTerms = append(lowerTerms, t)
+		}
+	}
+
+	first := -1
+	for _, term := range lowerTerms {
+		if i := indexRunes(lower, term, 0); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+
+	start, end := 0, len(text)
+	if first >= 0 {
+		start = max(0, first-SearchSnippetRadius)
+		end = min(len(text), first+SearchSnippetRadius)
+	} else if end > 2*SearchSnippetRadius {
+		end = 2 * SearchSnippetRadius
+	}
+
+	var b strings.Builder
+	offset := 0
+	if start > 0 {
+		b.WriteString("…")
+		offset = 1
+	}
+	b.WriteString(string(text[start:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	highlights := []Highlight{}
+	for _, term := range lowerTerms {
+		for i := indexRunes(lower[:end], term, start); i >= 0; i = indexRunes(lower[:end], term, i+len(term)) {
+			highlights = append(highlights, Highlight{Start: i - start + offset, End: i - start + offset + len(term)})
+		}
+	}
+	sort.Slice(highlights, func(i, j int) bool { return highlights[i].Start < highlights[j].Start })
+
+	return SearchResult{
+		Message:    message,
+		Snippet:    b.String(),
+		Highlights: highlights,
+	}
+}
+
+// indexRunes はsのfrom以降で最初にsubstrが現れる位置を返す。現れない場合は-1
+func indexRunes(s, substr []rune, from int) int {
+	for i := from; i+len(substr) <= len(s); i++ {
+		match := true
+		for j := range substr {
+			if s[i+j] != substr[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEntity_ParseSearchTerms(t *testing.T) {
+	t.Parallel()
+
+	got := ParseSearchTerms(`  deploy "release" Deploy  ""  `)
+	want := []string{"deploy", "release"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSearchTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestEntity_NewSearchResult(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name           string
+		text           string
+		terms          []string
+		wantSnippet    string
+		wantHighlights []Highlight
+	}{
+		{
+			name:           "short text is not truncated",
+			text:           "Deploy the release, then deploy again",
+			terms:          []string{"deploy"},
+			wantSnippet:    "Deploy the release, then deploy again",
+			wantHighlights: []Highlight{{Start: 0, End: 6}, {Start: 25, End: 31}},
+		},
+		{
+			name:           "long text is trimmed around the first match",
+			text:           strings.Repeat("a", 50) + "デプロイ" + strings.Repeat("b", 50),
+			terms:          []string{"デプロイ"},
+			wantSnippet:    "…" + strings.Repeat("a", 40) + "デプロイ" + strings.Repeat("b", 36) + "…",
+			wantHighlights: []Highlight{{Start: 41, End: 45}},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := NewSearchResult(&Message{Text: tt.text}, tt.terms)
+			if got.Snippet != tt.wantSnippet {
+				t.Errorf("NewSearchResult() snippet = %q, want %q", got.Snippet, tt.wantSnippet)
+			}
+			if !reflect.DeepEqual(got.Highlights, tt.wantHighlights) {
+				t.Errorf("NewSearchResult() highlights = %v, want %v", got.Highlights, tt.wantHighlights)
+			}
+		})
+	}
+}
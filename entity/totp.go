@@ -0,0 +1,168 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // TOTP(RFC 6238)の既定のアルゴリズムで、認証アプリとの互換性の為に使う
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+// RecoveryCodeCount は一度に発行するリカバリーコードの数
+const RecoveryCodeCount = 10
+
+const (
+	// totpSecretBytes はTOTPの共有鍵のバイト数(RFC 4226で推奨される160bit)
+	totpSecretBytes = 20
+	totpDigits      = 6
+	totpModulus     = 1_000_000 // 10^totpDigits
+	totpPeriod      = 30
+	// totpSkew は端末との時刻のずれを考慮して受け付ける前後のステップ数
+	totpSkew = 1
+
+	// hotpOffsetMask, hotpValueMask はHOTPの動的切り捨て(RFC 4226 5.3)に使う
+	hotpOffsetMask = 0x0f
+	hotpValueMask  = 0x7fffffff
+
+	// recoveryCodeBytes はリカバリーコードの乱数のバイト数。Base32で16文字(80bit)になる
+	recoveryCodeBytes = 10
+	recoveryCodeGroup = 4
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorEnrollment は認証アプリに登録する共有鍵とotpauth URI。URIはQRコードにして読み取らせる
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorChallenge は二要素認証が有効なユーザがパスワードでログインした際に返す一時的なトークン
+// 認証アプリのコード(またはリカバリーコード)と共に送信するとアクセストークンを発行する
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"` // 有効期間(秒)
+}
+
+func NewTwoFactorEnrollment(issuer, accountName string) (*TwoFactorEnrollment, error) {
+	b := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		log.Error("Failed to generate totp secret", log.Ferror(err))
+		return nil, err
+	}
+	secret := totpEncoding.EncodeToString(b)
+
+	// Key Uri Format: otpauth://totp/{issuer}:{accountName}?secret=...&issuer=...
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", strconv.Itoa(totpDigits))
+	params.Set("period", strconv.Itoa(totpPeriod))
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: params.Encode(),
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: uri.String(),
+	}, nil
+}
+
+// GenerateTOTPCode は時刻tにおけるTOTPのコードを返す(RFC 6238, HMAC-SHA1, 30秒, 6桁)
+func GenerateTOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret: %w", err)
+	}
+	return hotp(key, uint64(t.Unix()/totpPeriod)), nil
+}
+
+// ValidateTOTPCode はコードが現在時刻の前後totpSkewステップのいずれかと一致するかを返す
+// 一致した場合はそのステップ(Unix時間/30秒)も返す。同じコードの再利用を防ぐには、ステップが以前に受け付けたものより後であることを確認する
+func ValidateTOTPCode(secret, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	counter := now.Unix() / totpPeriod
+	var step int64
+	valid := false
+	for i := -totpSkew; i <= totpSkew; i++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(counter+int64(i)))), []byte(code)) == 1 {
+			step, valid = counter+int64(i), true
+		}
+	}
+	return step, valid
+}
+
+// hotp はRFC 4226のHOTPの値を返す
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// 最後のバイトの下位4bitが示す位置から4バイトを取り出す
+	offset := sum[len(sum)-1] & hotpOffsetMask
+	value := binary.BigEndian.Uint32(sum[offset:]) & hotpValueMask
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulus)
+}
+
+// RecoveryCode は認証アプリを使えない場合に一度だけ使えるコード。コードそのものは保存せずハッシュ値で照合する
+type RecoveryCode struct {
+	Code     string // 発行時のみ設定される
+	CodeHash string
+	UserID   string
+}
+
+// NewRecoveryCodes はRecoveryCodeCount個のリカバリーコードを発行する。コードは"xxxx-xxxx-xxxx-xxxx"の形式
+func NewRecoveryCodes(userID string) ([]RecoveryCode, error) {
+	if userID == "" {
+		log.Error("UserID is required", log.Fstring("userID", userID))
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	codes := make([]RecoveryCode, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			log.Error("Failed to generate recovery code", log.Ferror(err))
+			return nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(b))
+
+		groups := make([]string, 0, len(raw)/recoveryCodeGroup)
+		for j := 0; j < len(raw); j += recoveryCodeGroup {
+			groups = append(groups, raw[j:j+recoveryCodeGroup])
+		}
+		code := strings.Join(groups, "-")
+
+		codes[i] = RecoveryCode{
+			Code:     code,
+			CodeHash: HashRecoveryCode(code),
+			UserID:   userID,
+		}
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode は区切り文字・大文字小文字の違いを無視してリカバリーコードのハッシュ値を返す
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return HashToken(normalized)
+}
@@ -0,0 +1,178 @@
+package entity
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RFC 6238 Appendix BのSHA1の共有鍵("12345678901234567890")をBase32エンコードしたもの
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestEntity_GenerateTOTPCode(t *testing.T) {
+	t.Parallel()
+
+	// RFC 6238 Appendix Bのテストベクタ(8桁)の下6桁
+	patterns := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range patterns {
+		got, err := GenerateTOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateTOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestEntity_ValidateTOTPCode(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1234567890, 0)
+	code := func(tm time.Time) string {
+		c, _ := GenerateTOTPCode(rfc6238Secret, tm)
+		return c
+	}
+
+	patterns := []struct {
+		name     string
+		secret   string
+		code     string
+		want     bool
+		wantStep int64
+	}{
+		{
+			name:     "valid: current step",
+			secret:   rfc6238Secret,
+			code:     code(now),
+			want:     true,
+			wantStep: 1234567890 / 30,
+		},
+		{
+			name:     "valid: previous step (clock skew)",
+			secret:   strings.ToLower(rfc6238Secret),
+			code:     code(now.Add(-30 * time.Second)),
+			want:     true,
+			wantStep: 1234567890/30 - 1,
+		},
+		{
+			name:   "invalid: two steps ago",
+			secret: rfc6238Secret,
+			code:   code(now.Add(-60 * time.Second)),
+		},
+		{
+			name:   "invalid: wrong length",
+			secret: rfc6238Secret,
+			code:   "05924",
+		},
+		{
+			name:   "invalid: secret is broken",
+			secret: "not base32!",
+			code:   code(now),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			step, got := ValidateTOTPCode(tt.secret, tt.code, now)
+			if got != tt.want {
+				t.Errorf("ValidateTOTPCode() = %v, want %v", got, tt.want)
+			}
+			if got && step != tt.wantStep {
+				t.Errorf("ValidateTOTPCode() step = %d, want %d", step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestEntity_NewTwoFactorEnrollment(t *testing.T) {
+	t.Parallel()
+
+	got, err := NewTwoFactorEnrollment("go-chat-app", "test@gmail.com")
+	if err != nil {
+		t.Fatalf("NewTwoFactorEnrollment() error = %v", err)
+	}
+	if _, err = GenerateTOTPCode(got.Secret, time.Now()); err != nil {
+		t.Errorf("NewTwoFactorEnrollment() secret = %q: %v", got.Secret, err)
+	}
+
+	u, err := url.Parse(got.OTPAuthURI)
+	if err != nil {
+		t.Fatalf("Failed to parse otpauth uri: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/go-chat-app:test@gmail.com" {
+		t.Errorf("NewTwoFactorEnrollment() uri = %s", got.OTPAuthURI)
+	}
+	if q := u.Query(); q.Get("secret") != got.Secret || q.Get("issuer") != "go-chat-app" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("NewTwoFactorEnrollment() uri = %s", got.OTPAuthURI)
+	}
+}
+
+func TestEntity_NewRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name    string
+		userID  string
+		wantErr error
+	}{
+		{
+			name:   "Success",
+			userID: userID,
+		},
+		{
+			name:    "Fail: userID is required",
+			wantErr: errors.New("userID is required"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewRecoveryCodes(tt.userID)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewRecoveryCodes() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("NewRecoveryCodes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(got) != RecoveryCodeCount {
+				t.Fatalf("NewRecoveryCodes() len = %d, want %d", len(got), RecoveryCodeCount)
+			}
+			seen := map[string]bool{}
+			for _, code := range got {
+				if len(code.Code) != len("xxxx-xxxx-xxxx-xxxx") || seen[code.CodeHash] || code.UserID != tt.userID {
+					t.Errorf("NewRecoveryCodes() code = %+v", code)
+				}
+				seen[code.CodeHash] = true
+				// 区切り文字や大文字で入力されても照合できる
+				if HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(code.Code, "-", ""))) != code.CodeHash {
+					t.Errorf("HashRecoveryCode() does not normalize code: %s", code.Code)
+				}
+			}
+		})
+	}
+}
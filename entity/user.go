@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
@@ -9,9 +10,12 @@ import (
 )
 
 type User struct {
-	ID       string
-	Email    string
-	Password string
+	ID              string
+	Email           string
+	Password        string
+	EmailVerifiedAt *time.Time // メールアドレスを確認するまではnil
+	TOTPSecret      string     // 二要素認証の共有鍵。登録後、コードを確認するまではTOTPEnabledAtがnil
+	TOTPEnabledAt   *time.Time
 }
 
 func NewUser(id, email, password string) (*User, error) {
@@ -33,6 +37,39 @@ func NewUser(id, email, password string) (*User, error) {
 	}, nil
 }
 
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// VerifyEmail はメールアドレスを確認済みにする。確認済みの場合は日時を更新しない
+func (u *User) VerifyEmail(now time.Time) {
+	if u.EmailVerifiedAt != nil {
+		return
+	}
+	verifiedAt := now.UTC().Truncate(time.Second)
+	u.EmailVerifiedAt = &verifiedAt
+}
+
+func (u *User) IsTwoFactorEnabled() bool {
+	return u.TOTPSecret != "" && u.TOTPEnabledAt != nil
+}
+
+// EnrollTwoFactor は共有鍵を登録する。有効にするのはEnableTwoFactorでコードを確認してから
+func (u *User) EnrollTwoFactor(secret string) {
+	u.TOTPSecret = secret
+	u.TOTPEnabledAt = nil
+}
+
+func (u *User) EnableTwoFactor(now time.Time) {
+	enabledAt := now.UTC().Truncate(time.Second)
+	u.TOTPEnabledAt = &enabledAt
+}
+
+func (u *User) DisableTwoFactor() {
+	u.TOTPSecret = ""
+	u.TOTPEnabledAt = nil
+}
+
 func (u *User) CompareHashAndPassword(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
@@ -3,6 +3,7 @@ package entity
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -168,3 +169,45 @@ func TestEntity_User_CompareHashAndPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestEntity_User_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	hashPassword, _ := PasswordEncrypt("password123")
+	user, _ := NewUser("", "test@gmail.com", hashPassword)
+	require.False(t, user.IsEmailVerified())
+
+	now := time.Now()
+	user.VerifyEmail(now)
+	require.True(t, user.IsEmailVerified())
+	require.Equal(t, now.UTC().Truncate(time.Second), *user.EmailVerifiedAt)
+
+	// 確認済みの場合は日時を更新しない
+	user.VerifyEmail(now.Add(time.Hour))
+	require.Equal(t, now.UTC().Truncate(time.Second), *user.EmailVerifiedAt)
+}
+
+func TestEntity_User_TwoFactor(t *testing.T) {
+	t.Parallel()
+
+	user := &User{ID: uuid.New().String(), Email: "test@gmail.com", Password: "password"}
+	if user.IsTwoFactorEnabled() {
+		t.Fatal("IsTwoFactorEnabled() = true before enrollment")
+	}
+
+	// 登録しただけでは有効にならない
+	user.EnrollTwoFactor(rfc6238Secret)
+	if user.IsTwoFactorEnabled() {
+		t.Fatal("IsTwoFactorEnabled() = true before activation")
+	}
+
+	user.EnableTwoFactor(time.Now())
+	if !user.IsTwoFactorEnabled() {
+		t.Fatal("IsTwoFactorEnabled() = false after activation")
+	}
+
+	user.DisableTwoFactor()
+	if user.IsTwoFactorEnabled() || user.TOTPSecret != "" {
+		t.Fatalf("DisableTwoFactor() user = %+v", user)
+	}
+}
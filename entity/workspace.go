@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+type Workspace struct {
+	ID   string
+	Name string
+}
+
+func NewWorkspace(id, name string) (*Workspace, error) {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	if name == "" {
+		log.Error("name is required")
+		return nil, errors.New("name is required")
+	}
+	return &Workspace{
+		ID:   id,
+		Name: name,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+)
+
+func TestEntity_NewWorkspace(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New().String()
+
+	patterns := []struct {
+		name string
+		arg  struct {
+			id   string
+			name string
+		}
+		want struct {
+			workspace *Workspace
+			err       error
+		}
+	}{
+		{
+			name: "Success: id is not empty",
+			arg: struct {
+				id   string
+				name string
+			}{
+				id:   id,
+				name: "workspace",
+			},
+			want: struct {
+				workspace *Workspace
+				err       error
+			}{
+				workspace: &Workspace{
+					ID:   id,
+					Name: "workspace",
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "Success: id is empty",
+			arg: struct {
+				id   string
+				name string
+			}{
+				id:   "",
+				name: "workspace",
+			},
+			want: struct {
+				workspace *Workspace
+				err       error
+			}{
+				workspace: &Workspace{
+					Name: "workspace",
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "Fail: name is empty",
+			arg: struct {
+				id   string
+				name string
+			}{
+				id:   id,
+				name: "",
+			},
+			want: struct {
+				workspace *Workspace
+				err       error
+			}{
+				workspace: nil,
+				err:       errors.New("name is required"),
+			},
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			workspace, err := NewWorkspace(tt.arg.id, tt.arg.name)
+
+			if (err != nil) != (tt.want.err != nil) {
+				t.Errorf("NewWorkspace() error = %v, wantErr %v", err, tt.want.err)
+			} else if err != nil && tt.want.err != nil && err.Error() != tt.want.err.Error() {
+				t.Errorf("NewWorkspace() error = %v, wantErr %v", err, tt.want.err)
+			}
+
+			if d := cmp.Diff(workspace, tt.want.workspace, cmpopts.IgnoreFields(Workspace{}, "ID")); len(d) != 0 {
+				t.Errorf("NewWorkspace() mismatch (-got +want):\n%s", d)
+			}
+		})
+	}
+}
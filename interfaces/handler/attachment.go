@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+// attachmentFormName はアップロードするファイルを含むmultipart/form-dataのフィールド名
+const attachmentFormName = "file"
+
+type AttachmentHandler interface {
+	UploadAttachment(w http.ResponseWriter, r *http.Request)
+	DownloadAttachment(w http.ResponseWriter, r *http.Request)
+}
+
+type attachmentHandler struct {
+	auc usecase.AttachmentUseCase
+}
+
+func NewAttachmentHandler(auc usecase.AttachmentUseCase) AttachmentHandler {
+	return &attachmentHandler{
+		auc: auc,
+	}
+}
+
+// UploadAttachment はmultipart/form-dataのfileフィールドのファイルを、メモリに読み込まずにストレージへ保存する
+// 返されたidをCREATE_MESSAGE・REPLY_MESSAGEのattachment_idsに指定するとメッセージに添付される
+func (ah *attachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+	channelID := chi.URLParam(r, "channelID")
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		log.Warn("Request is not multipart", log.Ferror(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for {
+		part, err := reader.NextPart() //nolint:govet // err shadowing
+		if err != nil {
+			log.Warn("File is required", log.Ferror(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if part.FormName() != attachmentFormName {
+			part.Close()
+			continue
+		}
+
+		attachment, err := ah.auc.UploadAttachment(ctx, userID, workspaceID, channelID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			log.Error("Failed to upload attachment", log.Fstring("channelID", channelID), log.Ferror(err))
+			w.WriteHeader(attachmentErrorStatus(err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, attachment)
+		return
+	}
+}
+
+// DownloadAttachment は添付ファイルを返す。画像はブラウザで表示できるようにinlineで返す
+func (ah *attachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	attachmentID := chi.URLParam(r, "attachmentID")
+
+	attachment, body, err := ah.auc.GetAttachment(ctx, userID, attachmentID)
+	if err != nil {
+		log.Error("Failed to get attachment", log.Fstring("attachmentID", attachmentID), log.Ferror(err))
+		w.WriteHeader(attachmentErrorStatus(err))
+		return
+	}
+	defer body.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, body); err != nil {
+		log.Error("Failed to write attachment", log.Fstring("attachmentID", attachmentID), log.Ferror(err))
+	}
+}
+
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidAttachment):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrAttachmentNotFound), errors.Is(err, usecase.ErrChannelNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, usecase.ErrUnsupportedContentType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestAttachmentHandler_UploadAttachment(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockAttachmentUseCase)
+		field      string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockAttachmentUseCase) {
+				m.EXPECT().UploadAttachment(gomock.Any(), userID, workspaceID, channelID, "photo.png", gomock.Any()).DoAndReturn(
+					func(_ context.Context, _, _, _, name string, body io.Reader) (*entity.Attachment, error) {
+						if b, _ := io.ReadAll(body); string(b) != "image" {
+							t.Errorf("unexpected body: %q", b)
+						}
+						return &entity.Attachment{ID: uuid.New().String(), Name: name}, nil
+					},
+				)
+			},
+			field:      "file",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Fail: file is required",
+			field:      "image",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: too large",
+			setup: func(m *mock.MockAttachmentUseCase) {
+				m.EXPECT().UploadAttachment(gomock.Any(), userID, workspaceID, channelID, "photo.png", gomock.Any()).Return(nil, usecase.ErrAttachmentTooLarge)
+			},
+			field:      "file",
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "Fail: unsupported content type",
+			setup: func(m *mock.MockAttachmentUseCase) {
+				m.EXPECT().UploadAttachment(gomock.Any(), userID, workspaceID, channelID, "photo.png", gomock.Any()).Return(nil, usecase.ErrUnsupportedContentType)
+			},
+			field:      "file",
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			auc := mock.NewMockAttachmentUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(auc)
+			}
+
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			part, _ := mw.CreateFormFile(tt.field, "photo.png")
+			_, _ = part.Write([]byte("image"))
+			mw.Close()
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/workspaces/"+workspaceID+"/channels/"+channelID+"/attachments", &body)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			req = withURLParam(req, "channelID", channelID)
+			req = withWorkspaceID(req, workspaceID)
+			req = withUserID(req, userID)
+
+			handler := NewAttachmentHandler(auc)
+			recorder := httptest.NewRecorder()
+			handler.UploadAttachment(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAttachmentHandler_DownloadAttachment(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	attachmentID := uuid.New().String()
+
+	patterns := []struct {
+		name            string
+		setup           func(m *mock.MockAttachmentUseCase)
+		wantStatus      int
+		wantDisposition string
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockAttachmentUseCase) {
+				m.EXPECT().GetAttachment(gomock.Any(), userID, attachmentID).Return(
+					&entity.Attachment{ID: attachmentID, Name: "report.pdf", ContentType: "application/pdf", Size: 3},
+					io.NopCloser(strings.NewReader("pdf")), nil,
+				)
+			},
+			wantStatus:      http.StatusOK,
+			wantDisposition: `attachment; filename=report.pdf`,
+		},
+		{
+			name: "Fail: not found",
+			setup: func(m *mock.MockAttachmentUseCase) {
+				m.EXPECT().GetAttachment(gomock.Any(), userID, attachmentID).Return(nil, nil, usecase.ErrAttachmentNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			auc := mock.NewMockAttachmentUseCase(ctrl)
+			tt.setup(auc)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/attachments/"+attachmentID, nil)
+			req = withURLParam(req, "attachmentID", attachmentID)
+			req = withUserID(req, userID)
+
+			handler := NewAttachmentHandler(auc)
+			recorder := httptest.NewRecorder()
+			handler.DownloadAttachment(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := recorder.Header().Get("Content-Disposition"); got != tt.wantDisposition {
+				t.Errorf("Content-Disposition = %q, want %q", got, tt.wantDisposition)
+			}
+			if got := recorder.Body.String(); got != "pdf" {
+				t.Errorf("body = %q, want %q", got, "pdf")
+			}
+		})
+	}
+}
@@ -2,26 +2,93 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 
+	"github.com/go-chi/chi"
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
 
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
 	ws "github.com/tusmasoma/go-chat-app/interfaces/websocket"
 	"github.com/tusmasoma/go-chat-app/usecase"
 )
 
 type ChannelHandler interface {
+	ListChannels(w http.ResponseWriter, r *http.Request)
+	GetChannel(w http.ResponseWriter, r *http.Request)
 	CreateChannel(w http.ResponseWriter, r *http.Request)
+	UpdateChannel(w http.ResponseWriter, r *http.Request)
+	DeleteChannel(w http.ResponseWriter, r *http.Request)
+	InviteMember(w http.ResponseWriter, r *http.Request)
+	RemoveMember(w http.ResponseWriter, r *http.Request)
 }
 
 type channelHandler struct {
-	hm  *ws.HubManager // 現状、Workspaceは一つの為、containerにてHubManagerを生成して、DIする
+	hmr *ws.HubManagerRegistry
 	cuc usecase.ChannelUseCase
 }
 
-func NewChannelHandler() ChannelHandler {
-	return &channelHandler{}
+func NewChannelHandler(hmr *ws.HubManagerRegistry, cuc usecase.ChannelUseCase) ChannelHandler {
+	return &channelHandler{
+		hmr: hmr,
+		cuc: cuc,
+	}
+}
+
+type ChannelResponse struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Private bool   `json:"private"`
+}
+
+type ListChannelsResponse struct {
+	Channels []ChannelResponse `json:"channels"`
+}
+
+func newChannelResponse(channel entity.Channel) ChannelResponse {
+	return ChannelResponse{
+		ID:      channel.ID,
+		Name:    channel.Name,
+		Private: channel.Private,
+	}
+}
+
+func (ch *channelHandler) ListChannels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channels, err := ch.cuc.ListChannels(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to list channels", log.Ferror(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := ListChannelsResponse{Channels: make([]ChannelResponse, len(channels))}
+	for i, channel := range channels {
+		response.Channels[i] = newChannelResponse(channel)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ch *channelHandler) GetChannel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channelID := chi.URLParam(r, "channelID")
+	channel, err := ch.cuc.GetChannel(ctx, userID, workspaceID, channelID)
+	if err != nil {
+		log.Error("Failed to get channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newChannelResponse(*channel))
 }
 
 type CreateChannelRequest struct {
@@ -31,6 +98,8 @@ type CreateChannelRequest struct {
 
 func (ch *channelHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
 
 	var requestBody CreateChannelRequest
 	defer r.Body.Close()
@@ -39,15 +108,20 @@ func (ch *channelHandler) CreateChannel(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	channel, err := ch.cuc.CreateChannel(ctx, requestBody.Name, requestBody.Private)
+	channel, err := ch.cuc.CreateChannel(ctx, userID, workspaceID, requestBody.Name, requestBody.Private)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Failed to create channel", log.Fstring("name", requestBody.Name), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
 		return
 	}
 
-	ch.hm.RegisterChannel(ctx, channel)
+	// 全サーバにChannelの作成を通知し、各サーバでChannelManagerを起動して作成者のClientを登録させる
+	if hm := ch.hmr.FindHubManagerByWorkspaceID(workspaceID); hm != nil {
+		hm.AnnounceChannel(ctx, channel, []string{userID})
+	}
 
-	w.WriteHeader(http.StatusOK)
+	log.Info("Channel created successfully", log.Fstring("channelID", channel.ID))
+	writeJSON(w, http.StatusCreated, newChannelResponse(*channel))
 }
 
 func (ch *channelHandler) isValidCreateChannelRequest(body io.ReadCloser, requestBody *CreateChannelRequest) bool {
@@ -61,3 +135,152 @@ func (ch *channelHandler) isValidCreateChannelRequest(body io.ReadCloser, reques
 	}
 	return true
 }
+
+type UpdateChannelRequest struct {
+	Name string `json:"name"`
+}
+
+func (ch *channelHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channelID := chi.URLParam(r, "channelID")
+
+	var requestBody UpdateChannelRequest
+	defer r.Body.Close()
+	if !ch.isValidUpdateChannelRequest(r.Body, &requestBody) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	channel, err := ch.cuc.UpdateChannel(ctx, userID, workspaceID, channelID, requestBody.Name)
+	if err != nil {
+		log.Error("Failed to update channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	// 全サーバにChannelの名前の変更を通知し、各サーバのChannelManagerが持つChannelを更新させる
+	if hm := ch.hmr.FindHubManagerByWorkspaceID(workspaceID); hm != nil {
+		hm.AnnounceChannelUpdated(ctx, channel)
+	}
+
+	log.Info("Channel updated successfully", log.Fstring("channelID", channel.ID))
+	writeJSON(w, http.StatusOK, newChannelResponse(*channel))
+}
+
+func (ch *channelHandler) isValidUpdateChannelRequest(body io.ReadCloser, requestBody *UpdateChannelRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Error("Failed to decode request body: %v", err)
+		return false
+	}
+	if requestBody.Name == "" {
+		log.Warn("Invalid request body: %v", requestBody)
+		return false
+	}
+	return true
+}
+
+func (ch *channelHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channelID := chi.URLParam(r, "channelID")
+	if err := ch.cuc.DeleteChannel(ctx, userID, workspaceID, channelID); err != nil {
+		log.Error("Failed to delete channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	// 全サーバにChannelの削除を通知し、各サーバのChannelManagerを停止させる
+	if hm := ch.hmr.FindHubManagerByWorkspaceID(workspaceID); hm != nil {
+		hm.AnnounceChannelDeleted(ctx, channelID)
+	}
+
+	log.Info("Channel deleted successfully", log.Fstring("channelID", channelID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type InviteMemberRequest struct {
+	UserID string `json:"user_id"`
+}
+
+func (ch *channelHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channelID := chi.URLParam(r, "channelID")
+
+	var requestBody InviteMemberRequest
+	defer r.Body.Close()
+	if !ch.isValidInviteMemberRequest(r.Body, &requestBody) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := ch.cuc.InviteMember(ctx, userID, workspaceID, channelID, requestBody.UserID); err != nil {
+		log.Error("Failed to invite member", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	// 招待されたユーザが接続中であれば、接続先のサーバでそのClientをChannelに登録させる
+	if hm := ch.hmr.FindHubManagerByWorkspaceID(workspaceID); hm != nil {
+		hm.AnnounceMembersJoined(ctx, channelID, []string{requestBody.UserID})
+	}
+
+	log.Info("Member invited successfully", log.Fstring("channelID", channelID), log.Fstring("userID", requestBody.UserID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ch *channelHandler) isValidInviteMemberRequest(body io.ReadCloser, requestBody *InviteMemberRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Error("Failed to decode request body: %v", err)
+		return false
+	}
+	if requestBody.UserID == "" {
+		log.Warn("Invalid request body: %v", requestBody)
+		return false
+	}
+	return true
+}
+
+func (ch *channelHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channelID := chi.URLParam(r, "channelID")
+	memberID := chi.URLParam(r, "userID")
+	if err := ch.cuc.RemoveMember(ctx, userID, workspaceID, channelID, memberID); err != nil {
+		log.Error("Failed to remove member", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	// 削除されたユーザが接続中であれば、接続先のサーバでそのClientをChannelから削除させる
+	if hm := ch.hmr.FindHubManagerByWorkspaceID(workspaceID); hm != nil {
+		hm.AnnounceMembersLeft(ctx, channelID, []string{memberID})
+	}
+
+	log.Info("Member removed successfully", log.Fstring("channelID", channelID), log.Fstring("userID", memberID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func channelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrChannelNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrNotChannelOwner):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrChannelNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrNotWorkspaceMember), errors.Is(err, usecase.ErrInvalidParticipants):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
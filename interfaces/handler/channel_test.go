@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	ws "github.com/tusmasoma/go-chat-app/interfaces/websocket"
+	rmock "github.com/tusmasoma/go-chat-app/repository/mock"
+	"github.com/tusmasoma/go-chat-app/usecase"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestChannelHandler_GetChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockChannelUseCase) {
+				m.EXPECT().GetChannel(
+					gomock.Any(),
+					userID,
+					workspaceID,
+					channelID,
+				).Return(
+					&entity.Channel{
+						ID:          channelID,
+						WorkspaceID: workspaceID,
+						Name:        "general",
+					},
+					nil,
+				)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/channels/"+channelID, nil)
+				return withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: channel not found",
+			setup: func(m *mock.MockChannelUseCase) {
+				m.EXPECT().GetChannel(
+					gomock.Any(),
+					userID,
+					workspaceID,
+					channelID,
+				).Return(nil, usecase.ErrChannelNotFound)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/channels/"+channelID, nil)
+				return withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cuc)
+			}
+
+			handler := NewChannelHandler(ws.NewHubManagerRegistry(nil), cuc)
+			recorder := httptest.NewRecorder()
+			handler.GetChannel(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var body ChannelResponse
+				if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode response body: %v", err)
+				}
+				if body.ID != channelID {
+					t.Fatalf("unexpected channel ID: got %v want %v", body.ID, channelID)
+				}
+			}
+		})
+	}
+}
+
+func TestChannelHandler_CreateChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "Fail: invalid request",
+			in: func() *http.Request {
+				channelCreateReq := CreateChannelRequest{Private: true}
+				reqBody, _ := json.Marshal(channelCreateReq)
+				req, _ := http.NewRequest(http.MethodPost, "/api/workspaces/"+workspaceID+"/channels", bytes.NewBuffer(reqBody))
+				req.Header.Set("Content-Type", "application/json")
+				return withWorkspaceID(req, workspaceID)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: channel name is already taken",
+			setup: func(m *mock.MockChannelUseCase) {
+				m.EXPECT().CreateChannel(
+					gomock.Any(),
+					userID,
+					workspaceID,
+					"general",
+					false,
+				).Return(nil, usecase.ErrChannelNameTaken)
+			},
+			in: func() *http.Request {
+				channelCreateReq := CreateChannelRequest{Name: "general"}
+				reqBody, _ := json.Marshal(channelCreateReq)
+				req, _ := http.NewRequest(http.MethodPost, "/api/workspaces/"+workspaceID+"/channels", bytes.NewBuffer(reqBody))
+				req.Header.Set("Content-Type", "application/json")
+				return withUserID(withWorkspaceID(req, workspaceID), userID)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cuc)
+			}
+
+			handler := NewChannelHandler(ws.NewHubManagerRegistry(nil), cuc)
+			recorder := httptest.NewRecorder()
+			handler.CreateChannel(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestChannelHandler_UpdateChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelUseCase,
+			m1 *rmock.MockPubSubRepository,
+		)
+		wantStatus int
+	}{
+		{
+			name: "success: announces the new name to every server",
+			setup: func(m *mock.MockChannelUseCase, m1 *rmock.MockPubSubRepository) {
+				m.EXPECT().UpdateChannel(gomock.Any(), userID, workspaceID, channelID, "random").Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "random"}, nil,
+				)
+				m1.EXPECT().Publish(gomock.Any(), "hub:"+workspaceID+":channels", gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, payload []byte) error {
+						var announcement struct {
+							Action string `json:"action"`
+							ID     string `json:"id"`
+							Name   string `json:"name"`
+						}
+						if err := json.Unmarshal(payload, &announcement); err != nil {
+							t.Fatal(err)
+						}
+						if announcement.Action != "update" || announcement.ID != channelID || announcement.Name != "random" {
+							t.Errorf("unexpected announcement: %s", payload)
+						}
+						return nil
+					},
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: not channel owner",
+			setup: func(m *mock.MockChannelUseCase, _ *rmock.MockPubSubRepository) {
+				m.EXPECT().UpdateChannel(gomock.Any(), userID, workspaceID, channelID, "random").Return(nil, usecase.ErrNotChannelOwner)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			ctrl := gomock.NewController(t)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+			psr := rmock.NewMockPubSubRepository(ctrl)
+
+			rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+			psr.EXPECT().Subscribe(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, _ string) *redis.PubSub {
+					return rdb.Subscribe(ctx)
+				},
+			).AnyTimes()
+			if tt.setup != nil {
+				tt.setup(cuc, psr)
+			}
+
+			hmr := ws.NewHubManagerRegistry(psr)
+			if _, err := hmr.RegisterWorkspace(ctx, &entity.Workspace{ID: workspaceID, Name: "workspace"}, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			reqBody, _ := json.Marshal(UpdateChannelRequest{Name: "random"})
+			req, _ := http.NewRequest(http.MethodPut, "/api/workspaces/"+workspaceID+"/channels/"+channelID, bytes.NewBuffer(reqBody))
+			req = withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+
+			handler := NewChannelHandler(hmr, cuc)
+			recorder := httptest.NewRecorder()
+			handler.UpdateChannel(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestChannelHandler_DeleteChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockChannelUseCase) {
+				m.EXPECT().DeleteChannel(
+					gomock.Any(),
+					userID,
+					workspaceID,
+					channelID,
+				).Return(nil)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodDelete, "/api/workspaces/"+workspaceID+"/channels/"+channelID, nil)
+				return withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "Fail: not channel owner",
+			setup: func(m *mock.MockChannelUseCase) {
+				m.EXPECT().DeleteChannel(
+					gomock.Any(),
+					userID,
+					workspaceID,
+					channelID,
+				).Return(usecase.ErrNotChannelOwner)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodDelete, "/api/workspaces/"+workspaceID+"/channels/"+channelID, nil)
+				return withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cuc)
+			}
+
+			handler := NewChannelHandler(ws.NewHubManagerRegistry(nil), cuc)
+			recorder := httptest.NewRecorder()
+			handler.DeleteChannel(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestChannelHandler_InviteMember(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name       string
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "Fail: invalid request",
+			in: func() *http.Request {
+				reqBody, _ := json.Marshal(InviteMemberRequest{})
+				req, _ := http.NewRequest(http.MethodPost, "/api/workspaces/"+workspaceID+"/channels/"+channelID+"/members", bytes.NewBuffer(reqBody))
+				req.Header.Set("Content-Type", "application/json")
+				return withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			handler := NewChannelHandler(ws.NewHubManagerRegistry(nil), cuc)
+			recorder := httptest.NewRecorder()
+			handler.InviteMember(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func withWorkspaceID(req *http.Request, workspaceID string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), config.ContextWorkspaceIDKey, workspaceID))
+}
+
+func withUserID(req *http.Request, userID string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), config.ContextUserIDKey, userID))
+}
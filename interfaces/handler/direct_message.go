@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	ws "github.com/tusmasoma/go-chat-app/interfaces/websocket"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+type DirectMessageHandler interface {
+	ListDirectMessages(w http.ResponseWriter, r *http.Request)
+	OpenDirectMessage(w http.ResponseWriter, r *http.Request)
+}
+
+type directMessageHandler struct {
+	hmr *ws.HubManagerRegistry
+	cuc usecase.ChannelUseCase
+}
+
+func NewDirectMessageHandler(hmr *ws.HubManagerRegistry, cuc usecase.ChannelUseCase) DirectMessageHandler {
+	return &directMessageHandler{
+		hmr: hmr,
+		cuc: cuc,
+	}
+}
+
+type DirectMessageResponse struct {
+	ID      string   `json:"id"`
+	UserIDs []string `json:"user_ids"`
+}
+
+type ListDirectMessagesResponse struct {
+	DirectMessages []DirectMessageResponse `json:"direct_messages"`
+}
+
+func newDirectMessageResponse(dm entity.DirectMessage) DirectMessageResponse {
+	return DirectMessageResponse{
+		ID:      dm.Channel.ID,
+		UserIDs: dm.UserIDs,
+	}
+}
+
+func (dh *directMessageHandler) ListDirectMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	dms, err := dh.cuc.ListDirectMessages(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to list direct messages", log.Ferror(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := ListDirectMessagesResponse{DirectMessages: make([]DirectMessageResponse, len(dms))}
+	for i, dm := range dms {
+		response.DirectMessages[i] = newDirectMessageResponse(dm)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+type OpenDirectMessageRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// OpenDirectMessage は指定した参加者とのDMを返す。まだ存在しない場合は作成する
+// 返されたDMのidをtarget_idとして、WebSocketでメッセージを送信する
+func (dh *directMessageHandler) OpenDirectMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	var requestBody OpenDirectMessageRequest
+	defer r.Body.Close()
+	if !dh.isValidOpenDirectMessageRequest(r.Body, &requestBody) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	dm, err := dh.cuc.OpenDirectMessage(ctx, userID, workspaceID, requestBody.UserIDs)
+	if err != nil {
+		log.Error("Failed to open direct message", log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	// 参加者の接続中のClientをDMに登録する。他のサーバに接続している参加者にはRedis経由で通知する
+	// リクエストのContextはレスポンス後にキャンセルされる為、キャンセルを引き継がないContextを渡す
+	if hm := dh.hmr.FindHubManagerByWorkspaceID(workspaceID); hm != nil {
+		hm.RegisterChannel(context.WithoutCancel(ctx), dm.Channel)
+		for _, participant := range dm.UserIDs {
+			hm.RegisterUserInChannel(participant, dm.Channel.ID)
+		}
+		hm.AnnounceChannel(ctx, dm.Channel, dm.UserIDs)
+	}
+
+	writeJSON(w, http.StatusOK, newDirectMessageResponse(*dm))
+}
+
+func (dh *directMessageHandler) isValidOpenDirectMessageRequest(body io.ReadCloser, requestBody *OpenDirectMessageRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Error("Failed to decode request body: %v", err)
+		return false
+	}
+	if len(requestBody.UserIDs) == 0 {
+		log.Warn("Invalid request body: %v", requestBody)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	ws "github.com/tusmasoma/go-chat-app/interfaces/websocket"
+	"github.com/tusmasoma/go-chat-app/usecase"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestDirectMessageHandler_OpenDirectMessage(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	otherUserID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	dmID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelUseCase,
+		)
+		body       string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockChannelUseCase) {
+				m.EXPECT().OpenDirectMessage(gomock.Any(), userID, workspaceID, []string{otherUserID}).Return(
+					&entity.DirectMessage{
+						Channel: &entity.Channel{ID: dmID, WorkspaceID: workspaceID, Private: true, Kind: entity.ChannelKindDirectMessage},
+						UserIDs: []string{userID, otherUserID},
+					}, nil,
+				)
+			},
+			body:       fmt.Sprintf(`{"user_ids": ["%s"]}`, otherUserID),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: no participants",
+			body:       `{"user_ids": []}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: participant is not a workspace member",
+			setup: func(m *mock.MockChannelUseCase) {
+				m.EXPECT().OpenDirectMessage(gomock.Any(), userID, workspaceID, []string{otherUserID}).Return(nil, usecase.ErrNotWorkspaceMember)
+			},
+			body:       fmt.Sprintf(`{"user_ids": ["%s"]}`, otherUserID),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cuc)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/workspaces/"+workspaceID+"/dms", bytes.NewBufferString(tt.body))
+			req = withUserID(withWorkspaceID(req, workspaceID), userID)
+
+			handler := NewDirectMessageHandler(ws.NewHubManagerRegistry(nil), cuc)
+			recorder := httptest.NewRecorder()
+			handler.OpenDirectMessage(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var body DirectMessageResponse
+				if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode response body: %v", err)
+				}
+				if body.ID != dmID || len(body.UserIDs) != 2 {
+					t.Fatalf("unexpected response body: %+v", body)
+				}
+			}
+		})
+	}
+}
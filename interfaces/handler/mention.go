@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+type MentionHandler interface {
+	ListMentions(w http.ResponseWriter, r *http.Request)
+}
+
+type mentionHandler struct {
+	mnuc usecase.MentionUseCase
+}
+
+func NewMentionHandler(mnuc usecase.MentionUseCase) MentionHandler {
+	return &mentionHandler{
+		mnuc: mnuc,
+	}
+}
+
+type ListMentionsResponse struct {
+	Mentions []entity.MentionedMessage `json:"mentions"`
+}
+
+// ListMentions は自分宛てのメンションを含むメッセージを新しい順に返す
+// beforeにはカーソルとなるメッセージのidを指定する
+func (mnh *mentionHandler) ListMentions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	query := r.URL.Query()
+
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		log.Warn("Invalid limit", log.Fstring("limit", query.Get("limit")))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	mentions, err := mnh.mnuc.ListMentions(ctx, userID, query.Get("before"), limit)
+	if err != nil {
+		log.Error("Failed to list mentions", log.Fstring("userID", userID), log.Ferror(err))
+		w.WriteHeader(messageErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ListMentionsResponse{Mentions: mentions})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestMentionHandler_ListMentions(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	messageID := uuid.New().String()
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockMentionUseCase)
+		query      string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockMentionUseCase) {
+				m.EXPECT().ListMentions(gomock.Any(), userID, "", 10).Return(
+					[]entity.MentionedMessage{{Kind: entity.MentionKindUser, Message: &entity.Message{ID: messageID, Text: "@alice hi"}}}, nil,
+				)
+			},
+			query:      "?limit=10",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: invalid limit",
+			query:      "?limit=abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: cursor message not found",
+			setup: func(m *mock.MockMentionUseCase) {
+				m.EXPECT().ListMentions(gomock.Any(), userID, "unknown", 0).Return(nil, usecase.ErrMessageNotFound)
+			},
+			query:      "?before=unknown",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mnuc := mock.NewMockMentionUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mnuc)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/me/mentions"+tt.query, nil)
+			req = withUserID(req, userID)
+
+			handler := NewMentionHandler(mnuc)
+			recorder := httptest.NewRecorder()
+			handler.ListMentions(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response ListMentionsResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Mentions) != 1 || response.Mentions[0].Message.ID != messageID {
+				t.Errorf("ListMentions() got = %+v", response.Mentions)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+type MessageHandler interface {
+	ListMessages(w http.ResponseWriter, r *http.Request)
+	ListThreadMessages(w http.ResponseWriter, r *http.Request)
+	ListMessageRevisions(w http.ResponseWriter, r *http.Request)
+}
+
+type messageHandler struct {
+	muc usecase.MessageUseCase
+	cuc usecase.ChannelUseCase
+}
+
+func NewMessageHandler(muc usecase.MessageUseCase, cuc usecase.ChannelUseCase) MessageHandler {
+	return &messageHandler{
+		muc: muc,
+		cuc: cuc,
+	}
+}
+
+// ListMessages はChannelのメッセージ履歴を返す
+// before/afterにはカーソルとなるメッセージのidを指定する
+func (mh *messageHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channelID := chi.URLParam(r, "channelID")
+	query := r.URL.Query()
+
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		log.Warn("Invalid limit", log.Fstring("limit", query.Get("limit")))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// 閲覧できないChannelのメッセージは取得できない
+	if _, err = mh.cuc.GetAccessibleChannel(ctx, userID, workspaceID, channelID); err != nil {
+		log.Error("Failed to get channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	messages, err := mh.muc.ListMessages(ctx, channelID, query.Get("before"), query.Get("after"), limit)
+	if err != nil {
+		log.Error("Failed to list messages", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(messageErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, messages)
+}
+
+// ListThreadMessages はスレッドの返信履歴を返す
+func (mh *messageHandler) ListThreadMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	channelID := chi.URLParam(r, "channelID")
+	messageID := chi.URLParam(r, "messageID")
+	query := r.URL.Query()
+
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		log.Warn("Invalid limit", log.Fstring("limit", query.Get("limit")))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if _, err = mh.cuc.GetAccessibleChannel(ctx, userID, workspaceID, channelID); err != nil {
+		log.Error("Failed to get channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		w.WriteHeader(channelErrorStatus(err))
+		return
+	}
+
+	messages, err := mh.muc.ListThreadMessages(ctx, channelID, messageID, query.Get("before"), query.Get("after"), limit)
+	if err != nil {
+		log.Error("Failed to list thread messages", log.Fstring("messageID", messageID), log.Ferror(err))
+		w.WriteHeader(messageErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, messages)
+}
+
+type ListMessageRevisionsResponse struct {
+	Revisions []entity.MessageRevision `json:"revisions"`
+}
+
+// ListMessageRevisions はメッセージの編集履歴を返す。Workspaceの管理者のみ閲覧できる
+func (mh *messageHandler) ListMessageRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+	messageID := chi.URLParam(r, "messageID")
+
+	revisions, err := mh.muc.ListMessageRevisions(ctx, userID, workspaceID, messageID)
+	if err != nil {
+		log.Error("Failed to list message revisions", log.Fstring("messageID", messageID), log.Ferror(err))
+		switch {
+		case errors.Is(err, usecase.ErrNotWorkspaceAdmin):
+			w.WriteHeader(http.StatusForbidden)
+		case errors.Is(err, usecase.ErrMessageNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ListMessageRevisionsResponse{Revisions: revisions})
+}
+
+// parseLimit は取得件数を解釈する。未指定の場合は0を返し、usecase側の既定値に委ねる
+func parseLimit(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, err
+	}
+	if limit <= 0 {
+		return 0, errors.New("limit must be positive")
+	}
+	return limit, nil
+}
+
+func messageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrMessageNotFound), errors.Is(err, usecase.ErrNestedReply):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
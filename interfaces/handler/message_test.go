@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	rmock "github.com/tusmasoma/go-chat-app/repository/mock"
+	"github.com/tusmasoma/go-chat-app/usecase"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestMessageHandler_ListMessages(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockMessageUseCase,
+			m1 *mock.MockChannelUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockMessageUseCase, m1 *mock.MockChannelUseCase) {
+				m1.EXPECT().GetAccessibleChannel(gomock.Any(), userID, workspaceID, channelID).Return(
+					&entity.Channel{ID: channelID}, nil,
+				)
+				m.EXPECT().ListMessages(gomock.Any(), channelID, messageID, "", 20).Return(
+					&entity.Messages{
+						Messages: []*entity.Message{{ID: uuid.New().String(), TargetID: channelID}},
+						Action:   entity.ListMessagesAction,
+						TargetID: channelID,
+					}, nil,
+				)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/channels/"+channelID+"/messages?before="+messageID+"&limit=20", nil)
+				return withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: invalid limit",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/channels/"+channelID+"/messages?limit=abc", nil)
+				return withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: not a member of the channel",
+			setup: func(_ *mock.MockMessageUseCase, m1 *mock.MockChannelUseCase) {
+				m1.EXPECT().GetAccessibleChannel(gomock.Any(), userID, workspaceID, channelID).Return(nil, usecase.ErrChannelNotFound)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/channels/"+channelID+"/messages", nil)
+				return withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			muc := mock.NewMockMessageUseCase(ctrl)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(muc, cuc)
+			}
+
+			handler := NewMessageHandler(muc, cuc)
+			recorder := httptest.NewRecorder()
+			handler.ListMessages(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var body entity.Messages
+				if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode response body: %v", err)
+				}
+				if len(body.Messages) != 1 {
+					t.Fatalf("unexpected number of messages: got %v want %v", len(body.Messages), 1)
+				}
+			}
+		})
+	}
+}
+
+// 参加していない公開Channelの履歴は、WebSocketでの投稿と同じく取得できない
+func TestMessageHandler_ListMessagesRequiresChannelMembership(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name       string
+		members    []entity.MembershipChannel
+		wantStatus int
+	}{
+		{
+			name:       "success: joined member",
+			members:    []entity.MembershipChannel{{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: workspace member who has not joined the public channel",
+			members:    []entity.MembershipChannel{{UserID: uuid.New().String(), WorkspaceID: workspaceID, ChannelID: channelID}},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			muc := mock.NewMockMessageUseCase(ctrl)
+			cr := rmock.NewMockChannelRepository(ctrl)
+			mbr := rmock.NewMockMembershipRepository(ctrl)
+			mcr := rmock.NewMockMembershipChannelRepository(ctrl)
+			tr := rmock.NewMockTransactionRepository(ctrl)
+
+			cr.EXPECT().Get(gomock.Any(), channelID).Return(
+				&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "general"}, nil,
+			)
+			mbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+				&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil,
+			)
+			mcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(tt.members, nil)
+			if tt.wantStatus == http.StatusOK {
+				muc.EXPECT().ListMessages(gomock.Any(), channelID, "", "", 0).Return(
+					&entity.Messages{Action: entity.ListMessagesAction, TargetID: channelID}, nil,
+				)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/channels/"+channelID+"/messages", nil)
+			req = withUserID(withWorkspaceID(withURLParam(req, "channelID", channelID), workspaceID), userID)
+
+			handler := NewMessageHandler(muc, usecase.NewChannelUseCase(cr, mcr, mbr, tr))
+			recorder := httptest.NewRecorder()
+			handler.ListMessages(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMessageHandler_ListThreadMessages(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	parentID := uuid.New().String()
+
+	newRequest := func(path string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("channelID", channelID)
+		rctx.URLParams.Add("messageID", parentID)
+		return withUserID(withWorkspaceID(req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)), workspaceID), userID)
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockMessageUseCase,
+			m1 *mock.MockChannelUseCase,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockMessageUseCase, m1 *mock.MockChannelUseCase) {
+				m1.EXPECT().GetAccessibleChannel(gomock.Any(), userID, workspaceID, channelID).Return(
+					&entity.Channel{ID: channelID}, nil,
+				)
+				m.EXPECT().ListThreadMessages(gomock.Any(), channelID, parentID, "", "", 0).Return(
+					&entity.Messages{
+						Messages: []*entity.Message{{ID: uuid.New().String(), TargetID: channelID, ParentID: parentID}},
+						Action:   entity.ListThreadMessagesAction,
+						TargetID: channelID,
+						ParentID: parentID,
+					}, nil,
+				)
+			},
+			in: func() *http.Request {
+				return newRequest("/api/workspaces/" + workspaceID + "/channels/" + channelID + "/messages/" + parentID + "/replies")
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: parent is a reply",
+			setup: func(m *mock.MockMessageUseCase, m1 *mock.MockChannelUseCase) {
+				m1.EXPECT().GetAccessibleChannel(gomock.Any(), userID, workspaceID, channelID).Return(
+					&entity.Channel{ID: channelID}, nil,
+				)
+				m.EXPECT().ListThreadMessages(gomock.Any(), channelID, parentID, "", "", 0).Return(nil, usecase.ErrNestedReply)
+			},
+			in: func() *http.Request {
+				return newRequest("/api/workspaces/" + workspaceID + "/channels/" + channelID + "/messages/" + parentID + "/replies")
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			muc := mock.NewMockMessageUseCase(ctrl)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(muc, cuc)
+			}
+
+			handler := NewMessageHandler(muc, cuc)
+			recorder := httptest.NewRecorder()
+			handler.ListThreadMessages(recorder, tt.in())
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMessageHandler_ListMessageRevisions(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	messageID := uuid.New().String()
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockMessageUseCase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockMessageUseCase) {
+				m.EXPECT().ListMessageRevisions(gomock.Any(), userID, workspaceID, messageID).Return(
+					[]entity.MessageRevision{{ID: uuid.New().String(), MessageID: messageID, UserID: userID, Text: "before"}}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: not an admin",
+			setup: func(m *mock.MockMessageUseCase) {
+				m.EXPECT().ListMessageRevisions(gomock.Any(), userID, workspaceID, messageID).Return(nil, usecase.ErrNotWorkspaceAdmin)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: message not found",
+			setup: func(m *mock.MockMessageUseCase) {
+				m.EXPECT().ListMessageRevisions(gomock.Any(), userID, workspaceID, messageID).Return(nil, usecase.ErrMessageNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			muc := mock.NewMockMessageUseCase(ctrl)
+			cuc := mock.NewMockChannelUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(muc)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/messages/"+messageID+"/revisions", nil)
+			req = withUserID(withWorkspaceID(withURLParam(req, "messageID", messageID), workspaceID), userID)
+
+			handler := NewMessageHandler(muc, cuc)
+			recorder := httptest.NewRecorder()
+			handler.ListMessageRevisions(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var body ListMessageRevisionsResponse
+				if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode response body: %v", err)
+				}
+				if len(body.Revisions) != 1 {
+					t.Fatalf("unexpected number of revisions: got %v want %v", len(body.Revisions), 1)
+				}
+			}
+		})
+	}
+}
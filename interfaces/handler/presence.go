@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+type PresenceHandler interface {
+	ListPresences(w http.ResponseWriter, r *http.Request)
+}
+
+type presenceHandler struct {
+	puc usecase.PresenceUseCase
+}
+
+func NewPresenceHandler(puc usecase.PresenceUseCase) PresenceHandler {
+	return &presenceHandler{
+		puc: puc,
+	}
+}
+
+type PresenceResponse struct {
+	UserID      string `json:"user_id"`
+	Status      string `json:"status"`
+	Connections int    `json:"connections"`
+}
+
+type ListPresencesResponse struct {
+	Presences []PresenceResponse `json:"presences"`
+}
+
+func newPresenceResponse(presence entity.Presence) PresenceResponse {
+	return PresenceResponse{
+		UserID:      presence.UserID,
+		Status:      presence.Status,
+		Connections: presence.Connections,
+	}
+}
+
+// ListPresences はWorkspaceでonlineまたはawayのユーザを返す。含まれないユーザはofflineである
+func (ph *presenceHandler) ListPresences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceID, _ := ctx.Value(config.ContextWorkspaceIDKey).(string)
+
+	presences, err := ph.puc.ListPresences(ctx, workspaceID)
+	if err != nil {
+		log.Error("Failed to list presences", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := ListPresencesResponse{Presences: make([]PresenceResponse, len(presences))}
+	for i, presence := range presences {
+		response.Presences[i] = newPresenceResponse(presence)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestPresenceHandler_ListPresences(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockPresenceUseCase)
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockPresenceUseCase) {
+				m.EXPECT().ListPresences(gomock.Any(), workspaceID).Return(
+					[]entity.Presence{{UserID: userID, WorkspaceID: workspaceID, Status: entity.PresenceOnline, Connections: 2}}, nil,
+				)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name: "Fail: internal error",
+			setup: func(m *mock.MockPresenceUseCase) {
+				m.EXPECT().ListPresences(gomock.Any(), workspaceID).Return(nil, errors.New("redis error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			puc := mock.NewMockPresenceUseCase(ctrl)
+
+			tt.setup(puc)
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/workspaces/"+workspaceID+"/presence", nil)
+			req = withUserID(withWorkspaceID(req, workspaceID), userID)
+
+			handler := NewPresenceHandler(puc)
+			recorder := httptest.NewRecorder()
+			handler.ListPresences(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response ListPresencesResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Presences) != tt.wantCount {
+				t.Errorf("ListPresences() got = %v, want %d presences", response.Presences, tt.wantCount)
+			}
+		})
+	}
+}
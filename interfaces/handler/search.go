@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+type SearchHandler interface {
+	SearchMessages(w http.ResponseWriter, r *http.Request)
+}
+
+type searchHandler struct {
+	suc usecase.SearchUseCase
+}
+
+func NewSearchHandler(suc usecase.SearchUseCase) SearchHandler {
+	return &searchHandler{
+		suc: suc,
+	}
+}
+
+// SearchMessages は閲覧可能なChannelのメッセージを全文検索し、ハイライト付きのスニペットを返す
+// before/afterにはRFC3339形式または日付(2006-01-02)を、cursorには前のページのnext_cursorを指定する
+func (sh *searchHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	query := r.URL.Query()
+
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		log.Warn("Invalid limit", log.Fstring("limit", query.Get("limit")))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	before, err := parseSearchTime(query.Get("before"))
+	if err != nil {
+		log.Warn("Invalid before", log.Fstring("before", query.Get("before")))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	after, err := parseSearchTime(query.Get("after"))
+	if err != nil {
+		log.Warn("Invalid after", log.Fstring("after", query.Get("after")))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	results, err := sh.suc.SearchMessages(ctx, userID, usecase.SearchMessagesInput{
+		Query:     query.Get("q"),
+		ChannelID: query.Get("channel"),
+		From:      query.Get("from"),
+		Before:    before,
+		After:     after,
+		Cursor:    query.Get("cursor"),
+		Limit:     limit,
+	})
+	if err != nil {
+		log.Error("Failed to search messages", log.Fstring("userID", userID), log.Ferror(err))
+		w.WriteHeader(searchErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+func parseSearchTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil //nolint:nilnil // not specified
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func searchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidSearchQuery):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrChannelNotFound):
+		return http.StatusNotFound
+	default:
+		return messageErrorStatus(err)
+	}
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestSearchHandler_SearchMessages(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	patterns := []struct {
+		name       string
+		setup      func(m *mock.MockSearchUseCase)
+		query      string
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockSearchUseCase) {
+				m.EXPECT().SearchMessages(gomock.Any(), userID, usecase.SearchMessagesInput{
+					Query:     "hello",
+					ChannelID: channelID,
+					After:     &after,
+					Limit:     10,
+				}).Return(&entity.SearchResults{
+					Results: []entity.SearchResult{
+						{Message: &entity.Message{ID: messageID, Text: "hello"}, Snippet: "hello", Highlights: []entity.Highlight{{Start: 0, End: 5}}},
+					},
+				}, nil)
+			},
+			query:      "?q=hello&channel=" + channelID + "&after=2024-01-01&limit=10",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Fail: invalid before",
+			query:      "?q=hello&before=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: empty query",
+			setup: func(m *mock.MockSearchUseCase) {
+				m.EXPECT().SearchMessages(gomock.Any(), userID, gomock.Any()).Return(nil, usecase.ErrInvalidSearchQuery)
+			},
+			query:      "?q=",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: channel not visible",
+			setup: func(m *mock.MockSearchUseCase) {
+				m.EXPECT().SearchMessages(gomock.Any(), userID, gomock.Any()).Return(nil, usecase.ErrChannelNotFound)
+			},
+			query:      "?q=hello&channel=" + channelID,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			suc := mock.NewMockSearchUseCase(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(suc)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/api/search/messages"+tt.query, nil)
+			req = withUserID(req, userID)
+
+			handler := NewSearchHandler(suc)
+			recorder := httptest.NewRecorder()
+			handler.SearchMessages(recorder, req)
+
+			if status := recorder.Code; status != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response entity.SearchResults
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Results) != 1 || response.Results[0].Message.ID != messageID {
+				t.Errorf("SearchMessages() got = %+v", response.Results)
+			}
+		})
+	}
+}
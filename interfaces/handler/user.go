@@ -2,17 +2,32 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
 
+	"github.com/tusmasoma/go-chat-app/config"
 	"github.com/tusmasoma/go-chat-app/usecase"
 )
 
 type UserHandler interface {
 	SignUp(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
+	LoginTwoFactor(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+	JWKS(w http.ResponseWriter, r *http.Request)
+	VerifyEmail(w http.ResponseWriter, r *http.Request)
+	ResendVerificationEmail(w http.ResponseWriter, r *http.Request)
+	ForgotPassword(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
+	OIDCLogin(w http.ResponseWriter, r *http.Request)
+	OIDCCallback(w http.ResponseWriter, r *http.Request)
+	EnrollTwoFactor(w http.ResponseWriter, r *http.Request)
+	ActivateTwoFactor(w http.ResponseWriter, r *http.Request)
+	DisableTwoFactor(w http.ResponseWriter, r *http.Request)
 }
 
 type userHandler struct {
@@ -41,7 +56,13 @@ func (uh *userHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := uh.uuc.SignUpAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
+	tokens, err := uh.uuc.SignUpAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
+	if errors.Is(err, usecase.ErrEmailNotVerified) {
+		// メールアドレスの確認が必須の場合はトークンを発行しない
+		log.Info("User sign up successfully, waiting for email verification", log.Fstring("email", requestBody.Email))
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 	if err != nil {
 		log.Error("Failed to create user and generate token", log.Fstring("email", requestBody.Email), log.Ferror(err))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -49,8 +70,8 @@ func (uh *userHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info("User sign up successfully", log.Fstring("email", requestBody.Email))
-	w.Header().Set("Authorization", "Bearer "+token)
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	writeJSON(w, http.StatusOK, tokens)
 }
 
 func (uh *userHandler) isValidSignUpRequest(body io.ReadCloser, requestBody *SignUpRequest) bool {
@@ -81,16 +102,22 @@ func (uh *userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	jwt, err := uh.uuc.LoginAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
+	tokens, challenge, err := uh.uuc.LoginAndGenerateToken(ctx, requestBody.Email, requestBody.Password)
 	if err != nil {
 		log.Error("Failed to login or generate token", log.Fstring("email", requestBody.Email), log.Ferror(err))
-		http.Error(w, "Failed to Login or generate token", http.StatusInternalServerError)
+		http.Error(w, "Failed to Login or generate token", userErrorStatus(err))
+		return
+	}
+	if challenge != nil {
+		// 二要素認証が有効な場合は /api/user/login/2fa でコードを送信するとトークンを発行する
+		log.Info("User login requires two-factor authentication", log.Fstring("email", requestBody.Email))
+		writeJSON(w, http.StatusAccepted, challenge)
 		return
 	}
 
 	log.Info("User login successfully", log.Fstring("email", requestBody.Email))
-	w.Header().Set("Authorization", "Bearer "+jwt)
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	writeJSON(w, http.StatusOK, tokens)
 }
 
 func isValidLoginRequest(body io.ReadCloser, requestBody *LoginRequest) bool {
@@ -105,3 +132,350 @@ func isValidLoginRequest(body io.ReadCloser, requestBody *LoginRequest) bool {
 	}
 	return true
 }
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+func (uh *userHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var requestBody LoginTwoFactorRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.ChallengeToken == "" || requestBody.Code == "" {
+		log.Info("Invalid two-factor login request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid two-factor login request", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := uh.uuc.CompleteTwoFactorLogin(ctx, requestBody.ChallengeToken, requestBody.Code)
+	if err != nil {
+		log.Warn("Failed to complete two-factor login", log.Ferror(err))
+		http.Error(w, "Failed to complete two-factor login", userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (uh *userHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var requestBody RefreshRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.RefreshToken == "" {
+		log.Info("Invalid refresh request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid refresh request", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := uh.uuc.RefreshToken(ctx, requestBody.RefreshToken)
+	if err != nil {
+		log.Warn("Failed to refresh token", log.Ferror(err))
+		http.Error(w, "Failed to refresh token", userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (uh *userHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+	jti, _ := ctx.Value(config.ContextTokenIDKey).(string)
+
+	// リフレッシュトークンは任意
+	var requestBody LogoutRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil && !errors.Is(err, io.EOF) {
+		log.Info("Invalid logout request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid logout request", http.StatusBadRequest)
+		return
+	}
+
+	if err := uh.uuc.Logout(ctx, userID, jti, requestBody.RefreshToken); err != nil {
+		log.Error("Failed to logout", log.Fstring("userID", userID), log.Ferror(err))
+		http.Error(w, "Failed to logout", userErrorStatus(err))
+		return
+	}
+
+	log.Info("User logout successfully", log.Fstring("userID", userID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS はトークンの検証に使える公開鍵を返す。鍵のローテーション中は以前の鍵も含む
+func (uh *userHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	jwks := uh.uuc.GetJWKS(r.Context())
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, jwks)
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+func (uh *userHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var requestBody VerifyEmailRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Token == "" {
+		log.Info("Invalid verify email request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid verify email request", http.StatusBadRequest)
+		return
+	}
+
+	if err := uh.uuc.VerifyEmail(ctx, requestBody.Token); err != nil {
+		log.Warn("Failed to verify email", log.Ferror(err))
+		http.Error(w, "Failed to verify email", userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+// ResendVerificationEmail は登録の有無に関わらず 202 を返す
+func (uh *userHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var requestBody EmailRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Email == "" {
+		log.Info("Invalid resend verification request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid resend verification request", http.StatusBadRequest)
+		return
+	}
+
+	if err := uh.uuc.ResendVerificationEmail(ctx, requestBody.Email); err != nil {
+		log.Error("Failed to resend verification email", log.Fstring("email", requestBody.Email), log.Ferror(err))
+		http.Error(w, "Failed to resend verification email", userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ForgotPassword は登録の有無に関わらず 202 を返す
+func (uh *userHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var requestBody EmailRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Email == "" {
+		log.Info("Invalid forgot password request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid forgot password request", http.StatusBadRequest)
+		return
+	}
+
+	if err := uh.uuc.ForgotPassword(ctx, requestBody.Email); err != nil {
+		log.Error("Failed to send password reset email", log.Fstring("email", requestBody.Email), log.Ferror(err))
+		http.Error(w, "Failed to send password reset email", userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (uh *userHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var requestBody ResetPasswordRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Token == "" || requestBody.Password == "" {
+		log.Info("Invalid reset password request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid reset password request", http.StatusBadRequest)
+		return
+	}
+
+	if err := uh.uuc.ResetPassword(ctx, requestBody.Token, requestBody.Password); err != nil {
+		log.Warn("Failed to reset password", log.Ferror(err))
+		http.Error(w, "Failed to reset password", userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// oidcStateCookie はOIDCログインを開始したブラウザとコールバックを受けたブラウザが同じであることを確認する為のCookie
+// 攻撃者の認可コードでログインさせられること(ログインCSRF)を防ぐ
+const oidcStateCookie = "oidc_state"
+
+// OIDCLogin はOIDCプロバイダの認可エンドポイントへリダイレクトする
+func (uh *userHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	authURL, state, err := uh.uuc.BeginOIDCLogin(ctx)
+	if err != nil {
+		log.Error("Failed to begin oidc login", log.Ferror(err))
+		http.Error(w, "Failed to begin oidc login", userErrorStatus(err))
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/user/oidc",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback はOIDCプロバイダからのリダイレクトを受け、認可コードでログインしてトークンを返す
+func (uh *userHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	if errorCode := query.Get("error"); errorCode != "" {
+		log.Info("OIDC authorization was denied", log.Fstring("error", errorCode))
+		http.Error(w, "OIDC authorization was denied", http.StatusUnauthorized)
+		return
+	}
+	state, code := query.Get("state"), query.Get("code")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if state == "" || code == "" || err != nil || cookie.Value != state {
+		log.Info("Invalid oidc callback request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.Path))
+		http.Error(w, "Invalid oidc callback request", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/api/user/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	tokens, challenge, err := uh.uuc.OIDCLoginAndGenerateToken(ctx, state, code)
+	if err != nil {
+		log.Warn("Failed to login with oidc", log.Ferror(err))
+		http.Error(w, "Failed to login with oidc", userErrorStatus(err))
+		return
+	}
+	if challenge != nil {
+		// パスワードでのログインと同様に /api/user/login/2fa でコードを送信するとトークンを発行する
+		writeJSON(w, http.StatusAccepted, challenge)
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+tokens.AccessToken)
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// EnrollTwoFactor は認証アプリに登録する共有鍵とotpauth URIを返す
+func (uh *userHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+
+	enrollment, err := uh.uuc.EnrollTwoFactor(ctx, userID)
+	if err != nil {
+		log.Error("Failed to enroll two-factor authentication", log.Fstring("userID", userID), log.Ferror(err))
+		http.Error(w, "Failed to enroll two-factor authentication", userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, enrollment)
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type ActivateTwoFactorResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (uh *userHandler) ActivateTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+
+	var requestBody TwoFactorCodeRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Code == "" {
+		log.Info("Invalid activate two-factor request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid activate two-factor request", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := uh.uuc.ActivateTwoFactor(ctx, userID, requestBody.Code)
+	if err != nil {
+		log.Warn("Failed to activate two-factor authentication", log.Fstring("userID", userID), log.Ferror(err))
+		http.Error(w, "Failed to activate two-factor authentication", userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, ActivateTwoFactorResponse{RecoveryCodes: codes})
+}
+
+func (uh *userHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+
+	var requestBody TwoFactorCodeRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil || requestBody.Code == "" {
+		log.Info("Invalid disable two-factor request", log.Fstring("method", r.Method), log.Fstring("url", r.URL.String()))
+		http.Error(w, "Invalid disable two-factor request", http.StatusBadRequest)
+		return
+	}
+
+	if err := uh.uuc.DisableTwoFactor(ctx, userID, requestBody.Code); err != nil {
+		log.Warn("Failed to disable two-factor authentication", log.Fstring("userID", userID), log.Ferror(err))
+		http.Error(w, "Failed to disable two-factor authentication", userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRefreshToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrInvalidOneTimeToken):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrEmailNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrOIDCNotConfigured):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidOIDCState):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrOIDCAuthenticationFailed):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrOIDCEmailConflict):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrInvalidTwoFactorCode):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrTwoFactorNotEnrolled), errors.Is(err, usecase.ErrTwoFactorNotEnabled):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -21,14 +21,29 @@ var upgrader = websocket.Upgrader{
 }
 
 type WebsocketHandler struct {
-	hm  *ws.HubManager // 現状、Workspaceは一つの為、containerにてHubManagerを生成して、DIする
-	muc usecase.MessageUseCase
+	hmr  *ws.HubManagerRegistry
+	muc  usecase.MessageUseCase
+	cuc  usecase.ChannelUseCase
+	ruc  usecase.ReactionUseCase
+	puc  usecase.PresenceUseCase
+	rruc usecase.ReadReceiptUseCase
 }
 
-func NewWebsocketHandler(hm *ws.HubManager, muc usecase.MessageUseCase) *WebsocketHandler {
+func NewWebsocketHandler(
+	hmr *ws.HubManagerRegistry,
+	muc usecase.MessageUseCase,
+	cuc usecase.ChannelUseCase,
+	ruc usecase.ReactionUseCase,
+	puc usecase.PresenceUseCase,
+	rruc usecase.ReadReceiptUseCase,
+) *WebsocketHandler {
 	return &WebsocketHandler{
-		hm:  hm,
-		muc: muc,
+		hmr:  hmr,
+		muc:  muc,
+		cuc:  cuc,
+		ruc:  ruc,
+		puc:  puc,
+		rruc: rruc,
 	}
 }
 
@@ -40,6 +55,38 @@ func (wsh *WebsocketHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
 		log.Error("User ID not found in request context")
 		return
 	}
+	workspaceID, ok := ctx.Value(config.ContextWorkspaceIDKey).(string)
+	if !ok {
+		log.Error("Workspace ID not found in request context")
+		return
+	}
+
+	// 接続先WorkspaceのHubManagerを取得
+	hm := wsh.hmr.FindHubManagerByWorkspaceID(workspaceID)
+	if hm == nil {
+		log.Warn("HubManager not found", log.Fstring("workspaceID", workspaceID))
+		http.Error(w, "Workspace not found", http.StatusNotFound)
+		return
+	}
+
+	// ユーザが閲覧可能なChannel(公開Channelと参加しているプライベートChannel)と、参加しているChannel(DMを含む)を取得
+	channels, err := wsh.cuc.ListChannels(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to list channels", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	joinedChannels, err := wsh.cuc.ListJoinedChannels(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to list joined channels", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for _, channel := range joinedChannels {
+		if channel.IsDirectMessage() {
+			channels = append(channels, channel)
+		}
+	}
 
 	conn, err := upgrader.Upgrade(w, r, nil) // conn is *websocket.Conn
 	if err != nil {
@@ -47,24 +94,29 @@ func (wsh *WebsocketHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client, err := entity.NewClient("", userID, wsh.hm.Hub)
+	client, err := entity.NewClient("", userID, hm.Hub)
 	if err != nil {
 		log.Error("Failed to create new client", log.Ferror(err))
 		return
 	}
-	clientManager := ws.NewClientManager(client, conn, wsh.hm, wsh.muc)
+	clientManager := ws.NewClientManager(client, conn, hm, wsh.muc, wsh.cuc, wsh.ruc, wsh.puc, wsh.rruc)
 
-	go clientManager.WritePump()
-	go clientManager.ReadPump()
+	// ReadPumpの切断処理が登録より先に行われると、切断済みのClientが登録されたまま残る為、登録を終えてから起動する
+	hm.Register <- clientManager
+
+	// HubManagerに登録さているChannelのうち、参加しているChannelにClientを登録
+	// 退出した公開Channelは、再接続後も配信対象に含めない
+	hm.RegisterClientManagerInChannelManager(clientManager, joinedChannels)
 
-	wsh.hm.Register <- clientManager
+	// 閲覧可能なChannel毎の未読数を送信。WritePumpの起動前もsendのバッファに積まれる
+	clientManager.SendUnreadCounts(ctx, channels)
 
-	// HubManagerに登録さているChannelにClientを登録
-	wsh.hm.RegisterClientManagerInChannelManager(clientManager)
+	go clientManager.WritePump()
+	go clientManager.ReadPump()
 
 	log.Info(
 		"Successfully Client connected",
 		log.Fstring("userID", userID),
-		log.Fstring("workspaceID", wsh.hm.Hub.ID),
+		log.Fstring("workspaceID", hm.Hub.ID),
 	)
 }
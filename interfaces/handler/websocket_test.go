@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	ws "github.com/tusmasoma/go-chat-app/interfaces/websocket"
+	rmock "github.com/tusmasoma/go-chat-app/repository/mock"
+	"github.com/tusmasoma/go-chat-app/usecase"
+	"github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+// サインアップしたユーザが、接続後にデフォルトChannelへ投稿できる
+func TestWebsocketHandler_SignUpConnectAndPost(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	workspace := &entity.Workspace{ID: uuid.New().String(), Name: "DefaultWorkspace"}
+	channel, err := entity.NewChannel("", workspace.ID, "DefaultChannel", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultChannel := *channel
+
+	ctrl := gomock.NewController(t)
+	ur := rmock.NewMockUserRepository(ctrl)
+	mbr := rmock.NewMockMembershipRepository(ctrl)
+	cr := rmock.NewMockChannelRepository(ctrl)
+	mcr := rmock.NewMockMembershipChannelRepository(ctrl)
+	tr := rmock.NewMockTransactionRepository(ctrl)
+	ar := rmock.NewMockAuthRepository(ctrl)
+	tkr := rmock.NewMockTokenRepository(ctrl)
+	ml := rmock.NewMockMailer(ctrl)
+	psr := rmock.NewMockPubSubRepository(ctrl)
+	muc := mock.NewMockMessageUseCase(ctrl)
+
+	// 保存されたユーザとChannelへの参加を、接続時の参照でも返す
+	var (
+		mu       sync.Mutex
+		userID   string
+		joinedMC []entity.MembershipChannel
+	)
+	tr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	ur.EXPECT().LockByEmail(gomock.Any(), "test@gmail.com").Return(false, nil)
+	ur.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+		mu.Lock()
+		defer mu.Unlock()
+		userID = user.ID
+	}).Return(nil)
+	mbr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	cr.EXPECT().List(gomock.Any(), workspace.ID).Return([]entity.Channel{defaultChannel}, nil).AnyTimes()
+	mcr.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, mc entity.MembershipChannel) {
+		mu.Lock()
+		defer mu.Unlock()
+		joinedMC = append(joinedMC, mc)
+	}).Return(nil)
+	mcr.EXPECT().ListByUserID(gomock.Any(), gomock.Any(), workspace.ID).DoAndReturn(
+		func(_ context.Context, _, _ string) ([]entity.MembershipChannel, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return joinedMC, nil
+		},
+	).AnyTimes()
+	ar.EXPECT().GenerateToken(gomock.Any(), "test@gmail.com").Return("jwt", "jti", nil)
+	tkr.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+	tkr.EXPECT().SaveOneTimeToken(gomock.Any(), gomock.Any()).Return(nil)
+	ml.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	psr.EXPECT().Subscribe(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string) *redis.PubSub {
+			return rdb.Subscribe(ctx)
+		},
+	).AnyTimes()
+	psr.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	muc.EXPECT().CreateMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+	uuc := usecase.NewUserUseCase(
+		ur, mbr, cr, mcr, nil, nil, tr, ar, tkr, ml, nil,
+		&config.AuthConfig{RefreshTokenTTL: time.Hour, EmailVerificationTokenTTL: time.Hour},
+		&config.MailConfig{LinkBaseURL: "https://chat.example.com"},
+		nil,
+		&config.WorkspaceConfig{DefaultID: workspace.ID, DefaultChannels: []string{"DefaultChannel"}},
+	)
+	cuc := usecase.NewChannelUseCase(cr, mcr, mbr, tr)
+
+	if _, err = uuc.SignUpAndGenerateToken(ctx, "test@gmail.com", "password123"); err != nil {
+		t.Fatalf("SignUpAndGenerateToken() error = %v", err)
+	}
+
+	hmr := ws.NewHubManagerRegistry(psr)
+	if _, err = hmr.RegisterWorkspace(ctx, workspace, []entity.Channel{defaultChannel}); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewWebsocketHandler(hmr, muc, cuc, nil, nil, nil)
+
+	// 認証とWorkspaceの確認はミドルウェアで行われる為、そのコンテキストを設定する
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		id := userID
+		mu.Unlock()
+		ctx := context.WithValue(r.Context(), config.ContextUserIDKey, id)
+		ctx = context.WithValue(ctx, config.ContextWorkspaceIDKey, workspace.ID)
+		handler.WebSocket(w, r.WithContext(ctx))
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err = conn.WriteJSON(entity.Message{
+		Action:    entity.CreateMessageAction,
+		Text:      "hello",
+		TargetID:  defaultChannel.ID,
+		RequestID: "req-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, data, err := conn.ReadMessage() //nolint:govet // err shadowing
+		if err != nil {
+			t.Fatalf("no ACK for the posted message: %v", err)
+		}
+		var frame struct {
+			Action    string `json:"action"`
+			RequestID string `json:"request_id"`
+			Code      string `json:"code"`
+		}
+		if err = json.Unmarshal(data, &frame); err != nil {
+			t.Fatal(err)
+		}
+		if frame.RequestID != "req-1" {
+			continue
+		}
+		if frame.Action != entity.AckAction {
+			t.Fatalf("posting to the default channel failed: %s", data)
+		}
+		return
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	ws "github.com/tusmasoma/go-chat-app/interfaces/websocket"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+type WorkspaceHandler interface {
+	ListWorkspaces(w http.ResponseWriter, r *http.Request)
+	CreateWorkspace(w http.ResponseWriter, r *http.Request)
+}
+
+type workspaceHandler struct {
+	hmr *ws.HubManagerRegistry
+	wuc usecase.WorkspaceUseCase
+}
+
+func NewWorkspaceHandler(hmr *ws.HubManagerRegistry, wuc usecase.WorkspaceUseCase) WorkspaceHandler {
+	return &workspaceHandler{
+		hmr: hmr,
+		wuc: wuc,
+	}
+}
+
+type WorkspaceResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ListWorkspacesResponse struct {
+	Workspaces []WorkspaceResponse `json:"workspaces"`
+}
+
+func newWorkspaceResponse(workspace entity.Workspace) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:   workspace.ID,
+		Name: workspace.Name,
+	}
+}
+
+func (wh *workspaceHandler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+
+	workspaces, err := wh.wuc.ListWorkspaces(ctx, userID)
+	if err != nil {
+		log.Error("Failed to list workspaces", log.Fstring("userID", userID), log.Ferror(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := ListWorkspacesResponse{Workspaces: make([]WorkspaceResponse, len(workspaces))}
+	for i, workspace := range workspaces {
+		response.Workspaces[i] = newWorkspaceResponse(workspace)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+func (wh *workspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value(config.ContextUserIDKey).(string)
+
+	var requestBody CreateWorkspaceRequest
+	defer r.Body.Close()
+	if !wh.isValidCreateWorkspaceRequest(r.Body, &requestBody) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	workspace, err := wh.wuc.CreateWorkspace(ctx, userID, requestBody.Name)
+	if err != nil {
+		log.Error("Failed to create workspace", log.Fstring("name", requestBody.Name), log.Ferror(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Workspace作成と同時にHubManagerを起動し、Registryに登録する
+	// 他のサーバにはRedis経由で通知し、それぞれのRegistryにHubManagerを登録させる
+	if _, err = wh.hmr.RegisterWorkspace(context.WithoutCancel(ctx), workspace, nil); err != nil {
+		log.Error("Failed to register workspace", log.Fstring("workspaceID", workspace.ID), log.Ferror(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	wh.hmr.AnnounceWorkspace(ctx, workspace)
+
+	log.Info("Workspace created successfully", log.Fstring("workspaceID", workspace.ID))
+	writeJSON(w, http.StatusCreated, newWorkspaceResponse(*workspace))
+}
+
+func (wh *workspaceHandler) isValidCreateWorkspaceRequest(body io.ReadCloser, requestBody *CreateWorkspaceRequest) bool {
+	if err := json.NewDecoder(body).Decode(requestBody); err != nil {
+		log.Error("Failed to decode request body: %v", err)
+		return false
+	}
+	if requestBody.Name == "" {
+		log.Warn("Invalid request body: %v", requestBody)
+		return false
+	}
+	return true
+}
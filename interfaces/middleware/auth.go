@@ -20,12 +20,14 @@ type AuthMiddleware interface {
 }
 
 type authMiddleware struct {
-	ar repository.AuthRepository
+	ar  repository.AuthRepository
+	tkr repository.TokenRepository
 }
 
-func NewAuthMiddleware(ar repository.AuthRepository) AuthMiddleware {
+func NewAuthMiddleware(ar repository.AuthRepository, tkr repository.TokenRepository) AuthMiddleware {
 	return &authMiddleware{
-		ar: ar,
+		ar:  ar,
+		tkr: tkr,
 	}
 }
 
@@ -66,7 +68,21 @@ func (am *authMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		// ログアウト済みのアクセストークンは有効期限内でも拒否する
+		revoked, err := am.tkr.IsAccessTokenRevoked(ctx, payload["jti"])
+		if err != nil {
+			log.Error("Failed to check access token revocation", log.Ferror(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			log.Info("Authentication failed: access token revoked", log.Fstring("jti", payload["jti"]))
+			http.Error(w, "Authentication failed: access token revoked", http.StatusUnauthorized)
+			return
+		}
+
 		ctx = context.WithValue(ctx, config.ContextUserIDKey, payload["userId"])
+		ctx = context.WithValue(ctx, config.ContextTokenIDKey, payload["jti"])
 
 		log.Info("Successfully Authentication", log.Fstring("userID", payload["userId"]))
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type MembershipMiddleware interface {
+	Authorize(nextFunc http.Handler) http.Handler
+}
+
+type membershipMiddleware struct {
+	mr repository.MembershipRepository
+}
+
+func NewMembershipMiddleware(mr repository.MembershipRepository) MembershipMiddleware {
+	return &membershipMiddleware{
+		mr: mr,
+	}
+}
+
+// Authorize はリクエストされたWorkspaceにユーザが所属しているか検証する
+// WorkspaceIDはURLパラメータ、またはWebSocket接続のようにクエリパラメータ(workspace_id)で指定される
+func (mm *membershipMiddleware) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, ok := ctx.Value(config.ContextUserIDKey).(string)
+		if !ok || userID == "" {
+			log.Warn("Authorization failed: user ID not found in request context")
+			http.Error(w, "Authorization failed: user ID not found", http.StatusUnauthorized)
+			return
+		}
+
+		workspaceID := chi.URLParam(r, "workspaceID")
+		if workspaceID == "" {
+			workspaceID = r.URL.Query().Get("workspace_id")
+		}
+		if workspaceID == "" {
+			log.Info("Authorization failed: missing workspace ID")
+			http.Error(w, "Authorization failed: missing workspace ID", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := mm.mr.Get(ctx, userID, workspaceID); err != nil {
+			log.Warn("Authorization failed: user is not a member of the workspace", log.Fstring("userID", userID), log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+			http.Error(w, "Authorization failed: not a member of the workspace", http.StatusForbidden)
+			return
+		}
+
+		ctx = context.WithValue(ctx, config.ContextWorkspaceIDKey, workspaceID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func dummyWorkspaceTestHandler(w http.ResponseWriter, r *http.Request) {
+	workspaceIDValue := r.Context().Value(config.ContextWorkspaceIDKey)
+	if workspaceID, _ := workspaceIDValue.(string); workspaceID == "" {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestMembershipMiddleware_Authorize(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockMembershipRepository,
+		)
+		in         func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockMembershipRepository) {
+				m.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{
+						UserID:      userID,
+						WorkspaceID: workspaceID,
+						Name:        "test",
+					}, nil,
+				)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/ws?workspace_id="+workspaceID, nil)
+				return req.WithContext(context.WithValue(req.Context(), config.ContextUserIDKey, userID))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Fail: missing workspace ID",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/ws", nil)
+				return req.WithContext(context.WithValue(req.Context(), config.ContextUserIDKey, userID))
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Fail: not a member",
+			setup: func(m *mock.MockMembershipRepository) {
+				m.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					nil, errors.New("record not found"),
+				)
+			},
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/ws?workspace_id="+workspaceID, nil)
+				return req.WithContext(context.WithValue(req.Context(), config.ContextUserIDKey, userID))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "Fail: unauthenticated",
+			in: func() *http.Request {
+				req, _ := http.NewRequest(http.MethodGet, "/ws?workspace_id="+workspaceID, nil)
+				return req
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMembershipRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr)
+			}
+
+			mm := NewMembershipMiddleware(mr)
+
+			handler := mm.Authorize(http.HandlerFunc(dummyWorkspaceTestHandler))
+
+			recoder := httptest.NewRecorder()
+			handler.ServeHTTP(recoder, tt.in())
+
+			if status := recoder.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantStatus)
+			}
+		})
+	}
+}
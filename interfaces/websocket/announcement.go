@@ -0,0 +1,158 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+const (
+	announcementActionCreate = "create"
+	announcementActionUpdate = "update"
+	announcementActionDelete = "delete"
+	announcementActionJoin   = "join"
+	announcementActionLeave  = "leave"
+)
+
+// channelAnnouncement はChannelの作成・名前の変更・削除と参加者の変更を、Workspaceに接続している全サーバに通知する
+// 各サーバは通知を受けて自身のChannelManagerを更新し、接続中の参加者のClientを登録・削除する
+type channelAnnouncement struct {
+	Action          string   `json:"action"`
+	ID              string   `json:"id"`
+	WorkspaceID     string   `json:"workspace_id"`
+	Name            string   `json:"name"`
+	Private         bool     `json:"private"`
+	Kind            string   `json:"kind"`
+	ConversationKey string   `json:"conversation_key"`
+	UserIDs         []string `json:"user_ids"`
+}
+
+func (hm *HubManager) announcementTopic() string {
+	return "hub:" + hm.Hub.ID + ":channels"
+}
+
+// AnnounceChannel はChannelとその参加者を全サーバに通知する
+func (hm *HubManager) AnnounceChannel(ctx context.Context, channel *entity.Channel, userIDs []string) {
+	hm.publishAnnouncement(ctx, channelAnnouncement{
+		Action:          announcementActionCreate,
+		ID:              channel.ID,
+		WorkspaceID:     channel.WorkspaceID,
+		Name:            channel.Name,
+		Private:         channel.Private,
+		Kind:            channel.Kind,
+		ConversationKey: channel.ConversationKey,
+		UserIDs:         userIDs,
+	})
+}
+
+// AnnounceChannelUpdated はChannelの名前の変更を全サーバに通知し、各サーバのChannelManagerが持つChannelを更新させる
+func (hm *HubManager) AnnounceChannelUpdated(ctx context.Context, channel *entity.Channel) {
+	hm.publishAnnouncement(ctx, channelAnnouncement{
+		Action:      announcementActionUpdate,
+		ID:          channel.ID,
+		WorkspaceID: channel.WorkspaceID,
+		Name:        channel.Name,
+	})
+}
+
+// AnnounceChannelDeleted はChannelの削除を全サーバに通知し、各サーバのChannelManagerを停止させる
+func (hm *HubManager) AnnounceChannelDeleted(ctx context.Context, channelID string) {
+	hm.publishAnnouncement(ctx, channelAnnouncement{
+		Action:      announcementActionDelete,
+		ID:          channelID,
+		WorkspaceID: hm.Hub.ID,
+	})
+}
+
+// AnnounceMembersJoined はChannelへの参加を全サーバに通知し、参加したユーザの接続中のClientを登録させる
+func (hm *HubManager) AnnounceMembersJoined(ctx context.Context, channelID string, userIDs []string) {
+	hm.publishAnnouncement(ctx, channelAnnouncement{
+		Action:      announcementActionJoin,
+		ID:          channelID,
+		WorkspaceID: hm.Hub.ID,
+		UserIDs:     userIDs,
+	})
+}
+
+// AnnounceMembersLeft はChannelからの退出を全サーバに通知し、退出したユーザの接続中のClientを削除させる
+func (hm *HubManager) AnnounceMembersLeft(ctx context.Context, channelID string, userIDs []string) {
+	hm.publishAnnouncement(ctx, channelAnnouncement{
+		Action:      announcementActionLeave,
+		ID:          channelID,
+		WorkspaceID: hm.Hub.ID,
+		UserIDs:     userIDs,
+	})
+}
+
+func (hm *HubManager) publishAnnouncement(ctx context.Context, announcement channelAnnouncement) {
+	payload, err := json.Marshal(announcement)
+	if err != nil {
+		log.Error("Failed to encode channel announcement", log.Ferror(err))
+		return
+	}
+	if err = hm.psr.Publish(ctx, hm.announcementTopic(), payload); err != nil {
+		log.Error("Failed to publish channel announcement", log.Fstring("channelID", announcement.ID), log.Ferror(err))
+	}
+}
+
+func (hm *HubManager) subscribeToAnnouncements(ctx context.Context) {
+	pubsub := hm.psr.Subscribe(ctx, hm.announcementTopic())
+	defer pubsub.Close()
+
+	msgs := pubsub.Channel()
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			hm.handleAnnouncement(ctx, []byte(msg.Payload))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (hm *HubManager) handleAnnouncement(ctx context.Context, payload []byte) {
+	var announcement channelAnnouncement
+	if err := json.Unmarshal(payload, &announcement); err != nil {
+		log.Error("Failed to decode channel announcement", log.Ferror(err))
+		return
+	}
+
+	switch announcement.Action {
+	case announcementActionUpdate:
+		hm.RenameChannel(announcement.ID, announcement.Name)
+	case announcementActionDelete:
+		hm.UnregisterChannel(announcement.ID)
+	case announcementActionJoin:
+		for _, userID := range announcement.UserIDs {
+			hm.RegisterUserInChannel(userID, announcement.ID)
+		}
+	case announcementActionLeave:
+		for _, userID := range announcement.UserIDs {
+			hm.UnregisterUserFromChannel(userID, announcement.ID)
+		}
+	default:
+		hm.handleChannelCreated(ctx, announcement)
+	}
+}
+
+func (hm *HubManager) handleChannelCreated(ctx context.Context, announcement channelAnnouncement) {
+	channel, err := entity.NewChannel(announcement.ID, announcement.WorkspaceID, announcement.Name, announcement.Private)
+	if err != nil {
+		log.Error("Invalid channel announcement", log.Ferror(err))
+		return
+	}
+	channel.Kind = announcement.Kind
+	channel.ConversationKey = announcement.ConversationKey
+
+	hm.RegisterChannel(ctx, channel)
+	for _, userID := range announcement.UserIDs {
+		hm.RegisterUserInChannel(userID, channel.ID)
+	}
+}
@@ -0,0 +1,78 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func TestHubManager_HandleAnnouncement(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	ctrl := gomock.NewController(t)
+	psr := newTestPubSubRepository(ctrl)
+
+	hub, err := entity.NewHub(workspaceID, "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm := NewHubManager(hub, psr)
+	go hm.Run()
+
+	client, err := entity.NewClient("", userID, hub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientM := NewClientManager(client, nil, hm, nil, nil, nil, nil, nil)
+	hm.Register <- clientM
+	waitFor(t, func() bool { return len(hm.listClientManagersByUserID(userID)) == 1 })
+
+	// 他のサーバから受け取った通知と同様に、エンコードしたペイロードを処理する
+	handle := func(announcement channelAnnouncement) {
+		payload, err := json.Marshal(announcement) //nolint:govet // err shadowing
+		if err != nil {
+			t.Fatal(err)
+		}
+		hm.handleAnnouncement(ctx, payload)
+	}
+	isInChannel := func() bool {
+		cm := hm.findChannelManagerByChannelID(channelID)
+		return cm != nil && cm.isInChannel(clientM)
+	}
+
+	handle(channelAnnouncement{Action: announcementActionCreate, ID: channelID, WorkspaceID: workspaceID, Name: "general"})
+	if hm.findChannelManagerByChannelID(channelID) == nil {
+		t.Fatal("channel manager is not registered on create")
+	}
+	if isInChannel() {
+		t.Error("client is registered without joining")
+	}
+
+	handle(channelAnnouncement{Action: announcementActionJoin, ID: channelID, WorkspaceID: workspaceID, UserIDs: []string{userID}})
+	waitFor(t, isInChannel)
+
+	handle(channelAnnouncement{Action: announcementActionLeave, ID: channelID, WorkspaceID: workspaceID, UserIDs: []string{userID}})
+	waitFor(t, func() bool { return !isInChannel() })
+
+	handle(channelAnnouncement{Action: announcementActionUpdate, ID: channelID, WorkspaceID: workspaceID, Name: "random"})
+	if cm := hm.findChannelManagerByChannelID(channelID); cm == nil || cm.channel.Name != "random" {
+		t.Error("channel name is not updated")
+	}
+
+	handle(channelAnnouncement{Action: announcementActionDelete, ID: channelID, WorkspaceID: workspaceID})
+	if hm.findChannelManagerByChannelID(channelID) != nil {
+		t.Error("channel manager is still registered after delete")
+	}
+}
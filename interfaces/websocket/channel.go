@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"context"
+	"sync"
 
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
 
@@ -14,9 +15,11 @@ import (
 type channelManager struct {
 	channel        *entity.Channel
 	clientManagers map[*clientManager]bool
+	mu             sync.RWMutex // clientManagersはRedisの購読goroutineからも参照される為、ロックで保護する
 	register       chan *clientManager
 	unregister     chan *clientManager
 	broadcast      chan *entity.Message
+	quit           chan struct{}
 	psr            repository.PubSubRepository
 }
 
@@ -27,6 +30,7 @@ func NewChannelManager(channel *entity.Channel, psr repository.PubSubRepository)
 		register:       make(chan *clientManager),
 		unregister:     make(chan *clientManager),
 		broadcast:      make(chan *entity.Message),
+		quit:           make(chan struct{}),
 		psr:            psr,
 	}
 }
@@ -42,24 +46,72 @@ func (cm *channelManager) Run(ctx context.Context) {
 			cm.unregisterClientInChannel(clientM)
 		case message := <-cm.broadcast:
 			cm.publishChannelMessage(ctx, message)
+		case <-cm.quit:
+			return
 		}
 	}
 }
 
+// stop はChannelManagerのイベントループとRedisの購読を終了する
+func (cm *channelManager) stop() {
+	close(cm.quit)
+}
+
+// 以下の送信処理は、停止済みのChannelManagerに対してブロックしないようquitも監視する
+
+func (cm *channelManager) registerClientManager(clientM *clientManager) {
+	select {
+	case cm.register <- clientM:
+	case <-cm.quit:
+	}
+}
+
+func (cm *channelManager) unregisterClientManager(clientM *clientManager) {
+	select {
+	case cm.unregister <- clientM:
+	case <-cm.quit:
+	}
+}
+
+func (cm *channelManager) broadcastMessage(message *entity.Message) {
+	select {
+	case cm.broadcast <- message:
+	case <-cm.quit:
+		log.Warn("Channel already stopped", log.Fstring("channelID", cm.channel.ID))
+	}
+}
+
 func (cm *channelManager) registerClientInChannel(clientM *clientManager) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
 	cm.channel.RegisterClientInChannel(clientM.client)
 	cm.clientManagers[clientM] = true
 }
 
 func (cm *channelManager) unregisterClientInChannel(clientM *clientManager) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
 	cm.channel.UnRegisterClientInChannel(clientM.client)
 	delete(cm.clientManagers, clientM)
 }
 
+func (cm *channelManager) listClientManagers() []*clientManager {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	clientMs := make([]*clientManager, 0, len(cm.clientManagers))
+	for clientM := range cm.clientManagers {
+		clientMs = append(clientMs, clientM)
+	}
+	return clientMs
+}
+
 func (cm *channelManager) broadcastToClientsInChannel(message []byte) {
-	for clientManger := range cm.clientManagers {
+	for _, clientManger := range cm.listClientManagers() {
 		log.Info("Broadcasting message to clients in channel", log.Fstring("message", string(message)))
-		clientManger.send <- message
+		clientManger.trySend(message)
 	}
 }
 
@@ -81,12 +133,25 @@ func (cm *channelManager) subscribeToChannelMessages(ctx context.Context) {
 
 	msgs := pubsub.Channel()
 
-	for msg := range msgs {
-		cm.broadcastToClientsInChannel([]byte(msg.Payload))
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			cm.broadcastToClientsInChannel([]byte(msg.Payload))
+		case <-cm.quit:
+			return
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
 func (cm *channelManager) isInChannel(client *clientManager) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
 	_, ok := cm.clientManagers[client]
 	return ok
 }
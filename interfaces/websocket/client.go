@@ -3,6 +3,9 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -13,7 +16,11 @@ import (
 	"github.com/tusmasoma/go-chat-app/usecase"
 )
 
-var newline = []byte{'\n'}
+var (
+	errInvalidMessage = errors.New("invalid message")
+	errNotInChannel   = errors.New("client is not in channel")
+	errUnknownAction  = errors.New("unknown message action")
+)
 
 // type ClientManager interface {
 // 	ReadPump()
@@ -26,15 +33,32 @@ type clientManager struct {
 	hm     *HubManager
 	send   chan []byte
 	muc    usecase.MessageUseCase
+	cuc    usecase.ChannelUseCase
+	ruc    usecase.ReactionUseCase
+	puc    usecase.PresenceUseCase
+	rruc   usecase.ReadReceiptUseCase
+	status string // ReadPumpのgoroutineからのみ参照する
+	// 入力中の状態はタイマーのgoroutineからも参照される為、ロックで保護する
+	typing   map[string]*typingState
+	typingMu sync.Mutex
+	// sendはHubManagerのgoroutineが登録を削除した後に閉じる。他のgoroutineからの送信と競合しないよう、ロックで保護する
+	sendMu     sync.RWMutex
+	sendClosed bool
 }
 
-func NewClientManager(client *entity.Client, conn *websocket.Conn, hm *HubManager, muc usecase.MessageUseCase) *clientManager { //nolint:revive // This function is used in other packages
+func NewClientManager(client *entity.Client, conn *websocket.Conn, hm *HubManager, muc usecase.MessageUseCase, cuc usecase.ChannelUseCase, ruc usecase.ReactionUseCase, puc usecase.PresenceUseCase, rruc usecase.ReadReceiptUseCase) *clientManager { //nolint:revive // This function is used in other packages
 	return &clientManager{
 		client: client,
 		conn:   conn,
 		hm:     hm,
 		send:   make(chan []byte, config.BufferSize),
 		muc:    muc,
+		cuc:    cuc,
+		ruc:    ruc,
+		puc:    puc,
+		rruc:   rruc,
+		status: entity.PresenceOnline,
+		typing: make(map[string]*typingState),
 	}
 }
 
@@ -43,6 +67,9 @@ func (cm *clientManager) ReadPump() {
 		cm.disconnect()
 	}()
 
+	// 接続の状態の記録はRedisへのI/Oを伴う為、HubManagerのRunループではなくClient毎のこのgoroutineで行う
+	_ = cm.updatePresence(context.Background(), cm.status)
+
 	cm.conn.SetReadLimit(config.MaxMessageSize)
 	if err := cm.conn.SetReadDeadline(time.Now().Add(config.PongWait)); err != nil {
 		log.Error("Failed to set read deadline", log.Ferror(err))
@@ -53,6 +80,8 @@ func (cm *clientManager) ReadPump() {
 			log.Error("Error setting read deadline", log.Ferror(err))
 			return err
 		}
+		// pongを受信する度に接続の状態のTTLを延長する。失敗しても接続は維持する
+		_ = cm.updatePresence(context.Background(), cm.status)
 		return nil
 	})
 	// Start endless read loop, waiting for messages from client
@@ -93,34 +122,11 @@ func (cm *clientManager) WritePump() { //nolint: gocognit
 				return
 			}
 
-			w, err := cm.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				log.Error("Failed to get next writer", log.Ferror(err))
-				return
-			}
-
-			if _, err = w.Write(message); err != nil {
+			// ACK/ERRORフレームとメッセージをクライアントが個別にパースできるよう、1フレームずつ送信する
+			if err := cm.conn.WriteMessage(websocket.TextMessage, message); err != nil {
 				log.Error("Failed to write message", log.Ferror(err))
 				return
 			}
-
-			// Attach queued chat messages to the current websocket message.
-			n := len(cm.send)
-			for i := 0; i < n; i++ {
-				if _, err = w.Write(newline); err != nil {
-					log.Error("Failed to write newline", log.Ferror(err))
-					return
-				}
-				if _, err = w.Write(<-cm.send); err != nil {
-					log.Error("Failed to write queued message", log.Ferror(err))
-					return
-				}
-			}
-
-			if err = w.Close(); err != nil {
-				log.Error("Failed to close writer", log.Ferror(err))
-				return
-			}
 		case <-ticker.C:
 			if err := cm.conn.SetWriteDeadline(time.Now().Add(config.WriteWait)); err != nil {
 				log.Error("Failed to set write deadline", log.Ferror(err))
@@ -134,9 +140,13 @@ func (cm *clientManager) WritePump() { //nolint: gocognit
 	}
 }
 
+// disconnect はHubManagerに登録の削除を依頼する。sendはHubManagerのgoroutineが閉じる
+// pongのタイムアウトや読み込みエラーを含め、切断の理由に関わらずReadPumpの終了時に呼ばれる
 func (cm *clientManager) disconnect() {
+	cm.stopAllTyping()
 	cm.hm.unregister <- cm
-	close(cm.send)
+	// pongによる状態の更新と同じgoroutineで行う為、取り除いた接続が後から記録し直されることはない
+	cm.removePresence(context.Background())
 	if err := cm.conn.Close(); err != nil {
 		log.Warn("Failed to close connection", log.Ferror(err))
 	} else {
@@ -144,50 +154,370 @@ func (cm *clientManager) disconnect() {
 	}
 }
 
+// trySend はメッセージをClientの送信キューに追加する。sendは複数のgoroutineから送信される為、必ずこのメソッドを経由する
+// 切断済みのClientや、キューが溢れている遅いClientへのメッセージは破棄し、呼び出し元をブロックしない
+func (cm *clientManager) trySend(message []byte) bool {
+	cm.sendMu.RLock()
+	defer cm.sendMu.RUnlock()
+
+	if cm.sendClosed {
+		return false
+	}
+	select {
+	case cm.send <- message:
+		return true
+	default:
+		log.Warn("Client send buffer is full, dropping message", log.Fstring("clientID", cm.client.ID))
+		return false
+	}
+}
+
+// closeSend はsendを閉じ、WritePumpを終了させる。HubManagerのgoroutineからのみ呼び出す
+func (cm *clientManager) closeSend() {
+	cm.sendMu.Lock()
+	defer cm.sendMu.Unlock()
+
+	if !cm.sendClosed {
+		cm.sendClosed = true
+		close(cm.send)
+	}
+}
+
 func (cm *clientManager) handleNewMessage(jsonMessage []byte) {
 	ctx := context.Background()
 
 	var message entity.Message
 	if err := json.Unmarshal(jsonMessage, &message); err != nil {
 		log.Error("Error unmarshalling JSON message", log.Ferror(err))
+		cm.sendError("", err)
+		return
+	}
+
+	// 履歴取得はページネーション条件を含む為、別途デコードする
+	if message.Action == entity.ListMessagesAction || message.Action == entity.ListThreadMessagesAction {
+		var request listMessagesRequest
+		if err := json.Unmarshal(jsonMessage, &request); err != nil {
+			log.Error("Error unmarshalling JSON message", log.Ferror(err))
+			cm.sendError(message.RequestID, err)
+			return
+		}
+		request.Action = message.Action
+		if err := cm.handleListMessages(ctx, request); err != nil {
+			cm.sendError(message.RequestID, err)
+			return
+		}
+		cm.sendAck(message.RequestID, "", time.Time{})
+		return
+	}
+
+	if message.Action == entity.SetPresenceAction {
+		if err := cm.handleSetPresence(ctx, message.Text); err != nil {
+			cm.sendError(message.RequestID, err)
+			return
+		}
+		cm.sendAck(message.RequestID, "", time.Time{})
 		return
 	}
 
-	message.UserID = cm.client.UserID
+	result, err := cm.routeMessageAction(ctx, message)
+	if err != nil {
+		cm.sendError(message.RequestID, err)
+		return
+	}
+	cm.sendAck(message.RequestID, result.ID, result.CreatedAt)
+}
 
-	cm.routeMessageAction(ctx, message)
+// listMessagesRequest は履歴取得リクエスト
+// Before/Afterにはカーソルとなるメッセージのidを指定する
+// スレッドの履歴を取得する場合はParentIDに返信先のメッセージのidを指定する
+type listMessagesRequest struct {
+	Action   string `json:"action"`
+	TargetID string `json:"target_id"`
+	ParentID string `json:"parent_id"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+	Limit    int    `json:"limit"`
 }
 
-func (cm *clientManager) routeMessageAction(ctx context.Context, message entity.Message) {
+// handleListMessages は取得した履歴をリクエストしたClientにのみ送信する
+func (cm *clientManager) handleListMessages(ctx context.Context, request listMessagesRequest) error {
+	if !cm.isInChannel(request.TargetID) {
+		log.Warn("Client is not in channel", log.Fstring("clientID", cm.client.ID), log.Fstring("channelID", request.TargetID))
+		return errNotInChannel
+	}
+
+	var (
+		messages *entity.Messages
+		err      error
+	)
+	if request.Action == entity.ListThreadMessagesAction {
+		if request.ParentID == "" {
+			return fmt.Errorf("%w: parent_id is required", errInvalidMessage)
+		}
+		messages, err = cm.muc.ListThreadMessages(ctx, request.TargetID, request.ParentID, request.Before, request.After, request.Limit)
+	} else {
+		messages, err = cm.muc.ListMessages(ctx, request.TargetID, request.Before, request.After, request.Limit)
+	}
+	if err != nil {
+		log.Error("Failed to list messages", log.Fstring("channelID", request.TargetID), log.Ferror(err))
+		return err
+	}
+	msg, err := messages.Encode()
+	if err != nil {
+		return err
+	}
+	cm.trySend(msg)
+	return nil
+}
+
+// routeMessageAction はアクションを処理し、その結果となるメッセージを返す
+func (cm *clientManager) routeMessageAction(ctx context.Context, raw entity.Message) (*entity.Message, error) {
+	switch raw.Action {
+	case entity.JoinPublicChannelAction:
+		return cm.handleJoinPublicChannel(ctx, raw.TargetID)
+	case entity.LeavePublicChannelAction:
+		return cm.handleLeavePublicChannel(ctx, raw.TargetID)
+	case entity.CreatePublicChannelAction:
+		return cm.handleCreatePublicChannel(ctx, raw.Text)
+	case entity.AddReactionAction, entity.RemoveReactionAction:
+		return cm.handleReaction(ctx, raw)
+	case entity.TypingStartAction, entity.TypingStopAction:
+		return cm.handleTyping(raw)
+	case entity.MarkReadAction:
+		return cm.handleMarkRead(ctx, raw)
+	}
+
+	message, err := cm.newIncomingMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// 参加していないChannelへの操作は受け付けない
+	if !cm.isInChannel(message.TargetID) {
+		log.Warn("Client is not in channel", log.Fstring("clientID", cm.client.ID), log.Fstring("channelID", message.TargetID))
+		return nil, errNotInChannel
+	}
+
+	var parent *entity.Message // スレッドへの返信の場合、返信数を更新した返信先のメッセージ
 	switch message.Action {
 	case entity.CreateMessageAction:
-		if err := cm.muc.CreateMessage(ctx, &message); err != nil {
+		if err = cm.muc.CreateMessage(ctx, message); err != nil {
 			log.Error("Failed to create message", log.Ferror(err))
-			return
+			return nil, err
 		}
-		cm.broadcastMessage(message.TargetID, &message)
 	case entity.UpdateMessageAction:
-		if err := cm.muc.UpdateMessage(ctx, &message); err != nil {
+		if err = cm.muc.UpdateMessage(ctx, message); err != nil {
 			log.Error("Failed to update message", log.Ferror(err))
-			return
+			return nil, err
+		}
+	case entity.ReplyMessageAction:
+		if parent, err = cm.muc.ReplyMessage(ctx, message); err != nil {
+			log.Error("Failed to reply message", log.Ferror(err))
+			return nil, err
 		}
-		cm.broadcastMessage(message.TargetID, &message)
 	case entity.DeleteMessageAction:
-		if err := cm.muc.DeleteMessage(ctx, &message); err != nil {
+		if err = cm.muc.DeleteMessage(ctx, message); err != nil {
 			log.Error("Failed to delete message", log.Ferror(err))
-			return
+			return nil, err
+		}
+	}
+	// 返信はparent_idを持つREPLY_MESSAGEとして配信し、返信先の返信数の更新も合わせて配信する
+	cm.broadcastMessage(message.TargetID, message)
+	if parent != nil {
+		cm.broadcastThreadUpdate(parent)
+	}
+
+	if message.Action == entity.CreateMessageAction || message.Action == entity.ReplyMessageAction {
+		cm.notifyMentions(ctx, message)
+
+		// 投稿したユーザは入力を終えている為、入力中の表示を消す
+		if cm.stopTyping(message.TargetID) {
+			cm.broadcastTyping(message.TargetID, entity.TypingStopAction)
+		}
+	}
+	return message, nil
+}
+
+// newIncomingMessage はクライアントから受信したメッセージを検証する
+// 投稿者・Workspace・投稿日時はクライアントの値を信用せず、サーバ側で設定する
+func (cm *clientManager) newIncomingMessage(raw entity.Message) (*entity.Message, error) {
+	var id string
+	switch raw.Action {
+	case entity.CreateMessageAction:
+		// IDはサーバ側で採番する
+		if len(raw.AttachmentIDs) > entity.MaxAttachmentsPerMessage {
+			return nil, fmt.Errorf("%w: too many attachments", errInvalidMessage)
+		}
+	case entity.UpdateMessageAction:
+		if raw.ID == "" {
+			return nil, fmt.Errorf("%w: id is required", errInvalidMessage)
 		}
-		cm.broadcastMessage(message.TargetID, &message)
+		id = raw.ID
+	case entity.ReplyMessageAction:
+		if len(raw.AttachmentIDs) > entity.MaxAttachmentsPerMessage {
+			return nil, fmt.Errorf("%w: too many attachments", errInvalidMessage)
+		}
+		message, err := entity.NewReplyMessage("", cm.client.UserID, cm.hm.Hub.ID, raw.Text, raw.TargetID, raw.ParentID, raw.AlsoSendToChannel, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidMessage, err)
+		}
+		message.RequestID = raw.RequestID
+		message.AttachmentIDs = raw.AttachmentIDs
+		return message, nil
+	case entity.DeleteMessageAction:
+		// 削除は本文を持たない為、対象の指定のみを検証する
+		if raw.ID == "" || raw.TargetID == "" {
+			return nil, fmt.Errorf("%w: id and target_id are required", errInvalidMessage)
+		}
+		return &entity.Message{
+			ID:          raw.ID,
+			UserID:      cm.client.UserID,
+			WorkspaceID: cm.hm.Hub.ID,
+			Action:      raw.Action,
+			TargetID:    raw.TargetID,
+			RequestID:   raw.RequestID,
+		}, nil
 	default:
-		log.Warn("Unknown message action", log.Fstring("action", message.Action))
+		log.Warn("Unknown message action", log.Fstring("action", raw.Action))
+		return nil, errUnknownAction
+	}
+
+	message, err := entity.NewMessage(id, cm.client.UserID, cm.hm.Hub.ID, raw.Text, raw.Action, raw.TargetID, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidMessage, err)
+	}
+	message.RequestID = raw.RequestID
+	if raw.Action == entity.CreateMessageAction {
+		message.AttachmentIDs = raw.AttachmentIDs
+	}
+	return message, nil
+}
+
+// handleReaction はリアクションを追加・削除し、集計後のリアクションをChannelに送信する
+// idにリアクション対象のメッセージID、textに絵文字を指定する
+func (cm *clientManager) handleReaction(ctx context.Context, raw entity.Message) (*entity.Message, error) {
+	if raw.ID == "" || raw.TargetID == "" {
+		return nil, fmt.Errorf("%w: id and target_id are required", errInvalidMessage)
+	}
+	if !cm.isInChannel(raw.TargetID) {
+		log.Warn("Client is not in channel", log.Fstring("clientID", cm.client.ID), log.Fstring("channelID", raw.TargetID))
+		return nil, errNotInChannel
+	}
+
+	var (
+		reactions []entity.ReactionCount
+		err       error
+	)
+	if raw.Action == entity.AddReactionAction {
+		reactions, err = cm.ruc.AddReaction(ctx, cm.client.UserID, cm.hm.Hub.ID, raw.TargetID, raw.ID, raw.Text)
+	} else {
+		reactions, err = cm.ruc.RemoveReaction(ctx, cm.client.UserID, cm.hm.Hub.ID, raw.TargetID, raw.ID, raw.Text)
 	}
+	if err != nil {
+		log.Error("Failed to update reaction", log.Fstring("messageID", raw.ID), log.Ferror(err))
+		return nil, err
+	}
+
+	message := &entity.Message{
+		ID:          raw.ID,
+		UserID:      cm.client.UserID,
+		WorkspaceID: cm.hm.Hub.ID,
+		Text:        raw.Text,
+		CreatedAt:   time.Now(),
+		Action:      raw.Action,
+		TargetID:    raw.TargetID,
+		Reactions:   reactions,
+	}
+	cm.broadcastMessage(raw.TargetID, message)
+	return message, nil
 }
 
 func (cm *clientManager) broadcastMessage(channelID string, message *entity.Message) {
 	if channel := cm.hm.findChannelManagerByChannelID(channelID); channel != nil {
 		log.Info("Broadcasting message", log.Fstring("channelID", channelID), log.Fstring("messageID", message.ID))
-		channel.broadcast <- message
+		channel.broadcastMessage(message)
 	} else {
 		log.Warn("Channel not found", log.Fstring("channelID", channelID))
 	}
 }
+
+func (cm *clientManager) isInChannel(channelID string) bool {
+	channel := cm.hm.findChannelManagerByChannelID(channelID)
+	return channel != nil && channel.isInChannel(cm)
+}
+
+func (cm *clientManager) handleJoinPublicChannel(ctx context.Context, channelID string) (*entity.Message, error) {
+	membership, err := cm.cuc.JoinChannel(ctx, cm.client.UserID, cm.hm.Hub.ID, channelID)
+	if err != nil {
+		log.Error("Failed to join channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+	// 続くリクエストに備えて接続中のサーバでは即座に登録し、他のサーバに接続しているユーザのClientにはRedis経由で通知する
+	cm.hm.RegisterUserInChannel(cm.client.UserID, channelID)
+	cm.hm.AnnounceMembersJoined(ctx, channelID, []string{cm.client.UserID})
+
+	return cm.broadcastSystemMessage(channelID, entity.JoinPublicChannelAction, fmt.Sprintf(config.WelcomeMessage, membership.Name))
+}
+
+func (cm *clientManager) handleLeavePublicChannel(ctx context.Context, channelID string) (*entity.Message, error) {
+	membership, err := cm.cuc.LeaveChannel(ctx, cm.client.UserID, cm.hm.Hub.ID, channelID)
+	if err != nil {
+		log.Error("Failed to leave channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+
+	// 退出するユーザにもGoodbyeMessageが届くよう、送信後にChannelから登録を削除する
+	message, err := cm.broadcastSystemMessage(channelID, entity.LeavePublicChannelAction, fmt.Sprintf(config.GoodbyeMessage, membership.Name))
+	cm.hm.UnregisterUserFromChannel(cm.client.UserID, channelID)
+	cm.hm.AnnounceMembersLeft(ctx, channelID, []string{cm.client.UserID})
+	return message, err
+}
+
+func (cm *clientManager) handleCreatePublicChannel(ctx context.Context, name string) (*entity.Message, error) {
+	channel, err := cm.cuc.CreateChannel(ctx, cm.client.UserID, cm.hm.Hub.ID, name, false)
+	if err != nil {
+		log.Error("Failed to create channel", log.Fstring("name", name), log.Ferror(err))
+		return nil, err
+	}
+	cm.hm.RegisterChannel(ctx, channel)
+	cm.hm.RegisterUserInChannel(cm.client.UserID, channel.ID)
+	cm.hm.AnnounceChannel(ctx, channel, []string{cm.client.UserID})
+
+	// 作成直後のChannelはRedisの購読が完了していない可能性がある為、Hub経由でWorkspaceの全Clientに通知する
+	message, err := entity.NewMessage("", cm.client.UserID, cm.hm.Hub.ID, channel.Name, entity.CreatePublicChannelAction, channel.ID, time.Time{})
+	if err != nil {
+		log.Error("Failed to create system message", log.Ferror(err))
+		return nil, err
+	}
+	msg, err := message.Encode()
+	if err != nil {
+		return nil, err
+	}
+	cm.hm.broadcast <- msg
+	return message, nil
+}
+
+// broadcastSystemMessage はDBに保存しないシステムメッセージをChannelに送信する
+func (cm *clientManager) broadcastSystemMessage(channelID, action, text string) (*entity.Message, error) {
+	message, err := entity.NewMessage("", cm.client.UserID, cm.hm.Hub.ID, text, action, channelID, time.Time{})
+	if err != nil {
+		log.Error("Failed to create system message", log.Ferror(err))
+		return nil, err
+	}
+	cm.broadcastMessage(channelID, message)
+	return message, nil
+}
+
+// broadcastThreadUpdate は返信先のメッセージの返信数と最終返信日時をUPDATE_THREADとしてChannelに配信する
+func (cm *clientManager) broadcastThreadUpdate(parent *entity.Message) {
+	cm.broadcastMessage(parent.TargetID, &entity.Message{
+		ID:          parent.ID,
+		UserID:      parent.UserID,
+		WorkspaceID: parent.WorkspaceID,
+		CreatedAt:   parent.CreatedAt,
+		Action:      entity.UpdateThreadAction,
+		TargetID:    parent.TargetID,
+		ReplyCount:  parent.ReplyCount,
+		LastReplyAt: parent.LastReplyAt,
+	})
+}
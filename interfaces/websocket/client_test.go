@@ -0,0 +1,107 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	rmock "github.com/tusmasoma/go-chat-app/repository/mock"
+	umock "github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestClientManager_RouteReplyMessage(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	parentID := uuid.New().String()
+	lastReplyAt := time.Now().UTC().Truncate(time.Second)
+
+	ctrl := gomock.NewController(t)
+	muc := umock.NewMockMessageUseCase(ctrl)
+
+	channel, err := entity.NewChannel("", workspaceID, "general", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Channelに配信されたメッセージをRedisへの送信時に取り出す
+	published := make(chan entity.Message, 2)
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	psr := rmock.NewMockPubSubRepository(ctrl)
+	psr.EXPECT().Subscribe(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string) *redis.PubSub {
+			return rdb.Subscribe(ctx)
+		},
+	).AnyTimes()
+	psr.EXPECT().Publish(gomock.Any(), channel.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, msg []byte) error {
+			var message entity.Message
+			if err := json.Unmarshal(msg, &message); err != nil { //nolint:govet // err shadowing
+				t.Error(err)
+			}
+			published <- message
+			return nil
+		},
+	).Times(2)
+
+	muc.EXPECT().ReplyMessage(gomock.Any(), gomock.Any()).Return(&entity.Message{
+		ID:          parentID,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		TargetID:    channel.ID,
+		ReplyCount:  3,
+		LastReplyAt: &lastReplyAt,
+	}, nil)
+
+	hub, err := entity.NewHub(workspaceID, "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm := NewHubManager(hub, psr)
+	go hm.Run()
+	hm.RegisterChannel(ctx, channel)
+	cm := hm.findChannelManagerByChannelID(channel.ID)
+
+	client, err := entity.NewClient("", userID, hub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientM := NewClientManager(client, nil, hm, muc, nil, nil, nil, nil)
+	hm.Register <- clientM
+	hm.RegisterClientManagerInChannelManager(clientM, []entity.Channel{*channel})
+	waitFor(t, func() bool { return cm.isInChannel(clientM) })
+
+	if _, err = clientM.routeMessageAction(ctx, entity.Message{
+		Action:   entity.ReplyMessageAction,
+		Text:     "reply",
+		TargetID: channel.ID,
+		ParentID: parentID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 返信はスレッドの返信であることが分かるよう、返信先のidを持つ
+	reply := <-published
+	if reply.Action != entity.ReplyMessageAction || reply.ParentID != parentID {
+		t.Errorf("reply = %+v, want action %s with parent_id %s", reply, entity.ReplyMessageAction, parentID)
+	}
+
+	// 返信先の返信数と最終返信日時の更新も配信する
+	update := <-published
+	if update.Action != entity.UpdateThreadAction || update.ID != parentID {
+		t.Errorf("thread update = %+v, want action %s for %s", update, entity.UpdateThreadAction, parentID)
+	}
+	if update.ReplyCount != 3 || update.LastReplyAt == nil || !update.LastReplyAt.Equal(lastReplyAt) {
+		t.Errorf("thread update reply summary = (%d, %v), want (3, %v)", update.ReplyCount, update.LastReplyAt, lastReplyAt)
+	}
+}
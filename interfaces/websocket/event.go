@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+)
+
+// workspaceEvent はWorkspaceに接続している全サーバに配信するイベント
+// UserIDが指定されている場合は、そのユーザのClientにのみ送信する
+type workspaceEvent struct {
+	UserID  string          `json:"user_id,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func (hm *HubManager) eventTopic() string {
+	return "hub:" + hm.Hub.ID + ":events"
+}
+
+// publishEvent はWorkspaceに接続している全サーバのClientにイベントを送信する
+// HubManagerのRunループ内からも呼ばれる為、hm.broadcastには直接送信せずRedis経由で配信する
+func (hm *HubManager) publishEvent(ctx context.Context, payload []byte) {
+	hm.publishEventToUser(ctx, "", payload)
+}
+
+// publishEventToUser は全サーバに接続しているユーザのClientにイベントを送信する
+func (hm *HubManager) publishEventToUser(ctx context.Context, userID string, payload []byte) {
+	event, err := json.Marshal(workspaceEvent{UserID: userID, Payload: payload})
+	if err != nil {
+		log.Error("Failed to encode workspace event", log.Ferror(err))
+		return
+	}
+	if err = hm.psr.Publish(ctx, hm.eventTopic(), event); err != nil {
+		log.Error("Failed to publish workspace event", log.Fstring("workspaceID", hm.Hub.ID), log.Ferror(err))
+	}
+}
+
+func (hm *HubManager) subscribeToEvents(ctx context.Context) {
+	pubsub := hm.psr.Subscribe(ctx, hm.eventTopic())
+	defer pubsub.Close()
+
+	msgs := pubsub.Channel()
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			hm.handleEvent([]byte(msg.Payload))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (hm *HubManager) handleEvent(payload []byte) {
+	var event workspaceEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		log.Error("Failed to decode workspace event", log.Ferror(err))
+		return
+	}
+
+	if event.UserID == "" {
+		hm.broadcast <- event.Payload
+		return
+	}
+	for _, clientM := range hm.listClientManagersByUserID(event.UserID) {
+		clientM.trySend(event.Payload)
+	}
+}
@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/usecase"
+)
+
+// sendAck はリクエストの処理結果をリクエストしたClientにのみ送信する
+func (cm *clientManager) sendAck(requestID, messageID string, createdAt time.Time) {
+	msg, err := entity.NewAckFrame(requestID, messageID, createdAt).Encode()
+	if err != nil {
+		return
+	}
+	cm.trySend(msg)
+}
+
+// sendError はリクエストの失敗をリクエストしたClientにのみ送信する
+func (cm *clientManager) sendError(requestID string, cause error) {
+	frame, err := entity.NewErrorFrame(requestID, errorCode(cause), cause.Error())
+	if err != nil {
+		return
+	}
+	msg, err := frame.Encode()
+	if err != nil {
+		return
+	}
+	cm.trySend(msg)
+}
+
+func errorCode(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.Is(err, errInvalidMessage),
+		errors.Is(err, usecase.ErrNestedReply), errors.Is(err, usecase.ErrInvalidReaction),
+		errors.Is(err, usecase.ErrInvalidPresenceStatus):
+		return entity.ErrorCodeInvalidMessage
+	case errors.Is(err, errUnknownAction):
+		return entity.ErrorCodeUnknownAction
+	case errors.Is(err, errNotInChannel),
+		errors.Is(err, usecase.ErrChannelNotFound),
+		errors.Is(err, usecase.ErrMessageNotFound),
+		errors.Is(err, usecase.ErrAttachmentNotFound):
+		return entity.ErrorCodeNotFound
+	case errors.Is(err, usecase.ErrNotMessageAuthor),
+		errors.Is(err, usecase.ErrNotChannelOwner):
+		return entity.ErrorCodeForbidden
+	default:
+		return entity.ErrorCodeInternal
+	}
+}
@@ -2,8 +2,10 @@ package websocket
 
 import (
 	"context"
+	"sync"
 
 	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
 )
 
 // type HubManager interface{}
@@ -12,19 +14,22 @@ type HubManager struct {
 	Hub             *entity.Hub
 	clientManagers  map[*clientManager]bool
 	channelManagers map[*channelManager]bool
+	mu              sync.RWMutex // clientManagersとchannelManagersはHTTPハンドラからも参照される為、ロックで保護する
 	Register        chan *clientManager
 	unregister      chan *clientManager
 	broadcast       chan []byte
+	psr             repository.PubSubRepository
 }
 
-func NewHubManager(hub *entity.Hub) HubManager {
-	return HubManager{
+func NewHubManager(hub *entity.Hub, psr repository.PubSubRepository) *HubManager {
+	return &HubManager{
 		Hub:             hub,
 		clientManagers:  make(map[*clientManager]bool),
 		channelManagers: make(map[*channelManager]bool),
 		Register:        make(chan *clientManager),
 		unregister:      make(chan *clientManager),
 		broadcast:       make(chan []byte),
+		psr:             psr,
 	}
 }
 
@@ -42,25 +47,69 @@ func (hm *HubManager) Run() {
 }
 
 func (hm *HubManager) registerClient(clientM *clientManager) {
+	hm.mu.Lock()
 	hm.Hub.RegisterClient(clientM.client)
 	hm.clientManagers[clientM] = true
+	hm.mu.Unlock()
 }
 
 func (hm *HubManager) unregisterClient(clientM *clientManager) {
-	for cm := range hm.channelManagers {
-		cm.unregister <- clientM
+	for _, cm := range hm.listChannelManagers() {
+		cm.unregisterClientManager(clientM)
 	}
+
+	hm.mu.Lock()
 	hm.Hub.UnRegisterClient(clientM.client)
 	delete(hm.clientManagers, clientM)
+	hm.mu.Unlock()
+
+	// 全てのChannelManagerとHubManagerから削除した後にsendを閉じる
+	// 削除前に取得した一覧から送信されても、trySendが閉じたsendには送信しない
+	clientM.closeSend()
 }
 
 func (hm *HubManager) broadcastToClients(message []byte) {
-	for cm := range hm.clientManagers {
-		cm.send <- message
+	for _, cm := range hm.listClientManagers() {
+		cm.trySend(message)
+	}
+}
+
+func (hm *HubManager) listClientManagers() []*clientManager {
+	hm.mu.RLock()
+	defer hm.mu.RUnlock()
+
+	clientMs := make([]*clientManager, 0, len(hm.clientManagers))
+	for clientM := range hm.clientManagers {
+		clientMs = append(clientMs, clientM)
 	}
+	return clientMs
+}
+
+func (hm *HubManager) listClientManagersByUserID(userID string) []*clientManager {
+	clientMs := []*clientManager{}
+	for _, clientM := range hm.listClientManagers() {
+		if clientM.client.UserID == userID {
+			clientMs = append(clientMs, clientM)
+		}
+	}
+	return clientMs
+}
+
+func (hm *HubManager) listChannelManagers() []*channelManager {
+	hm.mu.RLock()
+	defer hm.mu.RUnlock()
+
+	cms := make([]*channelManager, 0, len(hm.channelManagers))
+	for cm := range hm.channelManagers {
+		cms = append(cms, cm)
+	}
+	return cms
 }
 
 func (hm *HubManager) findChannelManagerByChannelID(channelID string) *channelManager {
+	hm.mu.RLock()
+	defer hm.mu.RUnlock()
+
 	for cm := range hm.channelManagers {
 		if cm.channel.ID == channelID {
 			return cm
@@ -69,30 +118,102 @@ func (hm *HubManager) findChannelManagerByChannelID(channelID string) *channelMa
 	return nil
 }
 
+// RegisterChannel は新しく作成されたChannelのChannelManagerを起動し、HubManagerに登録する
+// Clientの登録は行わない。公開ChannelでもMembership_Channelsで参加しているユーザのみを、RegisterUserInChannelで登録する
+// 既に登録済みのChannelは何もしない
 func (hm *HubManager) RegisterChannel(ctx context.Context, channel *entity.Channel) {
-	cm := NewChannelManager(channel, nil)
-	go cm.Run(ctx)
+	hm.mu.Lock()
+	for cm := range hm.channelManagers {
+		if cm.channel.ID == channel.ID {
+			hm.mu.Unlock()
+			return
+		}
+	}
+	cm := NewChannelManager(channel, hm.psr)
 	hm.channelManagers[cm] = true
+	hm.mu.Unlock()
+
+	go cm.Run(ctx)
 }
 
 func (hm *HubManager) RegisterChannelManager(cm *channelManager) { // 一旦DIのためのメソッドを追加
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
 	hm.channelManagers[cm] = true
 }
 
-// HubManagerに登録されているChannelManagerのClientManagerにClientを登録
-func (hm *HubManager) RegisterClientManagerInChannelManager(clientManager *clientManager) {
+// RenameChannel は名前が変更されたChannelについて、ChannelManagerが持つChannelの名前を更新する
+func (hm *HubManager) RenameChannel(channelID, name string) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
 	for cm := range hm.channelManagers {
-		if !cm.isInChannel(clientManager) {
-			cm.register <- clientManager
+		if cm.channel.ID == channelID {
+			cm.channel.Name = name
+			return
+		}
+	}
+}
+
+// UnregisterChannel は削除されたChannelのChannelManagerを停止し、HubManagerから取り除く
+func (hm *HubManager) UnregisterChannel(channelID string) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	for cm := range hm.channelManagers {
+		if cm.channel.ID == channelID {
+			delete(hm.channelManagers, cm)
+			cm.stop()
+			return
+		}
+	}
+}
+
+// HubManagerに登録されているChannelManagerのうち、ユーザが参加しているChannelにClientを登録
+func (hm *HubManager) RegisterClientManagerInChannelManager(clientManager *clientManager, channels []entity.Channel) {
+	joined := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		joined[channel.ID] = true
+	}
+	for _, cm := range hm.listChannelManagers() {
+		if joined[cm.channel.ID] && !cm.isInChannel(clientManager) {
+			cm.registerClientManager(clientManager)
 		}
 	}
 }
 
 // channelManagerから該当するclientManagerの登録を削除する
 func (hm *HubManager) UnRegisterClientManagerInChannelManager(clientManager *clientManager) {
-	for cm := range hm.channelManagers {
+	for _, cm := range hm.listChannelManagers() {
 		if cm.isInChannel(clientManager) {
-			cm.unregister <- clientManager
+			cm.unregisterClientManager(clientManager)
+		}
+	}
+}
+
+// RegisterUserInChannel はユーザの接続中のClientを全てChannelに登録する
+func (hm *HubManager) RegisterUserInChannel(userID, channelID string) {
+	cm := hm.findChannelManagerByChannelID(channelID)
+	if cm == nil {
+		return
+	}
+	for _, clientM := range hm.listClientManagersByUserID(userID) {
+		if !cm.isInChannel(clientM) {
+			cm.registerClientManager(clientM)
+		}
+	}
+}
+
+// UnregisterUserFromChannel はユーザの接続中のClientを全てChannelから削除する
+func (hm *HubManager) UnregisterUserFromChannel(userID, channelID string) {
+	cm := hm.findChannelManagerByChannelID(channelID)
+	if cm == nil {
+		return
+	}
+	for _, clientM := range hm.listClientManagersByUserID(userID) {
+		if cm.isInChannel(clientM) {
+			cm.unregisterClientManager(clientM)
 		}
 	}
 }
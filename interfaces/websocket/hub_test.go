@@ -0,0 +1,187 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	rmock "github.com/tusmasoma/go-chat-app/repository/mock"
+	umock "github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+// newTestPubSubRepository はRedisに接続せずにChannelManagerを起動できるPubSubRepositoryを返す
+// 配信はbroadcastToClientsInChannelを直接呼び出して確認する
+func newTestPubSubRepository(ctrl *gomock.Controller) *rmock.MockPubSubRepository {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	psr := rmock.NewMockPubSubRepository(ctrl)
+	psr.EXPECT().Subscribe(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ string) *redis.PubSub {
+			return rdb.Subscribe(ctx)
+		},
+	).AnyTimes()
+	psr.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return psr
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHubManager_LeftPublicChannelIsNotDeliveredAfterReconnect(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	workspaceID := uuid.New().String()
+	aliceID := uuid.New().String()
+	bobID := uuid.New().String()
+
+	ctrl := gomock.NewController(t)
+	psr := newTestPubSubRepository(ctrl)
+	cuc := umock.NewMockChannelUseCase(ctrl)
+
+	channel, err := entity.NewChannel("", workspaceID, "general", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gomock.InOrder(
+		cuc.EXPECT().ListJoinedChannels(gomock.Any(), aliceID, workspaceID).Return([]entity.Channel{*channel}, nil),
+		cuc.EXPECT().LeaveChannel(gomock.Any(), aliceID, workspaceID, channel.ID).Return(&entity.Membership{UserID: aliceID, Name: "alice"}, nil),
+		cuc.EXPECT().ListJoinedChannels(gomock.Any(), aliceID, workspaceID).Return([]entity.Channel{}, nil),
+	)
+	cuc.EXPECT().ListJoinedChannels(gomock.Any(), bobID, workspaceID).Return([]entity.Channel{*channel}, nil)
+
+	hub, err := entity.NewHub(workspaceID, "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm := NewHubManager(hub, psr)
+	go hm.Run()
+	hm.RegisterChannel(ctx, channel)
+	cm := hm.findChannelManagerByChannelID(channel.ID)
+
+	// handler.WebsocketHandlerの接続処理と同様に、参加しているChannelにのみClientを登録する
+	connect := func(userID string) *clientManager {
+		joinedChannels, err := cuc.ListJoinedChannels(ctx, userID, workspaceID) //nolint:govet // err shadowing
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := entity.NewClient("", userID, hub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientM := NewClientManager(client, nil, hm, nil, cuc, nil, nil, nil)
+		hm.Register <- clientM
+		hm.RegisterClientManagerInChannelManager(clientM, joinedChannels)
+		return clientM
+	}
+
+	alice := connect(aliceID)
+	bob := connect(bobID)
+	waitFor(t, func() bool { return cm.isInChannel(alice) && cm.isInChannel(bob) })
+
+	if _, err = alice.handleLeavePublicChannel(ctx, channel.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return !cm.isInChannel(alice) })
+	hm.unregister <- alice
+
+	reconnected := connect(aliceID)
+
+	// 新たに作成された公開Channelにも、参加していない接続中のClientは登録されない
+	other, err := entity.NewChannel("", workspaceID, "random", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm.RegisterChannel(ctx, other)
+	if hm.findChannelManagerByChannelID(other.ID).isInChannel(reconnected) {
+		t.Error("reconnected client is registered in a channel it has not joined")
+	}
+
+	cm.broadcastToClientsInChannel([]byte("hello"))
+
+	if cm.isInChannel(reconnected) {
+		t.Error("reconnected client is registered in the left channel")
+	}
+	if got := len(reconnected.send); got != 0 {
+		t.Errorf("reconnected client received %d messages, want 0", got)
+	}
+	if got := len(bob.send); got != 1 {
+		t.Errorf("member client received %d messages, want 1", got)
+	}
+}
+
+func TestHubManager_UnregisterClientWhileBroadcasting(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	ctrl := gomock.NewController(t)
+	psr := newTestPubSubRepository(ctrl)
+
+	hub, err := entity.NewHub(workspaceID, "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm := NewHubManager(hub, psr)
+	go hm.Run()
+
+	channel, err := entity.NewChannel("", workspaceID, "general", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm.RegisterChannel(ctx, channel)
+	cm := hm.findChannelManagerByChannelID(channel.ID)
+
+	client, err := entity.NewClient("", userID, hub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientM := NewClientManager(client, nil, hm, nil, nil, nil, nil, nil)
+	hm.Register <- clientM
+	hm.RegisterClientManagerInChannelManager(clientM, []entity.Channel{*channel})
+	waitFor(t, func() bool { return cm.isInChannel(clientM) })
+
+	// 切断と並行して、Redisの購読goroutineからの配信が続いてもpanicしない
+	payload, err := json.Marshal(workspaceEvent{UserID: userID, Payload: []byte(`"event"`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			cm.broadcastToClientsInChannel([]byte("hello"))
+			hm.handleEvent(payload)
+		}
+	}()
+	hm.unregister <- clientM
+	<-done
+
+	waitFor(t, func() bool {
+		clientM.sendMu.RLock()
+		defer clientM.sendMu.RUnlock()
+		return clientM.sendClosed
+	})
+	if clientM.trySend([]byte("hello")) {
+		t.Error("message is sent to a disconnected client")
+	}
+}
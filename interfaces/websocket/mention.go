@@ -0,0 +1,19 @@
+package websocket
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+// notifyMentions はメッセージと同時に保存されたメンションについて、メンションされたユーザの全ての接続にMENTIONを送信する
+// ユーザがChannelを閲覧していない場合も送信する
+func (cm *clientManager) notifyMentions(ctx context.Context, message *entity.Message) {
+	for _, mention := range message.Mentions {
+		msg, err := entity.NewMentionEvent(mention.Kind, message).Encode()
+		if err != nil {
+			continue
+		}
+		cm.hm.publishEventToUser(ctx, mention.UserID, msg)
+	}
+}
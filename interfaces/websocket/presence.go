@@ -0,0 +1,58 @@
+package websocket
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+// updatePresence は接続の状態を記録し、ユーザの状態が変わった場合はWorkspaceに通知する
+func (cm *clientManager) updatePresence(ctx context.Context, status string) error {
+	if cm.puc == nil {
+		return nil
+	}
+	presence, changed, err := cm.puc.Heartbeat(ctx, cm.hm.Hub.ID, cm.client.UserID, cm.client.ID, status)
+	if err != nil {
+		log.Error("Failed to update presence", log.Fstring("clientID", cm.client.ID), log.Ferror(err))
+		return err
+	}
+	if changed {
+		cm.publishPresence(ctx, presence)
+	}
+	return nil
+}
+
+// removePresence は切断された接続を取り除き、ユーザの状態が変わった場合はWorkspaceに通知する
+// 接続の状態のTTLが先に切れていた場合は状態が変わらない為、他の接続が残っていなければofflineを改めて通知する
+func (cm *clientManager) removePresence(ctx context.Context) {
+	if cm.puc == nil {
+		return
+	}
+	presence, changed, err := cm.puc.Disconnect(ctx, cm.hm.Hub.ID, cm.client.UserID, cm.client.ID)
+	if err != nil {
+		log.Error("Failed to remove presence", log.Fstring("clientID", cm.client.ID), log.Ferror(err))
+		return
+	}
+	if changed || presence.Status == entity.PresenceOffline {
+		cm.publishPresence(ctx, presence)
+	}
+}
+
+func (cm *clientManager) publishPresence(ctx context.Context, presence *entity.Presence) {
+	msg, err := presence.Encode()
+	if err != nil {
+		return
+	}
+	cm.hm.publishEvent(ctx, msg)
+}
+
+// handleSetPresence はクライアントが指定した状態(online/away)を接続に設定する
+func (cm *clientManager) handleSetPresence(ctx context.Context, status string) error {
+	if err := cm.updatePresence(ctx, status); err != nil {
+		return err
+	}
+	cm.status = status
+	return nil
+}
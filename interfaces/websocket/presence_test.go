@@ -0,0 +1,104 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	rmock "github.com/tusmasoma/go-chat-app/repository/mock"
+	umock "github.com/tusmasoma/go-chat-app/usecase/mock"
+)
+
+func TestClientManager_RemovePresence(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name     string
+		presence *entity.Presence
+		changed  bool
+		publish  bool
+	}{
+		{
+			name:     "success: last connection is removed",
+			presence: &entity.Presence{Action: entity.PresenceAction, UserID: userID, WorkspaceID: workspaceID, Status: entity.PresenceOffline},
+			changed:  true,
+			publish:  true,
+		},
+		{
+			name:     "success: presence has already expired",
+			presence: &entity.Presence{Action: entity.PresenceAction, UserID: userID, WorkspaceID: workspaceID, Status: entity.PresenceOffline},
+			changed:  false,
+			publish:  true,
+		},
+		{
+			name:     "success: other connection remains online",
+			presence: &entity.Presence{Action: entity.PresenceAction, UserID: userID, WorkspaceID: workspaceID, Status: entity.PresenceOnline, Connections: 1},
+			changed:  false,
+			publish:  false,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			psr := rmock.NewMockPubSubRepository(ctrl)
+			puc := umock.NewMockPresenceUseCase(ctrl)
+
+			hub, err := entity.NewHub(workspaceID, "workspace")
+			if err != nil {
+				t.Fatal(err)
+			}
+			hm := NewHubManager(hub, psr)
+			client, err := entity.NewClient("", userID, hub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clientM := NewClientManager(client, nil, hm, nil, nil, nil, puc, nil)
+
+			puc.EXPECT().Disconnect(gomock.Any(), workspaceID, userID, client.ID).Return(tt.presence, tt.changed, nil)
+			if tt.publish {
+				psr.EXPECT().Publish(gomock.Any(), hm.eventTopic(), gomock.Any()).Return(nil)
+			}
+
+			clientM.removePresence(context.Background())
+		})
+	}
+}
+
+func TestHubManager_RunDoesNotUpdatePresence(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	ctrl := gomock.NewController(t)
+	psr := newTestPubSubRepository(ctrl)
+	// Redisへの状態の記録はClientのgoroutineで行い、HubManagerのRunループでは呼ばれない
+	puc := umock.NewMockPresenceUseCase(ctrl)
+
+	hub, err := entity.NewHub(workspaceID, "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hm := NewHubManager(hub, psr)
+	go hm.Run()
+
+	client, err := entity.NewClient("", userID, hub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientM := NewClientManager(client, nil, hm, nil, nil, nil, puc, nil)
+	hm.Register <- clientM
+	waitFor(t, func() bool { return len(hm.listClientManagersByUserID(userID)) == 1 })
+	hm.unregister <- clientM
+	waitFor(t, func() bool { return len(hm.listClientManagersByUserID(userID)) == 0 })
+}
@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+// handleMarkRead はChannelの既読位置をidのメッセージまで進める
+// 既読位置が更新された場合は、同じユーザの他の接続にもMARK_READを送信して同期する
+func (cm *clientManager) handleMarkRead(ctx context.Context, raw entity.Message) (*entity.Message, error) {
+	if raw.ID == "" || raw.TargetID == "" {
+		return nil, fmt.Errorf("%w: id and target_id are required", errInvalidMessage)
+	}
+	if !cm.isInChannel(raw.TargetID) {
+		log.Warn("Client is not in channel", log.Fstring("clientID", cm.client.ID), log.Fstring("channelID", raw.TargetID))
+		return nil, errNotInChannel
+	}
+
+	receipt, moved, err := cm.rruc.MarkRead(ctx, cm.client.UserID, cm.hm.Hub.ID, raw.TargetID, raw.ID)
+	if err != nil {
+		log.Error("Failed to mark read", log.Fstring("messageID", raw.ID), log.Ferror(err))
+		return nil, err
+	}
+
+	message := &entity.Message{
+		ID:          receipt.MessageID,
+		UserID:      cm.client.UserID,
+		WorkspaceID: cm.hm.Hub.ID,
+		CreatedAt:   receipt.ReadAt,
+		Action:      entity.MarkReadAction,
+		TargetID:    receipt.ChannelID,
+	}
+	if moved {
+		msg, err := message.Encode() //nolint:govet // err shadowing
+		if err != nil {
+			return nil, err
+		}
+		cm.hm.publishEventToUser(ctx, cm.client.UserID, msg)
+	}
+	return message, nil
+}
+
+// SendUnreadCounts は閲覧可能なChannel毎の未読数を接続したClientに送信する
+func (cm *clientManager) SendUnreadCounts(ctx context.Context, channels []entity.Channel) {
+	if cm.rruc == nil {
+		return
+	}
+	channelIDs := make([]string, len(channels))
+	for i, channel := range channels {
+		channelIDs[i] = channel.ID
+	}
+
+	counts, err := cm.rruc.ListUnreadCounts(ctx, cm.client.UserID, cm.hm.Hub.ID, channelIDs)
+	if err != nil {
+		log.Error("Failed to list unread counts", log.Fstring("clientID", cm.client.ID), log.Ferror(err))
+		return
+	}
+	msg, err := entity.NewUnreadCounts(counts).Encode()
+	if err != nil {
+		return
+	}
+	cm.trySend(msg)
+}
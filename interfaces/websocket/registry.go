@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+// HubManagerRegistry はWorkspace毎のHubManagerを保持する
+type HubManagerRegistry struct {
+	hubManagers map[string]*HubManager
+	mu          sync.RWMutex
+	psr         repository.PubSubRepository
+}
+
+func NewHubManagerRegistry(psr repository.PubSubRepository) *HubManagerRegistry {
+	return &HubManagerRegistry{
+		hubManagers: make(map[string]*HubManager),
+		psr:         psr,
+	}
+}
+
+// RegisterWorkspace はWorkspaceのHubManagerを起動し、Channel毎のChannelManagerを登録する
+// 既に登録済みのWorkspaceは、登録済みのHubManagerを返す
+func (r *HubManagerRegistry) RegisterWorkspace(ctx context.Context, workspace *entity.Workspace, channels []entity.Channel) (*HubManager, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if hm, ok := r.hubManagers[workspace.ID]; ok {
+		return hm, nil
+	}
+
+	hub, err := entity.NewHub(workspace.ID, workspace.Name)
+	if err != nil {
+		log.Error("Failed to create new hub", log.Fstring("workspaceID", workspace.ID), log.Ferror(err))
+		return nil, err
+	}
+	hm := NewHubManager(hub, r.psr)
+
+	go hm.Run()
+	go hm.subscribeToAnnouncements(ctx)
+	go hm.subscribeToEvents(ctx)
+
+	for i := range channels {
+		hm.RegisterChannel(ctx, &channels[i])
+	}
+	r.hubManagers[workspace.ID] = hm
+
+	log.Info("HubManager registered", log.Fstring("workspaceID", workspace.ID), log.Fint("channels", len(channels)))
+	return hm, nil
+}
+
+func (r *HubManagerRegistry) FindHubManagerByWorkspaceID(workspaceID string) *HubManager {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.hubManagers[workspaceID]
+}
+
+// workspaceTopic は作成されたWorkspaceを全サーバに通知するトピック
+const workspaceTopic = "hubs:workspaces"
+
+// workspaceAnnouncement は作成されたWorkspaceを、全サーバに通知する
+// 各サーバは通知を受けてHubManagerを起動し、再起動を待たずにWorkspaceへの接続を受け付ける
+type workspaceAnnouncement struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AnnounceWorkspace はWorkspaceの作成を全サーバに通知する
+func (r *HubManagerRegistry) AnnounceWorkspace(ctx context.Context, workspace *entity.Workspace) {
+	payload, err := json.Marshal(workspaceAnnouncement{ID: workspace.ID, Name: workspace.Name})
+	if err != nil {
+		log.Error("Failed to encode workspace announcement", log.Ferror(err))
+		return
+	}
+	if err = r.psr.Publish(ctx, workspaceTopic, payload); err != nil {
+		log.Error("Failed to publish workspace announcement", log.Fstring("workspaceID", workspace.ID), log.Ferror(err))
+	}
+}
+
+// SubscribeToWorkspaces は他のサーバで作成されたWorkspaceの通知を購読し、HubManagerを起動する
+func (r *HubManagerRegistry) SubscribeToWorkspaces(ctx context.Context) {
+	pubsub := r.psr.Subscribe(ctx, workspaceTopic)
+	defer pubsub.Close()
+
+	msgs := pubsub.Channel()
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			r.handleWorkspaceAnnouncement(ctx, []byte(msg.Payload))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *HubManagerRegistry) handleWorkspaceAnnouncement(ctx context.Context, payload []byte) {
+	var announcement workspaceAnnouncement
+	if err := json.Unmarshal(payload, &announcement); err != nil {
+		log.Error("Failed to decode workspace announcement", log.Ferror(err))
+		return
+	}
+	workspace, err := entity.NewWorkspace(announcement.ID, announcement.Name)
+	if err != nil {
+		log.Error("Invalid workspace announcement", log.Ferror(err))
+		return
+	}
+	// 作成直後のWorkspaceにはChannelが無い為、Channelは個別の通知で登録する
+	if _, err = r.RegisterWorkspace(ctx, workspace, nil); err != nil {
+		log.Error("Failed to register announced workspace", log.Fstring("workspaceID", announcement.ID), log.Ferror(err))
+	}
+}
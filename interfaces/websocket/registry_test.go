@@ -0,0 +1,39 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+)
+
+func TestHubManagerRegistry_HandleWorkspaceAnnouncement(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	workspaceID := uuid.New().String()
+
+	ctrl := gomock.NewController(t)
+	r := NewHubManagerRegistry(newTestPubSubRepository(ctrl))
+
+	payload, err := json.Marshal(workspaceAnnouncement{ID: workspaceID, Name: "workspace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.handleWorkspaceAnnouncement(ctx, payload)
+	hm := r.FindHubManagerByWorkspaceID(workspaceID)
+	if hm == nil {
+		t.Fatal("HubManager is not registered for the announced workspace")
+	}
+
+	// 作成したサーバ自身も通知を受け取る為、既存のHubManagerは置き換えない
+	r.handleWorkspaceAnnouncement(ctx, payload)
+	if got := r.FindHubManagerByWorkspaceID(workspaceID); got != hm {
+		t.Error("HubManager is replaced by a duplicated announcement")
+	}
+}
@@ -0,0 +1,115 @@
+package websocket
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+// typingState はClientがChannelで入力中であることを表す
+// timerが発火するとTYPING_STOPを送信し、クライアントが切断・クラッシュしても入力中の表示が残らないようにする
+type typingState struct {
+	timer  *time.Timer
+	sentAt time.Time
+}
+
+// handleTyping は入力中イベントをChannelに送信する。入力中イベントはDBに保存しない
+func (cm *clientManager) handleTyping(raw entity.Message) (*entity.Message, error) {
+	if raw.TargetID == "" {
+		return nil, fmt.Errorf("%w: target_id is required", errInvalidMessage)
+	}
+	if !cm.isInChannel(raw.TargetID) {
+		log.Warn("Client is not in channel", log.Fstring("clientID", cm.client.ID), log.Fstring("channelID", raw.TargetID))
+		return nil, errNotInChannel
+	}
+
+	if raw.Action == entity.TypingStartAction {
+		if cm.startTyping(raw.TargetID) {
+			cm.broadcastTyping(raw.TargetID, entity.TypingStartAction)
+		}
+	} else if cm.stopTyping(raw.TargetID) {
+		cm.broadcastTyping(raw.TargetID, entity.TypingStopAction)
+	}
+	return &entity.Message{Action: raw.Action, TargetID: raw.TargetID, CreatedAt: time.Now()}, nil
+}
+
+// startTyping は入力中の期限を延長し、TYPING_STARTを送信すべきかを返す
+// 前回の送信からTypingThrottleInterval以内であれば送信しない
+func (cm *clientManager) startTyping(channelID string) bool {
+	cm.typingMu.Lock()
+	defer cm.typingMu.Unlock()
+
+	now := time.Now()
+	if state, ok := cm.typing[channelID]; ok {
+		state.timer.Reset(config.TypingTimeout)
+		if now.Sub(state.sentAt) < config.TypingThrottleInterval {
+			return false
+		}
+		state.sentAt = now
+		return true
+	}
+
+	state := &typingState{sentAt: now}
+	state.timer = time.AfterFunc(config.TypingTimeout, func() {
+		cm.expireTyping(channelID, state)
+	})
+	cm.typing[channelID] = state
+	return true
+}
+
+// stopTyping は入力中の状態を取り除き、入力中であったかを返す
+func (cm *clientManager) stopTyping(channelID string) bool {
+	cm.typingMu.Lock()
+	defer cm.typingMu.Unlock()
+
+	state, ok := cm.typing[channelID]
+	if !ok {
+		return false
+	}
+	state.timer.Stop()
+	delete(cm.typing, channelID)
+	return true
+}
+
+// stopAllTyping は切断時に入力中の全てのChannelにTYPING_STOPを送信する
+func (cm *clientManager) stopAllTyping() {
+	cm.typingMu.Lock()
+	channelIDs := make([]string, 0, len(cm.typing))
+	for channelID, state := range cm.typing {
+		state.timer.Stop()
+		channelIDs = append(channelIDs, channelID)
+	}
+	cm.typing = make(map[string]*typingState)
+	cm.typingMu.Unlock()
+
+	for _, channelID := range channelIDs {
+		cm.broadcastTyping(channelID, entity.TypingStopAction)
+	}
+}
+
+func (cm *clientManager) expireTyping(channelID string, state *typingState) {
+	cm.typingMu.Lock()
+	if cm.typing[channelID] != state {
+		// 既に停止済み、または新しい入力中の状態に置き換えられている
+		cm.typingMu.Unlock()
+		return
+	}
+	delete(cm.typing, channelID)
+	cm.typingMu.Unlock()
+
+	cm.broadcastTyping(channelID, entity.TypingStopAction)
+}
+
+func (cm *clientManager) broadcastTyping(channelID, action string) {
+	cm.broadcastMessage(channelID, &entity.Message{
+		UserID:      cm.client.UserID,
+		WorkspaceID: cm.hm.Hub.ID,
+		CreatedAt:   time.Now(),
+		Action:      action,
+		TargetID:    channelID,
+	})
+}
@@ -1,5 +1,12 @@
 USE `go_chat_app_db`;
 
+DROP TABLE IF EXISTS RecoveryCodes CASCADE;
+DROP TABLE IF EXISTS UserIdentities CASCADE;
+DROP TABLE IF EXISTS Attachments CASCADE;
+DROP TABLE IF EXISTS Mentions CASCADE;
+DROP TABLE IF EXISTS ReadReceipts CASCADE;
+DROP TABLE IF EXISTS MessageRevisions CASCADE;
+DROP TABLE IF EXISTS Reactions CASCADE;
 DROP TABLE IF EXISTS Messages CASCADE;
 DROP TABLE IF EXISTS Membership_Channels CASCADE;
 DROP TABLE IF EXISTS Memberships CASCADE;
@@ -17,13 +24,20 @@ CREATE TABLE Channels (
     workspace_id CHAR(36) NOT NULL,
     name VARCHAR(50) NOT NULL,
     private BOOLEAN NOT NULL,
-    UNIQUE (workspace_id, name)
+    kind VARCHAR(20) NOT NULL DEFAULT 'channel', -- channel または direct_message
+    conversation_key CHAR(64) DEFAULT NULL, -- DMの参加者の組み合わせを表すキー
+    UNIQUE (workspace_id, name),
+    UNIQUE (workspace_id, conversation_key)
 );
 
 CREATE TABLE Users (
     id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
     email VARCHAR(150) UNIQUE NOT NULL,
-    password VARCHAR(255) NOT NULL  -- 暗号化されたパスワードを格納
+    password VARCHAR(255) NOT NULL,  -- 暗号化されたパスワードを格納
+    email_verified_at TIMESTAMP NULL DEFAULT NULL, -- メールアドレスを確認するまではNULL
+    totp_secret VARCHAR(64) NULL DEFAULT NULL, -- 二要素認証の共有鍵(Base32)
+    totp_enabled_at TIMESTAMP NULL DEFAULT NULL, -- 二要素認証を有効にするまではNULL
+    totp_last_used_step BIGINT NOT NULL DEFAULT 0 -- 最後に受け付けたTOTPのステップ。同じコードの再利用を防ぐ
 );
 
 CREATE TABLE Memberships (
@@ -39,6 +53,7 @@ CREATE TABLE Membership_Channels (
     user_id CHAR(36) NOT NULL,
     workspace_id CHAR(36) NOT NULL,
     channel_id CHAR(36) NOT NULL,
+    is_owner BOOLEAN NOT NULL DEFAULT FALSE,
     PRIMARY KEY (user_id, workspace_id, channel_id),
     FOREIGN KEY (user_id, workspace_id) REFERENCES Memberships(user_id, workspace_id) ON DELETE CASCADE,
     FOREIGN KEY (channel_id) REFERENCES Channels(id) ON DELETE CASCADE
@@ -49,6 +64,90 @@ CREATE TABLE Messages (
     user_id CHAR(36) NOT NULL,
     workspace_id CHAR(36) NOT NULL,
     channel_id CHAR(36) NOT NULL,
+    parent_id CHAR(36) DEFAULT NULL, -- スレッドの返信の場合、返信先のメッセージID
+    also_send_to_channel BOOLEAN NOT NULL DEFAULT FALSE, -- スレッドの返信をChannelにも表示するか
     text TEXT NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
\ No newline at end of file
+    reply_count INT NOT NULL DEFAULT 0,
+    last_reply_at TIMESTAMP(6) NULL DEFAULT NULL,
+    edited_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_at TIMESTAMP NULL DEFAULT NULL, -- 削除されたメッセージは履歴上「message deleted」として表示する
+    created_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6), -- 同じ秒に投稿されたメッセージも投稿順に並べる為、マイクロ秒まで保存する
+    INDEX idx_messages_channel_id_created_at_id (channel_id, created_at, id), -- 履歴取得のカーソルページネーション用
+    INDEX idx_messages_parent_id_created_at_id (parent_id, created_at, id), -- スレッド履歴取得用
+    FULLTEXT INDEX ft_messages_text (text) WITH PARSER ngram -- 全文検索用。日本語を扱う為ngramで分割する
+);
+
+CREATE TABLE Reactions (
+    message_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    emoji VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji), -- 同じユーザは同じ絵文字を一度だけ付けられる
+    FOREIGN KEY (message_id) REFERENCES Messages(id) ON DELETE CASCADE
+);
+
+CREATE TABLE MessageRevisions (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    message_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL, -- 編集したユーザ
+    text TEXT NOT NULL, -- 編集前の本文
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_message_revisions_message_id_created_at (message_id, created_at),
+    FOREIGN KEY (message_id) REFERENCES Messages(id) ON DELETE CASCADE
+);
+
+CREATE TABLE ReadReceipts (
+    user_id CHAR(36) NOT NULL,
+    workspace_id CHAR(36) NOT NULL,
+    channel_id CHAR(36) NOT NULL,
+    message_id CHAR(36) NOT NULL, -- 最後に読んだメッセージ
+    read_at TIMESTAMP(6) NOT NULL, -- 最後に読んだメッセージの投稿日時
+    PRIMARY KEY (user_id, channel_id),
+    INDEX idx_read_receipts_user_id_workspace_id (user_id, workspace_id),
+    FOREIGN KEY (channel_id) REFERENCES Channels(id) ON DELETE CASCADE
+);
+
+CREATE TABLE Mentions (
+    message_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL, -- メンションされたユーザ
+    workspace_id CHAR(36) NOT NULL,
+    channel_id CHAR(36) NOT NULL,
+    kind VARCHAR(20) NOT NULL, -- user, channel または here
+    created_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6), -- メッセージの投稿日時
+    PRIMARY KEY (message_id, user_id),
+    INDEX idx_mentions_user_id_created_at_message_id (user_id, created_at, message_id), -- メンション一覧のカーソルページネーション用
+    FOREIGN KEY (message_id) REFERENCES Messages(id) ON DELETE CASCADE
+);
+
+CREATE TABLE Attachments (
+    id CHAR(36) PRIMARY KEY, -- UUIDは36文字の文字列として格納されます
+    message_id CHAR(36) NULL, -- メッセージに添付されるまではNULL
+    user_id CHAR(36) NOT NULL, -- アップロードしたユーザ
+    workspace_id CHAR(36) NOT NULL,
+    channel_id CHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_attachments_message_id (message_id),
+    FOREIGN KEY (message_id) REFERENCES Messages(id) ON DELETE CASCADE,
+    FOREIGN KEY (channel_id) REFERENCES Channels(id) ON DELETE CASCADE
+);
+
+CREATE TABLE UserIdentities (
+    issuer VARCHAR(255) NOT NULL, -- OIDCプロバイダのIssuer
+    subject VARCHAR(255) NOT NULL, -- プロバイダ内でのユーザの識別子(sub)
+    user_id CHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (issuer, subject),
+    INDEX idx_user_identities_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES Users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE RecoveryCodes (
+    user_id CHAR(36) NOT NULL,
+    code_hash CHAR(64) NOT NULL, -- リカバリーコードのSHA-256ハッシュ
+    used_at TIMESTAMP NULL DEFAULT NULL, -- 使用済みの場合に設定
+    PRIMARY KEY (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES Users(id) ON DELETE CASCADE
+);
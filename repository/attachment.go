@@ -0,0 +1,16 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type AttachmentRepository interface {
+	Get(ctx context.Context, id string) (*entity.Attachment, error)
+	ListByIDs(ctx context.Context, ids []string) ([]entity.Attachment, error)
+	ListByMessageIDs(ctx context.Context, messageIDs []string) ([]entity.Attachment, error)
+	Create(ctx context.Context, attachment entity.Attachment) error
+	AttachToMessage(ctx context.Context, ids []string, messageID string) (int, error)
+}
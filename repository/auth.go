@@ -1,8 +1,18 @@
 //go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
 package repository
 
+import (
+	"errors"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+var ErrTokenExpired = errors.New("token expired")
+
 type AuthRepository interface {
-	GenerateToken(userID, email string) (jwt string, jti string)
+	GenerateToken(userID, email string) (jwt string, jti string, err error)
 	ValidateAccessToken(jwt string) error
 	GetPayloadFromToken(jwt string) (map[string]string, error)
+	// JWKS はトークンの検証に使える公開鍵の一覧を返す
+	JWKS() entity.JWKSet
 }
@@ -1,30 +1,89 @@
 package auth
 
 import (
-	"reflect"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/repository"
 )
 
+func writePrivateKey(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal private key: %s", err)
+	}
+	return writePEM(t, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %s", err)
+	}
+	return writePEM(t, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %s", err)
+	}
+	return path
+}
+
 func Test_JWTToken(t *testing.T) {
 	userID := uuid.MustParse("f6db2530-cd9b-4ac1-8dc1-38c795e6eec2")
 	email := "test@gmail.com"
 
-	repo := NewAuthRepository()
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	conf := &config.AuthConfig{
+		Issuer:         "go-chat-app",
+		AccessTokenTTL: 15 * time.Minute,
+		SigningKeyFile: writePrivateKey(t, key),
+	}
+	repo, err := NewAuthRepository(conf)
+	if err != nil {
+		t.Fatalf("Failed to NewAuthRepository: %s", err)
+	}
 
 	// GenerateToken test
-	jwt, jti := repo.GenerateToken(userID.String(), email)
+	jwt, jti, err := repo.GenerateToken(userID.String(), email)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %s", err)
+	}
 
 	// JWTのフォーマットが正しいことを確認
 	token, err := jwtgo.Parse(jwt, func(token *jwtgo.Token) (interface{}, error) {
-		// ここで公開キーを使って署名を検証する（公開キーは環境に依存する）
-		return loadPublicKey(rawPublicKey)
+		return &key.PublicKey, nil
 	})
 	if err != nil {
 		t.Errorf("Failed to parse JWT: %s", err)
 	}
+	if kid := token.Header["kid"]; kid != repo.JWKS().Keys[0].Kid {
+		t.Errorf("Expected kid %s, got %v", repo.JWKS().Keys[0].Kid, kid)
+	}
 
 	// クレームを検証
 	claims, ok := token.Claims.(jwtgo.MapClaims)
@@ -55,8 +114,259 @@ func Test_JWTToken(t *testing.T) {
 		"jti":    jti,
 		"userId": userID.String(),
 		"Email":  email,
+		"iss":    conf.Issuer,
+	}
+	for k, v := range wantPayload {
+		if payload[k] != v {
+			t.Errorf("GetPayloadFromToken() %s \n got = %v,\n want = %v", k, payload[k], v)
+		}
+	}
+	if payload["iat"] == "" || payload["exp"] == "" || payload["exp"] <= payload["iat"] {
+		t.Errorf("GetPayloadFromToken() iat = %s, exp = %s", payload["iat"], payload["exp"])
+	}
+}
+
+func Test_ValidateAccessToken(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	email := "test@gmail.com"
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	conf := &config.AuthConfig{
+		Issuer:         "go-chat-app",
+		AccessTokenTTL: 15 * time.Minute,
+		SigningKeyFile: writePrivateKey(t, key),
 	}
-	if !reflect.DeepEqual(payload, wantPayload) {
-		t.Errorf("GetPayloadFromToken() \n got = %v,\n want = %v", payload, wantPayload)
+
+	patterns := []struct {
+		name    string
+		signer  config.AuthConfig
+		tamper  func(jwt string) string
+		wantErr error
+	}{
+		{
+			name:   "Success",
+			signer: *conf,
+		},
+		{
+			name: "Fail: token expired",
+			signer: config.AuthConfig{
+				Issuer:         conf.Issuer,
+				AccessTokenTTL: -time.Minute,
+				SigningKeyFile: conf.SigningKeyFile,
+			},
+			wantErr: repository.ErrTokenExpired,
+		},
+		{
+			name: "Fail: unexpected issuer",
+			signer: config.AuthConfig{
+				Issuer:         "other",
+				AccessTokenTTL: conf.AccessTokenTTL,
+				SigningKeyFile: conf.SigningKeyFile,
+			},
+			wantErr: errors.New("unexpected issuer: other"),
+		},
+		{
+			name:   "Fail: signing algorithm replaced",
+			signer: *conf,
+			tamper: func(jwt string) string {
+				parts := strings.Split(jwt, ".")
+				header, _ := base64UrlDecode(parts[0])
+				parts[0] = base64UrlEncode([]byte(strings.Replace(string(header), `"alg":"RS256"`, `"alg":"none"`, 1)))
+				return strings.Join(parts, ".")
+			},
+			wantErr: errors.New("unexpected signing algorithm: none"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signer, err := NewAuthRepository(&tt.signer)
+			if err != nil {
+				t.Fatalf("Failed to NewAuthRepository: %s", err)
+			}
+			jwt, _, err := signer.GenerateToken(userID, email)
+			if err != nil {
+				t.Fatalf("Failed to GenerateToken: %s", err)
+			}
+			if tt.tamper != nil {
+				jwt = tt.tamper(jwt)
+			}
+
+			repo, err := NewAuthRepository(conf)
+			if err != nil {
+				t.Fatalf("Failed to NewAuthRepository: %s", err)
+			}
+			err = repo.ValidateAccessToken(jwt)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ValidateAccessToken() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ValidateAccessToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_SigningAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	patterns := []struct {
+		name    string
+		key     crypto.Signer
+		wantAlg string
+		wantKty string
+	}{
+		{name: "RS256", key: rsaKey, wantAlg: "RS256", wantKty: "RSA"},
+		{name: "ES256", key: ecKey, wantAlg: "ES256", wantKty: "EC"},
+		{name: "EdDSA", key: edKey, wantAlg: "EdDSA", wantKty: "OKP"},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, err := NewAuthRepository(&config.AuthConfig{
+				Issuer:         "go-chat-app",
+				AccessTokenTTL: time.Minute,
+				SigningKeyFile: writePrivateKey(t, tt.key),
+			})
+			if err != nil {
+				t.Fatalf("Failed to NewAuthRepository: %s", err)
+			}
+
+			jwt, _, err := repo.GenerateToken(uuid.New().String(), "test@gmail.com")
+			if err != nil {
+				t.Fatalf("Failed to GenerateToken: %s", err)
+			}
+			if err = repo.ValidateAccessToken(jwt); err != nil {
+				t.Errorf("Failed to ValidateAccessToken: %s", err)
+			}
+
+			header, _ := decodeSegment(strings.Split(jwt, ".")[0])
+			if header["alg"] != tt.wantAlg {
+				t.Errorf("alg = %v, want %s", header["alg"], tt.wantAlg)
+			}
+			jwks := repo.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kty != tt.wantKty || jwks.Keys[0].Alg != tt.wantAlg || jwks.Keys[0].Kid != header["kid"] {
+				t.Errorf("JWKS() = %+v", jwks)
+			}
+		})
+	}
+}
+
+func Test_KeyRotation(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	email := "test@gmail.com"
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	oldRepo, err := NewAuthRepository(&config.AuthConfig{
+		Issuer:         "go-chat-app",
+		AccessTokenTTL: time.Minute,
+		SigningKeyFile: writePrivateKey(t, oldKey),
+	})
+	if err != nil {
+		t.Fatalf("Failed to NewAuthRepository: %s", err)
+	}
+	jwt, _, err := oldRepo.GenerateToken(userID, email)
+	if err != nil {
+		t.Fatalf("Failed to GenerateToken: %s", err)
+	}
+
+	// 以前の鍵を検証用に残している間は、以前の鍵で発行したトークンも検証できる
+	rotated, err := NewAuthRepository(&config.AuthConfig{
+		Issuer:               "go-chat-app",
+		AccessTokenTTL:       time.Minute,
+		SigningKeyFile:       writePrivateKey(t, newKey),
+		VerificationKeyFiles: []string{writePublicKey(t, oldKey)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to NewAuthRepository: %s", err)
+	}
+	if err = rotated.ValidateAccessToken(jwt); err != nil {
+		t.Errorf("Failed to ValidateAccessToken: %s", err)
+	}
+	if jwks := rotated.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].Alg != "EdDSA" || jwks.Keys[1].Alg != "RS256" {
+		t.Errorf("JWKS() = %+v", jwks)
+	}
+
+	// 以前の鍵を外すと検証できない
+	removed, err := NewAuthRepository(&config.AuthConfig{
+		Issuer:         "go-chat-app",
+		AccessTokenTTL: time.Minute,
+		SigningKeyFile: writePrivateKey(t, newKey),
+	})
+	if err != nil {
+		t.Fatalf("Failed to NewAuthRepository: %s", err)
+	}
+	if err = removed.ValidateAccessToken(jwt); err == nil || !strings.HasPrefix(err.Error(), "unknown key id") {
+		t.Errorf("ValidateAccessToken() error = %v, want unknown key id", err)
+	}
+}
+
+func Test_NewAuthRepository(t *testing.T) {
+	t.Parallel()
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	patterns := []struct {
+		name    string
+		conf    *config.AuthConfig
+		wantErr bool
+	}{
+		{
+			name: "Success: ephemeral key for development",
+			conf: &config.AuthConfig{AllowEphemeralSigningKey: true},
+		},
+		{
+			name:    "Fail: signing key is not configured",
+			conf:    &config.AuthConfig{},
+			wantErr: true,
+		},
+		{
+			name:    "Fail: invalid PEM",
+			conf:    &config.AuthConfig{SigningKey: "invalid"},
+			wantErr: true,
+		},
+		{
+			name:    "Fail: signing key file not found",
+			conf:    &config.AuthConfig{SigningKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: true,
+		},
+		{
+			name:    "Fail: unsupported curve",
+			conf:    &config.AuthConfig{SigningKeyFile: writePrivateKey(t, ecKey)},
+			wantErr: true,
+		},
+		{
+			name: "Fail: verification key file not found",
+			conf: &config.AuthConfig{
+				AllowEphemeralSigningKey: true,
+				VerificationKeyFiles:     []string{filepath.Join(t.TempDir(), "missing.pem")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewAuthRepository(tt.conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAuthRepository() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
 	}
 }
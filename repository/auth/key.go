@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+const (
+	algRS256 = "RS256"
+	algES256 = "ES256"
+	algEdDSA = "EdDSA"
+
+	// ES256の署名はr, sをそれぞれ32バイトで連結したもの
+	es256CoordinateSize = 32
+
+	ephemeralRSAKeyBits = 2048
+)
+
+var (
+	errInvalidSignature        = errors.New("invalid signature")
+	errSigningKeyNotConfigured = errors.New("signing key is not configured: set SIGNING_KEY_FILE or SIGNING_KEY")
+)
+
+// verificationKey はトークンの検証に使う公開鍵。kidはJWKのサムプリント(RFC 7638)
+type verificationKey struct {
+	kid    string
+	alg    string
+	public crypto.PublicKey
+}
+
+type signingKey struct {
+	*verificationKey
+	private crypto.Signer
+}
+
+// loadSigningKey は設定から署名鍵を読み込む
+// 未指定の場合はエラーとし、AllowEphemeralSigningKeyが有効な場合のみ一時的な鍵を生成する(開発用)
+func loadSigningKey(conf *config.AuthConfig) (*signingKey, error) {
+	var private crypto.Signer
+	switch {
+	case conf.SigningKeyFile != "":
+		pemBytes, err := os.ReadFile(conf.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		if private, err = parsePrivateKey(pemBytes); err != nil {
+			return nil, err
+		}
+	case conf.SigningKey != "":
+		var err error
+		if private, err = parsePrivateKey([]byte(conf.SigningKey)); err != nil {
+			return nil, err
+		}
+	case conf.AllowEphemeralSigningKey:
+		log.Warn("Signing key is not configured, generating an ephemeral key for development")
+		var err error
+		if private, err = rsa.GenerateKey(rand.Reader, ephemeralRSAKeyBits); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+	default:
+		log.Critical("Signing key is not configured")
+		return nil, errSigningKeyNotConfigured
+	}
+
+	vk, err := newVerificationKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &signingKey{
+		verificationKey: vk,
+		private:         private,
+	}, nil
+}
+
+// loadVerificationKey はPEM形式の公開鍵または秘密鍵のファイルから検証用の鍵を読み込む
+func loadVerificationKey(path string) (*verificationKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification key: %w", err)
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block containing the key")
+	}
+	if block.Type != "PUBLIC KEY" {
+		private, err := parsePrivateKey(pemBytes) //nolint:govet // err shadowing
+		if err != nil {
+			return nil, err
+		}
+		return newVerificationKey(private.Public())
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return newVerificationKey(public)
+}
+
+func parsePrivateKey(pemBytes []byte) (crypto.Signer, error) {
+	// PEMエンコードされたデータからPEMブロックをデコード
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block containing the key")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", key)
+	}
+	return signer, nil
+}
+
+// newVerificationKey は鍵の種類から署名アルゴリズムを決め、kidを計算する
+func newVerificationKey(public crypto.PublicKey) (*verificationKey, error) {
+	var alg string
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		alg = algRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported elliptic curve: %s", pub.Curve.Params().Name)
+		}
+		alg = algES256
+	case ed25519.PublicKey:
+		alg = algEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type: %T", public)
+	}
+
+	vk := &verificationKey{
+		alg:    alg,
+		public: public,
+	}
+	vk.kid = thumbprint(vk.jwk())
+	return vk, nil
+}
+
+// verificationKeyFromJWK は外部(OIDCプロバイダ)が公開するJWKから検証用の鍵を作る
+// kidはプロバイダが付けたものをそのまま使う
+func verificationKeyFromJWK(jwk entity.JWK) (*verificationKey, error) {
+	var public crypto.PublicKey
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64UrlDecode(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64UrlDecode(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		public = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	case "EC":
+		if jwk.Crv != elliptic.P256().Params().Name {
+			return nil, fmt.Errorf("unsupported elliptic curve: %s", jwk.Crv)
+		}
+		x, err := base64UrlDecode(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := base64UrlDecode(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		// 曲線上の点であることを確認する(非圧縮形式 0x04||x||y)
+		if len(x) != es256CoordinateSize || len(y) != es256CoordinateSize {
+			return nil, fmt.Errorf("invalid EC public key")
+		}
+		if _, err = ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, fmt.Errorf("invalid EC public key: %w", err)
+		}
+		public = &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+	case "OKP":
+		x, err := base64UrlDecode(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OKP public key: %w", err)
+		}
+		if jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported OKP key: %s", jwk.Crv)
+		}
+		public = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+
+	vk, err := newVerificationKey(public)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.Kid != "" {
+		vk.kid = jwk.Kid
+	}
+	return vk, nil
+}
+
+func (vk *verificationKey) jwk() entity.JWK {
+	jwk := entity.JWK{
+		Kid: vk.kid,
+		Use: "sig",
+		Alg: vk.alg,
+	}
+	switch pub := vk.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64UrlEncode(pub.N.Bytes())
+		jwk.E = base64UrlEncode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = base64UrlEncode(pub.X.FillBytes(make([]byte, es256CoordinateSize)))
+		jwk.Y = base64UrlEncode(pub.Y.FillBytes(make([]byte, es256CoordinateSize)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64UrlEncode(pub)
+	}
+	return jwk
+}
+
+// thumbprint はJWKの必須メンバーを辞書順に並べたJSONのSHA-256ハッシュ(RFC 7638)
+func thumbprint(jwk entity.JWK) string {
+	var members string
+	switch jwk.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, jwk.E, jwk.Kty, jwk.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, jwk.Crv, jwk.Kty, jwk.X, jwk.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, jwk.Crv, jwk.Kty, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return base64UrlEncode(sum[:])
+}
+
+func (sk *signingKey) sign(data []byte) ([]byte, error) {
+	switch private := sk.private.(type) {
+	case *rsa.PrivateKey:
+		hashed := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, hashed[:])
+	case *ecdsa.PrivateKey:
+		hashed := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, private, hashed[:])
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 2*es256CoordinateSize)
+		r.FillBytes(signature[:es256CoordinateSize])
+		s.FillBytes(signature[es256CoordinateSize:])
+		return signature, nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(private, data), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", sk.private)
+	}
+}
+
+func (vk *verificationKey) verify(data, signature []byte) error {
+	switch pub := vk.public.(type) {
+	case *rsa.PublicKey:
+		hashed := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, hashed[:], signature)
+	case *ecdsa.PublicKey:
+		if len(signature) != 2*es256CoordinateSize {
+			return errInvalidSignature
+		}
+		hashed := sha256.Sum256(data)
+		r := new(big.Int).SetBytes(signature[:es256CoordinateSize])
+		s := new(big.Int).SetBytes(signature[es256CoordinateSize:])
+		if !ecdsa.Verify(pub, hashed[:], r, s) {
+			return errInvalidSignature
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, data, signature) {
+			return errInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type: %T", vk.public)
+	}
+}
@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+const (
+	oidcHTTPTimeout = 10 * time.Second
+	// IDトークンのexpを検証する際に許容する時刻のずれ
+	oidcClockSkew = time.Minute
+	// エラー時にログへ出力するレスポンスボディの最大長
+	oidcMaxErrorBodySize = 512
+)
+
+// oidcMetadata はディスカバリ(/.well-known/openid-configuration)で取得するプロバイダの情報のうち使うもの
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcProvider は認可コードフロー(PKCE)でOIDCプロバイダからIDトークンを取得し検証する
+// プロバイダの情報と公開鍵は初回の利用時に取得してキャッシュし、未知のkidのトークンを受け取った場合は公開鍵を取得し直す
+type oidcProvider struct {
+	conf   *config.OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *oidcMetadata
+	keys     []*verificationKey
+}
+
+func NewOIDCProvider(conf *config.OIDCConfig) repository.OIDCProvider {
+	return &oidcProvider{
+		conf:   conf,
+		client: &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(md.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.conf.ClientID)
+	q.Set("redirect_uri", p.conf.RedirectURL)
+	q.Set("scope", strings.Join(p.conf.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*entity.OIDCIdentity, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.conf.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.conf.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.conf.ClientSecret != "" {
+		// client_secret_basic(RFC 6749 2.3.1): IDとシークレットはURLエンコードしてからBasic認証に使う
+		req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err = p.doJSON(req, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, fmt.Errorf("id_token is missing in token response")
+	}
+
+	return p.verifyIDToken(ctx, md, tokenResponse.IDToken, nonce)
+}
+
+// verifyIDToken はIDトークンの署名とクレームを検証する(OpenID Connect Core 3.1.3.7)
+func (p *oidcProvider) verifyIDToken(ctx context.Context, md *oidcMetadata, idToken, nonce string) (*entity.OIDCIdentity, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != expectedTokenParts {
+		return nil, fmt.Errorf("invalid id token")
+	}
+
+	header, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	kid, _ := header["kid"].(string)
+	vk, err := p.findProviderKey(ctx, md, kid)
+	if err != nil {
+		return nil, err
+	}
+	if alg, _ := header["alg"].(string); alg != vk.alg {
+		return nil, fmt.Errorf("unexpected signing algorithm: %v", header["alg"])
+	}
+	signature, err := base64UrlDecode(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+	if err = vk.verify([]byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	claims, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if iss, _ := claims["iss"].(string); iss != md.Issuer {
+		return nil, fmt.Errorf("unexpected issuer: %v", claims["iss"])
+	}
+	if !hasAudience(claims["aud"], p.conf.ClientID) {
+		return nil, fmt.Errorf("unexpected audience: %v", claims["aud"])
+	}
+	exp, err := numericClaim(claims, "exp")
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Add(-oidcClockSkew).Before(time.Unix(exp, 0)) {
+		return nil, repository.ErrTokenExpired
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, fmt.Errorf("nonce mismatch")
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("sub claim is required")
+	}
+
+	email, _ := claims["email"].(string)
+	// email_verifiedを文字列で返すプロバイダもある
+	emailVerified := claims["email_verified"] == true || claims["email_verified"] == "true"
+
+	return &entity.OIDCIdentity{
+		Issuer:        md.Issuer,
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: emailVerified,
+	}, nil
+}
+
+// discover はプロバイダの情報を取得する。取得に成功した場合のみキャッシュする
+func (p *oidcProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	if p.conf.Issuer == "" {
+		return nil, repository.ErrOIDCNotConfigured
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.conf.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var md oidcMetadata
+	if err = p.doJSON(req, &md); err != nil {
+		return nil, fmt.Errorf("failed to discover oidc provider: %w", err)
+	}
+	// なりすましを防ぐため、ディスカバリで返されたissuerは設定と一致しなければならない
+	if md.Issuer != p.conf.Issuer {
+		return nil, fmt.Errorf("issuer mismatch: %s", md.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc provider metadata is incomplete")
+	}
+
+	log.Info("OIDC provider discovered", log.Fstring("issuer", md.Issuer))
+	p.metadata = &md
+	return p.metadata, nil
+}
+
+// findProviderKey はkidに対応するプロバイダの公開鍵を返す。キャッシュにない場合はJWKSを取得し直す
+func (p *oidcProvider) findProviderKey(ctx context.Context, md *oidcMetadata, kid string) (*verificationKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if vk := findKey(p.keys, kid); vk != nil {
+		return vk, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, md.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var jwks entity.JWKSet
+	if err = p.doJSON(req, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch oidc provider keys: %w", err)
+	}
+
+	keys := make([]*verificationKey, 0, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		vk, err := verificationKeyFromJWK(jwk) //nolint:govet // err shadowing
+		if err != nil {
+			// 対応していない種類の鍵は無視する
+			log.Warn("Skip unsupported oidc provider key", log.Fstring("kid", jwk.Kid), log.Ferror(err))
+			continue
+		}
+		keys = append(keys, vk)
+	}
+	p.keys = keys
+
+	if vk := findKey(p.keys, kid); vk != nil {
+		return vk, nil
+	}
+	return nil, fmt.Errorf("unknown key id: %s", kid)
+}
+
+func (p *oidcProvider) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, oidcMaxErrorBodySize))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// hasAudience はaudクレーム(文字列または配列)にclientIDが含まれるかを返す
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,368 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+const (
+	mockClientID     = "go-chat-app"
+	mockClientSecret = "client secret"
+	mockRedirectURL  = "http://localhost:8080/api/user/oidc/callback"
+)
+
+// mockOIDCServer はテスト用のOIDCプロバイダ
+// ユーザの認証画面の代わりにauthorizeで認可コードを発行し、トークンエンドポイントではPKCEを検証してIDトークンを返す
+type mockOIDCServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu     sync.Mutex
+	key    *signingKey
+	codes  map[string]mockAuthorization
+	issuer string // 空の場合はサーバのURL
+}
+
+type mockAuthorization struct {
+	codeChallenge string
+	claims        map[string]interface{}
+}
+
+func newMockOIDCServer(t *testing.T) *mockOIDCServer {
+	t.Helper()
+
+	m := &mockOIDCServer{
+		t:     t,
+		codes: map[string]mockAuthorization{},
+	}
+	m.rotateKey(mustGenerateRSAKey(t))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.getIssuer(),
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(entity.JWKSet{Keys: []entity.JWK{m.key.jwk()}})
+	})
+	mux.HandleFunc("/token", m.token)
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+func mustGenerateRSAKey(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	return key
+}
+
+func (m *mockOIDCServer) getIssuer() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.issuer != "" {
+		return m.issuer
+	}
+	return m.URL
+}
+
+func (m *mockOIDCServer) rotateKey(private crypto.Signer) {
+	m.t.Helper()
+
+	vk, err := newVerificationKey(private.Public())
+	if err != nil {
+		m.t.Fatalf("Failed to create verification key: %s", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.key = &signingKey{verificationKey: vk, private: private}
+}
+
+// authorize はユーザが認可した場合にプロバイダが行う処理の代わりに、認可URLのパラメータから認可コードを発行する
+func (m *mockOIDCServer) authorize(authURL string, claims map[string]interface{}) string {
+	m.t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatalf("Failed to parse auth url: %s", err)
+	}
+	q := u.Query()
+
+	idTokenClaims := map[string]interface{}{
+		"iss":   m.getIssuer(),
+		"aud":   q.Get("client_id"),
+		"sub":   "248289761001",
+		"nonce": q.Get("nonce"),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		idTokenClaims[k] = v
+	}
+
+	code := "code-" + q.Get("state")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes[code] = mockAuthorization{
+		codeChallenge: q.Get("code_challenge"),
+		claims:        idTokenClaims,
+	}
+	return code
+}
+
+func (m *mockOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != url.QueryEscape(mockClientID) || clientSecret != url.QueryEscape(mockClientSecret) {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	if r.FormValue("grant_type") != "authorization_code" || r.FormValue("redirect_uri") != mockRedirectURL {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	authorization, ok := m.codes[r.FormValue("code")]
+	delete(m.codes, r.FormValue("code"))
+	verifier := entity.OIDCAuthRequest{CodeVerifier: r.FormValue("code_verifier")}
+	if !ok || verifier.CodeChallenge() != authorization.codeChallenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": m.key.alg, "kid": m.key.kid})
+	payload, _ := json.Marshal(authorization.claims)
+	signingInput := base64UrlEncode(header) + "." + base64UrlEncode(payload)
+	signature, err := m.key.sign([]byte(signingInput))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"id_token":     signingInput + "." + base64UrlEncode(signature),
+	})
+}
+
+func newTestOIDCProvider(issuer string) repository.OIDCProvider {
+	return NewOIDCProvider(&config.OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     mockClientID,
+		ClientSecret: mockClientSecret,
+		RedirectURL:  mockRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+}
+
+// login は認可URLの取得から認可コードの交換までのフローを実行する
+func login(
+	t *testing.T,
+	server *mockOIDCServer,
+	provider repository.OIDCProvider,
+	claims map[string]interface{},
+	tamper func(req *entity.OIDCAuthRequest),
+) (*entity.OIDCIdentity, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	req, err := entity.NewOIDCAuthRequest(time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to NewOIDCAuthRequest: %s", err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, req.State, req.Nonce, req.CodeChallenge())
+	if err != nil {
+		t.Fatalf("Failed to AuthCodeURL: %s", err)
+	}
+	code := server.authorize(authURL, claims)
+
+	if tamper != nil {
+		tamper(req)
+	}
+	return provider.Exchange(ctx, code, req.CodeVerifier, req.Nonce)
+}
+
+func Test_OIDCProvider_AuthCodeURL(t *testing.T) {
+	server := newMockOIDCServer(t)
+	provider := newTestOIDCProvider(server.URL)
+
+	got, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	if err != nil {
+		t.Fatalf("Failed to AuthCodeURL: %s", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("Failed to parse auth url: %s", err)
+	}
+	if u.Scheme+"://"+u.Host+u.Path != server.URL+"/authorize" {
+		t.Errorf("AuthCodeURL() endpoint = %s", got)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             mockClientID,
+		"redirect_uri":          mockRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        "challenge",
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if u.Query().Get(k) != v {
+			t.Errorf("AuthCodeURL() %s = %q, want %q", k, u.Query().Get(k), v)
+		}
+	}
+}
+
+func Test_OIDCProvider_Exchange(t *testing.T) {
+	server := newMockOIDCServer(t)
+	provider := newTestOIDCProvider(server.URL)
+
+	patterns := []struct {
+		name    string
+		claims  map[string]interface{}
+		tamper  func(req *entity.OIDCAuthRequest)
+		want    *entity.OIDCIdentity
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			claims: map[string]interface{}{"email": "test@example.com", "email_verified": true},
+			want: &entity.OIDCIdentity{
+				Issuer:        server.URL,
+				Subject:       "248289761001",
+				Email:         "test@example.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name:   "success: multiple audiences and email_verified as string",
+			claims: map[string]interface{}{"aud": []string{"other", mockClientID}, "email": "test@example.com", "email_verified": "true"},
+			want: &entity.OIDCIdentity{
+				Issuer:        server.URL,
+				Subject:       "248289761001",
+				Email:         "test@example.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name:   "success: email is not verified",
+			claims: map[string]interface{}{"email": "test@example.com"},
+			want: &entity.OIDCIdentity{
+				Issuer:  server.URL,
+				Subject: "248289761001",
+				Email:   "test@example.com",
+			},
+		},
+		{
+			name: "Fail: code verifier mismatch",
+			tamper: func(req *entity.OIDCAuthRequest) {
+				req.CodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fail: nonce mismatch",
+			tamper: func(req *entity.OIDCAuthRequest) {
+				req.Nonce = "another nonce"
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Fail: audience mismatch",
+			claims:  map[string]interface{}{"aud": "another client"},
+			wantErr: true,
+		},
+		{
+			name:    "Fail: issuer mismatch",
+			claims:  map[string]interface{}{"iss": "https://evil.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "Fail: expired",
+			claims:  map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()},
+			wantErr: true,
+		},
+		{
+			name:    "Fail: sub is missing",
+			claims:  map[string]interface{}{"sub": ""},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := login(t, server, provider, tt.claims, tt.tamper)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Exchange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && *got != *tt.want {
+				t.Errorf("Exchange() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_OIDCProvider_KeyRotation(t *testing.T) {
+	server := newMockOIDCServer(t)
+	provider := newTestOIDCProvider(server.URL)
+
+	if _, err := login(t, server, provider, nil, nil); err != nil {
+		t.Fatalf("Failed to login: %s", err)
+	}
+
+	// プロバイダが鍵を変更した場合は公開鍵を取得し直して検証する
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	server.rotateKey(key)
+	if _, err := login(t, server, provider, nil, nil); err != nil {
+		t.Fatalf("Failed to login after key rotation: %s", err)
+	}
+}
+
+func Test_OIDCProvider_Discovery(t *testing.T) {
+	ctx := context.Background()
+
+	// Issuerが未設定の場合はOIDCログインを無効にする
+	_, err := newTestOIDCProvider("").AuthCodeURL(ctx, "state", "nonce", "challenge")
+	if !errors.Is(err, repository.ErrOIDCNotConfigured) {
+		t.Errorf("AuthCodeURL() error = %v, want %v", err, repository.ErrOIDCNotConfigured)
+	}
+
+	// ディスカバリで返されたissuerが設定と異なる場合は使わない
+	server := newMockOIDCServer(t)
+	server.issuer = "https://evil.example.com"
+	if _, err = newTestOIDCProvider(server.URL).AuthCodeURL(ctx, "state", "nonce", "challenge"); err == nil {
+		t.Error("AuthCodeURL() error = nil, want issuer mismatch")
+	}
+}
@@ -3,13 +3,21 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/tusmasoma/go-chat-app/entity"
 )
 
+var (
+	ErrChannelNotFound = errors.New("channel not found")
+	// ErrChannelNameDuplicated はWorkspace内で同じ名前のChannelが既に存在する場合に返す
+	ErrChannelNameDuplicated = errors.New("channel name duplicated")
+)
+
 type ChannelRepository interface {
-	List(ctx context.Context) ([]entity.Channel, error)
+	List(ctx context.Context, workspaceID string) ([]entity.Channel, error)
 	Get(ctx context.Context, id string) (*entity.Channel, error)
+	GetByConversationKey(ctx context.Context, workspaceID, conversationKey string) (*entity.Channel, error)
 	Create(ctx context.Context, channel entity.Channel) error
 	Update(ctx context.Context, channel entity.Channel) error
 	Delete(ctx context.Context, id string) error
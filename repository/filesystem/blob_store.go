@@ -0,0 +1,92 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+var errInvalidKey = errors.New("invalid blob key")
+
+type blobStore struct {
+	dir string
+}
+
+// NewBlobStore はdir以下にファイルとして保存するBlobStoreを返す
+func NewBlobStore(dir string) (repository.BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		log.Error("Failed to create storage directory", log.Fstring("dir", dir), log.Ferror(err))
+		return nil, err
+	}
+	return &blobStore{
+		dir: dir,
+	}, nil
+}
+
+// Put は一時ファイルに書き込んだ後に名前を変更する。途中で失敗した場合は何も残さない
+func (bs *blobStore) Put(_ context.Context, key string, body io.Reader, _ string) error {
+	path, err := bs.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (bs *blobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := bs.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, repository.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (bs *blobStore) Delete(_ context.Context, key string) error {
+	path, err := bs.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path はキーに対応するファイルのパスを返す。保存先のディレクトリの外を指すキーは受け付けない
+func (bs *blobStore) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) || strings.HasPrefix(filepath.Base(key), ".") {
+		return "", errInvalidKey
+	}
+	return filepath.Join(bs.dir, filepath.FromSlash(key)), nil
+}
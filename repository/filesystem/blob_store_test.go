@@ -0,0 +1,68 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+func Test_BlobStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	store, err := NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "workspace/channel/attachment"
+
+	// Put
+	if err = store.Put(ctx, key, strings.NewReader("hello"), "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Get
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(got) != "hello" {
+		t.Errorf("Get() got = %q, %v, want %q", got, err, "hello")
+	}
+
+	// Put: 読み込みに失敗した場合は保存しない
+	failed := "workspace/channel/failed"
+	if err = store.Put(ctx, failed, io.MultiReader(strings.NewReader("partial"), errReader{}), "text/plain"); err == nil {
+		t.Error("Put() error = nil, want error")
+	}
+	if _, err = store.Get(ctx, failed); !errors.Is(err, repository.ErrBlobNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, repository.ErrBlobNotFound)
+	}
+
+	// Put: 保存先の外を指すキーは受け付けない
+	if err = store.Put(ctx, "../escape", strings.NewReader("hello"), "text/plain"); err == nil {
+		t.Error("Put() error = nil, want error")
+	}
+
+	// Delete
+	if err = store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = store.Get(ctx, key); !errors.Is(err, repository.ErrBlobNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, repository.ErrBlobNotFound)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read error")
+}
@@ -0,0 +1,23 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type logMailer struct{}
+
+// NewLogMailer はメールを送信せずにログに出力するMailerを返す(開発用)
+// 確認・再設定のURLもログに出力されるので、本番環境では使わないこと
+func NewLogMailer() repository.Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(_ context.Context, mail entity.Mail) error {
+	log.Info("Mail", log.Fstring("to", mail.To), log.Fstring("subject", mail.Subject), log.Fstring("body", mail.Body))
+	return nil
+}
@@ -0,0 +1,94 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/ory/dockertest"
+)
+
+var (
+	smtpPort int
+	apiURL   string
+)
+
+func TestMain(m *testing.M) {
+	var closeMailHog func()
+	var err error
+
+	smtpPort, apiURL, closeMailHog, err = startMailHog()
+	if err != nil {
+		log.Println(err)
+	} else {
+		defer closeMailHog()
+	}
+
+	m.Run()
+}
+
+// startMailHog は受信したメールをHTTP APIで確認できるSMTPサーバ(MailHog)を起動する
+func startMailHog() (int, string, func(), error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Printf("Could not construct pool: %s\n", err)
+		return 0, "", nil, err
+	}
+
+	err = pool.Client.Ping()
+	if err != nil {
+		log.Printf("Could not connect to Docker: %s", err)
+		return 0, "", nil, err
+	}
+
+	mailhogOptions := &dockertest.RunOptions{
+		Repository: "mailhog/mailhog",
+		Tag:        "latest",
+	}
+
+	mailhogResource, err := pool.RunWithOptions(mailhogOptions)
+	if err != nil {
+		log.Printf("Could not start MailHog resource: %s", err)
+		return 0, "", nil, err
+	}
+
+	port, err := strconv.Atoi(mailhogResource.GetPort("1025/tcp"))
+	if err != nil {
+		return 0, "", nil, err
+	}
+	url := fmt.Sprintf("http://localhost:%s", mailhogResource.GetPort("8025/tcp"))
+
+	err = pool.Retry(func() error {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port)) //nolint:govet // err shadowing
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+	if err != nil {
+		log.Printf("Could not connect to MailHog container: %s", err)
+		return 0, "", nil, err
+	}
+
+	log.Println("start MailHog container🐳")
+
+	return port, url, func() { closeMailHog(pool, mailhogResource) }, nil
+}
+
+func closeMailHog(pool *dockertest.Pool, resource *dockertest.Resource) {
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Failed to purge resource: %s", err)
+	}
+
+	log.Println("close MailHog container🐳")
+}
+
+func ValidateErr(t *testing.T, err error, wantErr error) {
+	if (err != nil) != (wantErr != nil) {
+		t.Errorf("error = %v, wantErr %v", err, wantErr)
+	} else if err != nil && wantErr != nil && err.Error() != wantErr.Error() {
+		t.Errorf("error = %v, wantErr %v", err, wantErr)
+	}
+}
@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type smtpMailer struct {
+	conf *config.MailConfig
+}
+
+// NewSMTPMailer はSMTPサーバからメールを送信するMailerを返す
+// サーバがSTARTTLSに対応している場合は暗号化し、SMTPUsernameが設定されている場合はPLAIN認証を行う
+func NewSMTPMailer(conf *config.MailConfig) repository.Mailer {
+	return &smtpMailer{
+		conf: conf,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, mail entity.Mail) error {
+	msg, err := m.buildMessage(mail)
+	if err != nil {
+		return err
+	}
+
+	// net/smtpはcontextに対応していないので、接続とデッドラインをcontextから設定する
+	addr := net.JoinHostPort(m.conf.SMTPHost, strconv.Itoa(m.conf.SMTPPort))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, m.conf.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: m.conf.SMTPHost, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.conf.SMTPUsername != "" {
+		if err = c.Auth(smtp.PlainAuth("", m.conf.SMTPUsername, m.conf.SMTPPassword, m.conf.SMTPHost)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err = c.Mail(m.conf.From); err != nil {
+		return err
+	}
+	if err = c.Rcpt(mail.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildMessage はUTF-8のテキスト形式のメッセージを作る。件名はMIMEエンコードし、本文はquoted-printableで送る
+func (m *smtpMailer) buildMessage(mail entity.Mail) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.conf.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mail.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(mail.Body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+// mailhogMessages はMailHogの検索APIのレスポンス
+type mailhogMessages struct {
+	Total int `json:"total"`
+	Items []struct {
+		Content struct {
+			Headers map[string][]string `json:"Headers"`
+			Body    string              `json:"Body"`
+		} `json:"Content"`
+	} `json:"items"`
+}
+
+func Test_SMTPMailer(t *testing.T) {
+	if apiURL == "" {
+		t.Skip("MailHog is not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mailer := NewSMTPMailer(&config.MailConfig{
+		From:     "no-reply@example.com",
+		SMTPHost: "localhost",
+		SMTPPort: smtpPort,
+	})
+
+	to := uuid.New().String() + "@example.com"
+	mail, err := entity.NewMail(to, "メールアドレスの確認", "Open http://localhost:3000/verify?token=abc to verify.")
+	ValidateErr(t, err, nil)
+
+	// Send
+	err = mailer.Send(ctx, *mail)
+	ValidateErr(t, err, nil)
+
+	// MailHogで受信したメールを確認する
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/api/v2/search?kind=to&query="+url.QueryEscape(to), nil)
+	resp, err := http.DefaultClient.Do(req)
+	ValidateErr(t, err, nil)
+	defer resp.Body.Close()
+
+	var got mailhogMessages
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	ValidateErr(t, err, nil)
+	if got.Total != 1 {
+		t.Fatalf("Total = %d, want 1", got.Total)
+	}
+
+	headers := got.Items[0].Content.Headers
+	subject, err := new(mime.WordDecoder).DecodeHeader(headers["Subject"][0])
+	ValidateErr(t, err, nil)
+	if subject != mail.Subject {
+		t.Errorf("Subject = %s, want %s", subject, mail.Subject)
+	}
+	if headers["From"][0] != "no-reply@example.com" {
+		t.Errorf("From = %s", headers["From"][0])
+	}
+	if !strings.Contains(got.Items[0].Content.Body, "token=3Dabc") {
+		t.Errorf("Body = %s", got.Items[0].Content.Body)
+	}
+}
@@ -0,0 +1,12 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, mail entity.Mail) error
+}
@@ -9,6 +9,7 @@ import (
 
 type MembershipRepository interface {
 	Get(ctx context.Context, userID, workspaceID string) (*entity.Membership, error)
+	ListByNames(ctx context.Context, workspaceID string, names []string) ([]entity.Membership, error)
 	Create(ctx context.Context, membership entity.Membership) error
 	Update(ctx context.Context, membership entity.Membership) error
 	Delete(ctx context.Context, userID, workspaceID string) error
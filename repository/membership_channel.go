@@ -0,0 +1,15 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type MembershipChannelRepository interface {
+	ListByUserID(ctx context.Context, userID, workspaceID string) ([]entity.MembershipChannel, error)
+	ListByChannelID(ctx context.Context, channelID string) ([]entity.MembershipChannel, error)
+	Create(ctx context.Context, membershipChannel entity.MembershipChannel) error
+	Delete(ctx context.Context, userID, channelID string) error
+}
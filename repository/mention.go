@@ -0,0 +1,13 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type MentionRepository interface {
+	ListByUserID(ctx context.Context, userID string, before *MessageCursor, limit int) ([]entity.Mention, error)
+	BatchCreate(ctx context.Context, mentions []entity.Mention) error
+}
@@ -3,14 +3,46 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/tusmasoma/go-chat-app/entity"
 )
 
+// MessageCursor はメッセージ一覧のページネーションに用いる位置(created_at, id)
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// ListMessagesQuery はメッセージ一覧の取得条件
+// Before/Afterが両方nilの場合は最新のメッセージを取得する
+type ListMessagesQuery struct {
+	Before *MessageCursor
+	After  *MessageCursor
+	Limit  int
+}
+
+// SearchMessagesQuery はメッセージ検索の条件
+// Termsの全ての語を本文に含むメッセージを、Cursorより前から新しい順に取得する
+type SearchMessagesQuery struct {
+	Terms      []string
+	ChannelIDs []string
+	UserID     string
+	Before     *time.Time
+	After      *time.Time
+	Cursor     *MessageCursor
+	Limit      int
+}
+
 type MessageRepository interface {
-	List(ctx context.Context, channleID string) (*entity.Messages, error)
+	List(ctx context.Context, channleID string, query ListMessagesQuery) (*entity.Messages, error)
+	ListReplies(ctx context.Context, parentID string, query ListMessagesQuery) (*entity.Messages, error)
 	Get(ctx context.Context, id string) (*entity.Message, error)
 	Create(ctx context.Context, message entity.Message) error
 	Update(ctx context.Context, message entity.Message) error
+	ListByIDs(ctx context.Context, ids []string) ([]entity.Message, error)
+	Search(ctx context.Context, query SearchMessagesQuery) ([]entity.Message, error)
+	CountUnread(ctx context.Context, channelID, userID string, after *MessageCursor) (*entity.UnreadCount, error)
+	RefreshReplySummary(ctx context.Context, parentID string) error
 	Delete(ctx context.Context, id string) error
 }
@@ -0,0 +1,13 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type MessageRevisionRepository interface {
+	ListByMessageID(ctx context.Context, messageID string) ([]entity.MessageRevision, error)
+	Create(ctx context.Context, revision entity.MessageRevision) error
+}
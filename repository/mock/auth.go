@@ -8,6 +8,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
 )
 
 // MockAuthRepository is a mock of AuthRepository interface.
@@ -34,12 +36,13 @@ func (m *MockAuthRepository) EXPECT() *MockAuthRepositoryMockRecorder {
 }
 
 // GenerateToken mocks base method.
-func (m *MockAuthRepository) GenerateToken(userID, email string) (string, string) {
+func (m *MockAuthRepository) GenerateToken(userID, email string) (string, string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GenerateToken", userID, email)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
-	return ret0, ret1
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
@@ -63,6 +66,20 @@ func (mr *MockAuthRepositoryMockRecorder) GetPayloadFromToken(jwt interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayloadFromToken", reflect.TypeOf((*MockAuthRepository)(nil).GetPayloadFromToken), jwt)
 }
 
+// JWKS mocks base method.
+func (m *MockAuthRepository) JWKS() entity.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(entity.JWKSet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockAuthRepositoryMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuthRepository)(nil).JWKS))
+}
+
 // ValidateAccessToken mocks base method.
 func (m *MockAuthRepository) ValidateAccessToken(jwt string) error {
 	m.ctrl.T.Helper()
@@ -79,19 +79,34 @@ func (mr *MockChannelRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockChannelRepository)(nil).Get), ctx, id)
 }
 
+// GetByConversationKey mocks base method.
+func (m *MockChannelRepository) GetByConversationKey(ctx context.Context, workspaceID, conversationKey string) (*entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByConversationKey", ctx, workspaceID, conversationKey)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByConversationKey indicates an expected call of GetByConversationKey.
+func (mr *MockChannelRepositoryMockRecorder) GetByConversationKey(ctx, workspaceID, conversationKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByConversationKey", reflect.TypeOf((*MockChannelRepository)(nil).GetByConversationKey), ctx, workspaceID, conversationKey)
+}
+
 // List mocks base method.
-func (m *MockChannelRepository) List(ctx context.Context) ([]entity.Channel, error) {
+func (m *MockChannelRepository) List(ctx context.Context, workspaceID string) ([]entity.Channel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
+	ret := m.ctrl.Call(m, "List", ctx, workspaceID)
 	ret0, _ := ret[0].([]entity.Channel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockChannelRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockChannelRepositoryMockRecorder) List(ctx, workspaceID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockChannelRepository)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockChannelRepository)(nil).List), ctx, workspaceID)
 }
 
 // Update mocks base method.
@@ -79,6 +79,21 @@ func (mr *MockMembershipRepositoryMockRecorder) Get(ctx, userID, workspaceID int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMembershipRepository)(nil).Get), ctx, userID, workspaceID)
 }
 
+// ListByNames mocks base method.
+func (m *MockMembershipRepository) ListByNames(ctx context.Context, workspaceID string, names []string) ([]entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByNames", ctx, workspaceID, names)
+	ret0, _ := ret[0].([]entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByNames indicates an expected call of ListByNames.
+func (mr *MockMembershipRepositoryMockRecorder) ListByNames(ctx, workspaceID, names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByNames", reflect.TypeOf((*MockMembershipRepository)(nil).ListByNames), ctx, workspaceID, names)
+}
+
 // Update mocks base method.
 func (m *MockMembershipRepository) Update(ctx context.Context, membership entity.Membership) error {
 	m.ctrl.T.Helper()
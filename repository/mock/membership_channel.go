@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: membership_channel.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockMembershipChannelRepository is a mock of MembershipChannelRepository interface.
+type MockMembershipChannelRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipChannelRepositoryMockRecorder
+}
+
+// MockMembershipChannelRepositoryMockRecorder is the mock recorder for MockMembershipChannelRepository.
+type MockMembershipChannelRepositoryMockRecorder struct {
+	mock *MockMembershipChannelRepository
+}
+
+// NewMockMembershipChannelRepository creates a new mock instance.
+func NewMockMembershipChannelRepository(ctrl *gomock.Controller) *MockMembershipChannelRepository {
+	mock := &MockMembershipChannelRepository{ctrl: ctrl}
+	mock.recorder = &MockMembershipChannelRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipChannelRepository) EXPECT() *MockMembershipChannelRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockMembershipChannelRepository) Create(ctx context.Context, membershipChannel entity.MembershipChannel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, membershipChannel)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMembershipChannelRepositoryMockRecorder) Create(ctx, membershipChannel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMembershipChannelRepository)(nil).Create), ctx, membershipChannel)
+}
+
+// Delete mocks base method.
+func (m *MockMembershipChannelRepository) Delete(ctx context.Context, userID, channelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID, channelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMembershipChannelRepositoryMockRecorder) Delete(ctx, userID, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMembershipChannelRepository)(nil).Delete), ctx, userID, channelID)
+}
+
+// ListByChannelID mocks base method.
+func (m *MockMembershipChannelRepository) ListByChannelID(ctx context.Context, channelID string) ([]entity.MembershipChannel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByChannelID", ctx, channelID)
+	ret0, _ := ret[0].([]entity.MembershipChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByChannelID indicates an expected call of ListByChannelID.
+func (mr *MockMembershipChannelRepositoryMockRecorder) ListByChannelID(ctx, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByChannelID", reflect.TypeOf((*MockMembershipChannelRepository)(nil).ListByChannelID), ctx, channelID)
+}
+
+// ListByUserID mocks base method.
+func (m *MockMembershipChannelRepository) ListByUserID(ctx context.Context, userID, workspaceID string) ([]entity.MembershipChannel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID, workspaceID)
+	ret0, _ := ret[0].([]entity.MembershipChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockMembershipChannelRepositoryMockRecorder) ListByUserID(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockMembershipChannelRepository)(nil).ListByUserID), ctx, userID, workspaceID)
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: mention.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+	repository "github.com/tusmasoma/go-chat-app/repository"
+)
+
+// MockMentionRepository is a mock of MentionRepository interface.
+type MockMentionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMentionRepositoryMockRecorder
+}
+
+// MockMentionRepositoryMockRecorder is the mock recorder for MockMentionRepository.
+type MockMentionRepositoryMockRecorder struct {
+	mock *MockMentionRepository
+}
+
+// NewMockMentionRepository creates a new mock instance.
+func NewMockMentionRepository(ctrl *gomock.Controller) *MockMentionRepository {
+	mock := &MockMentionRepository{ctrl: ctrl}
+	mock.recorder = &MockMentionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMentionRepository) EXPECT() *MockMentionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// BatchCreate mocks base method.
+func (m *MockMentionRepository) BatchCreate(ctx context.Context, mentions []entity.Mention) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreate", ctx, mentions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchCreate indicates an expected call of BatchCreate.
+func (mr *MockMentionRepositoryMockRecorder) BatchCreate(ctx, mentions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockMentionRepository)(nil).BatchCreate), ctx, mentions)
+}
+
+// ListByUserID mocks base method.
+func (m *MockMentionRepository) ListByUserID(ctx context.Context, userID string, before *repository.MessageCursor, limit int) ([]entity.Mention, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID, before, limit)
+	ret0, _ := ret[0].([]entity.Mention)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockMentionRepositoryMockRecorder) ListByUserID(ctx, userID, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockMentionRepository)(nil).ListByUserID), ctx, userID, before, limit)
+}
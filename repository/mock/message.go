@@ -11,6 +11,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 
 	entity "github.com/tusmasoma/go-chat-app/entity"
+	repository "github.com/tusmasoma/go-chat-app/repository"
 )
 
 // MockMessageRepository is a mock of MessageRepository interface.
@@ -36,6 +37,21 @@ func (m *MockMessageRepository) EXPECT() *MockMessageRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountUnread mocks base method.
+func (m *MockMessageRepository) CountUnread(ctx context.Context, channelID, userID string, after *repository.MessageCursor) (*entity.UnreadCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, channelID, userID, after)
+	ret0, _ := ret[0].(*entity.UnreadCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockMessageRepositoryMockRecorder) CountUnread(ctx, channelID, userID, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockMessageRepository)(nil).CountUnread), ctx, channelID, userID, after)
+}
+
 // Create mocks base method.
 func (m *MockMessageRepository) Create(ctx context.Context, message entity.Message) error {
 	m.ctrl.T.Helper()
@@ -80,18 +96,77 @@ func (mr *MockMessageRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Ca
 }
 
 // List mocks base method.
-func (m *MockMessageRepository) List(ctx context.Context, channleID string) (*entity.Messages, error) {
+func (m *MockMessageRepository) List(ctx context.Context, channleID string, query repository.ListMessagesQuery) (*entity.Messages, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, channleID)
+	ret := m.ctrl.Call(m, "List", ctx, channleID, query)
 	ret0, _ := ret[0].(*entity.Messages)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockMessageRepositoryMockRecorder) List(ctx, channleID interface{}) *gomock.Call {
+func (mr *MockMessageRepositoryMockRecorder) List(ctx, channleID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMessageRepository)(nil).List), ctx, channleID, query)
+}
+
+// ListByIDs mocks base method.
+func (m *MockMessageRepository) ListByIDs(ctx context.Context, ids []string) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByIDs", ctx, ids)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByIDs indicates an expected call of ListByIDs.
+func (mr *MockMessageRepositoryMockRecorder) ListByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByIDs", reflect.TypeOf((*MockMessageRepository)(nil).ListByIDs), ctx, ids)
+}
+
+// ListReplies mocks base method.
+func (m *MockMessageRepository) ListReplies(ctx context.Context, parentID string, query repository.ListMessagesQuery) (*entity.Messages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReplies", ctx, parentID, query)
+	ret0, _ := ret[0].(*entity.Messages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReplies indicates an expected call of ListReplies.
+func (mr *MockMessageRepositoryMockRecorder) ListReplies(ctx, parentID, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReplies", reflect.TypeOf((*MockMessageRepository)(nil).ListReplies), ctx, parentID, query)
+}
+
+// RefreshReplySummary mocks base method.
+func (m *MockMessageRepository) RefreshReplySummary(ctx context.Context, parentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshReplySummary", ctx, parentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshReplySummary indicates an expected call of RefreshReplySummary.
+func (mr *MockMessageRepositoryMockRecorder) RefreshReplySummary(ctx, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshReplySummary", reflect.TypeOf((*MockMessageRepository)(nil).RefreshReplySummary), ctx, parentID)
+}
+
+// Search mocks base method.
+func (m *MockMessageRepository) Search(ctx context.Context, query repository.SearchMessagesQuery) ([]entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockMessageRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMessageRepository)(nil).List), ctx, channleID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockMessageRepository)(nil).Search), ctx, query)
 }
 
 // Update mocks base method.
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: message_revision.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockMessageRevisionRepository is a mock of MessageRevisionRepository interface.
+type MockMessageRevisionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageRevisionRepositoryMockRecorder
+}
+
+// MockMessageRevisionRepositoryMockRecorder is the mock recorder for MockMessageRevisionRepository.
+type MockMessageRevisionRepositoryMockRecorder struct {
+	mock *MockMessageRevisionRepository
+}
+
+// NewMockMessageRevisionRepository creates a new mock instance.
+func NewMockMessageRevisionRepository(ctrl *gomock.Controller) *MockMessageRevisionRepository {
+	mock := &MockMessageRevisionRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageRevisionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageRevisionRepository) EXPECT() *MockMessageRevisionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockMessageRevisionRepository) Create(ctx context.Context, revision entity.MessageRevision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, revision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMessageRevisionRepositoryMockRecorder) Create(ctx, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMessageRevisionRepository)(nil).Create), ctx, revision)
+}
+
+// ListByMessageID mocks base method.
+func (m *MockMessageRevisionRepository) ListByMessageID(ctx context.Context, messageID string) ([]entity.MessageRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByMessageID", ctx, messageID)
+	ret0, _ := ret[0].([]entity.MessageRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByMessageID indicates an expected call of ListByMessageID.
+func (mr *MockMessageRevisionRepositoryMockRecorder) ListByMessageID(ctx, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByMessageID", reflect.TypeOf((*MockMessageRevisionRepository)(nil).ListByMessageID), ctx, messageID)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presence.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockPresenceRepository is a mock of PresenceRepository interface.
+type MockPresenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceRepositoryMockRecorder
+}
+
+// MockPresenceRepositoryMockRecorder is the mock recorder for MockPresenceRepository.
+type MockPresenceRepositoryMockRecorder struct {
+	mock *MockPresenceRepository
+}
+
+// NewMockPresenceRepository creates a new mock instance.
+func NewMockPresenceRepository(ctrl *gomock.Controller) *MockPresenceRepository {
+	mock := &MockPresenceRepository{ctrl: ctrl}
+	mock.recorder = &MockPresenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceRepository) EXPECT() *MockPresenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockPresenceRepository) Get(ctx context.Context, workspaceID, userID string) (*entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(*entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPresenceRepositoryMockRecorder) Get(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPresenceRepository)(nil).Get), ctx, workspaceID, userID)
+}
+
+// Heartbeat mocks base method.
+func (m *MockPresenceRepository) Heartbeat(ctx context.Context, workspaceID, userID, connectionID, status string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Heartbeat", ctx, workspaceID, userID, connectionID, status, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Heartbeat indicates an expected call of Heartbeat.
+func (mr *MockPresenceRepositoryMockRecorder) Heartbeat(ctx, workspaceID, userID, connectionID, status, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Heartbeat", reflect.TypeOf((*MockPresenceRepository)(nil).Heartbeat), ctx, workspaceID, userID, connectionID, status, ttl)
+}
+
+// List mocks base method.
+func (m *MockPresenceRepository) List(ctx context.Context, workspaceID string) ([]entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, workspaceID)
+	ret0, _ := ret[0].([]entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockPresenceRepositoryMockRecorder) List(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPresenceRepository)(nil).List), ctx, workspaceID)
+}
+
+// Remove mocks base method.
+func (m *MockPresenceRepository) Remove(ctx context.Context, workspaceID, userID, connectionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, workspaceID, userID, connectionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockPresenceRepositoryMockRecorder) Remove(ctx, workspaceID, userID, connectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockPresenceRepository)(nil).Remove), ctx, workspaceID, userID, connectionID)
+}
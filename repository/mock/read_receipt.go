@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: read_receipt.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockReadReceiptRepository is a mock of ReadReceiptRepository interface.
+type MockReadReceiptRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadReceiptRepositoryMockRecorder
+}
+
+// MockReadReceiptRepositoryMockRecorder is the mock recorder for MockReadReceiptRepository.
+type MockReadReceiptRepositoryMockRecorder struct {
+	mock *MockReadReceiptRepository
+}
+
+// NewMockReadReceiptRepository creates a new mock instance.
+func NewMockReadReceiptRepository(ctrl *gomock.Controller) *MockReadReceiptRepository {
+	mock := &MockReadReceiptRepository{ctrl: ctrl}
+	mock.recorder = &MockReadReceiptRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadReceiptRepository) EXPECT() *MockReadReceiptRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListByUserID mocks base method.
+func (m *MockReadReceiptRepository) ListByUserID(ctx context.Context, userID, workspaceID string) ([]entity.ReadReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID, workspaceID)
+	ret0, _ := ret[0].([]entity.ReadReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockReadReceiptRepositoryMockRecorder) ListByUserID(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockReadReceiptRepository)(nil).ListByUserID), ctx, userID, workspaceID)
+}
+
+// Upsert mocks base method.
+func (m *MockReadReceiptRepository) Upsert(ctx context.Context, receipt entity.ReadReceipt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, receipt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockReadReceiptRepositoryMockRecorder) Upsert(ctx, receipt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockReadReceiptRepository)(nil).Upsert), ctx, receipt)
+}
@@ -0,0 +1,196 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: token.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockTokenRepository is a mock of TokenRepository interface.
+type MockTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRepositoryMockRecorder
+}
+
+// MockTokenRepositoryMockRecorder is the mock recorder for MockTokenRepository.
+type MockTokenRepositoryMockRecorder struct {
+	mock *MockTokenRepository
+}
+
+// NewMockTokenRepository creates a new mock instance.
+func NewMockTokenRepository(ctrl *gomock.Controller) *MockTokenRepository {
+	mock := &MockTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRepository) EXPECT() *MockTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeOIDCAuthRequest mocks base method.
+func (m *MockTokenRepository) ConsumeOIDCAuthRequest(ctx context.Context, state string) (*entity.OIDCAuthRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOIDCAuthRequest", ctx, state)
+	ret0, _ := ret[0].(*entity.OIDCAuthRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOIDCAuthRequest indicates an expected call of ConsumeOIDCAuthRequest.
+func (mr *MockTokenRepositoryMockRecorder) ConsumeOIDCAuthRequest(ctx, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOIDCAuthRequest", reflect.TypeOf((*MockTokenRepository)(nil).ConsumeOIDCAuthRequest), ctx, state)
+}
+
+// ConsumeOneTimeToken mocks base method.
+func (m *MockTokenRepository) ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOneTimeToken", ctx, purpose, tokenHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOneTimeToken indicates an expected call of ConsumeOneTimeToken.
+func (mr *MockTokenRepositoryMockRecorder) ConsumeOneTimeToken(ctx, purpose, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOneTimeToken", reflect.TypeOf((*MockTokenRepository)(nil).ConsumeOneTimeToken), ctx, purpose, tokenHash)
+}
+
+// GetRefreshToken mocks base method.
+func (m *MockTokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", ctx, tokenHash)
+	ret0, _ := ret[0].(*entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken.
+func (mr *MockTokenRepositoryMockRecorder) GetRefreshToken(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockTokenRepository)(nil).GetRefreshToken), ctx, tokenHash)
+}
+
+// IsAccessTokenRevoked mocks base method.
+func (m *MockTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessTokenRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenRevoked indicates an expected call of IsAccessTokenRevoked.
+func (mr *MockTokenRepositoryMockRecorder) IsAccessTokenRevoked(ctx, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenRevoked", reflect.TypeOf((*MockTokenRepository)(nil).IsAccessTokenRevoked), ctx, jti)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockTokenRepository) RevokeAccessToken(ctx context.Context, jti string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, jti, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockTokenRepositoryMockRecorder) RevokeAccessToken(ctx, jti, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockTokenRepository)(nil).RevokeAccessToken), ctx, jti, ttl)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockTokenRepositoryMockRecorder) RevokeRefreshTokenFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockTokenRepository)(nil).RevokeRefreshTokenFamily), ctx, familyID)
+}
+
+// RevokeUserRefreshTokenFamilies mocks base method.
+func (m *MockTokenRepository) RevokeUserRefreshTokenFamilies(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokenFamilies", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokenFamilies indicates an expected call of RevokeUserRefreshTokenFamilies.
+func (mr *MockTokenRepositoryMockRecorder) RevokeUserRefreshTokenFamilies(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokenFamilies", reflect.TypeOf((*MockTokenRepository)(nil).RevokeUserRefreshTokenFamilies), ctx, userID)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockTokenRepository) RotateRefreshToken(ctx context.Context, current, next entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, current, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockTokenRepositoryMockRecorder) RotateRefreshToken(ctx, current, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockTokenRepository)(nil).RotateRefreshToken), ctx, current, next)
+}
+
+// SaveOIDCAuthRequest mocks base method.
+func (m *MockTokenRepository) SaveOIDCAuthRequest(ctx context.Context, req entity.OIDCAuthRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOIDCAuthRequest", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOIDCAuthRequest indicates an expected call of SaveOIDCAuthRequest.
+func (mr *MockTokenRepositoryMockRecorder) SaveOIDCAuthRequest(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOIDCAuthRequest", reflect.TypeOf((*MockTokenRepository)(nil).SaveOIDCAuthRequest), ctx, req)
+}
+
+// SaveOneTimeToken mocks base method.
+func (m *MockTokenRepository) SaveOneTimeToken(ctx context.Context, token entity.OneTimeToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOneTimeToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOneTimeToken indicates an expected call of SaveOneTimeToken.
+func (mr *MockTokenRepositoryMockRecorder) SaveOneTimeToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOneTimeToken", reflect.TypeOf((*MockTokenRepository)(nil).SaveOneTimeToken), ctx, token)
+}
+
+// SaveRefreshToken mocks base method.
+func (m *MockTokenRepository) SaveRefreshToken(ctx context.Context, token entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefreshToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefreshToken indicates an expected call of SaveRefreshToken.
+func (mr *MockTokenRepositoryMockRecorder) SaveRefreshToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockTokenRepository)(nil).SaveRefreshToken), ctx, token)
+}
@@ -36,6 +36,20 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ConsumeTOTPStep mocks base method.
+func (m *MockUserRepository) ConsumeTOTPStep(ctx context.Context, id string, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeTOTPStep", ctx, id, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeTOTPStep indicates an expected call of ConsumeTOTPStep.
+func (mr *MockUserRepositoryMockRecorder) ConsumeTOTPStep(ctx, id, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeTOTPStep", reflect.TypeOf((*MockUserRepository)(nil).ConsumeTOTPStep), ctx, id, step)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(ctx context.Context, user entity.User) error {
 	m.ctrl.T.Helper()
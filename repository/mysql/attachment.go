@@ -0,0 +1,154 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type attachmentModel struct {
+	ID          string    `gorm:"type:char(36);primaryKey"`
+	MessageID   *string   `gorm:"column:message_id"`
+	UserID      string    `gorm:"column:user_id"`
+	WorkspaceID string    `gorm:"column:workspace_id"`
+	ChannelID   string    `gorm:"column:channel_id"`
+	Name        string    `gorm:"column:name"`
+	ContentType string    `gorm:"column:content_type"`
+	Size        int64     `gorm:"column:size"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (attachmentModel) TableName() string {
+	return "Attachments"
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) repository.AttachmentRepository {
+	return &attachmentRepository{
+		db: db,
+	}
+}
+
+func (ar *attachmentRepository) Get(ctx context.Context, id string) (*entity.Attachment, error) {
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var am attachmentModel
+	if err := executor.WithContext(ctx).First(&am, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return toAttachment(am)
+}
+
+// ListByIDs は指定したIDの添付ファイルを返す。存在しないIDは無視する
+func (ar *attachmentRepository) ListByIDs(ctx context.Context, ids []string) ([]entity.Attachment, error) {
+	if len(ids) == 0 {
+		return []entity.Attachment{}, nil
+	}
+
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var ams []attachmentModel
+	if err := executor.WithContext(ctx).Find(&ams, "id IN ?", ids).Error; err != nil {
+		return nil, err
+	}
+	return toAttachments(ams)
+}
+
+// ListByMessageIDs はメッセージの添付ファイルをアップロードされた順に返す
+func (ar *attachmentRepository) ListByMessageIDs(ctx context.Context, messageIDs []string) ([]entity.Attachment, error) {
+	if len(messageIDs) == 0 {
+		return []entity.Attachment{}, nil
+	}
+
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var ams []attachmentModel
+	if err := executor.WithContext(ctx).
+		Where("message_id IN ?", messageIDs).
+		Order("created_at ASC, id ASC").
+		Find(&ams).Error; err != nil {
+		return nil, err
+	}
+	return toAttachments(ams)
+}
+
+func (ar *attachmentRepository) Create(ctx context.Context, attachment entity.Attachment) error {
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	am := attachmentModel{
+		ID:          attachment.ID,
+		UserID:      attachment.UserID,
+		WorkspaceID: attachment.WorkspaceID,
+		ChannelID:   attachment.ChannelID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		CreatedAt:   attachment.CreatedAt,
+	}
+	if attachment.MessageID != "" {
+		am.MessageID = &attachment.MessageID
+	}
+	if err := executor.WithContext(ctx).Create(&am).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// AttachToMessage は未添付の添付ファイルをメッセージに紐付け、紐付けた数を返す。既に他のメッセージに添付されているものは変更しない
+func (ar *attachmentRepository) AttachToMessage(ctx context.Context, ids []string, messageID string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	executor := ar.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	result := executor.WithContext(ctx).Model(&attachmentModel{}).
+		Where("id IN ? AND message_id IS NULL", ids).
+		Update("message_id", messageID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func toAttachments(ams []attachmentModel) ([]entity.Attachment, error) {
+	attachments := make([]entity.Attachment, len(ams))
+	for i, am := range ams {
+		attachment, err := toAttachment(am)
+		if err != nil {
+			return nil, err
+		}
+		attachments[i] = *attachment
+	}
+	return attachments, nil
+}
+
+func toAttachment(am attachmentModel) (*entity.Attachment, error) {
+	var messageID string
+	if am.MessageID != nil {
+		messageID = *am.MessageID
+	}
+	return entity.NewAttachment(am.ID, messageID, am.UserID, am.WorkspaceID, am.ChannelID, am.Name, am.ContentType, am.Size, am.CreatedAt)
+}
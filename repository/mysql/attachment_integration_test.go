@@ -0,0 +1,74 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func Test_AttachmentRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewAttachmentRepository(db)
+	channelRepo := NewChannelRepository(db)
+	messageRepo := NewMessageRepository(db)
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+
+	channel, err := entity.NewChannel("", workspaceID, "general", false)
+	ValidateErr(t, err, nil)
+	err = channelRepo.Create(ctx, *channel)
+	ValidateErr(t, err, nil)
+
+	message, err := entity.NewMessage("", userID, workspaceID, "Hello, World!", entity.CreateMessageAction, channel.ID, time.Time{})
+	ValidateErr(t, err, nil)
+	err = messageRepo.Create(ctx, *message)
+	ValidateErr(t, err, nil)
+
+	attachment, err := entity.NewAttachment("", "", userID, workspaceID, channel.ID, "photo.png", "image/png", 1024, time.Now().Truncate(time.Second))
+	ValidateErr(t, err, nil)
+
+	// Create
+	err = repo.Create(ctx, *attachment)
+	ValidateErr(t, err, nil)
+
+	// Get
+	got, err := repo.Get(ctx, attachment.ID)
+	ValidateErr(t, err, nil)
+	if got.Name != attachment.Name || got.Size != attachment.Size || got.IsAttached() {
+		t.Errorf("Get() got: %v, want: %v", got, attachment)
+	}
+
+	// ListByIDs
+	attachments, err := repo.ListByIDs(ctx, []string{attachment.ID, uuid.New().String()})
+	ValidateErr(t, err, nil)
+	if len(attachments) != 1 || attachments[0].ID != attachment.ID {
+		t.Errorf("ListByIDs() got: %v, want: [%v]", attachments, attachment)
+	}
+
+	// AttachToMessage
+	attached, err := repo.AttachToMessage(ctx, []string{attachment.ID}, message.ID)
+	ValidateErr(t, err, nil)
+	if attached != 1 {
+		t.Errorf("AttachToMessage() got: %d, want: 1", attached)
+	}
+
+	// AttachToMessage: 添付済みのファイルは他のメッセージに付け替えられない
+	attached, err = repo.AttachToMessage(ctx, []string{attachment.ID}, uuid.New().String())
+	ValidateErr(t, err, nil)
+	if attached != 0 {
+		t.Errorf("AttachToMessage() got: %d, want: 0", attached)
+	}
+
+	// ListByMessageIDs
+	attachments, err = repo.ListByMessageIDs(ctx, []string{message.ID})
+	ValidateErr(t, err, nil)
+	if len(attachments) != 1 || attachments[0].MessageID != message.ID {
+		t.Errorf("ListByMessageIDs() got: %v, want message_id %s", attachments, message.ID)
+	}
+}
@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type channelModel struct {
+	ID          string `gorm:"type:char(36);primaryKey"`
+	WorkspaceID string `gorm:"column:workspace_id"`
+	Name        string `gorm:"column:name"`
+	Private     bool   `gorm:"column:private"`
+	Kind        string `gorm:"column:kind"`
+	// DM以外のChannelはNULLとする為、ポインタで保持する
+	ConversationKey *string `gorm:"column:conversation_key"`
+}
+
+func (channelModel) TableName() string {
+	return "Channels"
+}
+
+type channelRepository struct {
+	db *gorm.DB
+}
+
+func NewChannelRepository(db *gorm.DB) repository.ChannelRepository {
+	return &channelRepository{
+		db: db,
+	}
+}
+
+func (cr *channelRepository) List(ctx context.Context, workspaceID string) ([]entity.Channel, error) {
+	executor := cr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var cms []channelModel
+	if err := executor.WithContext(ctx).Order("name").Find(&cms, "workspace_id = ?", workspaceID).Error; err != nil {
+		return nil, err
+	}
+
+	channels := make([]entity.Channel, len(cms))
+	for i, cm := range cms {
+		channel, err := toChannel(cm)
+		if err != nil {
+			return nil, err
+		}
+		channels[i] = *channel
+	}
+	return channels, nil
+}
+
+func (cr *channelRepository) Get(ctx context.Context, id string) (*entity.Channel, error) {
+	executor := cr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var cm channelModel
+	if err := executor.WithContext(ctx).First(&cm, "id = ?", id).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrChannelNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return toChannel(cm)
+}
+
+func (cr *channelRepository) GetByConversationKey(ctx context.Context, workspaceID, conversationKey string) (*entity.Channel, error) {
+	executor := cr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var cm channelModel
+	if err := executor.WithContext(ctx).First(&cm, "workspace_id = ? AND conversation_key = ?", workspaceID, conversationKey).Error; err != nil {
+		return nil, err
+	}
+	return toChannel(cm)
+}
+
+func toChannel(cm channelModel) (*entity.Channel, error) {
+	channel, err := entity.NewChannel(cm.ID, cm.WorkspaceID, cm.Name, cm.Private)
+	if err != nil {
+		return nil, err
+	}
+	if cm.Kind != "" {
+		channel.Kind = cm.Kind
+	}
+	if cm.ConversationKey != nil {
+		channel.ConversationKey = *cm.ConversationKey
+	}
+	return channel, nil
+}
+
+func (cr *channelRepository) Create(ctx context.Context, channel entity.Channel) error {
+	executor := cr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var conversationKey *string
+	if channel.ConversationKey != "" {
+		conversationKey = &channel.ConversationKey
+	}
+	kind := channel.Kind
+	if kind == "" {
+		kind = entity.ChannelKindChannel
+	}
+	if err := executor.WithContext(ctx).Create(&channelModel{
+		ID:              channel.ID,
+		WorkspaceID:     channel.WorkspaceID,
+		Name:            channel.Name,
+		Private:         channel.Private,
+		Kind:            kind,
+		ConversationKey: conversationKey,
+	}).Error; errors.Is(err, gorm.ErrDuplicatedKey) && !channel.IsDirectMessage() {
+		return repository.ErrChannelNameDuplicated
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *channelRepository) Update(ctx context.Context, channel entity.Channel) error {
+	executor := cr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Model(&channelModel{}).Where("id = ?", channel.ID).Updates(&channelModel{
+		Name: channel.Name,
+	}).Error; errors.Is(err, gorm.ErrDuplicatedKey) {
+		return repository.ErrChannelNameDuplicated
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *channelRepository) Delete(ctx context.Context, id string) error {
+	executor := cr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Delete(&channelModel{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	return nil
+}
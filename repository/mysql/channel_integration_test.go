@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+func Test_ChannelRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewChannelRepository(db)
+
+	workspaceID := uuid.New().String()
+
+	channel1, err := entity.NewChannel("", workspaceID, "general", false)
+	ValidateErr(t, err, nil)
+	channel2, err := entity.NewChannel("", workspaceID, "random", true)
+	ValidateErr(t, err, nil)
+
+	// Create
+	err = repo.Create(ctx, *channel1)
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, *channel2)
+	ValidateErr(t, err, nil)
+
+	// 同じWorkspaceに同じ名前のChannelは作成できない
+	duplicated, err := entity.NewChannel("", workspaceID, "general", false)
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, *duplicated)
+	ValidateErr(t, err, repository.ErrChannelNameDuplicated)
+
+	// Get
+	gotChannel, err := repo.Get(ctx, channel1.ID)
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(channel1, gotChannel) {
+		t.Errorf("want: %v, got: %v", channel1, gotChannel)
+	}
+
+	// List
+	channels, err := repo.List(ctx, workspaceID)
+	ValidateErr(t, err, nil)
+	if len(channels) != 2 {
+		t.Errorf("len(channels) got: %d, want: 2", len(channels))
+	}
+
+	// GetByConversationKey
+	dm, err := entity.NewDirectMessageChannel("", workspaceID, []string{uuid.New().String(), uuid.New().String()})
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, *dm)
+	ValidateErr(t, err, nil)
+
+	gotChannel, err = repo.GetByConversationKey(ctx, workspaceID, dm.ConversationKey)
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(dm, gotChannel) {
+		t.Errorf("want: %v, got: %v", dm, gotChannel)
+	}
+
+	// Update
+	channel1.Name = "updated"
+	err = repo.Update(ctx, *channel1)
+	ValidateErr(t, err, nil)
+
+	gotChannel, err = repo.Get(ctx, channel1.ID)
+	ValidateErr(t, err, nil)
+	if gotChannel.Name != "updated" {
+		t.Errorf("Expected channel name 'updated', got %s", gotChannel.Name)
+	}
+
+	// Delete
+	err = repo.Delete(ctx, channel1.ID)
+	ValidateErr(t, err, nil)
+
+	_, err = repo.Get(ctx, channel1.ID)
+	ValidateErr(t, err, repository.ErrChannelNotFound)
+}
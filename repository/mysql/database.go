@@ -77,7 +77,8 @@ func NewMySQLDB(ctx context.Context) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true",
 		conf.User, conf.Password, conf.Host, conf.Port, conf.DBName)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{}) // ping is automatically called
+	// 一意制約違反をgorm.ErrDuplicatedKeyとして扱えるよう、ドライバのエラーを変換する
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true}) // ping is automatically called
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +89,7 @@ func startMySQL() (*gorm.DB, string, func(), error) {
 
 	err = pool.Retry(func() error {
 		dsn := fmt.Sprintf("root:go-chat-app@(localhost:%s)/go_chat_app_test_db?charset=utf8mb4&parseTime=True", port)
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 		if err != nil {
 			return err
 		}
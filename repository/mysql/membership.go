@@ -55,6 +55,39 @@ func (mr *membershipRepository) Get(ctx context.Context, userID, workspaceID str
 	return membership, nil
 }
 
+// ListByNames はWorkspaceで表示名が一致するメンバーを返す。同じ表示名のメンバーは全て含める
+func (mr *membershipRepository) ListByNames(ctx context.Context, workspaceID string, names []string) ([]entity.Membership, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var mms []membershipModel
+	if err := executor.WithContext(ctx).Find(&mms, "workspace_id = ? AND name IN ?", workspaceID, names).Error; err != nil {
+		return nil, err
+	}
+
+	memberships := make([]entity.Membership, len(mms))
+	for i, mm := range mms {
+		membership, err := entity.NewMembership(
+			mm.UserID,
+			mm.WorkspaceID,
+			mm.Name,
+			mm.ProfileImageURL,
+			mm.IsAdmin,
+		)
+		if err != nil {
+			return nil, err
+		}
+		memberships[i] = *membership
+	}
+	return memberships, nil
+}
+
 func (mr *membershipRepository) Create(ctx context.Context, membership entity.Membership) error {
 	executor := mr.db
 	if tx := TxFromCtx(ctx); tx != nil {
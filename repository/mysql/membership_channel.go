@@ -0,0 +1,103 @@
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type membershipChannelModel struct {
+	UserID      string `gorm:"column:user_id"`
+	WorkspaceID string `gorm:"column:workspace_id"`
+	ChannelID   string `gorm:"column:channel_id"`
+	IsOwner     bool   `gorm:"column:is_owner"`
+}
+
+func (membershipChannelModel) TableName() string {
+	return "Membership_Channels"
+}
+
+type membershipChannelRepository struct {
+	db *gorm.DB
+}
+
+func NewMembershipChannelRepository(db *gorm.DB) repository.MembershipChannelRepository {
+	return &membershipChannelRepository{
+		db: db,
+	}
+}
+
+func (mcr *membershipChannelRepository) ListByUserID(ctx context.Context, userID, workspaceID string) ([]entity.MembershipChannel, error) {
+	executor := mcr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var mcms []membershipChannelModel
+	if err := executor.WithContext(ctx).Find(&mcms, "user_id = ? AND workspace_id = ?", userID, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return toMembershipChannels(mcms)
+}
+
+func (mcr *membershipChannelRepository) ListByChannelID(ctx context.Context, channelID string) ([]entity.MembershipChannel, error) {
+	executor := mcr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var mcms []membershipChannelModel
+	if err := executor.WithContext(ctx).Find(&mcms, "channel_id = ?", channelID).Error; err != nil {
+		return nil, err
+	}
+	return toMembershipChannels(mcms)
+}
+
+func toMembershipChannels(mcms []membershipChannelModel) ([]entity.MembershipChannel, error) {
+	membershipChannels := make([]entity.MembershipChannel, len(mcms))
+	for i, mcm := range mcms {
+		membershipChannel, err := entity.NewMembershipChannel(
+			mcm.UserID,
+			mcm.WorkspaceID,
+			mcm.ChannelID,
+			mcm.IsOwner,
+		)
+		if err != nil {
+			return nil, err
+		}
+		membershipChannels[i] = *membershipChannel
+	}
+	return membershipChannels, nil
+}
+
+func (mcr *membershipChannelRepository) Create(ctx context.Context, membershipChannel entity.MembershipChannel) error {
+	executor := mcr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Create(&membershipChannelModel{
+		UserID:      membershipChannel.UserID,
+		WorkspaceID: membershipChannel.WorkspaceID,
+		ChannelID:   membershipChannel.ChannelID,
+		IsOwner:     membershipChannel.IsOwner,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mcr *membershipChannelRepository) Delete(ctx context.Context, userID, channelID string) error {
+	executor := mcr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Delete(&membershipChannelModel{}, "user_id = ? AND channel_id = ?", userID, channelID).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func Test_MembershipChannelRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewMembershipChannelRepository(db)
+	membershipRepo := NewMembershipRepository(db)
+	channelRepo := NewChannelRepository(db)
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	membership, _ := entity.NewMembership(userID, workspaceID, "test", "", false)
+	err := membershipRepo.Create(ctx, *membership)
+	ValidateErr(t, err, nil)
+
+	channel, _ := entity.NewChannel("", workspaceID, "private", true)
+	err = channelRepo.Create(ctx, *channel)
+	ValidateErr(t, err, nil)
+
+	membershipChannel, err := entity.NewMembershipChannel(userID, workspaceID, channel.ID, true)
+	ValidateErr(t, err, nil)
+
+	// Create
+	err = repo.Create(ctx, *membershipChannel)
+	ValidateErr(t, err, nil)
+
+	// ListByUserID
+	membershipChannels, err := repo.ListByUserID(ctx, userID, workspaceID)
+	ValidateErr(t, err, nil)
+	if len(membershipChannels) != 1 || membershipChannels[0] != *membershipChannel {
+		t.Errorf("ListByUserID() got: %v, want: [%v]", membershipChannels, membershipChannel)
+	}
+
+	// ListByChannelID
+	membershipChannels, err = repo.ListByChannelID(ctx, channel.ID)
+	ValidateErr(t, err, nil)
+	if len(membershipChannels) != 1 || membershipChannels[0] != *membershipChannel {
+		t.Errorf("ListByChannelID() got: %v, want: [%v]", membershipChannels, membershipChannel)
+	}
+
+	// Delete
+	err = repo.Delete(ctx, userID, channel.ID)
+	ValidateErr(t, err, nil)
+
+	membershipChannels, err = repo.ListByChannelID(ctx, channel.ID)
+	ValidateErr(t, err, nil)
+	if len(membershipChannels) != 0 {
+		t.Errorf("len(membershipChannels) got: %d, want: 0", len(membershipChannels))
+	}
+}
@@ -36,6 +36,13 @@ func Test_MembershipRepository(t *testing.T) {
 		t.Errorf("Get() got = %v, want %v", gotMembership, membership)
 	}
 
+	// ListByNames
+	memberships, err := repo.ListByNames(ctx, workspaceID, []string{"test", "unknown"})
+	ValidateErr(t, err, nil)
+	if len(memberships) != 1 || memberships[0].UserID != userID {
+		t.Errorf("ListByNames() got = %v, want [%v]", memberships, membership)
+	}
+
 	// Update
 	membership.Name = "updated"
 	err = repo.Update(ctx, *membership)
@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type mentionModel struct {
+	MessageID   string    `gorm:"column:message_id;primaryKey"`
+	UserID      string    `gorm:"column:user_id;primaryKey"`
+	WorkspaceID string    `gorm:"column:workspace_id"`
+	ChannelID   string    `gorm:"column:channel_id"`
+	Kind        string    `gorm:"column:kind"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (mentionModel) TableName() string {
+	return "Mentions"
+}
+
+type mentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) repository.MentionRepository {
+	return &mentionRepository{
+		db: db,
+	}
+}
+
+// ListByUserID はユーザ宛てのメンションを(created_at, message_id)をカーソルとして新しい順に返す
+func (mnr *mentionRepository) ListByUserID(ctx context.Context, userID string, before *repository.MessageCursor, limit int) ([]entity.Mention, error) {
+	executor := mnr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	db := executor.WithContext(ctx).Where("user_id = ?", userID)
+	if before != nil {
+		db = db.Where(
+			"(created_at < ? OR (created_at = ? AND message_id < ?))",
+			before.CreatedAt, before.CreatedAt, before.ID,
+		)
+	}
+	db = db.Order("created_at DESC, message_id DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var mms []mentionModel
+	if err := db.Find(&mms).Error; err != nil {
+		return nil, err
+	}
+
+	mentions := make([]entity.Mention, len(mms))
+	for i, mm := range mms {
+		mention, err := entity.NewMention(mm.MessageID, mm.UserID, mm.WorkspaceID, mm.ChannelID, mm.Kind, mm.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		mentions[i] = *mention
+	}
+	return mentions, nil
+}
+
+// BatchCreate はメンションをまとめて保存する。既に保存済みのメンションは無視する
+func (mnr *mentionRepository) BatchCreate(ctx context.Context, mentions []entity.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	executor := mnr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	mms := make([]mentionModel, len(mentions))
+	for i, mention := range mentions {
+		mms[i] = mentionModel{
+			MessageID:   mention.MessageID,
+			UserID:      mention.UserID,
+			WorkspaceID: mention.WorkspaceID,
+			ChannelID:   mention.ChannelID,
+			Kind:        mention.Kind,
+			CreatedAt:   mention.CreatedAt,
+		}
+	}
+	if err := executor.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&mms).Error; err != nil {
+		return err
+	}
+	return nil
+}
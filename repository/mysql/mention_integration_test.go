@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+func Test_MentionRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewMentionRepository(db)
+	messageRepo := NewMessageRepository(db)
+
+	userID := uuid.New().String()
+	mentionedUserID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	var mentions []entity.Mention
+	for i := 0; i < 2; i++ {
+		message, err := entity.NewMessage("", userID, workspaceID, "@bob hello", entity.CreateMessageAction, channelID, time.Now().Add(time.Duration(i)*time.Second).Truncate(time.Second))
+		ValidateErr(t, err, nil)
+		err = messageRepo.Create(ctx, *message)
+		ValidateErr(t, err, nil)
+
+		mention, err := entity.NewMention(message.ID, mentionedUserID, workspaceID, channelID, entity.MentionKindUser, message.CreatedAt)
+		ValidateErr(t, err, nil)
+		mentions = append(mentions, *mention)
+	}
+
+	// BatchCreate: 重複は無視する
+	err := repo.BatchCreate(ctx, mentions)
+	ValidateErr(t, err, nil)
+	err = repo.BatchCreate(ctx, mentions[:1])
+	ValidateErr(t, err, nil)
+
+	// ListByUserID: 新しい順
+	got, err := repo.ListByUserID(ctx, mentionedUserID, nil, 10)
+	ValidateErr(t, err, nil)
+	if len(got) != 2 || got[0].MessageID != mentions[1].MessageID {
+		t.Errorf("ListByUserID() got: %v", got)
+	}
+
+	// ListByUserID with cursor
+	got, err = repo.ListByUserID(ctx, mentionedUserID, &repository.MessageCursor{CreatedAt: got[0].CreatedAt, ID: got[0].MessageID}, 10)
+	ValidateErr(t, err, nil)
+	if len(got) != 1 || got[0].MessageID != mentions[0].MessageID {
+		t.Errorf("ListByUserID() with cursor got: %v", got)
+	}
+
+	// CountUnread: メンションされたメッセージを数える
+	unread, err := messageRepo.CountUnread(ctx, channelID, mentionedUserID, nil)
+	ValidateErr(t, err, nil)
+	if unread.UnreadCount != 2 || unread.MentionCount != 2 {
+		t.Errorf("CountUnread() got: %+v, want: 2 unread and 2 mentions", unread)
+	}
+}
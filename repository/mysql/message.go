@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,12 +12,18 @@ import (
 )
 
 type messageModel struct {
-	ID          string    `gorm:"type:char(36);primaryKey"`
-	UserID      string    `gorm:"column:user_id"`
-	WorkspaceID string    `gorm:"column:workspace_id"`
-	ChannelID   string    `gorm:"column:channel_id"`
-	Text        string    `gorm:"column:text"`
-	CreatedAt   time.Time `gorm:"column:created_at"`
+	ID                string     `gorm:"type:char(36);primaryKey"`
+	UserID            string     `gorm:"column:user_id"`
+	WorkspaceID       string     `gorm:"column:workspace_id"`
+	ChannelID         string     `gorm:"column:channel_id"`
+	ParentID          *string    `gorm:"column:parent_id"`
+	AlsoSendToChannel bool       `gorm:"column:also_send_to_channel"`
+	Text              string     `gorm:"column:text"`
+	ReplyCount        int        `gorm:"column:reply_count"`
+	LastReplyAt       *time.Time `gorm:"column:last_reply_at"`
+	EditedAt          *time.Time `gorm:"column:edited_at"`
+	DeletedAt         *time.Time `gorm:"column:deleted_at"`
+	CreatedAt         time.Time  `gorm:"column:created_at"`
 }
 
 func (messageModel) TableName() string {
@@ -33,39 +40,46 @@ func NewMessageRepository(db *gorm.DB) repository.MessageRepository {
 	}
 }
 
-func (mr *messageRepository) List(ctx context.Context, channleID string) (*entity.Messages, error) {
+// List はChannelのメッセージを(created_at, id)をカーソルとして取得し、古い順に並べて返す
+// スレッドへの返信は、Channelにも表示するものだけを含める
+func (mr *messageRepository) List(ctx context.Context, channleID string, query repository.ListMessagesQuery) (*entity.Messages, error) {
 	executor := mr.db
 	if tx := TxFromCtx(ctx); tx != nil {
 		executor = tx
 	}
 
+	db := executor.WithContext(ctx).
+		Where("channel_id = ?", channleID).
+		Where("(parent_id IS NULL OR also_send_to_channel = ?)", true)
+	db, ascending := paginate(db, query)
+
 	var mms []messageModel
-	if err := executor.WithContext(ctx).Find(&mms, "channel_id = ?", channleID).Error; err != nil {
+	if err := db.Find(&mms).Error; err != nil {
 		return nil, err
 	}
+	return toMessages(mms, ascending, entity.ListMessagesAction, channleID, "")
+}
 
-	var err error
-	msgs := make([]*entity.Message, len(mms))
-	for i, mm := range mms {
-		msgs[i], err = entity.NewMessage(
-			mm.ID,
-			mm.UserID,
-			mm.WorkspaceID,
-			mm.Text,
-			entity.NoneAction,
-			mm.ChannelID,
-			mm.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
+// ListReplies はスレッドの返信を(created_at, id)をカーソルとして取得し、古い順に並べて返す
+func (mr *messageRepository) ListReplies(ctx context.Context, parentID string, query repository.ListMessagesQuery) (*entity.Messages, error) {
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
 	}
 
-	messages, err := entity.NewMessages(msgs, entity.ListMessagesAction, channleID)
-	if err != nil {
+	var parent messageModel
+	if err := executor.WithContext(ctx).First(&parent, "id = ?", parentID).Error; err != nil {
 		return nil, err
 	}
-	return messages, nil
+
+	db := executor.WithContext(ctx).Where("parent_id = ?", parentID)
+	db, ascending := paginate(db, query)
+
+	var mms []messageModel
+	if err := db.Find(&mms).Error; err != nil {
+		return nil, err
+	}
+	return toMessages(mms, ascending, entity.ListThreadMessagesAction, parent.ChannelID, parentID)
 }
 
 func (mr *messageRepository) Get(ctx context.Context, id string) (*entity.Message, error) {
@@ -78,20 +92,83 @@ func (mr *messageRepository) Get(ctx context.Context, id string) (*entity.Messag
 	if err := executor.WithContext(ctx).First(&mm, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
+	return toMessage(mm, entity.GetMessagesAction), nil
+}
 
-	msg, err := entity.NewMessage(
-		mm.ID,
-		mm.UserID,
-		mm.WorkspaceID,
-		mm.Text,
-		entity.GetMessagesAction,
-		mm.ChannelID,
-		mm.CreatedAt,
-	)
-	if err != nil {
+// ListByIDs は指定したIDのメッセージを返す。存在しないIDは無視する
+func (mr *messageRepository) ListByIDs(ctx context.Context, ids []string) ([]entity.Message, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var mms []messageModel
+	if err := executor.WithContext(ctx).Find(&mms, "id IN ?", ids).Error; err != nil {
 		return nil, err
 	}
-	return msg, nil
+
+	messages := make([]entity.Message, len(mms))
+	for i, mm := range mms {
+		messages[i] = *toMessage(mm, entity.NoneAction)
+	}
+	return messages, nil
+}
+
+// Search は本文の全文検索インデックスを用いて、検索語を全て含むメッセージを新しい順に返す
+// 削除済みのメッセージは含めない
+func (mr *messageRepository) Search(ctx context.Context, query repository.SearchMessagesQuery) ([]entity.Message, error) {
+	if len(query.Terms) == 0 || len(query.ChannelIDs) == 0 {
+		return nil, nil
+	}
+
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	// 各検索語を必須のフレーズとして指定し、BOOLEAN MODEの演算子として解釈されないようにする
+	phrases := make([]string, len(query.Terms))
+	for i, term := range query.Terms {
+		phrases[i] = `+"` + strings.ReplaceAll(term, `"`, "") + `"`
+	}
+
+	db := executor.WithContext(ctx).
+		Where("MATCH(text) AGAINST (? IN BOOLEAN MODE)", strings.Join(phrases, " ")).
+		Where("channel_id IN ? AND deleted_at IS NULL", query.ChannelIDs)
+	if query.UserID != "" {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Before != nil {
+		db = db.Where("created_at < ?", *query.Before)
+	}
+	if query.After != nil {
+		db = db.Where("created_at > ?", *query.After)
+	}
+	if query.Cursor != nil {
+		db = db.Where(
+			"(created_at < ? OR (created_at = ? AND id < ?))",
+			query.Cursor.CreatedAt, query.Cursor.CreatedAt, query.Cursor.ID,
+		)
+	}
+	db = db.Order("created_at DESC, id DESC")
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var mms []messageModel
+	if err := db.Find(&mms).Error; err != nil {
+		return nil, err
+	}
+
+	messages := make([]entity.Message, len(mms))
+	for i, mm := range mms {
+		messages[i] = *toMessage(mm, entity.NoneAction)
+	}
+	return messages, nil
 }
 
 func (mr *messageRepository) Create(ctx context.Context, message entity.Message) error {
@@ -100,13 +177,19 @@ func (mr *messageRepository) Create(ctx context.Context, message entity.Message)
 		executor = tx
 	}
 
+	var parentID *string
+	if message.IsReply() {
+		parentID = &message.ParentID
+	}
 	if err := executor.WithContext(ctx).Create(&messageModel{
-		ID:          message.ID,
-		UserID:      message.UserID,
-		WorkspaceID: message.WorkspaceID,
-		ChannelID:   message.TargetID,
-		Text:        message.Text,
-		CreatedAt:   message.CreatedAt,
+		ID:                message.ID,
+		UserID:            message.UserID,
+		WorkspaceID:       message.WorkspaceID,
+		ChannelID:         message.TargetID,
+		ParentID:          parentID,
+		AlsoSendToChannel: message.AlsoSendToChannel,
+		Text:              message.Text,
+		CreatedAt:         message.CreatedAt,
 	}).Error; err != nil {
 		return err
 	}
@@ -120,21 +203,167 @@ func (mr *messageRepository) Update(ctx context.Context, message entity.Message)
 	}
 
 	if err := executor.WithContext(ctx).Model(&messageModel{}).Where("id = ?", message.ID).Updates(&messageModel{
-		Text: message.Text,
+		Text:     message.Text,
+		EditedAt: message.EditedAt,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RefreshReplySummary はスレッドの返信数と最終返信日時を、削除されていない返信から再計算する
+func (mr *messageRepository) RefreshReplySummary(ctx context.Context, parentID string) error {
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var summary struct {
+		ReplyCount  int
+		LastReplyAt *time.Time
+	}
+	if err := executor.WithContext(ctx).Model(&messageModel{}).
+		Select("COUNT(*) AS reply_count, MAX(created_at) AS last_reply_at").
+		Where("parent_id = ? AND deleted_at IS NULL", parentID).
+		Scan(&summary).Error; err != nil {
+		return err
+	}
+
+	if err := executor.WithContext(ctx).Model(&messageModel{}).Where("id = ?", parentID).Updates(map[string]interface{}{
+		"reply_count":   summary.ReplyCount,
+		"last_reply_at": summary.LastReplyAt,
 	}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// CountUnread はChannelでカーソルより新しい、他のユーザが投稿したメッセージの数と、そのうちユーザがメンションされている数を返す
+func (mr *messageRepository) CountUnread(ctx context.Context, channelID, userID string, after *repository.MessageCursor) (*entity.UnreadCount, error) {
+	executor := mr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	db := executor.WithContext(ctx).Model(&messageModel{}).
+		Where("channel_id = ? AND user_id <> ? AND deleted_at IS NULL", channelID, userID).
+		Where("(parent_id IS NULL OR also_send_to_channel = ?)", true)
+	if after != nil {
+		db = db.Where(
+			"(created_at > ? OR (created_at = ? AND id > ?))",
+			after.CreatedAt, after.CreatedAt, after.ID,
+		)
+	}
+
+	var result struct {
+		Unread   int
+		Mentions int
+	}
+	if err := db.Select(
+		"COUNT(*) AS unread, "+
+			"COALESCE(SUM(EXISTS (SELECT 1 FROM Mentions WHERE Mentions.message_id = Messages.id AND Mentions.user_id = ?)), 0) AS mentions",
+		userID,
+	).Scan(&result).Error; err != nil {
+		return nil, err
+	}
+	return &entity.UnreadCount{
+		ChannelID:    channelID,
+		UnreadCount:  result.Unread,
+		MentionCount: result.Mentions,
+	}, nil
+}
+
+// Delete はメッセージを論理削除する。スレッドや監査の為に行自体は残す
 func (mr *messageRepository) Delete(ctx context.Context, id string) error {
 	executor := mr.db
 	if tx := TxFromCtx(ctx); tx != nil {
 		executor = tx
 	}
 
-	if err := executor.WithContext(ctx).Delete(&messageModel{}, "id = ?", id).Error; err != nil {
+	if err := executor.WithContext(ctx).Model(&messageModel{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now()).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+// paginate はカーソルと件数の条件を付与する
+// Afterのみ指定された場合はカーソルの直後から、それ以外はカーソルの直前(最新)から取得する為、取得順を合わせて返す
+func paginate(db *gorm.DB, query repository.ListMessagesQuery) (*gorm.DB, bool) {
+	if query.Before != nil {
+		db = db.Where(
+			"(created_at < ? OR (created_at = ? AND id < ?))",
+			query.Before.CreatedAt, query.Before.CreatedAt, query.Before.ID,
+		)
+	}
+	if query.After != nil {
+		db = db.Where(
+			"(created_at > ? OR (created_at = ? AND id > ?))",
+			query.After.CreatedAt, query.After.CreatedAt, query.After.ID,
+		)
+	}
+
+	ascending := query.After != nil && query.Before == nil
+	if ascending {
+		db = db.Order("created_at ASC, id ASC")
+	} else {
+		db = db.Order("created_at DESC, id DESC")
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	return db, ascending
+}
+
+// toMessage は保存済みの行からメッセージを組み立てる
+// 本文の検証は書き込み時のみ行い、制限の導入前に保存された行などでも一覧の取得が失敗しないようにする
+func toMessage(mm messageModel, action string) *entity.Message {
+	msg := &entity.Message{
+		ID:                mm.ID,
+		UserID:            mm.UserID,
+		WorkspaceID:       mm.WorkspaceID,
+		Text:              mm.Text,
+		CreatedAt:         mm.CreatedAt,
+		Action:            action,
+		TargetID:          mm.ChannelID,
+		AlsoSendToChannel: mm.AlsoSendToChannel,
+		ReplyCount:        mm.ReplyCount,
+		LastReplyAt:       mm.LastReplyAt,
+		EditedAt:          mm.EditedAt,
+		DeletedAt:         mm.DeletedAt,
+	}
+	if mm.ParentID != nil {
+		msg.ParentID = *mm.ParentID
+	}
+	return msg
+}
+
+// toMessages は取得したメッセージを古い順に並べ替えて返す
+func toMessages(mms []messageModel, ascending bool, action, targetID, parentID string) (*entity.Messages, error) {
+	msgs := make([]*entity.Message, len(mms))
+	for i, mm := range mms {
+		idx := i
+		if !ascending {
+			idx = len(mms) - 1 - i
+		}
+		msgs[idx] = toMessage(mm, entity.NoneAction)
+	}
+
+	// 該当するメッセージが無い場合も空の一覧として返す
+	if len(msgs) == 0 {
+		return &entity.Messages{
+			Messages: msgs,
+			Action:   action,
+			TargetID: targetID,
+			ParentID: parentID,
+		}, nil
+	}
+
+	messages, err := entity.NewMessages(msgs, action, targetID)
+	if err != nil {
+		return nil, err
+	}
+	messages.ParentID = parentID
+	return messages, nil
+}
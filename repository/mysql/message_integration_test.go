@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
 )
 
 func Test_MessageRepository(t *testing.T) {
@@ -57,12 +58,89 @@ func Test_MessageRepository(t *testing.T) {
 	}
 
 	// List
-	msgs, err := repo.List(ctx, channelID)
+	msgs, err := repo.List(ctx, channelID, repository.ListMessagesQuery{})
 	ValidateErr(t, err, nil)
 	if len(msgs.Messages) != 2 {
 		t.Errorf("len(msgs.Messages) got: %d, want: 2", len(msgs.Messages))
 	}
 
+	// List with cursor
+	latest := msgs.Messages[1]
+	msgs, err = repo.List(ctx, channelID, repository.ListMessagesQuery{
+		Before: &repository.MessageCursor{CreatedAt: latest.CreatedAt, ID: latest.ID},
+		Limit:  10,
+	})
+	ValidateErr(t, err, nil)
+	if len(msgs.Messages) != 1 {
+		t.Errorf("len(msgs.Messages) got: %d, want: 1", len(msgs.Messages))
+	}
+	msgs, err = repo.List(ctx, channelID, repository.ListMessagesQuery{
+		After: &repository.MessageCursor{CreatedAt: latest.CreatedAt, ID: latest.ID},
+		Limit: 10,
+	})
+	ValidateErr(t, err, nil)
+	if len(msgs.Messages) != 0 {
+		t.Errorf("len(msgs.Messages) got: %d, want: 0", len(msgs.Messages))
+	}
+
+	// Reply
+	reply, err := entity.NewReplyMessage(
+		uuid.New().String(),
+		userID,
+		workspaceID,
+		"Reply",
+		channelID,
+		msg1.ID,
+		false,
+		time.Time{},
+	)
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, *reply)
+	ValidateErr(t, err, nil)
+	err = repo.RefreshReplySummary(ctx, msg1.ID)
+	ValidateErr(t, err, nil)
+
+	gotMsg, err = repo.Get(ctx, msg1.ID)
+	ValidateErr(t, err, nil)
+	if gotMsg.ReplyCount != 1 {
+		t.Errorf("ReplyCount got: %d, want: 1", gotMsg.ReplyCount)
+	}
+	if gotMsg.LastReplyAt == nil {
+		t.Error("LastReplyAt should be set")
+	}
+
+	replies, err := repo.ListReplies(ctx, msg1.ID, repository.ListMessagesQuery{})
+	ValidateErr(t, err, nil)
+	if len(replies.Messages) != 1 || replies.Messages[0].ParentID != msg1.ID {
+		t.Errorf("unexpected replies: %+v", replies.Messages)
+	}
+
+	// 返信はChannelのメッセージ一覧に含まれない
+	msgs, err = repo.List(ctx, channelID, repository.ListMessagesQuery{})
+	ValidateErr(t, err, nil)
+	if len(msgs.Messages) != 2 {
+		t.Errorf("len(msgs.Messages) got: %d, want: 2", len(msgs.Messages))
+	}
+
+	// CountUnread: 自分が投稿したメッセージは未読に含めない
+	unread, err := repo.CountUnread(ctx, channelID, userID, nil)
+	ValidateErr(t, err, nil)
+	if unread.UnreadCount != 0 {
+		t.Errorf("CountUnread() got: %d, want: 0", unread.UnreadCount)
+	}
+	unread, err = repo.CountUnread(ctx, channelID, uuid.New().String(), nil)
+	ValidateErr(t, err, nil)
+	if unread.UnreadCount != 2 || unread.MentionCount != 0 {
+		t.Errorf("CountUnread() got: %+v, want: 2 unread", unread)
+	}
+
+	// ListByIDs
+	listed, err := repo.ListByIDs(ctx, []string{msg1.ID, msg2.ID, uuid.New().String()})
+	ValidateErr(t, err, nil)
+	if len(listed) != 2 {
+		t.Errorf("ListByIDs() got: %d messages, want: 2", len(listed))
+	}
+
 	// Update
 	msg1.Text = "Hello, World! Updated"
 	err = repo.Update(ctx, *msg1)
@@ -74,12 +152,87 @@ func Test_MessageRepository(t *testing.T) {
 		t.Errorf("differs: (-want +got)\n%s", d)
 	}
 
-	// Delete
+	// Update: 編集日時
+	editedAt := time.Now().Truncate(time.Second)
+	msg1.EditedAt = &editedAt
+	err = repo.Update(ctx, *msg1)
+	ValidateErr(t, err, nil)
+
+	gotMsg, err = repo.Get(ctx, msg1.ID)
+	ValidateErr(t, err, nil)
+	if gotMsg.EditedAt == nil {
+		t.Error("EditedAt got: nil, want: not nil")
+	}
+
+	// Delete: 論理削除の為、行は残りdeleted_atが設定される
 	err = repo.Delete(ctx, msg1.ID)
 	ValidateErr(t, err, nil)
 
-	_, err = repo.Get(ctx, msg1.ID)
-	if err == nil {
-		t.Error("want error, but got nil")
+	gotMsg, err = repo.Get(ctx, msg1.ID)
+	ValidateErr(t, err, nil)
+	if !gotMsg.IsDeleted() {
+		t.Error("IsDeleted() got: false, want: true")
+	}
+
+	// 書き込み時の検証を満たさない保存済みの行があっても一覧を取得できる
+	legacyID := uuid.New().String()
+	err = db.Exec(
+		"INSERT INTO Messages (id, user_id, workspace_id, channel_id, text) VALUES (?, ?, ?, ?, ?)",
+		legacyID, userID, workspaceID, channelID, "",
+	).Error
+	ValidateErr(t, err, nil)
+
+	msgs, err = repo.List(ctx, channelID, repository.ListMessagesQuery{})
+	ValidateErr(t, err, nil)
+	if len(msgs.Messages) != 3 {
+		t.Errorf("len(msgs.Messages) got: %d, want: 3", len(msgs.Messages))
+	}
+}
+
+func Test_MessageRepository_Search(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewMessageRepository(db)
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	otherChannelID := uuid.New().String()
+
+	for _, m := range []struct {
+		channelID string
+		text      string
+	}{
+		{channelID, "本番環境にデプロイしました"},
+		{channelID, "deploy the release tomorrow"},
+		{otherChannelID, "本番環境のデプロイは明日です"},
+	} {
+		message, err := entity.NewMessage("", userID, workspaceID, m.text, entity.CreateMessageAction, m.channelID, time.Time{})
+		ValidateErr(t, err, nil)
+		err = repo.Create(ctx, *message)
+		ValidateErr(t, err, nil)
+	}
+
+	// 閲覧可能なChannelのメッセージのみを検索する
+	got, err := repo.Search(ctx, repository.SearchMessagesQuery{
+		Terms:      []string{"デプロイ", "本番"},
+		ChannelIDs: []string{channelID},
+		Limit:      10,
+	})
+	ValidateErr(t, err, nil)
+	if len(got) != 1 || got[0].Text != "本番環境にデプロイしました" {
+		t.Errorf("Search() got: %v", got)
+	}
+
+	// 投稿者で絞り込む
+	got, err = repo.Search(ctx, repository.SearchMessagesQuery{
+		Terms:      []string{"deploy"},
+		ChannelIDs: []string{channelID, otherChannelID},
+		UserID:     uuid.New().String(),
+		Limit:      10,
+	})
+	ValidateErr(t, err, nil)
+	if len(got) != 0 {
+		t.Errorf("Search() got: %v, want: empty", got)
 	}
 }
@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type messageRevisionModel struct {
+	ID        string    `gorm:"type:char(36);primaryKey"`
+	MessageID string    `gorm:"column:message_id"`
+	UserID    string    `gorm:"column:user_id"`
+	Text      string    `gorm:"column:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (messageRevisionModel) TableName() string {
+	return "MessageRevisions"
+}
+
+type messageRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageRevisionRepository(db *gorm.DB) repository.MessageRevisionRepository {
+	return &messageRevisionRepository{
+		db: db,
+	}
+}
+
+// ListByMessageID はメッセージの編集履歴を古い順に返す
+func (mrr *messageRevisionRepository) ListByMessageID(ctx context.Context, messageID string) ([]entity.MessageRevision, error) {
+	executor := mrr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var mrms []messageRevisionModel
+	if err := executor.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("created_at ASC, id ASC").
+		Find(&mrms).Error; err != nil {
+		return nil, err
+	}
+
+	revisions := make([]entity.MessageRevision, len(mrms))
+	for i, mrm := range mrms {
+		revision, err := entity.NewMessageRevision(mrm.ID, mrm.MessageID, mrm.UserID, mrm.Text, mrm.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions[i] = *revision
+	}
+	return revisions, nil
+}
+
+func (mrr *messageRevisionRepository) Create(ctx context.Context, revision entity.MessageRevision) error {
+	executor := mrr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Create(&messageRevisionModel{
+		ID:        revision.ID,
+		MessageID: revision.MessageID,
+		UserID:    revision.UserID,
+		Text:      revision.Text,
+		CreatedAt: revision.CreatedAt,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
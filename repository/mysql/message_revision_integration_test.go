@@ -0,0 +1,39 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func Test_MessageRevisionRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewMessageRevisionRepository(db)
+	messageRepo := NewMessageRepository(db)
+
+	userID := uuid.New().String()
+
+	message, err := entity.NewMessage("", userID, uuid.New().String(), "Hello, World!", entity.CreateMessageAction, uuid.New().String(), time.Time{})
+	ValidateErr(t, err, nil)
+	err = messageRepo.Create(ctx, *message)
+	ValidateErr(t, err, nil)
+
+	revision, err := entity.NewMessageRevision("", message.ID, userID, message.Text, time.Time{})
+	ValidateErr(t, err, nil)
+
+	// Create
+	err = repo.Create(ctx, *revision)
+	ValidateErr(t, err, nil)
+
+	// ListByMessageID
+	revisions, err := repo.ListByMessageID(ctx, message.ID)
+	ValidateErr(t, err, nil)
+	if len(revisions) != 1 || revisions[0].ID != revision.ID || revisions[0].Text != "Hello, World!" {
+		t.Errorf("ListByMessageID() got: %v, want: [%v]", revisions, revision)
+	}
+}
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type reactionModel struct {
+	MessageID string    `gorm:"column:message_id"`
+	UserID    string    `gorm:"column:user_id"`
+	Emoji     string    `gorm:"column:emoji"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (reactionModel) TableName() string {
+	return "Reactions"
+}
+
+type reactionRepository struct {
+	db *gorm.DB
+}
+
+func NewReactionRepository(db *gorm.DB) repository.ReactionRepository {
+	return &reactionRepository{
+		db: db,
+	}
+}
+
+// ListByMessageIDs はメッセージに付けられたリアクションを作成日時の昇順で返す
+func (rr *reactionRepository) ListByMessageIDs(ctx context.Context, messageIDs []string) ([]entity.Reaction, error) {
+	if len(messageIDs) == 0 {
+		return []entity.Reaction{}, nil
+	}
+
+	executor := rr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var rms []reactionModel
+	if err := executor.WithContext(ctx).
+		Where("message_id IN ?", messageIDs).
+		Order("created_at ASC").
+		Find(&rms).Error; err != nil {
+		return nil, err
+	}
+
+	reactions := make([]entity.Reaction, len(rms))
+	for i, rm := range rms {
+		reaction, err := entity.NewReaction(rm.MessageID, rm.UserID, rm.Emoji, rm.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		reactions[i] = *reaction
+	}
+	return reactions, nil
+}
+
+// Create はリアクションを保存する。既に同じリアクションが存在する場合は何もしない
+func (rr *reactionRepository) Create(ctx context.Context, reaction entity.Reaction) error {
+	executor := rr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&reactionModel{
+		MessageID: reaction.MessageID,
+		UserID:    reaction.UserID,
+		Emoji:     reaction.Emoji,
+		CreatedAt: reaction.CreatedAt,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *reactionRepository) Delete(ctx context.Context, messageID, userID, emoji string) error {
+	executor := rr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Delete(
+		&reactionModel{}, "message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji,
+	).Error; err != nil {
+		return err
+	}
+	return nil
+}
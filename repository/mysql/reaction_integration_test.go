@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func Test_ReactionRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewReactionRepository(db)
+	messageRepo := NewMessageRepository(db)
+
+	userID := uuid.New().String()
+
+	message, err := entity.NewMessage("", userID, uuid.New().String(), "Hello, World!", entity.CreateMessageAction, uuid.New().String(), time.Time{})
+	ValidateErr(t, err, nil)
+	err = messageRepo.Create(ctx, *message)
+	ValidateErr(t, err, nil)
+
+	reaction, err := entity.NewReaction(message.ID, userID, "👍", time.Time{})
+	ValidateErr(t, err, nil)
+
+	// Create
+	err = repo.Create(ctx, *reaction)
+	ValidateErr(t, err, nil)
+
+	// Create: 同じリアクションは重複して保存されない
+	err = repo.Create(ctx, *reaction)
+	ValidateErr(t, err, nil)
+
+	// ListByMessageIDs
+	reactions, err := repo.ListByMessageIDs(ctx, []string{message.ID})
+	ValidateErr(t, err, nil)
+	if len(reactions) != 1 || reactions[0].Emoji != reaction.Emoji || reactions[0].UserID != userID {
+		t.Errorf("ListByMessageIDs() got: %v, want: [%v]", reactions, reaction)
+	}
+
+	// Delete
+	err = repo.Delete(ctx, message.ID, userID, reaction.Emoji)
+	ValidateErr(t, err, nil)
+
+	reactions, err = repo.ListByMessageIDs(ctx, []string{message.ID})
+	ValidateErr(t, err, nil)
+	if len(reactions) != 0 {
+		t.Errorf("len(reactions) got: %d, want: 0", len(reactions))
+	}
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type readReceiptModel struct {
+	UserID      string    `gorm:"column:user_id;primaryKey"`
+	WorkspaceID string    `gorm:"column:workspace_id"`
+	ChannelID   string    `gorm:"column:channel_id;primaryKey"`
+	MessageID   string    `gorm:"column:message_id"`
+	ReadAt      time.Time `gorm:"column:read_at"`
+}
+
+func (readReceiptModel) TableName() string {
+	return "ReadReceipts"
+}
+
+type readReceiptRepository struct {
+	db *gorm.DB
+}
+
+func NewReadReceiptRepository(db *gorm.DB) repository.ReadReceiptRepository {
+	return &readReceiptRepository{
+		db: db,
+	}
+}
+
+func (rrr *readReceiptRepository) ListByUserID(ctx context.Context, userID, workspaceID string) ([]entity.ReadReceipt, error) {
+	executor := rrr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var rrms []readReceiptModel
+	if err := executor.WithContext(ctx).Find(&rrms, "user_id = ? AND workspace_id = ?", userID, workspaceID).Error; err != nil {
+		return nil, err
+	}
+
+	receipts := make([]entity.ReadReceipt, len(rrms))
+	for i, rrm := range rrms {
+		receipt, err := entity.NewReadReceipt(rrm.UserID, rrm.WorkspaceID, rrm.ChannelID, rrm.MessageID, rrm.ReadAt)
+		if err != nil {
+			return nil, err
+		}
+		receipts[i] = *receipt
+	}
+	return receipts, nil
+}
+
+// Upsert はChannelの既読位置を保存する。既に保存されている場合は上書きする
+func (rrr *readReceiptRepository) Upsert(ctx context.Context, receipt entity.ReadReceipt) error {
+	executor := rrr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&readReceiptModel{
+		UserID:      receipt.UserID,
+		WorkspaceID: receipt.WorkspaceID,
+		ChannelID:   receipt.ChannelID,
+		MessageID:   receipt.MessageID,
+		ReadAt:      receipt.ReadAt,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
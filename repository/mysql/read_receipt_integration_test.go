@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func Test_ReadReceiptRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewReadReceiptRepository(db)
+	channelRepo := NewChannelRepository(db)
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+
+	channel, err := entity.NewChannel("", workspaceID, "general", false)
+	ValidateErr(t, err, nil)
+	err = channelRepo.Create(ctx, *channel)
+	ValidateErr(t, err, nil)
+	channelID := channel.ID
+
+	receipt, err := entity.NewReadReceipt(userID, workspaceID, channelID, uuid.New().String(), time.Now().Truncate(time.Second))
+	ValidateErr(t, err, nil)
+
+	// Upsert
+	err = repo.Upsert(ctx, *receipt)
+	ValidateErr(t, err, nil)
+
+	// Upsert: 既読位置を更新する
+	updated, err := entity.NewReadReceipt(userID, workspaceID, channelID, uuid.New().String(), receipt.ReadAt.Add(time.Second))
+	ValidateErr(t, err, nil)
+	err = repo.Upsert(ctx, *updated)
+	ValidateErr(t, err, nil)
+
+	// ListByUserID
+	receipts, err := repo.ListByUserID(ctx, userID, workspaceID)
+	ValidateErr(t, err, nil)
+	if len(receipts) != 1 || receipts[0].MessageID != updated.MessageID {
+		t.Errorf("ListByUserID() got: %v, want: [%v]", receipts, updated)
+	}
+}
@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type recoveryCodeModel struct {
+	UserID   string     `gorm:"column:user_id;primaryKey"`
+	CodeHash string     `gorm:"column:code_hash;primaryKey"`
+	UsedAt   *time.Time `gorm:"column:used_at"`
+}
+
+func (recoveryCodeModel) TableName() string {
+	return "RecoveryCodes"
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) repository.RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		db: db,
+	}
+}
+
+func (rcr *recoveryCodeRepository) Replace(ctx context.Context, userID string, codes []entity.RecoveryCode) error {
+	executor := rcr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Delete(&recoveryCodeModel{}, "user_id = ?", userID).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	rcms := make([]recoveryCodeModel, len(codes))
+	for i, code := range codes {
+		rcms[i] = recoveryCodeModel{
+			UserID:   userID,
+			CodeHash: code.CodeHash,
+		}
+	}
+	return executor.WithContext(ctx).Create(&rcms).Error
+}
+
+func (rcr *recoveryCodeRepository) Consume(ctx context.Context, userID, codeHash string) error {
+	executor := rcr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	// 同じコードが同時に使われても一方だけが成功するよう、未使用の場合のみ更新する
+	result := executor.WithContext(ctx).Model(&recoveryCodeModel{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now().UTC().Truncate(time.Second))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+func (rcr *recoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	executor := rcr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	return executor.WithContext(ctx).Delete(&recoveryCodeModel{}, "user_id = ?", userID).Error
+}
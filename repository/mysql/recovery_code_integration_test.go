@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+func Test_RecoveryCodeRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewRecoveryCodeRepository(db)
+	userRepo := NewUserRepository(db)
+
+	user, err := entity.NewUser("", uuid.New().String()+"@example.com", "password")
+	ValidateErr(t, err, nil)
+	err = userRepo.Create(ctx, *user)
+	ValidateErr(t, err, nil)
+
+	// Replace
+	codes, err := entity.NewRecoveryCodes(user.ID)
+	ValidateErr(t, err, nil)
+	err = repo.Replace(ctx, user.ID, codes)
+	ValidateErr(t, err, nil)
+
+	// Consume
+	err = repo.Consume(ctx, user.ID, codes[0].CodeHash)
+	ValidateErr(t, err, nil)
+
+	// Consume: 使用済みのコードは使えない
+	err = repo.Consume(ctx, user.ID, codes[0].CodeHash)
+	ValidateErr(t, err, repository.ErrRecoveryCodeNotFound)
+
+	// Replace: 再発行すると以前のコードは使えない
+	newCodes, err := entity.NewRecoveryCodes(user.ID)
+	ValidateErr(t, err, nil)
+	err = repo.Replace(ctx, user.ID, newCodes)
+	ValidateErr(t, err, nil)
+
+	err = repo.Consume(ctx, user.ID, codes[1].CodeHash)
+	ValidateErr(t, err, repository.ErrRecoveryCodeNotFound)
+
+	// DeleteByUserID
+	err = repo.DeleteByUserID(ctx, user.ID)
+	ValidateErr(t, err, nil)
+
+	err = repo.Consume(ctx, user.ID, newCodes[0].CodeHash)
+	ValidateErr(t, err, repository.ErrRecoveryCodeNotFound)
+}
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -11,15 +12,33 @@ import (
 )
 
 type userModel struct {
-	ID       string `gorm:"type:char(36);primaryKey"`
-	Email    string `gorm:"column:email"`
-	Password string `gorm:"column:password"`
+	ID              string     `gorm:"type:char(36);primaryKey"`
+	Email           string     `gorm:"column:email"`
+	Password        string     `gorm:"column:password"`
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at"`
+	TOTPSecret      *string    `gorm:"column:totp_secret"`
+	TOTPEnabledAt   *time.Time `gorm:"column:totp_enabled_at"`
+	// TOTPLastUsedStep は ConsumeTOTPStep でのみ更新する
+	TOTPLastUsedStep int64 `gorm:"column:totp_last_used_step"`
 }
 
 func (userModel) TableName() string {
 	return "Users"
 }
 
+func (um userModel) toEntity() (*entity.User, error) {
+	user, err := entity.NewUser(um.ID, um.Email, um.Password)
+	if err != nil {
+		return nil, err
+	}
+	user.EmailVerifiedAt = um.EmailVerifiedAt
+	if um.TOTPSecret != nil {
+		user.TOTPSecret = *um.TOTPSecret
+	}
+	user.TOTPEnabledAt = um.TOTPEnabledAt
+	return user, nil
+}
+
 type userRepository struct {
 	db *gorm.DB
 }
@@ -37,15 +56,13 @@ func (ur *userRepository) Get(ctx context.Context, id string) (*entity.User, err
 	}
 
 	var um userModel
-	if err := executor.WithContext(ctx).First(&um, "id = ?", id).Error; err != nil {
+	if err := executor.WithContext(ctx).First(&um, "id = ?", id).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrUserNotFound
+	} else if err != nil {
 		return nil, err
 	}
 
-	user, err := entity.NewUser(um.ID, um.Email, um.Password)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	return um.toEntity()
 }
 
 func (ur *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
@@ -55,15 +72,13 @@ func (ur *userRepository) GetByEmail(ctx context.Context, email string) (*entity
 	}
 
 	var um userModel
-	if err := executor.WithContext(ctx).First(&um, "email = ?", email).Error; err != nil {
+	if err := executor.WithContext(ctx).First(&um, "email = ?", email).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrUserNotFound
+	} else if err != nil {
 		return nil, err
 	}
 
-	user, err := entity.NewUser(um.ID, um.Email, um.Password)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	return um.toEntity()
 }
 
 func (ur *userRepository) Create(ctx context.Context, user entity.User) error {
@@ -73,9 +88,12 @@ func (ur *userRepository) Create(ctx context.Context, user entity.User) error {
 	}
 
 	if err := executor.WithContext(ctx).Create(&userModel{
-		ID:       user.ID,
-		Email:    user.Email,
-		Password: user.Password,
+		ID:              user.ID,
+		Email:           user.Email,
+		Password:        user.Password,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		TOTPSecret:      nullableString(user.TOTPSecret),
+		TOTPEnabledAt:   user.TOTPEnabledAt,
 	}).Error; err != nil {
 		return err
 	}
@@ -88,9 +106,15 @@ func (ur *userRepository) Update(ctx context.Context, user entity.User) error {
 		executor = tx
 	}
 
-	if err := executor.WithContext(ctx).Model(&userModel{}).Where("id = ?", user.ID).Updates(&userModel{
-		Password: user.Password,
-	}).Error; err != nil {
+	// 二要素認証の無効化でNULLに戻せるよう、ゼロ値も更新対象にする
+	if err := executor.WithContext(ctx).Model(&userModel{}).Where("id = ?", user.ID).
+		Select("password", "email_verified_at", "totp_secret", "totp_enabled_at").
+		Updates(&userModel{
+			Password:        user.Password,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			TOTPSecret:      nullableString(user.TOTPSecret),
+			TOTPEnabledAt:   user.TOTPEnabledAt,
+		}).Error; err != nil {
 		return err
 	}
 	return nil
@@ -123,3 +147,29 @@ func (ur *userRepository) LockByEmail(ctx context.Context, email string) (bool,
 
 	return true, nil
 }
+
+func (ur *userRepository) ConsumeTOTPStep(ctx context.Context, id string, step int64) error {
+	executor := ur.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	// 同じコードが同時に使われても一方だけが成功するよう、記録済みのステップより後の場合のみ更新する
+	result := executor.WithContext(ctx).Model(&userModel{}).
+		Where("id = ? AND totp_last_used_step < ?", id, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrTOTPStepAlreadyUsed
+	}
+	return nil
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,69 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type userIdentityModel struct {
+	Issuer    string    `gorm:"column:issuer;primaryKey"`
+	Subject   string    `gorm:"column:subject;primaryKey"`
+	UserID    string    `gorm:"column:user_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (userIdentityModel) TableName() string {
+	return "UserIdentities"
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) repository.UserIdentityRepository {
+	return &userIdentityRepository{
+		db: db,
+	}
+}
+
+func (uir *userIdentityRepository) Get(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error) {
+	executor := uir.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var uim userIdentityModel
+	if err := executor.WithContext(ctx).First(&uim, "issuer = ? AND subject = ?", issuer, subject).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrUserIdentityNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &entity.UserIdentity{
+		Issuer:    uim.Issuer,
+		Subject:   uim.Subject,
+		UserID:    uim.UserID,
+		CreatedAt: uim.CreatedAt,
+	}, nil
+}
+
+func (uir *userIdentityRepository) Create(ctx context.Context, identity entity.UserIdentity) error {
+	executor := uir.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	uim := userIdentityModel{
+		Issuer:    identity.Issuer,
+		Subject:   identity.Subject,
+		UserID:    identity.UserID,
+		CreatedAt: identity.CreatedAt,
+	}
+	return executor.WithContext(ctx).Create(&uim).Error
+}
@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+func Test_UserIdentityRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewUserIdentityRepository(db)
+	userRepo := NewUserRepository(db)
+
+	user, err := entity.NewUser("", uuid.New().String()+"@example.com", "password")
+	ValidateErr(t, err, nil)
+	err = userRepo.Create(ctx, *user)
+	ValidateErr(t, err, nil)
+
+	// Get: 紐付けがない場合
+	_, err = repo.Get(ctx, "https://sso.example.com", "248289761001")
+	ValidateErr(t, err, repository.ErrUserIdentityNotFound)
+
+	// Create
+	identity, err := entity.NewUserIdentity("https://sso.example.com", "248289761001", user.ID)
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, *identity)
+	ValidateErr(t, err, nil)
+
+	// Get
+	got, err := repo.Get(ctx, "https://sso.example.com", "248289761001")
+	ValidateErr(t, err, nil)
+	if got.UserID != user.ID {
+		t.Errorf("Get() = %+v, want userID %s", got, user.ID)
+	}
+
+	// 同じSubjectでもIssuerが異なる場合は別のアカウント
+	_, err = repo.Get(ctx, "https://other.example.com", "248289761001")
+	ValidateErr(t, err, repository.ErrUserIdentityNotFound)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
 )
 
 func Test_UserRepository(t *testing.T) {
@@ -57,12 +59,56 @@ func Test_UserRepository(t *testing.T) {
 		t.Errorf("want: %v, got: %v", gotUser, updatedUser)
 	}
 
+	// Update: メールアドレスの確認
+	updatedUser.VerifyEmail(time.Now())
+	err = repo.Update(ctx, *updatedUser)
+	ValidateErr(t, err, nil)
+
+	verifiedUser, err := repo.GetByEmail(ctx, "test@gmail.com")
+	ValidateErr(t, err, nil)
+	if !verifiedUser.IsEmailVerified() || !verifiedUser.EmailVerifiedAt.Equal(*updatedUser.EmailVerifiedAt) {
+		t.Errorf("want: %v, got: %v", updatedUser.EmailVerifiedAt, verifiedUser.EmailVerifiedAt)
+	}
+
+	// Update: 二要素認証の有効化
+	enrollment, err := entity.NewTwoFactorEnrollment("go-chat-app", verifiedUser.Email)
+	ValidateErr(t, err, nil)
+	verifiedUser.EnrollTwoFactor(enrollment.Secret)
+	verifiedUser.EnableTwoFactor(time.Now())
+	err = repo.Update(ctx, *verifiedUser)
+	ValidateErr(t, err, nil)
+
+	twoFactorUser, err := repo.Get(ctx, user.ID)
+	ValidateErr(t, err, nil)
+	if !twoFactorUser.IsTwoFactorEnabled() || twoFactorUser.TOTPSecret != enrollment.Secret {
+		t.Errorf("want: %v, got: %v", verifiedUser, twoFactorUser)
+	}
+
+	// ConsumeTOTPStep: 同じステップ以前のコードは再利用できない
+	err = repo.ConsumeTOTPStep(ctx, user.ID, 100)
+	ValidateErr(t, err, nil)
+	err = repo.ConsumeTOTPStep(ctx, user.ID, 100)
+	ValidateErr(t, err, repository.ErrTOTPStepAlreadyUsed)
+	err = repo.ConsumeTOTPStep(ctx, user.ID, 99)
+	ValidateErr(t, err, repository.ErrTOTPStepAlreadyUsed)
+	err = repo.ConsumeTOTPStep(ctx, user.ID, 101)
+	ValidateErr(t, err, nil)
+
+	// Update: 二要素認証の無効化
+	twoFactorUser.DisableTwoFactor()
+	err = repo.Update(ctx, *twoFactorUser)
+	ValidateErr(t, err, nil)
+
+	disabledUser, err := repo.Get(ctx, user.ID)
+	ValidateErr(t, err, nil)
+	if disabledUser.IsTwoFactorEnabled() || disabledUser.TOTPSecret != "" || disabledUser.TOTPEnabledAt != nil {
+		t.Errorf("want: two factor disabled, got: %v", disabledUser)
+	}
+
 	// Delete
 	err = repo.Delete(ctx, user.ID)
 	ValidateErr(t, err, nil)
 
 	_, err = repo.Get(ctx, user.ID)
-	if err == nil {
-		t.Errorf("want: %v, got: %v", nil, err)
-	}
+	ValidateErr(t, err, repository.ErrUserNotFound)
 }
@@ -0,0 +1,130 @@
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type workspaceModel struct {
+	ID   string `gorm:"type:char(36);primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (workspaceModel) TableName() string {
+	return "Workspaces"
+}
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) repository.WorkspaceRepository {
+	return &workspaceRepository{
+		db: db,
+	}
+}
+
+func (wr *workspaceRepository) List(ctx context.Context) ([]entity.Workspace, error) {
+	executor := wr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var wms []workspaceModel
+	if err := executor.WithContext(ctx).Order("name").Find(&wms).Error; err != nil {
+		return nil, err
+	}
+	return toWorkspaces(wms)
+}
+
+func (wr *workspaceRepository) ListByUserID(ctx context.Context, userID string) ([]entity.Workspace, error) {
+	executor := wr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var wms []workspaceModel
+	if err := executor.WithContext(ctx).
+		Joins("JOIN Memberships ON Memberships.workspace_id = Workspaces.id").
+		Where("Memberships.user_id = ?", userID).
+		Order("Workspaces.name").
+		Find(&wms).Error; err != nil {
+		return nil, err
+	}
+	return toWorkspaces(wms)
+}
+
+func toWorkspaces(wms []workspaceModel) ([]entity.Workspace, error) {
+	workspaces := make([]entity.Workspace, len(wms))
+	for i, wm := range wms {
+		workspace, err := entity.NewWorkspace(wm.ID, wm.Name)
+		if err != nil {
+			return nil, err
+		}
+		workspaces[i] = *workspace
+	}
+	return workspaces, nil
+}
+
+func (wr *workspaceRepository) Get(ctx context.Context, id string) (*entity.Workspace, error) {
+	executor := wr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var wm workspaceModel
+	if err := executor.WithContext(ctx).First(&wm, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	workspace, err := entity.NewWorkspace(wm.ID, wm.Name)
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (wr *workspaceRepository) Create(ctx context.Context, workspace entity.Workspace) error {
+	executor := wr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Create(&workspaceModel{
+		ID:   workspace.ID,
+		Name: workspace.Name,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *workspaceRepository) Update(ctx context.Context, workspace entity.Workspace) error {
+	executor := wr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Model(&workspaceModel{}).Where("id = ?", workspace.ID).Updates(&workspaceModel{
+		Name: workspace.Name,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wr *workspaceRepository) Delete(ctx context.Context, id string) error {
+	executor := wr.db
+	if tx := TxFromCtx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if err := executor.WithContext(ctx).Delete(&workspaceModel{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func Test_WorkspaceRepository(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewWorkspaceRepository(db)
+	membershipRepo := NewMembershipRepository(db)
+
+	userID := uuid.New().String()
+
+	workspace1, err := entity.NewWorkspace("", "workspace1")
+	ValidateErr(t, err, nil)
+	workspace2, err := entity.NewWorkspace("", "workspace2")
+	ValidateErr(t, err, nil)
+
+	// Create
+	err = repo.Create(ctx, *workspace1)
+	ValidateErr(t, err, nil)
+	err = repo.Create(ctx, *workspace2)
+	ValidateErr(t, err, nil)
+
+	// Get
+	gotWorkspace, err := repo.Get(ctx, workspace1.ID)
+	ValidateErr(t, err, nil)
+	if !reflect.DeepEqual(workspace1, gotWorkspace) {
+		t.Errorf("want: %v, got: %v", workspace1, gotWorkspace)
+	}
+
+	// List
+	workspaces, err := repo.List(ctx)
+	ValidateErr(t, err, nil)
+	if len(workspaces) < 2 {
+		t.Errorf("len(workspaces) got: %d, want: >= 2", len(workspaces))
+	}
+
+	// ListByUserID
+	membership, _ := entity.NewMembership(userID, workspace1.ID, "test", "", false)
+	err = membershipRepo.Create(ctx, *membership)
+	ValidateErr(t, err, nil)
+
+	workspaces, err = repo.ListByUserID(ctx, userID)
+	ValidateErr(t, err, nil)
+	if len(workspaces) != 1 || workspaces[0].ID != workspace1.ID {
+		t.Errorf("ListByUserID() got: %v, want: [%v]", workspaces, workspace1)
+	}
+
+	// Update
+	workspace1.Name = "updated"
+	err = repo.Update(ctx, *workspace1)
+	ValidateErr(t, err, nil)
+
+	gotWorkspace, err = repo.Get(ctx, workspace1.ID)
+	ValidateErr(t, err, nil)
+	if gotWorkspace.Name != "updated" {
+		t.Errorf("Expected workspace name 'updated', got %s", gotWorkspace.Name)
+	}
+
+	// Delete
+	err = repo.Delete(ctx, workspace1.ID)
+	ValidateErr(t, err, nil)
+
+	_, err = repo.Get(ctx, workspace1.ID)
+	if err == nil {
+		t.Error("want error, but got nil")
+	}
+}
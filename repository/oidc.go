@@ -0,0 +1,19 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+var ErrOIDCNotConfigured = errors.New("oidc provider is not configured")
+
+// OIDCProvider はOpenID Connectプロバイダとの認可コードフロー(PKCE)を扱う
+type OIDCProvider interface {
+	// AuthCodeURL はユーザをリダイレクトする認可エンドポイントのURLを返す
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange は認可コードをトークンに交換し、IDトークンの署名・クレーム(iss, aud, exp, nonce)を検証して外部アカウントの情報を返す
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*entity.OIDCIdentity, error)
+}
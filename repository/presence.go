@@ -0,0 +1,16 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type PresenceRepository interface {
+	Get(ctx context.Context, workspaceID, userID string) (*entity.Presence, error)
+	List(ctx context.Context, workspaceID string) ([]entity.Presence, error)
+	Heartbeat(ctx context.Context, workspaceID, userID, connectionID, status string, ttl time.Duration) error
+	Remove(ctx context.Context, workspaceID, userID, connectionID string) error
+}
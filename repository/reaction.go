@@ -0,0 +1,14 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type ReactionRepository interface {
+	ListByMessageIDs(ctx context.Context, messageIDs []string) ([]entity.Reaction, error)
+	Create(ctx context.Context, reaction entity.Reaction) error
+	Delete(ctx context.Context, messageID, userID, emoji string) error
+}
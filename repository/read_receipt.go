@@ -0,0 +1,13 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type ReadReceiptRepository interface {
+	ListByUserID(ctx context.Context, userID, workspaceID string) ([]entity.ReadReceipt, error)
+	Upsert(ctx context.Context, receipt entity.ReadReceipt) error
+}
@@ -0,0 +1,19 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+var ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+
+type RecoveryCodeRepository interface {
+	// Replace はユーザのリカバリーコードをすべて削除してから codes を保存する
+	Replace(ctx context.Context, userID string, codes []entity.RecoveryCode) error
+	// Consume は未使用のリカバリーコードを使用済みにする。該当するコードがない場合は ErrRecoveryCodeNotFound を返す
+	Consume(ctx context.Context, userID, codeHash string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
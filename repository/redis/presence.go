@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+// presenceRepository は接続毎の状態をTTL付きのキーで保持する
+// presence:{workspaceID}                         -> 接続中のユーザIDのSET
+// presence:{workspaceID}:{userID}                -> ユーザの接続IDのSET
+// presence:{workspaceID}:{userID}:{connectionID} -> 接続の状態(TTL付き)
+// 期限切れの接続はSETから読み取り時に取り除く
+type presenceRepository struct {
+	client *redis.Client
+}
+
+func NewPresenceRepository(client *redis.Client) repository.PresenceRepository {
+	return &presenceRepository{
+		client,
+	}
+}
+
+func workspacePresenceKey(workspaceID string) string {
+	return "presence:" + workspaceID
+}
+
+func userPresenceKey(workspaceID, userID string) string {
+	return workspacePresenceKey(workspaceID) + ":" + userID
+}
+
+func connectionPresenceKey(workspaceID, userID, connectionID string) string {
+	return userPresenceKey(workspaceID, userID) + ":" + connectionID
+}
+
+func (r *presenceRepository) Get(ctx context.Context, workspaceID, userID string) (*entity.Presence, error) {
+	connectionIDs, err := r.client.SMembers(ctx, userPresenceKey(workspaceID, userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []string
+	var expired []interface{}
+	if len(connectionIDs) > 0 {
+		keys := make([]string, len(connectionIDs))
+		for i, connectionID := range connectionIDs {
+			keys[i] = connectionPresenceKey(workspaceID, userID, connectionID)
+		}
+		values, err := r.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			status, ok := value.(string)
+			if !ok {
+				expired = append(expired, connectionIDs[i])
+				continue
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	if len(expired) > 0 {
+		if err = r.client.SRem(ctx, userPresenceKey(workspaceID, userID), expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(statuses) == 0 {
+		if err = r.client.SRem(ctx, workspacePresenceKey(workspaceID), userID).Err(); err != nil {
+			return nil, err
+		}
+	}
+	return entity.NewPresence(workspaceID, userID, statuses)
+}
+
+func (r *presenceRepository) List(ctx context.Context, workspaceID string) ([]entity.Presence, error) {
+	userIDs, err := r.client.SMembers(ctx, workspacePresenceKey(workspaceID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var presences []entity.Presence
+	for _, userID := range userIDs {
+		presence, err := r.Get(ctx, workspaceID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if presence.Status == entity.PresenceOffline {
+			continue
+		}
+		presences = append(presences, *presence)
+	}
+	return presences, nil
+}
+
+func (r *presenceRepository) Heartbeat(ctx context.Context, workspaceID, userID, connectionID, status string, ttl time.Duration) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, connectionPresenceKey(workspaceID, userID, connectionID), status, ttl)
+		pipe.SAdd(ctx, userPresenceKey(workspaceID, userID), connectionID)
+		pipe.SAdd(ctx, workspacePresenceKey(workspaceID), userID)
+		return nil
+	})
+	return err
+}
+
+func (r *presenceRepository) Remove(ctx context.Context, workspaceID, userID, connectionID string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, connectionPresenceKey(workspaceID, userID, connectionID))
+		pipe.SRem(ctx, userPresenceKey(workspaceID, userID), connectionID)
+		return nil
+	})
+	return err
+}
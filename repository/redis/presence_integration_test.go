@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+func Test_PresenceRepository(t *testing.T) {
+	repo := NewPresenceRepository(client)
+	ctx := context.Background()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	connectionID1 := uuid.New().String()
+	connectionID2 := uuid.New().String()
+
+	// Heartbeat
+	err := repo.Heartbeat(ctx, workspaceID, userID, connectionID1, entity.PresenceAway, time.Minute)
+	ValidateErr(t, err, nil)
+	err = repo.Heartbeat(ctx, workspaceID, userID, connectionID2, entity.PresenceOnline, time.Second)
+	ValidateErr(t, err, nil)
+
+	// Get
+	presence, err := repo.Get(ctx, workspaceID, userID)
+	ValidateErr(t, err, nil)
+	if presence.Status != entity.PresenceOnline || presence.Connections != 2 {
+		t.Errorf("Get() \n got = %+v", presence)
+	}
+
+	// 期限切れの接続は数えない
+	time.Sleep(2 * time.Second)
+	presence, err = repo.Get(ctx, workspaceID, userID)
+	ValidateErr(t, err, nil)
+	if presence.Status != entity.PresenceAway || presence.Connections != 1 {
+		t.Errorf("Get() \n got = %+v", presence)
+	}
+
+	// List
+	presences, err := repo.List(ctx, workspaceID)
+	ValidateErr(t, err, nil)
+	if len(presences) != 1 || presences[0].UserID != userID {
+		t.Errorf("List() \n got = %+v", presences)
+	}
+
+	// Remove
+	err = repo.Remove(ctx, workspaceID, userID, connectionID1)
+	ValidateErr(t, err, nil)
+	presences, err = repo.List(ctx, workspaceID)
+	ValidateErr(t, err, nil)
+	if len(presences) != 0 {
+		t.Errorf("List() \n got = %+v, want empty", presences)
+	}
+}
@@ -0,0 +1,203 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+// tokenRepository はリフレッシュトークンと失効したアクセストークンを有効期限付きのキーで保持する
+// refresh_token:{tokenHash}          -> リフレッシュトークン(JSON)。再利用を検知する為、使用済みでも期限まで残す
+// refresh_token_family:{familyID}    -> Familyの最新のトークンのハッシュ値。削除するとFamily全体が無効になる
+// refresh_token_families:{userID}    -> ユーザに発行したFamilyのID(Set)。パスワードの再設定時に全てのFamilyを無効にする
+// revoked_access_token:{jti}         -> ログアウト等で失効したアクセストークン
+// one_time_token:{purpose}:{tokenHash} -> ワンタイムトークンの発行先のユーザID
+// oidc_auth_request:{state}          -> OIDCプロバイダからのコールバックを待っている認可リクエスト(JSON)
+type tokenRepository struct {
+	client *redis.Client
+}
+
+func NewTokenRepository(client *redis.Client) repository.TokenRepository {
+	return &tokenRepository{
+		client,
+	}
+}
+
+func refreshTokenKey(tokenHash string) string {
+	return "refresh_token:" + tokenHash
+}
+
+func refreshTokenFamilyKey(familyID string) string {
+	return "refresh_token_family:" + familyID
+}
+
+func userRefreshTokenFamiliesKey(userID string) string {
+	return "refresh_token_families:" + userID
+}
+
+func revokedAccessTokenKey(jti string) string {
+	return "revoked_access_token:" + jti
+}
+
+func oneTimeTokenKey(purpose, tokenHash string) string {
+	return "one_time_token:" + purpose + ":" + tokenHash
+}
+
+func oidcAuthRequestKey(state string) string {
+	return "oidc_auth_request:" + state
+}
+
+func (r *tokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	value, err := r.client.Get(ctx, refreshTokenKey(tokenHash)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, repository.ErrRefreshTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var token entity.RefreshToken
+	if err = json.Unmarshal(value, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) SaveRefreshToken(ctx context.Context, token entity.RefreshToken) error {
+	value, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(token.ExpiresAt)
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, refreshTokenKey(token.TokenHash), value, ttl)
+		pipe.Set(ctx, refreshTokenFamilyKey(token.FamilyID), token.TokenHash, ttl)
+		pipe.SAdd(ctx, userRefreshTokenFamiliesKey(token.UserID), token.FamilyID)
+		pipe.Expire(ctx, userRefreshTokenFamiliesKey(token.UserID), ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *tokenRepository) RotateRefreshToken(ctx context.Context, current, next entity.RefreshToken) error {
+	value, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(next.ExpiresAt)
+	familyKey := refreshTokenFamilyKey(current.FamilyID)
+
+	err = r.client.Watch(ctx, func(tx *redis.Tx) error {
+		latest, err := tx.Get(ctx, familyKey).Result() //nolint:govet // err shadowing
+		if errors.Is(err, redis.Nil) {
+			return repository.ErrRefreshTokenNotFound
+		} else if err != nil {
+			return err
+		}
+		if latest != current.TokenHash {
+			return repository.ErrRefreshTokenReused
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, refreshTokenKey(next.TokenHash), value, ttl)
+			pipe.Set(ctx, familyKey, next.TokenHash, ttl)
+			pipe.Expire(ctx, userRefreshTokenFamiliesKey(next.UserID), ttl)
+			return nil
+		})
+		return err
+	}, familyKey)
+	// 同じトークンで同時に更新された場合は再利用として扱う
+	if errors.Is(err, redis.TxFailedErr) {
+		return repository.ErrRefreshTokenReused
+	}
+	return err
+}
+
+func (r *tokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return r.client.Del(ctx, refreshTokenFamilyKey(familyID)).Err()
+}
+
+func (r *tokenRepository) RevokeUserRefreshTokenFamilies(ctx context.Context, userID string) error {
+	key := userRefreshTokenFamiliesKey(userID)
+	familyIDs, err := r.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(familyIDs)+1)
+	for _, familyID := range familyIDs {
+		keys = append(keys, refreshTokenFamilyKey(familyID))
+	}
+	keys = append(keys, key)
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *tokenRepository) RevokeAccessToken(ctx context.Context, jti string, ttl time.Duration) error {
+	return r.client.Set(ctx, revokedAccessTokenKey(jti), 1, ttl).Err()
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := r.client.Exists(ctx, revokedAccessTokenKey(jti)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *tokenRepository) SaveOneTimeToken(ctx context.Context, token entity.OneTimeToken) error {
+	return r.client.Set(ctx, oneTimeTokenKey(token.Purpose, token.TokenHash), token.UserID, token.TTL).Err()
+}
+
+func (r *tokenRepository) ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (string, error) {
+	key := oneTimeTokenKey(purpose, tokenHash)
+
+	// GETとDELを同じトランザクションで実行し、同時に使われても一方だけが成功するようにする
+	var get *redis.StringCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return "", repository.ErrOneTimeTokenNotFound
+	} else if err != nil {
+		return "", err
+	}
+	return get.Val(), nil
+}
+
+func (r *tokenRepository) SaveOIDCAuthRequest(ctx context.Context, req entity.OIDCAuthRequest) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, oidcAuthRequestKey(req.State), b, req.TTL).Err()
+}
+
+func (r *tokenRepository) ConsumeOIDCAuthRequest(ctx context.Context, state string) (*entity.OIDCAuthRequest, error) {
+	key := oidcAuthRequestKey(state)
+
+	var get *redis.StringCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, repository.ErrOIDCAuthRequestNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var req entity.OIDCAuthRequest
+	if err = json.Unmarshal([]byte(get.Val()), &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+func Test_TokenRepository(t *testing.T) {
+	repo := NewTokenRepository(client)
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+
+	// SaveRefreshToken
+	current, err := entity.NewRefreshToken(userID, "", time.Hour)
+	ValidateErr(t, err, nil)
+	err = repo.SaveRefreshToken(ctx, *current)
+	ValidateErr(t, err, nil)
+
+	// GetRefreshToken
+	got, err := repo.GetRefreshToken(ctx, current.TokenHash)
+	ValidateErr(t, err, nil)
+	if got.UserID != userID || got.FamilyID != current.FamilyID || got.Token != "" {
+		t.Errorf("GetRefreshToken() \n got = %+v", got)
+	}
+	_, err = repo.GetRefreshToken(ctx, entity.HashToken("unknown"))
+	ValidateErr(t, err, repository.ErrRefreshTokenNotFound)
+
+	// RotateRefreshToken
+	next, err := entity.NewRefreshToken(userID, current.FamilyID, time.Hour)
+	ValidateErr(t, err, nil)
+	err = repo.RotateRefreshToken(ctx, *current, *next)
+	ValidateErr(t, err, nil)
+
+	// 使用済みのトークンは再利用できない
+	another, err := entity.NewRefreshToken(userID, current.FamilyID, time.Hour)
+	ValidateErr(t, err, nil)
+	err = repo.RotateRefreshToken(ctx, *current, *another)
+	ValidateErr(t, err, repository.ErrRefreshTokenReused)
+
+	// RevokeRefreshTokenFamily
+	err = repo.RevokeRefreshTokenFamily(ctx, current.FamilyID)
+	ValidateErr(t, err, nil)
+	err = repo.RotateRefreshToken(ctx, *next, *another)
+	ValidateErr(t, err, repository.ErrRefreshTokenNotFound)
+
+	// RevokeUserRefreshTokenFamilies
+	first, err := entity.NewRefreshToken(userID, "", time.Hour)
+	ValidateErr(t, err, nil)
+	err = repo.SaveRefreshToken(ctx, *first)
+	ValidateErr(t, err, nil)
+	second, err := entity.NewRefreshToken(userID, "", time.Hour)
+	ValidateErr(t, err, nil)
+	err = repo.SaveRefreshToken(ctx, *second)
+	ValidateErr(t, err, nil)
+	err = repo.RevokeUserRefreshTokenFamilies(ctx, userID)
+	ValidateErr(t, err, nil)
+	for _, token := range []*entity.RefreshToken{first, second} {
+		rotated, err := entity.NewRefreshToken(userID, token.FamilyID, time.Hour) //nolint:govet // err shadowing
+		ValidateErr(t, err, nil)
+		err = repo.RotateRefreshToken(ctx, *token, *rotated)
+		ValidateErr(t, err, repository.ErrRefreshTokenNotFound)
+	}
+
+	// RevokeAccessToken, IsAccessTokenRevoked
+	jti := uuid.New().String()
+	revoked, err := repo.IsAccessTokenRevoked(ctx, jti)
+	ValidateErr(t, err, nil)
+	if revoked {
+		t.Error("IsAccessTokenRevoked() = true, want false")
+	}
+	err = repo.RevokeAccessToken(ctx, jti, time.Minute)
+	ValidateErr(t, err, nil)
+	revoked, err = repo.IsAccessTokenRevoked(ctx, jti)
+	ValidateErr(t, err, nil)
+	if !revoked {
+		t.Error("IsAccessTokenRevoked() = false, want true")
+	}
+
+	// SaveOneTimeToken, ConsumeOneTimeToken
+	oneTimeToken, err := entity.NewOneTimeToken(entity.OneTimeTokenPurposePasswordReset, userID, time.Minute)
+	ValidateErr(t, err, nil)
+	err = repo.SaveOneTimeToken(ctx, *oneTimeToken)
+	ValidateErr(t, err, nil)
+
+	// 用途が異なるトークンとしては使えない
+	_, err = repo.ConsumeOneTimeToken(ctx, entity.OneTimeTokenPurposeEmailVerification, oneTimeToken.TokenHash)
+	ValidateErr(t, err, repository.ErrOneTimeTokenNotFound)
+
+	gotUserID, err := repo.ConsumeOneTimeToken(ctx, entity.OneTimeTokenPurposePasswordReset, oneTimeToken.TokenHash)
+	ValidateErr(t, err, nil)
+	if gotUserID != userID {
+		t.Errorf("ConsumeOneTimeToken() = %s, want %s", gotUserID, userID)
+	}
+
+	// 一度使ったトークンは使えない
+	_, err = repo.ConsumeOneTimeToken(ctx, entity.OneTimeTokenPurposePasswordReset, oneTimeToken.TokenHash)
+	ValidateErr(t, err, repository.ErrOneTimeTokenNotFound)
+
+	// SaveOIDCAuthRequest, ConsumeOIDCAuthRequest
+	authRequest, err := entity.NewOIDCAuthRequest(time.Minute)
+	ValidateErr(t, err, nil)
+	err = repo.SaveOIDCAuthRequest(ctx, *authRequest)
+	ValidateErr(t, err, nil)
+
+	gotAuthRequest, err := repo.ConsumeOIDCAuthRequest(ctx, authRequest.State)
+	ValidateErr(t, err, nil)
+	if gotAuthRequest.Nonce != authRequest.Nonce || gotAuthRequest.CodeVerifier != authRequest.CodeVerifier {
+		t.Errorf("ConsumeOIDCAuthRequest() = %+v, want %+v", gotAuthRequest, authRequest)
+	}
+
+	// 一度使ったstateは使えない
+	_, err = repo.ConsumeOIDCAuthRequest(ctx, authRequest.State)
+	ValidateErr(t, err, repository.ErrOIDCAuthRequestNotFound)
+}
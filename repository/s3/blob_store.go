@@ -0,0 +1,87 @@
+package s3
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+const (
+	noSuchKey = "NoSuchKey"
+
+	// partSize はマルチパートアップロードの1パートの大きさ。サイズが分からない場合に1パート分をメモリに読み込む
+	partSize = 5 << 20
+)
+
+type blobStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewBlobStore はS3互換のストレージに保存するBlobStoreを返す。バケットが存在しない場合は作成する
+func NewBlobStore(ctx context.Context, conf *config.StorageConfig) (repository.BlobStore, error) {
+	client, err := minio.New(conf.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(conf.S3AccessKeyID, conf.S3SecretAccessKey, ""),
+		Secure: conf.S3UseSSL,
+		Region: conf.S3Region,
+	})
+	if err != nil {
+		log.Error("Failed to create S3 client", log.Fstring("endpoint", conf.S3Endpoint), log.Ferror(err))
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, conf.S3Bucket)
+	if err != nil {
+		log.Error("Failed to check bucket", log.Fstring("bucket", conf.S3Bucket), log.Ferror(err))
+		return nil, err
+	}
+	if !exists {
+		if err = client.MakeBucket(ctx, conf.S3Bucket, minio.MakeBucketOptions{Region: conf.S3Region}); err != nil {
+			log.Error("Failed to create bucket", log.Fstring("bucket", conf.S3Bucket), log.Ferror(err))
+			return nil, err
+		}
+	}
+
+	log.Info("Successfully connected to S3", log.Fstring("endpoint", conf.S3Endpoint), log.Fstring("bucket", conf.S3Bucket))
+	return &blobStore{
+		client: client,
+		bucket: conf.S3Bucket,
+	}, nil
+}
+
+// Put はサイズが分からない為、マルチパートアップロードで保存する
+func (bs *blobStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if _, err := bs.client.PutObject(ctx, bs.bucket, key, body, -1, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    partSize,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (bs *blobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	// GetObjectはリクエストを遅延させる為、Statで存在を確認する
+	object, err := bs.client.GetObject(ctx, bs.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == noSuchKey {
+			return nil, repository.ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+func (bs *blobStore) Delete(ctx context.Context, key string) error {
+	return bs.client.RemoveObject(ctx, bs.bucket, key, minio.RemoveObjectOptions{})
+}
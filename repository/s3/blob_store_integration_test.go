@@ -0,0 +1,42 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+func Test_BlobStore(t *testing.T) {
+	if store == nil {
+		t.Skip("MinIO is not available")
+	}
+	ctx := context.Background()
+
+	key := uuid.New().String() + "/" + uuid.New().String()
+
+	// Put
+	err := store.Put(ctx, key, strings.NewReader("hello"), "text/plain")
+	ValidateErr(t, err, nil)
+
+	// Get
+	body, err := store.Get(ctx, key)
+	ValidateErr(t, err, nil)
+	got, err := io.ReadAll(body)
+	body.Close()
+	ValidateErr(t, err, nil)
+	if string(got) != "hello" {
+		t.Errorf("Get() got = %q, want %q", got, "hello")
+	}
+
+	// Delete
+	err = store.Delete(ctx, key)
+	ValidateErr(t, err, nil)
+
+	_, err = store.Get(ctx, key)
+	ValidateErr(t, err, repository.ErrBlobNotFound)
+}
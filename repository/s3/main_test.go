@@ -0,0 +1,104 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/ory/dockertest"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+const (
+	minioUser     = "minioadmin"
+	minioPassword = "minioadmin"
+	minioBucket   = "attachments"
+)
+
+var store repository.BlobStore
+
+func TestMain(m *testing.M) {
+	var closeMinio func()
+	var err error
+
+	store, closeMinio, err = startMinio()
+	if err != nil {
+		log.Println(err)
+	} else {
+		defer closeMinio()
+	}
+
+	m.Run()
+}
+
+func startMinio() (repository.BlobStore, func(), error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Printf("Could not construct pool: %s\n", err)
+		return nil, nil, err
+	}
+
+	err = pool.Client.Ping()
+	if err != nil {
+		log.Printf("Could not connect to Docker: %s", err)
+		return nil, nil, err
+	}
+
+	minioOptions := &dockertest.RunOptions{
+		Repository: "minio/minio",
+		Tag:        "latest",
+		Cmd:        []string{"server", "/data"},
+		Env: []string{
+			"MINIO_ROOT_USER=" + minioUser,
+			"MINIO_ROOT_PASSWORD=" + minioPassword,
+		},
+	}
+
+	minioResource, err := pool.RunWithOptions(minioOptions)
+	if err != nil {
+		log.Printf("Could not start MinIO resource: %s", err)
+		return nil, nil, err
+	}
+
+	conf := &config.StorageConfig{
+		Driver:            "s3",
+		S3Endpoint:        fmt.Sprintf("localhost:%s", minioResource.GetPort("9000/tcp")),
+		S3Region:          "us-east-1",
+		S3Bucket:          minioBucket,
+		S3AccessKeyID:     minioUser,
+		S3SecretAccessKey: minioPassword,
+	}
+
+	var bs repository.BlobStore
+	err = pool.Retry(func() error {
+		bs, err = NewBlobStore(context.Background(), conf)
+		return err
+	})
+	if err != nil {
+		log.Printf("Could not connect to MinIO container: %s", err)
+		return nil, nil, err
+	}
+
+	log.Println("start MinIO container🐳")
+
+	return bs, func() { closeMinio(pool, minioResource) }, nil
+}
+
+func closeMinio(pool *dockertest.Pool, resource *dockertest.Resource) {
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("Failed to purge resource: %s", err)
+	}
+
+	log.Println("close MinIO container🐳")
+}
+
+func ValidateErr(t *testing.T, err error, wantErr error) {
+	if (err != nil) != (wantErr != nil) {
+		t.Errorf("error = %v, wantErr %v", err, wantErr)
+	} else if err != nil && wantErr != nil && err.Error() != wantErr.Error() {
+		t.Errorf("error = %v, wantErr %v", err, wantErr)
+	}
+}
@@ -0,0 +1,37 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenReused   = errors.New("refresh token reused")
+	ErrOneTimeTokenNotFound = errors.New("one-time token not found")
+
+	ErrOIDCAuthRequestNotFound = errors.New("oidc auth request not found")
+)
+
+type TokenRepository interface {
+	GetRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	SaveRefreshToken(ctx context.Context, token entity.RefreshToken) error
+	// RotateRefreshToken はcurrentがFamilyの最新のトークンである場合のみnextに置き換える
+	// 最新でない場合(使用済みトークンの再利用)はErrRefreshTokenReusedを返す
+	RotateRefreshToken(ctx context.Context, current, next entity.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	// RevokeUserRefreshTokenFamilies はユーザに発行した全てのFamilyを無効にする
+	RevokeUserRefreshTokenFamilies(ctx context.Context, userID string) error
+	RevokeAccessToken(ctx context.Context, jti string, ttl time.Duration) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	SaveOneTimeToken(ctx context.Context, token entity.OneTimeToken) error
+	// ConsumeOneTimeToken はトークンを削除し、発行先のユーザIDを返す。同じトークンは一度しか使えない
+	ConsumeOneTimeToken(ctx context.Context, purpose, tokenHash string) (string, error)
+	SaveOIDCAuthRequest(ctx context.Context, req entity.OIDCAuthRequest) error
+	// ConsumeOIDCAuthRequest はstateに対応する認可リクエストを削除して返す。同じstateは一度しか使えない
+	ConsumeOIDCAuthRequest(ctx context.Context, state string) (*entity.OIDCAuthRequest, error)
+}
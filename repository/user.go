@@ -3,10 +3,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/tusmasoma/go-chat-app/entity"
 )
 
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrTOTPStepAlreadyUsed = errors.New("totp step already used")
+)
+
 type UserRepository interface {
 	Get(ctx context.Context, id string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
@@ -14,4 +20,6 @@ type UserRepository interface {
 	Update(ctx context.Context, user entity.User) error
 	Delete(ctx context.Context, id string) error
 	LockByEmail(ctx context.Context, email string) (bool, error)
+	// ConsumeTOTPStep は受け付けたTOTPのステップを記録する。記録済みのステップ以前の場合は ErrTOTPStepAlreadyUsed を返す
+	ConsumeTOTPStep(ctx context.Context, id string, step int64) error
 }
@@ -0,0 +1,16 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+var ErrUserIdentityNotFound = errors.New("user identity not found")
+
+type UserIdentityRepository interface {
+	Get(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error)
+	Create(ctx context.Context, identity entity.UserIdentity) error
+}
@@ -0,0 +1,17 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package repository
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+)
+
+type WorkspaceRepository interface {
+	List(ctx context.Context) ([]entity.Workspace, error)
+	ListByUserID(ctx context.Context, userID string) ([]entity.Workspace, error)
+	Get(ctx context.Context, id string) (*entity.Workspace, error)
+	Create(ctx context.Context, workspace entity.Workspace) error
+	Update(ctx context.Context, workspace entity.Workspace) error
+	Delete(ctx context.Context, id string) error
+}
@@ -33,7 +33,7 @@ describe("WebSocket E2E Tests with Go Server", () => {
 
       console.log("authToken: ", authToken);
 
-      ws = new WebSocket(`ws://localhost:8080/ws`, { headers });
+      ws = new WebSocket(`ws://localhost:8080/ws?workspace_id=${workspaceID}`, { headers });
 
       await new Promise<void>((resolve, reject) => {
         ws.on("open", () => {
@@ -96,16 +96,19 @@ describe("WebSocket E2E Tests with Go Server", () => {
       // membership_id: "",
     };
 
-    ws.once("message", (data) => {
+    // ACK/ERRORフレームも届く為、対象のアクションを受信するまで待つ
+    const onMessage = (data: WebSocket.RawData) => {
       const receivedMessage = JSON.parse(data.toString());
       if (receivedMessage.action === "CREATE_MESSAGE") {
+        ws.off("message", onMessage);
         expect(receivedMessage.action).toBe(testMessage.action);
         expect(receivedMessage.target_id).toBe(testMessage.target_id);
         //expect(receivedMessage.sender_id).toBe(testMessage.sender_id);
         expect(receivedMessage.id).not.toBe("");
         expect(receivedMessage.membership_id).not.toBe("");
         expect(receivedMessage.text).toBe(testMessage.text);
-        expect(receivedMessage.created_at).toBe(
+        // 投稿日時はサーバ側で設定される
+        expect(receivedMessage.created_at).not.toBe(
           testMessage.created_at
         );
         console.log("SUCCESS: CREATE_MESSAGE");
@@ -113,7 +116,8 @@ describe("WebSocket E2E Tests with Go Server", () => {
         membershipIDofMsg = receivedMessage.membership_id;
         done();
       }
-    });
+    };
+    ws.on("message", onMessage);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(testMessage));
@@ -143,9 +147,11 @@ describe("WebSocket E2E Tests with Go Server", () => {
       membership_id: membershipIDofMsg,
     };
 
-    ws.once("message", (data) => {
+    // ACK/ERRORフレームも届く為、対象のアクションを受信するまで待つ
+    const onMessage = (data: WebSocket.RawData) => {
       const receivedMessage = JSON.parse(data.toString());
       if (receivedMessage.action === "UPDATE_MESSAGE") {
+        ws.off("message", onMessage);
         expect(receivedMessage.action).toBe(testMessage.action);
         expect(receivedMessage.target_id).toBe(testMessage.target_id);
         //expect(receivedMessage.sender_id).toBe(testMessage.sender_id);
@@ -154,13 +160,15 @@ describe("WebSocket E2E Tests with Go Server", () => {
           testMessage.membership_id
         );
         expect(receivedMessage.text).toBe(testMessage.text);
-        expect(receivedMessage.created_at).toBe(
+        // 投稿日時はクライアントの値ではなく、保存されている値が返される
+        expect(receivedMessage.created_at).not.toBe(
           testMessage.created_at
         );
         console.log("SUCCESS: UPDATE_MESSAGE");
         done();
       }
-    });
+    };
+    ws.on("message", onMessage);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(testMessage));
@@ -190,23 +198,23 @@ describe("WebSocket E2E Tests with Go Server", () => {
       membership_id: membershipIDofMsg,
     };
 
-    ws.once("message", (data) => {
+    // ACK/ERRORフレームも届く為、対象のアクションを受信するまで待つ
+    const onMessage = (data: WebSocket.RawData) => {
       const receivedMessage = JSON.parse(data.toString());
       if (receivedMessage.action === "DELETE_MESSAGE") {
+        ws.off("message", onMessage);
         expect(receivedMessage.action).toBe(testMessage.action);
         expect(receivedMessage.target_id).toBe(testMessage.target_id);
         //expect(receivedMessage.sender_id).toBe(testMessage.sender_id);
         expect(receivedMessage.membership_id).toBe(
           testMessage.membership_id
         );
-        expect(receivedMessage.text).toBe(testMessage.text);
-        expect(receivedMessage.created_at).toBe(
-          testMessage.created_at
-        );
+        expect(receivedMessage.id).toBe(testMessage.id);
         console.log("SUCCESS: DELETE_MESSAGE");
         done();
       }
-    });
+    };
+    ws.on("message", onMessage);
 
     if (ws.readyState === WebSocket.OPEN) {
       ws.send(JSON.stringify(testMessage));
@@ -0,0 +1,208 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+// sniffLength はファイルの種類の判定に使う先頭のバイト数
+const sniffLength = 512
+
+var (
+	ErrAttachmentNotFound     = errors.New("attachment not found")
+	ErrAttachmentTooLarge     = errors.New("attachment is too large")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrInvalidAttachment      = errors.New("invalid attachment")
+)
+
+type AttachmentUseCase interface {
+	UploadAttachment(ctx context.Context, userID, workspaceID, channelID, name string, body io.Reader) (*entity.Attachment, error)
+	GetAttachment(ctx context.Context, userID, attachmentID string) (*entity.Attachment, io.ReadCloser, error)
+}
+
+type attachmentUseCase struct {
+	ar   repository.AttachmentRepository
+	mr   repository.MessageRepository
+	cr   repository.ChannelRepository
+	mbr  repository.MembershipRepository
+	mcr  repository.MembershipChannelRepository
+	bs   repository.BlobStore
+	conf *config.StorageConfig
+}
+
+func NewAttachmentUseCase(
+	ar repository.AttachmentRepository,
+	mr repository.MessageRepository,
+	cr repository.ChannelRepository,
+	mbr repository.MembershipRepository,
+	mcr repository.MembershipChannelRepository,
+	bs repository.BlobStore,
+	conf *config.StorageConfig,
+) AttachmentUseCase {
+	return &attachmentUseCase{
+		ar:   ar,
+		mr:   mr,
+		cr:   cr,
+		mbr:  mbr,
+		mcr:  mcr,
+		bs:   bs,
+		conf: conf,
+	}
+}
+
+// UploadAttachment はChannelを閲覧できるユーザがアップロードしたファイルを保存する
+// ファイルの種類はクライアントの申告ではなく先頭のバイト列から判定し、サイズは読み込みながら確認する
+func (auc *attachmentUseCase) UploadAttachment(ctx context.Context, userID, workspaceID, channelID, name string, body io.Reader) (*entity.Attachment, error) {
+	if err := auc.authorizeChannel(ctx, userID, workspaceID, channelID); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(body, sniffLength)
+	head, err := br.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Error("Failed to read attachment", log.Ferror(err))
+		return nil, err
+	}
+	if len(head) == 0 {
+		log.Warn("Attachment is empty", log.Fstring("name", name))
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidAttachment)
+	}
+	contentType := detectContentType(head)
+	if !isAllowedContentType(contentType, auc.conf.AllowedContentTypes) {
+		log.Warn("Unsupported content type", log.Fstring("contentType", contentType))
+		return nil, ErrUnsupportedContentType
+	}
+
+	attachment, err := entity.NewAttachment("", "", userID, workspaceID, channelID, name, contentType, 0, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttachment, err)
+	}
+
+	lr := &sizeLimitedReader{r: br, limit: auc.conf.MaxUploadSize}
+	if err = auc.bs.Put(ctx, attachment.StorageKey(), lr, contentType); err != nil {
+		if lr.exceeded {
+			log.Warn("Attachment is too large", log.Fstring("name", attachment.Name))
+			auc.deleteBlob(ctx, attachment)
+			return nil, ErrAttachmentTooLarge
+		}
+		log.Error("Failed to store attachment", log.Fstring("attachmentID", attachment.ID), log.Ferror(err))
+		return nil, err
+	}
+	attachment.Size = lr.n
+
+	if err = auc.ar.Create(ctx, *attachment); err != nil {
+		log.Error("Failed to create attachment", log.Fstring("attachmentID", attachment.ID), log.Ferror(err))
+		auc.deleteBlob(ctx, attachment)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// GetAttachment は添付ファイルとその本体を返す。呼び出し側で本体を閉じる必要がある
+// メッセージに添付される前のファイルはアップロードしたユーザのみ、添付後はChannelを閲覧できるユーザのみ取得できる
+func (auc *attachmentUseCase) GetAttachment(ctx context.Context, userID, attachmentID string) (*entity.Attachment, io.ReadCloser, error) {
+	attachment, err := auc.ar.Get(ctx, attachmentID)
+	if err != nil {
+		log.Warn("Failed to get attachment", log.Fstring("attachmentID", attachmentID), log.Ferror(err))
+		return nil, nil, ErrAttachmentNotFound
+	}
+
+	if !attachment.IsAttached() {
+		if attachment.UserID != userID {
+			log.Warn("Attachment is not attached yet", log.Fstring("attachmentID", attachmentID))
+			return nil, nil, ErrAttachmentNotFound
+		}
+	} else {
+		if err = auc.authorizeChannel(ctx, userID, attachment.WorkspaceID, attachment.ChannelID); err != nil {
+			if errors.Is(err, ErrChannelNotFound) {
+				return nil, nil, ErrAttachmentNotFound
+			}
+			return nil, nil, err
+		}
+		// 削除されたメッセージの添付ファイルは存在しないものとして扱う
+		message, err := auc.mr.Get(ctx, attachment.MessageID) //nolint:govet // err shadowing
+		if err != nil || message.IsDeleted() {
+			log.Warn("Attachment message not found", log.Fstring("messageID", attachment.MessageID))
+			return nil, nil, ErrAttachmentNotFound
+		}
+	}
+
+	body, err := auc.bs.Get(ctx, attachment.StorageKey())
+	if errors.Is(err, repository.ErrBlobNotFound) {
+		log.Warn("Attachment blob not found", log.Fstring("attachmentID", attachmentID))
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		log.Error("Failed to get attachment blob", log.Fstring("attachmentID", attachmentID), log.Ferror(err))
+		return nil, nil, err
+	}
+	return attachment, body, nil
+}
+
+// authorizeChannel はユーザがWorkspaceのChannelを閲覧できることを確認する
+// メッセージ履歴やWebSocketでの投稿と同じく、Channelに参加しているユーザのみ閲覧できる
+func (auc *attachmentUseCase) authorizeChannel(ctx context.Context, userID, workspaceID, channelID string) error {
+	_, err := getJoinedChannel(ctx, auc.cr, auc.mbr, auc.mcr, userID, workspaceID, channelID)
+	return err
+}
+
+func (auc *attachmentUseCase) deleteBlob(ctx context.Context, attachment *entity.Attachment) {
+	if err := auc.bs.Delete(ctx, attachment.StorageKey()); err != nil {
+		log.Error("Failed to delete attachment blob", log.Fstring("attachmentID", attachment.ID), log.Ferror(err))
+	}
+}
+
+// detectContentType はファイルの先頭のバイト列からパラメータを除いたMIMEタイプを判定する
+func detectContentType(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// isAllowedContentType はMIMEタイプが許可されているかを返す。"image/*"はimageの全てのサブタイプを許可する
+func isAllowedContentType(contentType string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "*"); ok && strings.HasSuffix(prefix, "/") && strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sizeLimitedReader は読み込んだバイト数を数え、上限を超えた時点でエラーを返す
+type sizeLimitedReader struct {
+	r        io.Reader
+	limit    int64
+	n        int64
+	exceeded bool
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		l.exceeded = true
+		return n, ErrAttachmentTooLarge
+	}
+	return n, err
+}
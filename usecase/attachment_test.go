@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func TestAttachmentUseCase_UploadAttachment(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	conf := &config.StorageConfig{MaxUploadSize: 16, AllowedContentTypes: []string{"image/*", "application/pdf"}}
+
+	patterns := []struct {
+		name  string
+		body  string
+		setup func(
+			mar *mock.MockAttachmentRepository,
+			mcr *mock.MockChannelRepository,
+			mmbr *mock.MockMembershipRepository,
+			mmcr *mock.MockMembershipChannelRepository,
+			mbs *mock.MockBlobStore,
+		)
+		wantErr error
+	}{
+		{
+			name: "success: member of public channel",
+			body: pngHeader + "image",
+			setup: func(mar *mock.MockAttachmentRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, mbs *mock.MockBlobStore) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: userID, ChannelID: channelID}}, nil)
+				mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/png").DoAndReturn(
+					func(_ context.Context, _ string, body io.Reader, _ string) error {
+						_, err := io.ReadAll(body)
+						return err
+					},
+				)
+				mar.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, attachment entity.Attachment) {
+					if attachment.Size != int64(len(pngHeader+"image")) || attachment.ContentType != "image/png" || attachment.Name != "photo.png" {
+						t.Errorf("unexpected attachment: %+v", attachment)
+					}
+				}).Return(nil)
+			},
+		},
+		{
+			name: "success: member of private channel",
+			body: pngHeader + "image",
+			setup: func(mar *mock.MockAttachmentRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, mbs *mock.MockBlobStore) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Private: true}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: userID, ChannelID: channelID}}, nil)
+				mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/png").Return(nil)
+				mar.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Fail: too large",
+			body: pngHeader + "a large image",
+			setup: func(_ *mock.MockAttachmentRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, mbs *mock.MockBlobStore) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: userID, ChannelID: channelID}}, nil)
+				mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/png").DoAndReturn(
+					func(_ context.Context, _ string, body io.Reader, _ string) error {
+						_, err := io.ReadAll(body)
+						return err
+					},
+				)
+				mbs.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: ErrAttachmentTooLarge,
+		},
+		{
+			name: "Fail: unsupported content type",
+			body: "<html></html>",
+			setup: func(_ *mock.MockAttachmentRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, _ *mock.MockBlobStore) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: userID, ChannelID: channelID}}, nil)
+			},
+			wantErr: ErrUnsupportedContentType,
+		},
+		{
+			name: "Fail: public channel the user has not joined",
+			body: pngHeader,
+			setup: func(_ *mock.MockAttachmentRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, _ *mock.MockBlobStore) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(nil, nil)
+			},
+			wantErr: ErrChannelNotFound,
+		},
+		{
+			name: "Fail: private channel the user has not joined",
+			body: pngHeader,
+			setup: func(_ *mock.MockAttachmentRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, _ *mock.MockBlobStore) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Private: true}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(nil, nil)
+			},
+			wantErr: ErrChannelNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+			mr := mock.NewMockMessageRepository(ctrl)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			bs := mock.NewMockBlobStore(ctrl)
+
+			tt.setup(ar, cr, mbr, mcr, bs)
+
+			usecase := NewAttachmentUseCase(ar, mr, cr, mbr, mcr, bs, conf)
+
+			_, err := usecase.UploadAttachment(context.Background(), userID, workspaceID, channelID, "photo.png", strings.NewReader(tt.body))
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("UploadAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("UploadAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAttachmentUseCase_GetAttachment(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	uploaderID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+	attachmentID := uuid.New().String()
+	deletedAt := time.Now()
+
+	attached := &entity.Attachment{ID: attachmentID, MessageID: messageID, UserID: uploaderID, WorkspaceID: workspaceID, ChannelID: channelID}
+	pending := &entity.Attachment{ID: attachmentID, UserID: uploaderID, WorkspaceID: workspaceID, ChannelID: channelID}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mar *mock.MockAttachmentRepository,
+			mmr *mock.MockMessageRepository,
+			mcr *mock.MockChannelRepository,
+			mmbr *mock.MockMembershipRepository,
+			mmcr *mock.MockMembershipChannelRepository,
+			mbs *mock.MockBlobStore,
+		)
+		wantErr error
+	}{
+		{
+			name: "success: member of public channel",
+			setup: func(mar *mock.MockAttachmentRepository, mmr *mock.MockMessageRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, mbs *mock.MockBlobStore) {
+				mar.EXPECT().Get(gomock.Any(), attachmentID).Return(attached, nil)
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: userID, ChannelID: channelID}}, nil)
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(&entity.Message{ID: messageID}, nil)
+				mbs.EXPECT().Get(gomock.Any(), attached.StorageKey()).Return(io.NopCloser(strings.NewReader("image")), nil)
+			},
+		},
+		{
+			name: "Fail: public channel the user has not joined",
+			setup: func(mar *mock.MockAttachmentRepository, _ *mock.MockMessageRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, _ *mock.MockBlobStore) {
+				mar.EXPECT().Get(gomock.Any(), attachmentID).Return(attached, nil)
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: uploaderID, ChannelID: channelID}}, nil)
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "Fail: private channel the user has not joined",
+			setup: func(mar *mock.MockAttachmentRepository, _ *mock.MockMessageRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, _ *mock.MockBlobStore) {
+				mar.EXPECT().Get(gomock.Any(), attachmentID).Return(attached, nil)
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Private: true}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: uploaderID, ChannelID: channelID}}, nil)
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "Fail: message is deleted",
+			setup: func(mar *mock.MockAttachmentRepository, mmr *mock.MockMessageRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, _ *mock.MockBlobStore) {
+				mar.EXPECT().Get(gomock.Any(), attachmentID).Return(attached, nil)
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: userID, ChannelID: channelID}}, nil)
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(&entity.Message{ID: messageID, DeletedAt: &deletedAt}, nil)
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "Fail: not attached yet and uploaded by another user",
+			setup: func(mar *mock.MockAttachmentRepository, _ *mock.MockMessageRepository, _ *mock.MockChannelRepository, _ *mock.MockMembershipRepository, _ *mock.MockMembershipChannelRepository, _ *mock.MockBlobStore) {
+				mar.EXPECT().Get(gomock.Any(), attachmentID).Return(pending, nil)
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "Fail: failed to get blob",
+			setup: func(mar *mock.MockAttachmentRepository, mmr *mock.MockMessageRepository, mcr *mock.MockChannelRepository, mmbr *mock.MockMembershipRepository, mmcr *mock.MockMembershipChannelRepository, mbs *mock.MockBlobStore) {
+				mar.EXPECT().Get(gomock.Any(), attachmentID).Return(attached, nil)
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return([]entity.MembershipChannel{{UserID: userID, ChannelID: channelID}}, nil)
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(&entity.Message{ID: messageID}, nil)
+				mbs.EXPECT().Get(gomock.Any(), attached.StorageKey()).Return(nil, errors.New("storage error"))
+			},
+			wantErr: errors.New("storage error"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+			mr := mock.NewMockMessageRepository(ctrl)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			bs := mock.NewMockBlobStore(ctrl)
+
+			tt.setup(ar, mr, cr, mbr, mcr, bs)
+
+			usecase := NewAttachmentUseCase(ar, mr, cr, mbr, mcr, bs, &config.StorageConfig{})
+
+			_, body, err := usecase.GetAttachment(context.Background(), userID, attachmentID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("GetAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("GetAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if body != nil {
+				body.Close()
+			}
+		})
+	}
+}
@@ -1,11 +1,470 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
 package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
 
 	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+var (
+	ErrChannelNotFound     = errors.New("channel not found")
+	ErrChannelNameTaken    = errors.New("channel name is already taken")
+	ErrNotChannelOwner     = errors.New("user is not the owner of the channel")
+	ErrNotWorkspaceMember  = errors.New("user is not a member of the workspace")
+	ErrInvalidParticipants = errors.New("invalid participants")
 )
 
 type ChannelUseCase interface {
-	CreateChannel(ctx context.Context, name string, private bool) (*entity.Channel, error)
+	ListChannels(ctx context.Context, userID, workspaceID string) ([]entity.Channel, error)
+	ListJoinedChannels(ctx context.Context, userID, workspaceID string) ([]entity.Channel, error)
+	GetChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Channel, error)
+	GetAccessibleChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Channel, error)
+	CreateChannel(ctx context.Context, userID, workspaceID, name string, private bool) (*entity.Channel, error)
+	UpdateChannel(ctx context.Context, userID, workspaceID, channelID, name string) (*entity.Channel, error)
+	DeleteChannel(ctx context.Context, userID, workspaceID, channelID string) error
+	InviteMember(ctx context.Context, userID, workspaceID, channelID, inviteeID string) error
+	RemoveMember(ctx context.Context, userID, workspaceID, channelID, memberID string) error
+	JoinChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Membership, error)
+	LeaveChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Membership, error)
+	OpenDirectMessage(ctx context.Context, userID, workspaceID string, userIDs []string) (*entity.DirectMessage, error)
+	ListDirectMessages(ctx context.Context, userID, workspaceID string) ([]entity.DirectMessage, error)
+}
+
+type channelUseCase struct {
+	cr  repository.ChannelRepository
+	mcr repository.MembershipChannelRepository
+	mr  repository.MembershipRepository
+	tr  repository.TransactionRepository
+}
+
+func NewChannelUseCase(
+	cr repository.ChannelRepository,
+	mcr repository.MembershipChannelRepository,
+	mr repository.MembershipRepository,
+	tr repository.TransactionRepository,
+) ChannelUseCase {
+	return &channelUseCase{
+		cr:  cr,
+		mcr: mcr,
+		mr:  mr,
+		tr:  tr,
+	}
+}
+
+// ListChannels はWorkspaceの公開Channelと、ユーザが参加しているプライベートChannelを返す。DMは含めない
+func (cuc *channelUseCase) ListChannels(ctx context.Context, userID, workspaceID string) ([]entity.Channel, error) {
+	return cuc.listWithMembership(ctx, workspaceID, userID, func(channel entity.Channel, joined bool) bool {
+		return !channel.IsDirectMessage() && (!channel.Private || joined)
+	})
+}
+
+// ListJoinedChannels はユーザがMembership_Channelsで参加しているChannelを返す。DMも含む
+// メッセージの配信先はこのChannelに限られ、公開Channelでも退出したユーザには配信しない
+func (cuc *channelUseCase) ListJoinedChannels(ctx context.Context, userID, workspaceID string) ([]entity.Channel, error) {
+	return cuc.listWithMembership(ctx, workspaceID, userID, func(_ entity.Channel, joined bool) bool {
+		return joined
+	})
+}
+
+func (cuc *channelUseCase) GetChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Channel, error) {
+	channel, err := cuc.cr.Get(ctx, channelID)
+	if errors.Is(err, repository.ErrChannelNotFound) {
+		log.Warn("Channel not found", log.Fstring("channelID", channelID))
+		return nil, ErrChannelNotFound
+	} else if err != nil {
+		log.Error("Failed to get channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+	// 他のWorkspaceのChannelは存在しないものとして扱う
+	if channel.WorkspaceID != workspaceID {
+		log.Warn("Channel does not belong to workspace", log.Fstring("channelID", channelID), log.Fstring("workspaceID", workspaceID))
+		return nil, ErrChannelNotFound
+	}
+	// 参加していないプライベートChannelも存在しないものとして扱う
+	if channel.Private {
+		membershipChannel, err := cuc.findMembershipChannel(ctx, userID, channelID) //nolint:govet // err shadowing
+		if err != nil {
+			return nil, err
+		}
+		if membershipChannel == nil {
+			log.Warn("User is not a member of private channel", log.Fstring("channelID", channelID), log.Fstring("userID", userID))
+			return nil, ErrChannelNotFound
+		}
+	}
+	return channel, nil
+}
+
+// GetAccessibleChannel はユーザが参加しているChannelを取得する
+// WebSocketでの投稿・購読と同じく、参加していないChannelは公開Channelであっても存在しないものとして扱う
+func (cuc *channelUseCase) GetAccessibleChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Channel, error) {
+	return getJoinedChannel(ctx, cuc.cr, cuc.mr, cuc.mcr, userID, workspaceID, channelID)
+}
+
+// Channel作成時に、作成者をChannelのオーナーとして登録する
+func (cuc *channelUseCase) CreateChannel(ctx context.Context, userID, workspaceID, name string, private bool) (*entity.Channel, error) {
+	channel, err := entity.NewChannel("", workspaceID, name, private)
+	if err != nil {
+		log.Error("Failed to create new channel", log.Fstring("name", name), log.Ferror(err))
+		return nil, err
+	}
+	membershipChannel, err := entity.NewMembershipChannel(userID, workspaceID, channel.ID, true)
+	if err != nil {
+		log.Error("Failed to create new membership channel", log.Fstring("name", name), log.Ferror(err))
+		return nil, err
+	}
+
+	if err = cuc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = cuc.cr.Create(ctx, *channel); errors.Is(err, repository.ErrChannelNameDuplicated) {
+			log.Warn("Channel name is already taken", log.Fstring("name", name))
+			return ErrChannelNameTaken
+		} else if err != nil {
+			log.Error("Failed to create channel", log.Fstring("name", name), log.Ferror(err))
+			return err
+		}
+		if err = cuc.mcr.Create(ctx, *membershipChannel); err != nil {
+			log.Error("Failed to create membership channel", log.Fstring("name", name), log.Ferror(err))
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// UpdateChannel はChannelの名前を変更する。DeleteChannelと同様に、オーナーまたはWorkspaceの管理者のみ変更できる
+func (cuc *channelUseCase) UpdateChannel(ctx context.Context, userID, workspaceID, channelID, name string) (*entity.Channel, error) {
+	channel, err := cuc.authorizeOwner(ctx, userID, workspaceID, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err = entity.NewChannel(channel.ID, channel.WorkspaceID, name, channel.Private)
+	if err != nil {
+		log.Error("Failed to rename channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+	if err = cuc.cr.Update(ctx, *channel); errors.Is(err, repository.ErrChannelNameDuplicated) {
+		log.Warn("Channel name is already taken", log.Fstring("name", name))
+		return nil, ErrChannelNameTaken
+	} else if err != nil {
+		log.Error("Failed to update channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (cuc *channelUseCase) DeleteChannel(ctx context.Context, userID, workspaceID, channelID string) error {
+	if _, err := cuc.authorizeOwner(ctx, userID, workspaceID, channelID); err != nil {
+		return err
+	}
+	if err := cuc.cr.Delete(ctx, channelID); err != nil {
+		log.Error("Failed to delete channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (cuc *channelUseCase) InviteMember(ctx context.Context, userID, workspaceID, channelID, inviteeID string) error {
+	if _, err := cuc.authorizeOwner(ctx, userID, workspaceID, channelID); err != nil {
+		return err
+	}
+	if _, err := cuc.mr.Get(ctx, inviteeID, workspaceID); err != nil {
+		log.Warn("Invitee is not a member of the workspace", log.Fstring("inviteeID", inviteeID), log.Ferror(err))
+		return ErrNotWorkspaceMember
+	}
+
+	membershipChannel, err := cuc.findMembershipChannel(ctx, inviteeID, channelID)
+	if err != nil {
+		return err
+	}
+	if membershipChannel != nil {
+		log.Info("User is already a member of the channel", log.Fstring("inviteeID", inviteeID), log.Fstring("channelID", channelID))
+		return nil
+	}
+
+	membershipChannel, err = entity.NewMembershipChannel(inviteeID, workspaceID, channelID, false)
+	if err != nil {
+		log.Error("Failed to create new membership channel", log.Fstring("inviteeID", inviteeID), log.Ferror(err))
+		return err
+	}
+	if err = cuc.mcr.Create(ctx, *membershipChannel); err != nil {
+		log.Error("Failed to create membership channel", log.Fstring("inviteeID", inviteeID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+// RemoveMember はChannelのオーナーによるメンバーの削除、またはユーザ自身の退出を行う
+func (cuc *channelUseCase) RemoveMember(ctx context.Context, userID, workspaceID, channelID, memberID string) error {
+	if userID != memberID {
+		if _, err := cuc.authorizeOwner(ctx, userID, workspaceID, channelID); err != nil {
+			return err
+		}
+	} else if _, err := cuc.GetChannel(ctx, userID, workspaceID, channelID); err != nil {
+		return err
+	}
+
+	if err := cuc.mcr.Delete(ctx, memberID, channelID); err != nil {
+		log.Error("Failed to delete membership channel", log.Fstring("memberID", memberID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+// JoinChannel はユーザを公開Channelに参加させ、参加したユーザのWorkspaceでのMembershipを返す
+func (cuc *channelUseCase) JoinChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Membership, error) {
+	if err := cuc.ensurePublicChannel(ctx, userID, workspaceID, channelID); err != nil {
+		return nil, err
+	}
+	membership, err := cuc.mr.Get(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to get membership", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+
+	membershipChannel, err := cuc.findMembershipChannel(ctx, userID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if membershipChannel != nil {
+		log.Info("User is already a member of the channel", log.Fstring("userID", userID), log.Fstring("channelID", channelID))
+		return membership, nil
+	}
+
+	membershipChannel, err = entity.NewMembershipChannel(userID, workspaceID, channelID, false)
+	if err != nil {
+		log.Error("Failed to create new membership channel", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	if err = cuc.mcr.Create(ctx, *membershipChannel); err != nil {
+		log.Error("Failed to create membership channel", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	return membership, nil
+}
+
+// LeaveChannel はユーザを公開Channelから退出させ、退出したユーザのWorkspaceでのMembershipを返す
+func (cuc *channelUseCase) LeaveChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Membership, error) {
+	if err := cuc.ensurePublicChannel(ctx, userID, workspaceID, channelID); err != nil {
+		return nil, err
+	}
+	membership, err := cuc.mr.Get(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to get membership", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+
+	if err = cuc.mcr.Delete(ctx, userID, channelID); err != nil {
+		log.Error("Failed to delete membership channel", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	return membership, nil
+}
+
+// ensurePublicChannel はChannelが公開Channelであることを確認する。プライベートChannelは存在しないものとして扱う
+func (cuc *channelUseCase) ensurePublicChannel(ctx context.Context, userID, workspaceID, channelID string) error {
+	channel, err := cuc.GetChannel(ctx, userID, workspaceID, channelID)
+	if err != nil {
+		return err
+	}
+	if channel.Private {
+		log.Warn("Channel is not public", log.Fstring("channelID", channelID))
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// OpenDirectMessage はユーザと指定した参加者のDMを返す。まだ存在しない場合は作成する
+func (cuc *channelUseCase) OpenDirectMessage(ctx context.Context, userID, workspaceID string, userIDs []string) (*entity.DirectMessage, error) {
+	participants, err := entity.NewParticipants(append([]string{userID}, userIDs...))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParticipants, err)
+	}
+	for _, participant := range participants {
+		if _, err = cuc.mr.Get(ctx, participant, workspaceID); err != nil {
+			log.Warn("Participant is not a member of the workspace", log.Fstring("userID", participant), log.Ferror(err))
+			return nil, ErrNotWorkspaceMember
+		}
+	}
+
+	channel, err := entity.NewDirectMessageChannel("", workspaceID, participants)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParticipants, err)
+	}
+	if existing, err := cuc.cr.GetByConversationKey(ctx, workspaceID, channel.ConversationKey); err == nil { //nolint:govet // err shadowing
+		return &entity.DirectMessage{Channel: existing, UserIDs: participants}, nil
+	}
+
+	if err = cuc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = cuc.cr.Create(ctx, *channel); err != nil {
+			log.Error("Failed to create direct message", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+			return err
+		}
+		for _, participant := range participants {
+			membershipChannel, err := entity.NewMembershipChannel(participant, workspaceID, channel.ID, false) //nolint:govet // err shadowing
+			if err != nil {
+				return err
+			}
+			if err = cuc.mcr.Create(ctx, *membershipChannel); err != nil {
+				log.Error("Failed to create membership channel", log.Fstring("userID", participant), log.Ferror(err))
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		// 同じ参加者のDMが同時に作成された場合は、作成済みのDMを返す
+		if existing, getErr := cuc.cr.GetByConversationKey(ctx, workspaceID, channel.ConversationKey); getErr == nil {
+			return &entity.DirectMessage{Channel: existing, UserIDs: participants}, nil
+		}
+		return nil, err
+	}
+	return &entity.DirectMessage{Channel: channel, UserIDs: participants}, nil
+}
+
+// ListDirectMessages はユーザが参加しているDMを参加者と共に返す
+func (cuc *channelUseCase) ListDirectMessages(ctx context.Context, userID, workspaceID string) ([]entity.DirectMessage, error) {
+	channels, err := cuc.listWithMembership(ctx, workspaceID, userID, func(channel entity.Channel, joined bool) bool {
+		return channel.IsDirectMessage() && joined
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dms := []entity.DirectMessage{}
+	for i := range channels {
+		members, err := cuc.mcr.ListByChannelID(ctx, channels[i].ID) //nolint:govet // err shadowing
+		if err != nil {
+			log.Error("Failed to list membership channels", log.Fstring("channelID", channels[i].ID), log.Ferror(err))
+			return nil, err
+		}
+		userIDs := make([]string, len(members))
+		for j, member := range members {
+			userIDs[j] = member.UserID
+		}
+		dms = append(dms, entity.DirectMessage{Channel: &channels[i], UserIDs: userIDs})
+	}
+	return dms, nil
+}
+
+// authorizeOwner はユーザがChannelのオーナー、またはWorkspaceの管理者であることを確認し、Channelを返す
+func (cuc *channelUseCase) authorizeOwner(ctx context.Context, userID, workspaceID, channelID string) (*entity.Channel, error) {
+	channel, err := cuc.GetChannel(ctx, userID, workspaceID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	// DMにはオーナーがおらず、名前も参加者も変更できない
+	if channel.IsDirectMessage() {
+		log.Warn("Direct message has no owner", log.Fstring("channelID", channelID))
+		return nil, ErrNotChannelOwner
+	}
+
+	membershipChannel, err := cuc.findMembershipChannel(ctx, userID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if membershipChannel != nil && membershipChannel.IsOwner {
+		return channel, nil
+	}
+
+	membership, err := cuc.mr.Get(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to get membership", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	if membership.IsAdmin {
+		return channel, nil
+	}
+
+	log.Warn("User is not the owner of the channel", log.Fstring("userID", userID), log.Fstring("channelID", channelID))
+	return nil, ErrNotChannelOwner
+}
+
+// listWithMembership はWorkspaceのChannelのうち、ユーザの参加状況をもとにfilterが真を返すものを返す
+func (cuc *channelUseCase) listWithMembership(
+	ctx context.Context,
+	workspaceID, userID string,
+	filter func(channel entity.Channel, joined bool) bool,
+) ([]entity.Channel, error) {
+	channels, err := cuc.cr.List(ctx, workspaceID)
+	if err != nil {
+		log.Error("Failed to list channels", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		return nil, err
+	}
+
+	membershipChannels, err := cuc.mcr.ListByUserID(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to list membership channels", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	joined := make(map[string]bool, len(membershipChannels))
+	for _, mc := range membershipChannels {
+		joined[mc.ChannelID] = true
+	}
+
+	filtered := make([]entity.Channel, 0, len(channels))
+	for _, channel := range channels {
+		if filter(channel, joined[channel.ID]) {
+			filtered = append(filtered, channel)
+		}
+	}
+	return filtered, nil
+}
+
+func (cuc *channelUseCase) findMembershipChannel(ctx context.Context, userID, channelID string) (*entity.MembershipChannel, error) {
+	membershipChannels, err := cuc.mcr.ListByChannelID(ctx, channelID)
+	if err != nil {
+		log.Error("Failed to list membership channels", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+	for i := range membershipChannels {
+		if membershipChannels[i].UserID == userID {
+			return &membershipChannels[i], nil
+		}
+	}
+	return nil, nil //nolint:nilnil // not a member
+}
+
+// getJoinedChannel はユーザがWorkspaceのメンバーであり、かつChannelに参加している場合にChannelを返す
+// メッセージ履歴や添付ファイルなど、Channelの中身を閲覧する経路はすべてこの規則で確認する
+func getJoinedChannel(
+	ctx context.Context,
+	cr repository.ChannelRepository,
+	mr repository.MembershipRepository,
+	mcr repository.MembershipChannelRepository,
+	userID, workspaceID, channelID string,
+) (*entity.Channel, error) {
+	channel, err := cr.Get(ctx, channelID)
+	if err != nil {
+		log.Warn("Failed to get channel", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, ErrChannelNotFound
+	}
+	// 他のWorkspaceのChannelは存在しないものとして扱う
+	if channel.WorkspaceID != workspaceID {
+		log.Warn("Channel does not belong to workspace", log.Fstring("channelID", channelID), log.Fstring("workspaceID", workspaceID))
+		return nil, ErrChannelNotFound
+	}
+	if _, err = mr.Get(ctx, userID, workspaceID); err != nil {
+		log.Warn("User is not a member of the workspace", log.Fstring("userID", userID), log.Fstring("workspaceID", workspaceID))
+		return nil, ErrChannelNotFound
+	}
+
+	membershipChannels, err := mcr.ListByChannelID(ctx, channelID)
+	if err != nil {
+		log.Error("Failed to list membership channels", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+	for _, mc := range membershipChannels {
+		if mc.UserID == userID {
+			return channel, nil
+		}
+	}
+	log.Warn("User is not a member of the channel", log.Fstring("userID", userID), log.Fstring("channelID", channelID))
+	return nil, ErrChannelNotFound
 }
@@ -0,0 +1,1274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func TestChannelUseCase_ListChannels(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	publicChannelID := uuid.New().String()
+	joinedChannelID := uuid.New().String()
+	privateChannelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+		}
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "success: private channels the user has not joined are hidden",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+			) {
+				m.EXPECT().List(gomock.Any(), workspaceID).Return(
+					[]entity.Channel{
+						{ID: publicChannelID, WorkspaceID: workspaceID, Name: "general"},
+						{ID: joinedChannelID, WorkspaceID: workspaceID, Name: "joined", Private: true},
+						{ID: privateChannelID, WorkspaceID: workspaceID, Name: "secret", Private: true},
+					}, nil,
+				)
+				m1.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: joinedChannelID},
+					}, nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+			},
+			want:    []string{publicChannelID, joinedChannelID},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			channels, err := usecase.ListChannels(tt.arg.ctx, tt.arg.userID, tt.arg.workspaceID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListChannels() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListChannels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(channels) != len(tt.want) {
+				t.Fatalf("ListChannels() got %d channels, want %d", len(channels), len(tt.want))
+			}
+			for i, channel := range channels {
+				if channel.ID != tt.want[i] {
+					t.Errorf("ListChannels() got %v, want %v", channel.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_ListJoinedChannels(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	publicChannelID := uuid.New().String()
+	leftChannelID := uuid.New().String()
+	privateChannelID := uuid.New().String()
+	dmID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+		}
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "success: public channels the user has left are excluded",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+			) {
+				m.EXPECT().List(gomock.Any(), workspaceID).Return(
+					[]entity.Channel{
+						{ID: publicChannelID, WorkspaceID: workspaceID, Name: "general"},
+						{ID: leftChannelID, WorkspaceID: workspaceID, Name: "random"},
+						{ID: privateChannelID, WorkspaceID: workspaceID, Name: "secret", Private: true},
+						{ID: dmID, WorkspaceID: workspaceID, Private: true, Kind: entity.ChannelKindDirectMessage},
+					}, nil,
+				)
+				m1.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: publicChannelID},
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: privateChannelID},
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: dmID},
+					}, nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+			},
+			want:    []string{publicChannelID, privateChannelID, dmID},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: failed to list channels",
+			setup: func(
+				m *mock.MockChannelRepository,
+				_ *mock.MockMembershipChannelRepository,
+			) {
+				m.EXPECT().List(gomock.Any(), workspaceID).Return(nil, fmt.Errorf("failed to list channels"))
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("failed to list channels"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			channels, err := usecase.ListJoinedChannels(tt.arg.ctx, tt.arg.userID, tt.arg.workspaceID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListJoinedChannels() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListJoinedChannels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(channels) != len(tt.want) {
+				t.Fatalf("ListJoinedChannels() got %d channels, want %d", len(channels), len(tt.want))
+			}
+			for i, channel := range channels {
+				if channel.ID != tt.want[i] {
+					t.Errorf("ListJoinedChannels() got %v, want %v", channel.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_GetChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+			channelID   string
+		}
+		wantErr error
+	}{
+		{
+			name: "success: member of private channel",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "secret", Private: true}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID},
+					}, nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: not a member of private channel",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "secret", Private: true}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(nil, nil)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: ErrChannelNotFound,
+		},
+		{
+			name: "Fail: channel not found",
+			setup: func(
+				m *mock.MockChannelRepository,
+				_ *mock.MockMembershipChannelRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(nil, repository.ErrChannelNotFound)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: ErrChannelNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			_, err := usecase.GetChannel(tt.arg.ctx, tt.arg.userID, tt.arg.workspaceID, tt.arg.channelID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("GetChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("GetChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_CreateChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+			m2 *mock.MockTransactionRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+			name        string
+			private     bool
+		}
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockTransactionRepository,
+			) {
+				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, channel entity.Channel) {
+					if channel.ID == "" {
+						t.Error("ID is empty")
+					}
+					if channel.WorkspaceID != workspaceID {
+						t.Errorf("unexpected WorkspaceID: got %v, want %v", channel.WorkspaceID, workspaceID)
+					}
+					if channel.Name != "general" {
+						t.Errorf("unexpected Name: got %v, want %v", channel.Name, "general")
+					}
+				}).Return(nil)
+				m1.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, membershipChannel entity.MembershipChannel) {
+					if membershipChannel.UserID != userID {
+						t.Errorf("unexpected UserID: got %v, want %v", membershipChannel.UserID, userID)
+					}
+					if !membershipChannel.IsOwner {
+						t.Error("creator should be owner")
+					}
+				}).Return(nil)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				name        string
+				private     bool
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				name:        "general",
+				private:     false,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: name is empty",
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				name        string
+				private     bool
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				name:        "",
+				private:     false,
+			},
+			wantErr: errors.New("name is required"),
+		},
+		{
+			name: "Fail: channel name is already taken",
+			setup: func(
+				m *mock.MockChannelRepository,
+				_ *mock.MockMembershipChannelRepository,
+				m2 *mock.MockTransactionRepository,
+			) {
+				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repository.ErrChannelNameDuplicated)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				name        string
+				private     bool
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				name:        "general",
+				private:     false,
+			},
+			wantErr: ErrChannelNameTaken,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr, tr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			channel, err := usecase.CreateChannel(
+				tt.arg.ctx,
+				tt.arg.userID,
+				tt.arg.workspaceID,
+				tt.arg.name,
+				tt.arg.private,
+			)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("CreateChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("CreateChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && channel == nil {
+				t.Error("Failed to create channel")
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_UpdateChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+			m2 *mock.MockMembershipRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+			channelID   string
+			name        string
+		}
+		wantErr error
+	}{
+		{
+			name: "success: channel owner",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				_ *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{
+						ID:          channelID,
+						WorkspaceID: workspaceID,
+						Name:        "general",
+					}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, IsOwner: true},
+					}, nil,
+				)
+				m.EXPECT().Update(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, channel entity.Channel) {
+					if channel.ID != channelID {
+						t.Errorf("unexpected ID: got %v, want %v", channel.ID, channelID)
+					}
+					if channel.Name != "renamed" {
+						t.Errorf("unexpected Name: got %v, want %v", channel.Name, "renamed")
+					}
+				}).Return(nil)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+				name        string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+				name:        "renamed",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: channel belongs to another workspace",
+			setup: func(
+				m *mock.MockChannelRepository,
+				_ *mock.MockMembershipChannelRepository,
+				_ *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{
+						ID:          channelID,
+						WorkspaceID: uuid.New().String(),
+						Name:        "general",
+					}, nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+				name        string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+				name:        "renamed",
+			},
+			wantErr: ErrChannelNotFound,
+		},
+		{
+			name: "Fail: neither owner nor admin",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{
+						ID:          channelID,
+						WorkspaceID: workspaceID,
+						Name:        "general",
+					}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID},
+					}, nil,
+				)
+				m2.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+				name        string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+				name:        "renamed",
+			},
+			wantErr: ErrNotChannelOwner,
+		},
+		{
+			name: "Fail: channel name is already taken",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				_ *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{
+						ID:          channelID,
+						WorkspaceID: workspaceID,
+						Name:        "general",
+					}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, IsOwner: true},
+					}, nil,
+				)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(repository.ErrChannelNameDuplicated)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+				name        string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+				name:        "random",
+			},
+			wantErr: ErrChannelNameTaken,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr, mr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			_, err := usecase.UpdateChannel(
+				tt.arg.ctx,
+				tt.arg.userID,
+				tt.arg.workspaceID,
+				tt.arg.channelID,
+				tt.arg.name,
+			)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("UpdateChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("UpdateChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_DeleteChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+			m2 *mock.MockMembershipRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+			channelID   string
+		}
+		wantErr error
+	}{
+		{
+			name: "success: channel owner",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				_ *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "general"}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, IsOwner: true},
+					}, nil,
+				)
+				m.EXPECT().Delete(gomock.Any(), channelID).Return(nil)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: workspace admin",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "general"}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(nil, nil)
+				m2.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{UserID: userID, WorkspaceID: workspaceID, IsAdmin: true}, nil,
+				)
+				m.EXPECT().Delete(gomock.Any(), channelID).Return(nil)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: neither owner nor admin",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "general"}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(nil, nil)
+				m2.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: ErrNotChannelOwner,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr, mr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			err := usecase.DeleteChannel(
+				tt.arg.ctx,
+				tt.arg.userID,
+				tt.arg.workspaceID,
+				tt.arg.channelID,
+			)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("DeleteChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("DeleteChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_InviteMember(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	inviteeID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+			m2 *mock.MockMembershipRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+			channelID   string
+			inviteeID   string
+		}
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "secret", Private: true}, nil,
+				)
+				owner := []entity.MembershipChannel{
+					{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, IsOwner: true},
+				}
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(owner, nil).Times(3)
+				m2.EXPECT().Get(gomock.Any(), inviteeID, workspaceID).Return(
+					&entity.Membership{UserID: inviteeID, WorkspaceID: workspaceID}, nil,
+				)
+				m1.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, membershipChannel entity.MembershipChannel) {
+					if membershipChannel.UserID != inviteeID {
+						t.Errorf("unexpected UserID: got %v, want %v", membershipChannel.UserID, inviteeID)
+					}
+					if membershipChannel.IsOwner {
+						t.Error("invitee should not be owner")
+					}
+				}).Return(nil)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+				inviteeID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+				inviteeID:   inviteeID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: invitee is not a workspace member",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "general"}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, IsOwner: true},
+					}, nil,
+				)
+				m2.EXPECT().Get(gomock.Any(), inviteeID, workspaceID).Return(nil, errors.New("record not found"))
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+				inviteeID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+				inviteeID:   inviteeID,
+			},
+			wantErr: ErrNotWorkspaceMember,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr, mr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			err := usecase.InviteMember(
+				tt.arg.ctx,
+				tt.arg.userID,
+				tt.arg.workspaceID,
+				tt.arg.channelID,
+				tt.arg.inviteeID,
+			)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("InviteMember() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("InviteMember() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_JoinChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+			m2 *mock.MockMembershipRepository,
+		)
+		arg struct {
+			ctx         context.Context
+			userID      string
+			workspaceID string
+			channelID   string
+		}
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "general"}, nil,
+				)
+				m2.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{UserID: userID, WorkspaceID: workspaceID, Name: "test"}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(nil, nil)
+				m1.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, membershipChannel entity.MembershipChannel) {
+					if membershipChannel.UserID != userID {
+						t.Errorf("unexpected UserID: got %v, want %v", membershipChannel.UserID, userID)
+					}
+					if membershipChannel.ChannelID != channelID {
+						t.Errorf("unexpected ChannelID: got %v, want %v", membershipChannel.ChannelID, channelID)
+					}
+				}).Return(nil)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: private channel",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				_ *mock.MockMembershipRepository,
+			) {
+				m.EXPECT().Get(gomock.Any(), channelID).Return(
+					&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Name: "secret", Private: true}, nil,
+				)
+				m1.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(
+					[]entity.MembershipChannel{
+						{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID},
+					}, nil,
+				)
+			},
+			arg: struct {
+				ctx         context.Context
+				userID      string
+				workspaceID string
+				channelID   string
+			}{
+				ctx:         context.Background(),
+				userID:      userID,
+				workspaceID: workspaceID,
+				channelID:   channelID,
+			},
+			wantErr: ErrChannelNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr, mr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			membership, err := usecase.JoinChannel(
+				tt.arg.ctx,
+				tt.arg.userID,
+				tt.arg.workspaceID,
+				tt.arg.channelID,
+			)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("JoinChannel() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("JoinChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && membership == nil {
+				t.Error("Failed to get membership")
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_OpenDirectMessage(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	otherUserID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	existingID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockChannelRepository,
+			m1 *mock.MockMembershipChannelRepository,
+			m2 *mock.MockMembershipRepository,
+			m3 *mock.MockTransactionRepository,
+		)
+		userIDs []string
+		wantID  string
+		wantErr error
+	}{
+		{
+			name: "success: create",
+			setup: func(
+				m *mock.MockChannelRepository,
+				m1 *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+				m3 *mock.MockTransactionRepository,
+			) {
+				m2.EXPECT().Get(gomock.Any(), gomock.Any(), workspaceID).Return(&entity.Membership{}, nil).Times(2)
+				m.EXPECT().GetByConversationKey(gomock.Any(), workspaceID, gomock.Any()).Return(nil, errors.New("record not found"))
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, channel entity.Channel) {
+					if !channel.IsDirectMessage() || !channel.Private {
+						t.Errorf("unexpected channel: %+v", channel)
+					}
+				}).Return(nil)
+				m1.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+			userIDs: []string{otherUserID},
+			wantErr: nil,
+		},
+		{
+			name: "success: already exists",
+			setup: func(
+				m *mock.MockChannelRepository,
+				_ *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+				_ *mock.MockTransactionRepository,
+			) {
+				m2.EXPECT().Get(gomock.Any(), gomock.Any(), workspaceID).Return(&entity.Membership{}, nil).Times(2)
+				m.EXPECT().GetByConversationKey(gomock.Any(), workspaceID, gomock.Any()).Return(
+					&entity.Channel{ID: existingID, WorkspaceID: workspaceID, Private: true, Kind: entity.ChannelKindDirectMessage}, nil,
+				)
+			},
+			userIDs: []string{otherUserID, userID},
+			wantID:  existingID,
+			wantErr: nil,
+		},
+		{
+			name:    "Fail: no other participants",
+			userIDs: []string{userID},
+			wantErr: fmt.Errorf("%w: at least two participants are required", ErrInvalidParticipants),
+		},
+		{
+			name: "Fail: participant is not a workspace member",
+			setup: func(
+				_ *mock.MockChannelRepository,
+				_ *mock.MockMembershipChannelRepository,
+				m2 *mock.MockMembershipRepository,
+				_ *mock.MockTransactionRepository,
+			) {
+				m2.EXPECT().Get(gomock.Any(), gomock.Any(), workspaceID).DoAndReturn(
+					func(_ context.Context, id, _ string) (*entity.Membership, error) {
+						if id == otherUserID {
+							return nil, errors.New("record not found")
+						}
+						return &entity.Membership{}, nil
+					},
+				).AnyTimes()
+			},
+			userIDs: []string{otherUserID},
+			wantErr: ErrNotWorkspaceMember,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(cr, mcr, mr, tr)
+			}
+
+			usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+			dm, err := usecase.OpenDirectMessage(context.Background(), userID, workspaceID, tt.userIDs)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("OpenDirectMessage() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("OpenDirectMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(dm.UserIDs) != 2 {
+				t.Errorf("unexpected participants: %v", dm.UserIDs)
+			}
+			if tt.wantID != "" && dm.Channel.ID != tt.wantID {
+				t.Errorf("unexpected channel ID: got %v, want %v", dm.Channel.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestChannelUseCase_ListDirectMessages(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	otherUserID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	dmID := uuid.New().String()
+	otherDMID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	ctrl := gomock.NewController(t)
+	cr := mock.NewMockChannelRepository(ctrl)
+	mcr := mock.NewMockMembershipChannelRepository(ctrl)
+	mr := mock.NewMockMembershipRepository(ctrl)
+	tr := mock.NewMockTransactionRepository(ctrl)
+
+	cr.EXPECT().List(gomock.Any(), workspaceID).Return([]entity.Channel{
+		{ID: channelID, WorkspaceID: workspaceID, Name: "general", Kind: entity.ChannelKindChannel},
+		{ID: dmID, WorkspaceID: workspaceID, Private: true, Kind: entity.ChannelKindDirectMessage},
+		{ID: otherDMID, WorkspaceID: workspaceID, Private: true, Kind: entity.ChannelKindDirectMessage},
+	}, nil)
+	mcr.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return([]entity.MembershipChannel{
+		{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID},
+		{UserID: userID, WorkspaceID: workspaceID, ChannelID: dmID},
+	}, nil)
+	mcr.EXPECT().ListByChannelID(gomock.Any(), dmID).Return([]entity.MembershipChannel{
+		{UserID: userID, WorkspaceID: workspaceID, ChannelID: dmID},
+		{UserID: otherUserID, WorkspaceID: workspaceID, ChannelID: dmID},
+	}, nil)
+
+	usecase := NewChannelUseCase(cr, mcr, mr, tr)
+
+	dms, err := usecase.ListDirectMessages(context.Background(), userID, workspaceID)
+	if err != nil {
+		t.Fatalf("ListDirectMessages() error = %v, want nil", err)
+	}
+	if len(dms) != 1 || dms[0].Channel.ID != dmID || len(dms[0].UserIDs) != 2 {
+		t.Errorf("ListDirectMessages() got = %+v", dms)
+	}
+}
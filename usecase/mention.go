@@ -0,0 +1,82 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type MentionUseCase interface {
+	ListMentions(ctx context.Context, userID, before string, limit int) ([]entity.MentionedMessage, error)
+}
+
+type mentionUseCase struct {
+	mnr repository.MentionRepository
+	mr  repository.MessageRepository
+}
+
+func NewMentionUseCase(
+	mnr repository.MentionRepository,
+	mr repository.MessageRepository,
+) MentionUseCase {
+	return &mentionUseCase{
+		mnr: mnr,
+		mr:  mr,
+	}
+}
+
+// ListMentions はユーザ宛てのメンションを含むメッセージを新しい順に返す
+// beforeにはカーソルとなるメッセージのidを指定する
+func (mnuc *mentionUseCase) ListMentions(ctx context.Context, userID, before string, limit int) ([]entity.MentionedMessage, error) {
+	if limit <= 0 {
+		limit = config.DefaultMessageListLimit
+	}
+	if limit > config.MaxMessageListLimit {
+		limit = config.MaxMessageListLimit
+	}
+
+	var cursor *repository.MessageCursor
+	if before != "" {
+		message, err := mnuc.mr.Get(ctx, before)
+		if err != nil {
+			log.Warn("Failed to get cursor message", log.Fstring("messageID", before), log.Ferror(err))
+			return nil, ErrMessageNotFound
+		}
+		cursor = &repository.MessageCursor{CreatedAt: message.CreatedAt, ID: message.ID}
+	}
+
+	mentions, err := mnuc.mnr.ListByUserID(ctx, userID, cursor, limit)
+	if err != nil {
+		log.Error("Failed to list mentions", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	ids := make([]string, len(mentions))
+	for i, mention := range mentions {
+		ids[i] = mention.MessageID
+	}
+	messages, err := mnuc.mr.ListByIDs(ctx, ids)
+	if err != nil {
+		log.Error("Failed to list mentioned messages", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	byID := make(map[string]*entity.Message, len(messages))
+	for i := range messages {
+		byID[messages[i].ID] = &messages[i]
+	}
+
+	mentioned := make([]entity.MentionedMessage, 0, len(mentions))
+	for _, mention := range mentions {
+		message, ok := byID[mention.MessageID]
+		if !ok {
+			continue
+		}
+		message.Redact()
+		mentioned = append(mentioned, entity.MentionedMessage{Kind: mention.Kind, Message: message})
+	}
+	return mentioned, nil
+}
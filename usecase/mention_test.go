@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func TestMentionUseCase_ListMentions(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+	deletedID := uuid.New().String()
+	createdAt := time.Now()
+	deletedAt := createdAt
+
+	ctrl := gomock.NewController(t)
+	mnr := mock.NewMockMentionRepository(ctrl)
+	mr := mock.NewMockMessageRepository(ctrl)
+
+	mr.EXPECT().Get(gomock.Any(), "cursor").Return(&entity.Message{ID: "cursor", CreatedAt: createdAt}, nil)
+	mnr.EXPECT().ListByUserID(gomock.Any(), userID, &repository.MessageCursor{CreatedAt: createdAt, ID: "cursor"}, 50).Return([]entity.Mention{
+		{MessageID: messageID, UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, Kind: entity.MentionKindUser},
+		{MessageID: deletedID, UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, Kind: entity.MentionKindChannel},
+	}, nil)
+	mr.EXPECT().ListByIDs(gomock.Any(), []string{messageID, deletedID}).Return([]entity.Message{
+		{ID: deletedID, Text: "@channel secret", DeletedAt: &deletedAt},
+		{ID: messageID, Text: "@bob hello"},
+	}, nil)
+
+	usecase := NewMentionUseCase(mnr, mr)
+
+	got, err := usecase.ListMentions(context.Background(), userID, "cursor", 0)
+	if err != nil {
+		t.Fatalf("ListMentions() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Message.ID != messageID || got[0].Kind != entity.MentionKindUser {
+		t.Fatalf("ListMentions() got = %+v", got)
+	}
+	if got[1].Message.Text != entity.DeletedMessageText {
+		t.Errorf("ListMentions() deleted message text = %v, want %v", got[1].Message.Text, entity.DeletedMessageText)
+	}
+}
@@ -3,53 +3,507 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
 
+	"github.com/tusmasoma/go-chat-app/config"
 	"github.com/tusmasoma/go-chat-app/entity"
 	"github.com/tusmasoma/go-chat-app/repository"
 )
 
+var (
+	ErrMessageNotFound   = errors.New("message not found")
+	ErrNotMessageAuthor  = errors.New("user is not the author of the message")
+	ErrNestedReply       = errors.New("cannot reply to a reply")
+	ErrNotWorkspaceAdmin = errors.New("user is not an admin of the workspace")
+)
+
 type MessageUseCase interface {
+	ListMessages(ctx context.Context, channelID, before, after string, limit int) (*entity.Messages, error)
+	ListThreadMessages(ctx context.Context, channelID, parentID, before, after string, limit int) (*entity.Messages, error)
 	CreateMessage(ctx context.Context, message *entity.Message) error
+	ReplyMessage(ctx context.Context, message *entity.Message) (*entity.Message, error)
 	UpdateMessage(ctx context.Context, message *entity.Message) error
 	DeleteMessage(ctx context.Context, message *entity.Message) error
+	ListMessageRevisions(ctx context.Context, userID, workspaceID, messageID string) ([]entity.MessageRevision, error)
 }
 
 type messageUseCase struct {
-	mr repository.MessageRepository
+	mr  repository.MessageRepository
+	mbr repository.MembershipRepository
+	tr  repository.TransactionRepository
+	rr  repository.ReactionRepository
+	mrr repository.MessageRevisionRepository
+	ar  repository.AttachmentRepository
+	mnr repository.MentionRepository
+	mcr repository.MembershipChannelRepository
+	cr  repository.ChannelRepository
+	pr  repository.PresenceRepository
 }
 
-func NewMessageUseCase(mr repository.MessageRepository) MessageUseCase {
+func NewMessageUseCase(
+	mr repository.MessageRepository,
+	mbr repository.MembershipRepository,
+	tr repository.TransactionRepository,
+	rr repository.ReactionRepository,
+	mrr repository.MessageRevisionRepository,
+	ar repository.AttachmentRepository,
+	mnr repository.MentionRepository,
+	mcr repository.MembershipChannelRepository,
+	cr repository.ChannelRepository,
+	pr repository.PresenceRepository,
+) MessageUseCase {
 	return &messageUseCase{
-		mr: mr,
+		mr:  mr,
+		mbr: mbr,
+		tr:  tr,
+		rr:  rr,
+		mrr: mrr,
+		ar:  ar,
+		mnr: mnr,
+		mcr: mcr,
+		cr:  cr,
+		pr:  pr,
+	}
+}
+
+// ListMessages はbefore/afterに指定されたメッセージIDを起点に、Channelのメッセージを古い順に返す
+func (muc *messageUseCase) ListMessages(ctx context.Context, channelID, before, after string, limit int) (*entity.Messages, error) {
+	query, err := muc.newListMessagesQuery(ctx, channelID, before, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := muc.mr.List(ctx, channelID, query)
+	if err != nil {
+		log.Error("Failed to list messages", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, err
+	}
+	if err = muc.renderMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ListThreadMessages はスレッドの返信を古い順に返す
+func (muc *messageUseCase) ListThreadMessages(ctx context.Context, channelID, parentID, before, after string, limit int) (*entity.Messages, error) {
+	if _, err := muc.getParent(ctx, channelID, parentID); err != nil {
+		return nil, err
+	}
+	query, err := muc.newListMessagesQuery(ctx, channelID, before, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := muc.mr.ListReplies(ctx, parentID, query)
+	if err != nil {
+		log.Error("Failed to list replies", log.Fstring("parentID", parentID), log.Ferror(err))
+		return nil, err
+	}
+	if err = muc.renderMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// renderMessages は履歴の各メッセージに集計したリアクションと添付ファイルを設定し、削除済みのメッセージの本文を伏せる
+func (muc *messageUseCase) renderMessages(ctx context.Context, messages *entity.Messages) error {
+	if len(messages.Messages) == 0 {
+		return nil
+	}
+	ids := make([]string, len(messages.Messages))
+	for i, message := range messages.Messages {
+		ids[i] = message.ID
+	}
+
+	reactions, err := muc.rr.ListByMessageIDs(ctx, ids)
+	if err != nil {
+		log.Error("Failed to list reactions", log.Ferror(err))
+		return err
+	}
+	attachments, err := muc.ar.ListByMessageIDs(ctx, ids)
+	if err != nil {
+		log.Error("Failed to list attachments", log.Ferror(err))
+		return err
+	}
+	counts := entity.NewReactionCounts(reactions)
+	groups := entity.GroupAttachmentsByMessageID(attachments)
+	for _, message := range messages.Messages {
+		message.Reactions = counts[message.ID]
+		message.Attachments = groups[message.ID]
+		message.Redact()
+	}
+	return nil
+}
+
+func (muc *messageUseCase) newListMessagesQuery(ctx context.Context, channelID, before, after string, limit int) (repository.ListMessagesQuery, error) {
+	if limit <= 0 {
+		limit = config.DefaultMessageListLimit
+	}
+	if limit > config.MaxMessageListLimit {
+		limit = config.MaxMessageListLimit
+	}
+
+	query := repository.ListMessagesQuery{Limit: limit}
+	var err error
+	if before != "" {
+		if query.Before, err = muc.getCursor(ctx, channelID, before); err != nil {
+			return query, err
+		}
+	}
+	if after != "" {
+		if query.After, err = muc.getCursor(ctx, channelID, after); err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+// getCursor はカーソルとして指定されたメッセージがChannelに属していることを確認し、その位置を返す
+func (muc *messageUseCase) getCursor(ctx context.Context, channelID, messageID string) (*repository.MessageCursor, error) {
+	message, err := muc.mr.Get(ctx, messageID)
+	if err != nil {
+		log.Warn("Failed to get cursor message", log.Fstring("messageID", messageID), log.Ferror(err))
+		return nil, ErrMessageNotFound
+	}
+	if message.TargetID != channelID {
+		log.Warn("Cursor message does not belong to channel", log.Fstring("messageID", messageID), log.Fstring("channelID", channelID))
+		return nil, ErrMessageNotFound
 	}
+	return &repository.MessageCursor{CreatedAt: message.CreatedAt, ID: message.ID}, nil
 }
 
+// CreateMessage はメッセージを保存する。添付ファイルとメンションはメッセージと同じトランザクションで保存する
 func (muc *messageUseCase) CreateMessage(ctx context.Context, message *entity.Message) error {
-	message.ID = uuid.New().String() // TODO: messageの生成を再度する？
-	if err := muc.mr.Create(ctx, *message); err != nil {
-		log.Error("Failed to create message", log.Ferror(err))
+	targets := entity.ParseMentions(message.Text)
+	if len(message.AttachmentIDs) == 0 && targets.IsEmpty() {
+		if err := muc.mr.Create(ctx, *message); err != nil {
+			log.Error("Failed to create message", log.Ferror(err))
+			return err
+		}
+		return nil
+	}
+
+	return muc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err := muc.mr.Create(ctx, *message); err != nil {
+			log.Error("Failed to create message", log.Ferror(err))
+			return err
+		}
+		if err := muc.attachAttachments(ctx, message); err != nil {
+			return err
+		}
+		return muc.createMentions(ctx, message, targets)
+	})
+}
+
+// ReplyMessage はスレッドへの返信を保存し、返信先の返信数と最終返信日時を更新する
+// 更新後の返信数と最終返信日時を持つ返信先のメッセージを返す
+func (muc *messageUseCase) ReplyMessage(ctx context.Context, message *entity.Message) (*entity.Message, error) {
+	parent, err := muc.getParent(ctx, message.TargetID, message.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.WorkspaceID != message.WorkspaceID {
+		log.Warn("Parent message does not belong to workspace", log.Fstring("parentID", parent.ID))
+		return nil, ErrMessageNotFound
+	}
+
+	if err = muc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = muc.mr.Create(ctx, *message); err != nil {
+			log.Error("Failed to create reply", log.Ferror(err))
+			return err
+		}
+		if err = muc.mr.RefreshReplySummary(ctx, parent.ID); err != nil {
+			log.Error("Failed to refresh reply summary", log.Fstring("parentID", parent.ID), log.Ferror(err))
+			return err
+		}
+		// 同じトランザクションで読み直し、この返信を含めた返信数を返す
+		if parent, err = muc.mr.Get(ctx, parent.ID); err != nil {
+			log.Error("Failed to get parent message", log.Fstring("parentID", message.ParentID), log.Ferror(err))
+			return err
+		}
+		if err = muc.attachAttachments(ctx, message); err != nil {
+			return err
+		}
+		return muc.createMentions(ctx, message, entity.ParseMentions(message.Text))
+	}); err != nil {
+		return nil, err
+	}
+	return parent, nil
+}
+
+// attachAttachments はメッセージの投稿者が同じChannelにアップロードした未添付のファイルをメッセージに添付し、
+// 送信するメッセージに添付ファイルの情報を設定する
+func (muc *messageUseCase) attachAttachments(ctx context.Context, message *entity.Message) error {
+	if len(message.AttachmentIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(message.AttachmentIDs))
+	seen := make(map[string]bool, len(message.AttachmentIDs))
+	for _, id := range message.AttachmentIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	attachments, err := muc.ar.ListByIDs(ctx, ids)
+	if err != nil {
+		log.Error("Failed to list attachments", log.Ferror(err))
 		return err
 	}
+	byID := make(map[string]entity.Attachment, len(attachments))
+	for _, attachment := range attachments {
+		byID[attachment.ID] = attachment
+	}
+
+	message.Attachments = make([]entity.Attachment, 0, len(ids))
+	for _, id := range ids {
+		attachment, ok := byID[id]
+		// 他のユーザや別のChannelのファイル、添付済みのファイルは存在しないものとして扱う
+		if !ok || attachment.UserID != message.UserID || attachment.ChannelID != message.TargetID || attachment.IsAttached() {
+			log.Warn("Attachment not found", log.Fstring("attachmentID", id), log.Fstring("messageID", message.ID))
+			return ErrAttachmentNotFound
+		}
+		attachment.MessageID = message.ID
+		message.Attachments = append(message.Attachments, attachment)
+	}
+
+	attached, err := muc.ar.AttachToMessage(ctx, ids, message.ID)
+	if err != nil {
+		log.Error("Failed to attach attachments", log.Fstring("messageID", message.ID), log.Ferror(err))
+		return err
+	}
+	// 確認した後に他のメッセージに添付された場合
+	if attached != len(ids) {
+		log.Warn("Attachments were attached to another message", log.Fstring("messageID", message.ID))
+		return ErrAttachmentNotFound
+	}
+	message.AttachmentIDs = nil
+	return nil
+}
+
+// createMentions はメッセージ本文の@name, @channel, @hereをユーザに解決して保存し、送信するメッセージにメンションを設定する
+// @nameはWorkspaceのメンバーの表示名、@channelはChannelの参加者、@hereはChannelの参加者のうちonlineのユーザに解決する
+// Channelの参加者は、メッセージの配信先と同じくMembership_Channelsに登録されたユーザとする。公開Channelも参加していないユーザには配信されない
+// プライベートChannelとDMでは参加者以外はメンションせず、投稿者自身もメンションしない
+func (muc *messageUseCase) createMentions(ctx context.Context, message *entity.Message, targets entity.MentionTargets) error {
+	if targets.IsEmpty() {
+		return nil
+	}
+
+	channel, err := muc.cr.Get(ctx, message.TargetID)
+	if err != nil {
+		log.Warn("Failed to get channel", log.Fstring("channelID", message.TargetID), log.Ferror(err))
+		return ErrChannelNotFound
+	}
+	mcs, err := muc.mcr.ListByChannelID(ctx, channel.ID)
+	if err != nil {
+		log.Error("Failed to list channel members", log.Fstring("channelID", channel.ID), log.Ferror(err))
+		return err
+	}
+	members := make(map[string]bool, len(mcs))
+	for _, mc := range mcs {
+		members[mc.UserID] = true
+	}
+
+	kinds := make(map[string]string)
+	var userIDs []string
+	add := func(userID, kind string) {
+		if userID == message.UserID {
+			return
+		}
+		if _, ok := kinds[userID]; ok {
+			return
+		}
+		kinds[userID] = kind
+		userIDs = append(userIDs, userID)
+	}
+
+	// 個別のメンションを優先し、同じユーザへのメンションは一つにまとめる
+	memberships, err := muc.mbr.ListByNames(ctx, message.WorkspaceID, targets.Names)
+	if err != nil {
+		log.Error("Failed to list memberships", log.Fstring("workspaceID", message.WorkspaceID), log.Ferror(err))
+		return err
+	}
+	for _, membership := range memberships {
+		if channel.Private && !members[membership.UserID] {
+			continue
+		}
+		add(membership.UserID, entity.MentionKindUser)
+	}
+	for _, mc := range mcs {
+		if _, ok := kinds[mc.UserID]; ok || mc.UserID == message.UserID {
+			continue
+		}
+		switch {
+		case targets.Channel:
+			add(mc.UserID, entity.MentionKindChannel)
+		case targets.Here:
+			if muc.isOnline(ctx, message.WorkspaceID, mc.UserID) {
+				add(mc.UserID, entity.MentionKindHere)
+			}
+		}
+	}
+
+	mentions := make([]entity.Mention, 0, len(userIDs))
+	for _, userID := range userIDs {
+		mention, err := entity.NewMention(message.ID, userID, message.WorkspaceID, channel.ID, kinds[userID], message.CreatedAt) //nolint:govet // err shadowing
+		if err != nil {
+			return err
+		}
+		mentions = append(mentions, *mention)
+	}
+	if err = muc.mnr.BatchCreate(ctx, mentions); err != nil {
+		log.Error("Failed to create mentions", log.Fstring("messageID", message.ID), log.Ferror(err))
+		return err
+	}
+	message.Mentions = mentions
 	return nil
 }
 
+func (muc *messageUseCase) isOnline(ctx context.Context, workspaceID, userID string) bool {
+	presence, err := muc.pr.Get(ctx, workspaceID, userID)
+	if err != nil {
+		log.Warn("Failed to get presence", log.Fstring("userID", userID), log.Ferror(err))
+		return false
+	}
+	return presence.Status == entity.PresenceOnline
+}
+
+// getParent はスレッドの返信先となるメッセージを取得する。返信への返信はできない
+func (muc *messageUseCase) getParent(ctx context.Context, channelID, parentID string) (*entity.Message, error) {
+	parent, err := muc.mr.Get(ctx, parentID)
+	if err != nil {
+		log.Warn("Failed to get parent message", log.Fstring("parentID", parentID), log.Ferror(err))
+		return nil, ErrMessageNotFound
+	}
+	if parent.TargetID != channelID || parent.IsDeleted() {
+		log.Warn("Parent message does not belong to channel", log.Fstring("parentID", parentID), log.Fstring("channelID", channelID))
+		return nil, ErrMessageNotFound
+	}
+	if parent.IsReply() {
+		log.Warn("Parent message is a reply", log.Fstring("parentID", parentID))
+		return nil, ErrNestedReply
+	}
+	return parent, nil
+}
+
 func (muc *messageUseCase) UpdateMessage(ctx context.Context, message *entity.Message) error {
-	// TODO: user認証 & messageの所有権確認
-	if err := muc.mr.Update(ctx, *message); err != nil {
-		log.Error("Failed to update message", log.Ferror(err))
+	stored, err := muc.authorizeMessage(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	// 編集前の本文を編集履歴として残す
+	revision, err := entity.NewMessageRevision("", stored.ID, message.UserID, stored.Text, time.Time{})
+	if err != nil {
+		return err
+	}
+	editedAt := revision.CreatedAt
+	stored.Text = message.Text
+	stored.EditedAt = &editedAt
+
+	if err = muc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = muc.mrr.Create(ctx, *revision); err != nil {
+			log.Error("Failed to create message revision", log.Fstring("messageID", stored.ID), log.Ferror(err))
+			return err
+		}
+		if err = muc.mr.Update(ctx, *stored); err != nil {
+			log.Error("Failed to update message", log.Ferror(err))
+			return err
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
+	message.UserID = stored.UserID
+	message.CreatedAt = stored.CreatedAt
+	message.EditedAt = stored.EditedAt
 	return nil
 }
 
 func (muc *messageUseCase) DeleteMessage(ctx context.Context, message *entity.Message) error {
-	// TODO: user認証 & messageの所有権確認
-	if err := muc.mr.Delete(ctx, message.ID); err != nil {
-		log.Error("Failed to delete message", log.Ferror(err))
+	stored, err := muc.authorizeMessage(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	if err = muc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = muc.mr.Delete(ctx, stored.ID); err != nil {
+			log.Error("Failed to delete message", log.Ferror(err))
+			return err
+		}
+		// スレッドの返信を削除した場合は、返信先の返信数を更新する
+		if stored.IsReply() {
+			if err = muc.mr.RefreshReplySummary(ctx, stored.ParentID); err != nil {
+				log.Error("Failed to refresh reply summary", log.Fstring("parentID", stored.ParentID), log.Ferror(err))
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
+	message.UserID = stored.UserID
+	message.ParentID = stored.ParentID
 	return nil
 }
+
+// ListMessageRevisions はメッセージの編集履歴を返す。Workspaceの管理者のみ閲覧できる
+func (muc *messageUseCase) ListMessageRevisions(ctx context.Context, userID, workspaceID, messageID string) ([]entity.MessageRevision, error) {
+	membership, err := muc.mbr.Get(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to get membership", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	if !membership.IsAdmin {
+		log.Warn("User is not an admin of the workspace", log.Fstring("userID", userID), log.Fstring("workspaceID", workspaceID))
+		return nil, ErrNotWorkspaceAdmin
+	}
+
+	message, err := muc.mr.Get(ctx, messageID)
+	if err != nil || message.WorkspaceID != workspaceID {
+		log.Warn("Message not found", log.Fstring("messageID", messageID))
+		return nil, ErrMessageNotFound
+	}
+
+	revisions, err := muc.mrr.ListByMessageID(ctx, messageID)
+	if err != nil {
+		log.Error("Failed to list message revisions", log.Fstring("messageID", messageID), log.Ferror(err))
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// authorizeMessage は保存されているメッセージを取得し、操作するユーザが投稿者またはWorkspaceの管理者であることを確認する
+// message.UserIDには操作するユーザのIDが設定されている必要がある
+func (muc *messageUseCase) authorizeMessage(ctx context.Context, message *entity.Message) (*entity.Message, error) {
+	stored, err := muc.mr.Get(ctx, message.ID)
+	if err != nil {
+		log.Warn("Failed to get message", log.Fstring("messageID", message.ID), log.Ferror(err))
+		return nil, ErrMessageNotFound
+	}
+	// 別のChannelやWorkspaceのメッセージ、削除済みのメッセージは存在しないものとして扱う
+	if stored.TargetID != message.TargetID || stored.WorkspaceID != message.WorkspaceID || stored.IsDeleted() {
+		log.Warn("Message does not belong to channel", log.Fstring("messageID", message.ID), log.Fstring("channelID", message.TargetID))
+		return nil, ErrMessageNotFound
+	}
+	if stored.UserID == message.UserID {
+		return stored, nil
+	}
+
+	membership, err := muc.mbr.Get(ctx, message.UserID, stored.WorkspaceID)
+	if err != nil {
+		log.Error("Failed to get membership", log.Fstring("userID", message.UserID), log.Ferror(err))
+		return nil, err
+	}
+	if !membership.IsAdmin {
+		log.Warn("User is not the author of the message", log.Fstring("userID", message.UserID), log.Fstring("messageID", message.ID))
+		return nil, ErrNotMessageAuthor
+	}
+	return stored, nil
+}
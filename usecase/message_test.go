@@ -2,13 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
+	"github.com/tusmasoma/go-chat-app/config"
 	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
 	"github.com/tusmasoma/go-chat-app/repository/mock"
 )
 
@@ -18,6 +21,8 @@ func TestMessageUseCase_CreateMessage(t *testing.T) { //nolint:gocognit // ignor
 	date := time.Now()
 	channelID := uuid.New().String()
 	userID := uuid.New().String()
+	messageID := uuid.New().String()
+	attachmentID := uuid.New().String()
 	message := entity.Message{
 		UserID:    userID,
 		Text:      "test message",
@@ -25,22 +30,38 @@ func TestMessageUseCase_CreateMessage(t *testing.T) { //nolint:gocognit // ignor
 		Action:    entity.CreateMessageAction,
 		TargetID:  channelID,
 	}
+	withAttachments := func(ids ...string) *entity.Message {
+		return &entity.Message{
+			ID:            messageID,
+			UserID:        userID,
+			Text:          "test message",
+			CreatedAt:     date,
+			Action:        entity.CreateMessageAction,
+			TargetID:      channelID,
+			AttachmentIDs: ids,
+		}
+	}
 
 	patterns := []struct {
 		name  string
 		setup func(
 			mmr *mock.MockMessageRepository,
+			mtr *mock.MockTransactionRepository,
+			mar *mock.MockAttachmentRepository,
 		)
 		arg struct {
 			ctx     context.Context
 			message *entity.Message
 		}
-		wantErr error
+		wantAttachments int
+		wantErr         error
 	}{
 		{
 			name: "success",
 			setup: func(
 				mmr *mock.MockMessageRepository,
+				_ *mock.MockTransactionRepository,
+				_ *mock.MockAttachmentRepository,
 			) {
 				mmr.EXPECT().Create(
 					gomock.Any(),
@@ -72,6 +93,80 @@ func TestMessageUseCase_CreateMessage(t *testing.T) { //nolint:gocognit // ignor
 			},
 			wantErr: nil,
 		},
+		{
+			name: "success: with attachments",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				mar *mock.MockAttachmentRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mar.EXPECT().ListByIDs(gomock.Any(), []string{attachmentID}).Return(
+					[]entity.Attachment{{ID: attachmentID, UserID: userID, ChannelID: channelID}}, nil,
+				)
+				mar.EXPECT().AttachToMessage(gomock.Any(), []string{attachmentID}, messageID).Return(1, nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx:     context.Background(),
+				message: withAttachments(attachmentID, attachmentID),
+			},
+			wantAttachments: 1,
+		},
+		{
+			name: "Fail: attachment uploaded by another user",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				mar *mock.MockAttachmentRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mar.EXPECT().ListByIDs(gomock.Any(), []string{attachmentID}).Return(
+					[]entity.Attachment{{ID: attachmentID, UserID: uuid.New().String(), ChannelID: channelID}}, nil,
+				)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx:     context.Background(),
+				message: withAttachments(attachmentID),
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "Fail: attachment was attached to another message",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				mar *mock.MockAttachmentRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mar.EXPECT().ListByIDs(gomock.Any(), []string{attachmentID}).Return(
+					[]entity.Attachment{{ID: attachmentID, UserID: userID, ChannelID: channelID}}, nil,
+				)
+				mar.EXPECT().AttachToMessage(gomock.Any(), []string{attachmentID}, messageID).Return(0, nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx:     context.Background(),
+				message: withAttachments(attachmentID),
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
 	}
 	for _, tt := range patterns {
 		tt := tt
@@ -79,12 +174,17 @@ func TestMessageUseCase_CreateMessage(t *testing.T) { //nolint:gocognit // ignor
 			t.Parallel()
 			ctrl := gomock.NewController(t)
 			mr := mock.NewMockMessageRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			mrr := mock.NewMockMessageRevisionRepository(ctrl)
+			ar := mock.NewMockAttachmentRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(mr)
+				tt.setup(mr, tr, ar)
 			}
 
-			usecase := NewMessageUseCase(mr)
+			usecase := NewMessageUseCase(mr, mbr, tr, rr, mrr, ar, nil, nil, nil, nil)
 
 			err := usecase.CreateMessage(
 				tt.arg.ctx,
@@ -96,6 +196,9 @@ func TestMessageUseCase_CreateMessage(t *testing.T) { //nolint:gocognit // ignor
 			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
 				t.Errorf("MessageCreate() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if err == nil && len(tt.arg.message.Attachments) != tt.wantAttachments {
+				t.Errorf("MessageCreate() attachments = %v, want %d", tt.arg.message.Attachments, tt.wantAttachments)
+			}
 		})
 	}
 }
@@ -105,19 +208,24 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 
 	msgID := uuid.New().String()
 	channelID := uuid.New().String()
+	workspaceID := uuid.New().String()
 	userID := uuid.New().String()
-	message := entity.Message{
-		ID:       msgID,
-		UserID:   userID,
-		Text:     "updated message",
-		Action:   entity.UpdateMessageAction,
-		TargetID: channelID,
+	otherUserID := uuid.New().String()
+	stored := entity.Message{
+		ID:          msgID,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Text:        "test message",
+		TargetID:    channelID,
 	}
 
 	patterns := []struct {
 		name  string
 		setup func(
 			mmr *mock.MockMessageRepository,
+			mmbr *mock.MockMembershipRepository,
+			mtr *mock.MockTransactionRepository,
+			mmrr *mock.MockMessageRevisionRepository,
 		)
 		arg struct {
 			ctx     context.Context
@@ -129,7 +237,23 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 			name: "success",
 			setup: func(
 				mmr *mock.MockMessageRepository,
+				_ *mock.MockMembershipRepository,
+				mtr *mock.MockTransactionRepository,
+				mmrr *mock.MockMessageRevisionRepository,
 			) {
+				msg := stored
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(&msg, nil)
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmrr.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, revision entity.MessageRevision) {
+					if revision.Text != "test message" {
+						t.Errorf("unexpected revision Text: got %v, want %v", revision.Text, "test message")
+					}
+				}).Return(nil)
 				mmr.EXPECT().Update(
 					gomock.Any(),
 					gomock.Any(),
@@ -137,17 +261,84 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 					if msg.Text != "updated message" {
 						t.Errorf("unexpected Text: got %v, want %v", msg.Text, "updated message")
 					}
+					if msg.EditedAt == nil {
+						t.Error("EditedAt is nil")
+					}
 				}).Return(nil)
 			},
 			arg: struct {
 				ctx     context.Context
 				message *entity.Message
 			}{
-				ctx:     context.Background(),
-				message: &message,
+				ctx: context.Background(),
+				message: &entity.Message{
+					ID:          msgID,
+					UserID:      userID,
+					WorkspaceID: workspaceID,
+					Text:        "updated message",
+					Action:      entity.UpdateMessageAction,
+					TargetID:    channelID,
+				},
 			},
 			wantErr: nil,
 		},
+		{
+			name: "Fail: not the author",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mmbr *mock.MockMembershipRepository,
+				_ *mock.MockTransactionRepository,
+				_ *mock.MockMessageRevisionRepository,
+			) {
+				msg := stored
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(&msg, nil)
+				mmbr.EXPECT().Get(gomock.Any(), otherUserID, workspaceID).Return(
+					&entity.Membership{UserID: otherUserID, WorkspaceID: workspaceID}, nil,
+				)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx: context.Background(),
+				message: &entity.Message{
+					ID:          msgID,
+					UserID:      otherUserID,
+					WorkspaceID: workspaceID,
+					Text:        "updated message",
+					Action:      entity.UpdateMessageAction,
+					TargetID:    channelID,
+				},
+			},
+			wantErr: ErrNotMessageAuthor,
+		},
+		{
+			name: "Fail: message belongs to another channel",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				_ *mock.MockMembershipRepository,
+				_ *mock.MockTransactionRepository,
+				_ *mock.MockMessageRevisionRepository,
+			) {
+				msg := stored
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(&msg, nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx: context.Background(),
+				message: &entity.Message{
+					ID:          msgID,
+					UserID:      userID,
+					WorkspaceID: workspaceID,
+					Text:        "updated message",
+					Action:      entity.UpdateMessageAction,
+					TargetID:    uuid.New().String(),
+				},
+			},
+			wantErr: ErrMessageNotFound,
+		},
 	}
 	for _, tt := range patterns {
 		tt := tt
@@ -155,12 +346,17 @@ func TestMessageUseCase_UpdateMessage(t *testing.T) {
 			t.Parallel()
 			ctrl := gomock.NewController(t)
 			mr := mock.NewMockMessageRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			mrr := mock.NewMockMessageRevisionRepository(ctrl)
+			ar := mock.NewMockAttachmentRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(mr)
+				tt.setup(mr, mbr, tr, mrr)
 			}
 
-			usecase := NewMessageUseCase(mr)
+			usecase := NewMessageUseCase(mr, mbr, tr, rr, mrr, ar, nil, nil, nil, nil)
 
 			err := usecase.UpdateMessage(
 				tt.arg.ctx,
@@ -181,19 +377,23 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 
 	msgID := uuid.New().String()
 	channelID := uuid.New().String()
+	workspaceID := uuid.New().String()
 	userID := uuid.New().String()
-	message := entity.Message{
-		ID:       msgID,
-		UserID:   userID,
-		Text:     "test message",
-		Action:   entity.DeleteMessageAction,
-		TargetID: channelID,
+	adminID := uuid.New().String()
+	stored := entity.Message{
+		ID:          msgID,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Text:        "test message",
+		TargetID:    channelID,
 	}
 
 	patterns := []struct {
 		name  string
 		setup func(
 			mmr *mock.MockMessageRepository,
+			mmbr *mock.MockMembershipRepository,
+			mtr *mock.MockTransactionRepository,
 		)
 		arg struct {
 			ctx     context.Context
@@ -205,15 +405,60 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 			name: "success",
 			setup: func(
 				mmr *mock.MockMessageRepository,
+				_ *mock.MockMembershipRepository,
+				mtr *mock.MockTransactionRepository,
 			) {
+				msg := stored
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(&msg, nil)
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
 				mmr.EXPECT().Delete(gomock.Any(), msgID).Return(nil)
 			},
 			arg: struct {
 				ctx     context.Context
 				message *entity.Message
 			}{
-				ctx:     context.Background(),
-				message: &message,
+				ctx: context.Background(),
+				message: &entity.Message{
+					ID:          msgID,
+					UserID:      userID,
+					WorkspaceID: workspaceID,
+					Action:      entity.DeleteMessageAction,
+					TargetID:    channelID,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: workspace admin",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mmbr *mock.MockMembershipRepository,
+				mtr *mock.MockTransactionRepository,
+			) {
+				msg := stored
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(&msg, nil)
+				mmbr.EXPECT().Get(gomock.Any(), adminID, workspaceID).Return(
+					&entity.Membership{UserID: adminID, WorkspaceID: workspaceID, IsAdmin: true}, nil,
+				)
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Delete(gomock.Any(), msgID).Return(nil)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx: context.Background(),
+				message: &entity.Message{
+					ID:          msgID,
+					UserID:      adminID,
+					WorkspaceID: workspaceID,
+					Action:      entity.DeleteMessageAction,
+					TargetID:    channelID,
+				},
 			},
 			wantErr: nil,
 		},
@@ -224,12 +469,17 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 			t.Parallel()
 			ctrl := gomock.NewController(t)
 			mr := mock.NewMockMessageRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			mrr := mock.NewMockMessageRevisionRepository(ctrl)
+			ar := mock.NewMockAttachmentRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(mr)
+				tt.setup(mr, mbr, tr)
 			}
 
-			usecase := NewMessageUseCase(mr)
+			usecase := NewMessageUseCase(mr, mbr, tr, rr, mrr, ar, nil, nil, nil, nil)
 
 			err := usecase.DeleteMessage(
 				tt.arg.ctx,
@@ -244,3 +494,598 @@ func TestMessageUseCase_DeleteMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageUseCase_ListMessages(t *testing.T) {
+	t.Parallel()
+
+	channelID := uuid.New().String()
+	cursorID := uuid.New().String()
+	cursorCreatedAt := time.Now()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mrr *mock.MockReactionRepository,
+			mar *mock.MockAttachmentRepository,
+		)
+		arg struct {
+			ctx       context.Context
+			channelID string
+			before    string
+			limit     int
+		}
+		wantErr error
+	}{
+		{
+			name: "success: default limit",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				_ *mock.MockReactionRepository,
+				_ *mock.MockAttachmentRepository,
+			) {
+				mmr.EXPECT().List(
+					gomock.Any(),
+					channelID,
+					repository.ListMessagesQuery{Limit: config.DefaultMessageListLimit},
+				).Return(&entity.Messages{TargetID: channelID}, nil)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				before    string
+				limit     int
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: before cursor",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				_ *mock.MockReactionRepository,
+				_ *mock.MockAttachmentRepository,
+			) {
+				mmr.EXPECT().Get(gomock.Any(), cursorID).Return(
+					&entity.Message{ID: cursorID, TargetID: channelID, CreatedAt: cursorCreatedAt}, nil,
+				)
+				mmr.EXPECT().List(
+					gomock.Any(),
+					channelID,
+					repository.ListMessagesQuery{
+						Before: &repository.MessageCursor{CreatedAt: cursorCreatedAt, ID: cursorID},
+						Limit:  config.MaxMessageListLimit,
+					},
+				).Return(&entity.Messages{TargetID: channelID}, nil)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				before    string
+				limit     int
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				before:    cursorID,
+				limit:     config.MaxMessageListLimit + 1,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success: with reactions and attachments",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mrr *mock.MockReactionRepository,
+				mar *mock.MockAttachmentRepository,
+			) {
+				mmr.EXPECT().List(
+					gomock.Any(),
+					channelID,
+					repository.ListMessagesQuery{Limit: config.DefaultMessageListLimit},
+				).Return(&entity.Messages{
+					Messages: []*entity.Message{{ID: cursorID, TargetID: channelID}},
+					TargetID: channelID,
+				}, nil)
+				mrr.EXPECT().ListByMessageIDs(gomock.Any(), []string{cursorID}).Return(
+					[]entity.Reaction{{MessageID: cursorID, UserID: uuid.New().String(), Emoji: "👍"}}, nil,
+				)
+				mar.EXPECT().ListByMessageIDs(gomock.Any(), []string{cursorID}).Return(
+					[]entity.Attachment{{ID: uuid.New().String(), MessageID: cursorID, Name: "photo.png"}}, nil,
+				)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				before    string
+				limit     int
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: cursor belongs to another channel",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				_ *mock.MockReactionRepository,
+				_ *mock.MockAttachmentRepository,
+			) {
+				mmr.EXPECT().Get(gomock.Any(), cursorID).Return(
+					&entity.Message{ID: cursorID, TargetID: uuid.New().String(), CreatedAt: cursorCreatedAt}, nil,
+				)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				before    string
+				limit     int
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				before:    cursorID,
+			},
+			wantErr: ErrMessageNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			mrr := mock.NewMockMessageRevisionRepository(ctrl)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr, rr, ar)
+			}
+
+			usecase := NewMessageUseCase(mr, mbr, tr, rr, mrr, ar, nil, nil, nil, nil)
+
+			_, err := usecase.ListMessages(tt.arg.ctx, tt.arg.channelID, tt.arg.before, "", tt.arg.limit)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListMessages() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageUseCase_ReplyMessage(t *testing.T) {
+	t.Parallel()
+
+	parentID := uuid.New().String()
+	channelID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	lastReplyAt := time.Now()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mtr *mock.MockTransactionRepository,
+		)
+		arg struct {
+			ctx     context.Context
+			message *entity.Message
+		}
+		wantReplyCount int
+		wantErr        error
+	}{
+		{
+			name: "success",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+			) {
+				mmr.EXPECT().Get(gomock.Any(), parentID).Return(
+					&entity.Message{ID: parentID, WorkspaceID: workspaceID, TargetID: channelID}, nil,
+				)
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, msg entity.Message) {
+					if msg.ParentID != parentID {
+						t.Errorf("unexpected ParentID: got %v, want %v", msg.ParentID, parentID)
+					}
+				}).Return(nil)
+				mmr.EXPECT().RefreshReplySummary(gomock.Any(), parentID).Return(nil)
+				mmr.EXPECT().Get(gomock.Any(), parentID).Return(
+					&entity.Message{ID: parentID, WorkspaceID: workspaceID, TargetID: channelID, ReplyCount: 1, LastReplyAt: &lastReplyAt}, nil,
+				)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx: context.Background(),
+				message: &entity.Message{
+					ID:          uuid.New().String(),
+					UserID:      userID,
+					WorkspaceID: workspaceID,
+					Text:        "reply",
+					Action:      entity.ReplyMessageAction,
+					TargetID:    channelID,
+					ParentID:    parentID,
+				},
+			},
+			wantReplyCount: 1,
+			wantErr:        nil,
+		},
+		{
+			name: "Fail: reply to a reply",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				_ *mock.MockTransactionRepository,
+			) {
+				mmr.EXPECT().Get(gomock.Any(), parentID).Return(
+					&entity.Message{ID: parentID, WorkspaceID: workspaceID, TargetID: channelID, ParentID: uuid.New().String()}, nil,
+				)
+			},
+			arg: struct {
+				ctx     context.Context
+				message *entity.Message
+			}{
+				ctx: context.Background(),
+				message: &entity.Message{
+					ID:          uuid.New().String(),
+					UserID:      userID,
+					WorkspaceID: workspaceID,
+					Text:        "reply",
+					Action:      entity.ReplyMessageAction,
+					TargetID:    channelID,
+					ParentID:    parentID,
+				},
+			},
+			wantErr: ErrNestedReply,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			mrr := mock.NewMockMessageRevisionRepository(ctrl)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr, tr)
+			}
+
+			usecase := NewMessageUseCase(mr, mbr, tr, rr, mrr, ar, nil, nil, nil, nil)
+
+			parent, err := usecase.ReplyMessage(tt.arg.ctx, tt.arg.message)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ReplyMessage() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ReplyMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && parent.ReplyCount != tt.wantReplyCount {
+				t.Errorf("ReplyMessage() reply count = %d, want %d", parent.ReplyCount, tt.wantReplyCount)
+			}
+		})
+	}
+}
+
+func TestMessageUseCase_ListMessageRevisions(t *testing.T) {
+	t.Parallel()
+
+	msgID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mmbr *mock.MockMembershipRepository,
+			mmrr *mock.MockMessageRevisionRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mmbr *mock.MockMembershipRepository,
+				mmrr *mock.MockMessageRevisionRepository,
+			) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{UserID: userID, WorkspaceID: workspaceID, IsAdmin: true}, nil,
+				)
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(&entity.Message{ID: msgID, WorkspaceID: workspaceID}, nil)
+				mmrr.EXPECT().ListByMessageID(gomock.Any(), msgID).Return(
+					[]entity.MessageRevision{{ID: uuid.New().String(), MessageID: msgID, UserID: userID, Text: "before"}}, nil,
+				)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: not an admin",
+			setup: func(
+				_ *mock.MockMessageRepository,
+				mmbr *mock.MockMembershipRepository,
+				_ *mock.MockMessageRevisionRepository,
+			) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{UserID: userID, WorkspaceID: workspaceID}, nil,
+				)
+			},
+			wantErr: ErrNotWorkspaceAdmin,
+		},
+		{
+			name: "Fail: message belongs to another workspace",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mmbr *mock.MockMembershipRepository,
+				_ *mock.MockMessageRevisionRepository,
+			) {
+				mmbr.EXPECT().Get(gomock.Any(), userID, workspaceID).Return(
+					&entity.Membership{UserID: userID, WorkspaceID: workspaceID, IsAdmin: true}, nil,
+				)
+				mmr.EXPECT().Get(gomock.Any(), msgID).Return(&entity.Message{ID: msgID, WorkspaceID: uuid.New().String()}, nil)
+			},
+			wantErr: ErrMessageNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			rr := mock.NewMockReactionRepository(ctrl)
+			mrr := mock.NewMockMessageRevisionRepository(ctrl)
+			ar := mock.NewMockAttachmentRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(mr, mbr, mrr)
+			}
+
+			usecase := NewMessageUseCase(mr, mbr, tr, rr, mrr, ar, nil, nil, nil, nil)
+
+			_, err := usecase.ListMessageRevisions(context.Background(), userID, workspaceID, msgID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListMessageRevisions() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListMessageRevisions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageUseCase_CreateMessageMentions(t *testing.T) {
+	t.Parallel()
+
+	authorID := uuid.New().String()
+	aliceID := uuid.New().String()
+	bobID := uuid.New().String()
+	outsiderID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+
+	members := []entity.MembershipChannel{
+		{UserID: authorID, WorkspaceID: workspaceID, ChannelID: channelID},
+		{UserID: aliceID, WorkspaceID: workspaceID, ChannelID: channelID},
+		{UserID: bobID, WorkspaceID: workspaceID, ChannelID: channelID},
+	}
+
+	patterns := []struct {
+		name  string
+		text  string
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mtr *mock.MockTransactionRepository,
+			mmnr *mock.MockMentionRepository,
+			mmbr *mock.MockMembershipRepository,
+			mmcr *mock.MockMembershipChannelRepository,
+			mcr *mock.MockChannelRepository,
+			mpr *mock.MockPresenceRepository,
+		)
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "success: no mentions",
+			text: "Hello, World!",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				_ *mock.MockTransactionRepository,
+				_ *mock.MockMentionRepository,
+				_ *mock.MockMembershipRepository,
+				_ *mock.MockMembershipChannelRepository,
+				_ *mock.MockChannelRepository,
+				_ *mock.MockPresenceRepository,
+			) {
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "success: private channel mentions only members",
+			text: "@alice @outsider @author",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				mmnr *mock.MockMentionRepository,
+				mmbr *mock.MockMembershipRepository,
+				mmcr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockChannelRepository,
+				_ *mock.MockPresenceRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID, Private: true}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(members, nil)
+				mmbr.EXPECT().ListByNames(gomock.Any(), workspaceID, []string{"alice", "outsider", "author"}).Return([]entity.Membership{
+					{UserID: aliceID, WorkspaceID: workspaceID, Name: "alice"},
+					{UserID: outsiderID, WorkspaceID: workspaceID, Name: "outsider"},
+					{UserID: authorID, WorkspaceID: workspaceID, Name: "author"},
+				}, nil)
+				mmnr.EXPECT().BatchCreate(gomock.Any(), gomock.Len(1)).Return(nil)
+			},
+			want: map[string]string{aliceID: entity.MentionKindUser},
+		},
+		{
+			name: "success: @channel on public channel mentions the members the message is delivered to",
+			text: "@channel",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				mmnr *mock.MockMentionRepository,
+				mmbr *mock.MockMembershipRepository,
+				mmcr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockChannelRepository,
+				_ *mock.MockPresenceRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(members, nil)
+				mmbr.EXPECT().ListByNames(gomock.Any(), workspaceID, gomock.Len(0)).Return(nil, nil)
+				mmnr.EXPECT().BatchCreate(gomock.Any(), gomock.Len(2)).Return(nil)
+			},
+			want: map[string]string{aliceID: entity.MentionKindChannel, bobID: entity.MentionKindChannel},
+		},
+		{
+			name: "success: @here mentions online members and keeps individual mentions",
+			text: "@here @alice",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				mmnr *mock.MockMentionRepository,
+				mmbr *mock.MockMembershipRepository,
+				mmcr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockChannelRepository,
+				mpr *mock.MockPresenceRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(members, nil)
+				mmbr.EXPECT().ListByNames(gomock.Any(), workspaceID, []string{"alice"}).Return([]entity.Membership{
+					{UserID: aliceID, WorkspaceID: workspaceID, Name: "alice"},
+				}, nil)
+				mpr.EXPECT().Get(gomock.Any(), workspaceID, bobID).Return(&entity.Presence{UserID: bobID, Status: entity.PresenceOnline}, nil)
+				mmnr.EXPECT().BatchCreate(gomock.Any(), gomock.Len(2)).Return(nil)
+			},
+			want: map[string]string{aliceID: entity.MentionKindUser, bobID: entity.MentionKindHere},
+		},
+		{
+			name: "Fail: channel not found",
+			text: "@channel",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				_ *mock.MockMentionRepository,
+				_ *mock.MockMembershipRepository,
+				_ *mock.MockMembershipChannelRepository,
+				mcr *mock.MockChannelRepository,
+				_ *mock.MockPresenceRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(nil, ErrChannelNotFound)
+			},
+			wantErr: ErrChannelNotFound,
+		},
+		{
+			name: "Fail: failed to create mentions rolls back the message",
+			text: "@alice",
+			setup: func(
+				mmr *mock.MockMessageRepository,
+				mtr *mock.MockTransactionRepository,
+				mmnr *mock.MockMentionRepository,
+				mmbr *mock.MockMembershipRepository,
+				mmcr *mock.MockMembershipChannelRepository,
+				mcr *mock.MockChannelRepository,
+				_ *mock.MockPresenceRepository,
+			) {
+				mtr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mmr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mcr.EXPECT().Get(gomock.Any(), channelID).Return(&entity.Channel{ID: channelID, WorkspaceID: workspaceID}, nil)
+				mmcr.EXPECT().ListByChannelID(gomock.Any(), channelID).Return(members, nil)
+				mmbr.EXPECT().ListByNames(gomock.Any(), workspaceID, []string{"alice"}).Return([]entity.Membership{
+					{UserID: aliceID, WorkspaceID: workspaceID, Name: "alice"},
+				}, nil)
+				mmnr.EXPECT().BatchCreate(gomock.Any(), gomock.Len(1)).Return(errors.New("failed to create mentions"))
+			},
+			wantErr: errors.New("failed to create mentions"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			mnr := mock.NewMockMentionRepository(ctrl)
+			mbr := mock.NewMockMembershipRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+			cr := mock.NewMockChannelRepository(ctrl)
+			pr := mock.NewMockPresenceRepository(ctrl)
+
+			tt.setup(mr, tr, mnr, mbr, mcr, cr, pr)
+
+			usecase := NewMessageUseCase(mr, mbr, tr, nil, nil, nil, mnr, mcr, cr, pr)
+
+			message := &entity.Message{
+				ID:          uuid.New().String(),
+				UserID:      authorID,
+				WorkspaceID: workspaceID,
+				Text:        tt.text,
+				TargetID:    channelID,
+				CreatedAt:   time.Now(),
+			}
+			err := usecase.CreateMessage(context.Background(), message)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("CreateMessage() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("CreateMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(message.Mentions) != len(tt.want) {
+				t.Fatalf("CreateMessage() mentions = %v, want %v", message.Mentions, tt.want)
+			}
+			for _, mention := range message.Mentions {
+				if tt.want[mention.UserID] != mention.Kind {
+					t.Errorf("CreateMessage() mentions = %v, want %v", message.Mentions, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: channel.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockChannelUseCase is a mock of ChannelUseCase interface.
+type MockChannelUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockChannelUseCaseMockRecorder
+}
+
+// MockChannelUseCaseMockRecorder is the mock recorder for MockChannelUseCase.
+type MockChannelUseCaseMockRecorder struct {
+	mock *MockChannelUseCase
+}
+
+// NewMockChannelUseCase creates a new mock instance.
+func NewMockChannelUseCase(ctrl *gomock.Controller) *MockChannelUseCase {
+	mock := &MockChannelUseCase{ctrl: ctrl}
+	mock.recorder = &MockChannelUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChannelUseCase) EXPECT() *MockChannelUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateChannel mocks base method.
+func (m *MockChannelUseCase) CreateChannel(ctx context.Context, userID, workspaceID, name string, private bool) (*entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChannel", ctx, userID, workspaceID, name, private)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChannel indicates an expected call of CreateChannel.
+func (mr *MockChannelUseCaseMockRecorder) CreateChannel(ctx, userID, workspaceID, name, private interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockChannelUseCase)(nil).CreateChannel), ctx, userID, workspaceID, name, private)
+}
+
+// DeleteChannel mocks base method.
+func (m *MockChannelUseCase) DeleteChannel(ctx context.Context, userID, workspaceID, channelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChannel", ctx, userID, workspaceID, channelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChannel indicates an expected call of DeleteChannel.
+func (mr *MockChannelUseCaseMockRecorder) DeleteChannel(ctx, userID, workspaceID, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannel", reflect.TypeOf((*MockChannelUseCase)(nil).DeleteChannel), ctx, userID, workspaceID, channelID)
+}
+
+// GetAccessibleChannel mocks base method.
+func (m *MockChannelUseCase) GetAccessibleChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessibleChannel", ctx, userID, workspaceID, channelID)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessibleChannel indicates an expected call of GetAccessibleChannel.
+func (mr *MockChannelUseCaseMockRecorder) GetAccessibleChannel(ctx, userID, workspaceID, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessibleChannel", reflect.TypeOf((*MockChannelUseCase)(nil).GetAccessibleChannel), ctx, userID, workspaceID, channelID)
+}
+
+// GetChannel mocks base method.
+func (m *MockChannelUseCase) GetChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannel", ctx, userID, workspaceID, channelID)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannel indicates an expected call of GetChannel.
+func (mr *MockChannelUseCaseMockRecorder) GetChannel(ctx, userID, workspaceID, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannel", reflect.TypeOf((*MockChannelUseCase)(nil).GetChannel), ctx, userID, workspaceID, channelID)
+}
+
+// InviteMember mocks base method.
+func (m *MockChannelUseCase) InviteMember(ctx context.Context, userID, workspaceID, channelID, inviteeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteMember", ctx, userID, workspaceID, channelID, inviteeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InviteMember indicates an expected call of InviteMember.
+func (mr *MockChannelUseCaseMockRecorder) InviteMember(ctx, userID, workspaceID, channelID, inviteeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteMember", reflect.TypeOf((*MockChannelUseCase)(nil).InviteMember), ctx, userID, workspaceID, channelID, inviteeID)
+}
+
+// JoinChannel mocks base method.
+func (m *MockChannelUseCase) JoinChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinChannel", ctx, userID, workspaceID, channelID)
+	ret0, _ := ret[0].(*entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinChannel indicates an expected call of JoinChannel.
+func (mr *MockChannelUseCaseMockRecorder) JoinChannel(ctx, userID, workspaceID, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinChannel", reflect.TypeOf((*MockChannelUseCase)(nil).JoinChannel), ctx, userID, workspaceID, channelID)
+}
+
+// LeaveChannel mocks base method.
+func (m *MockChannelUseCase) LeaveChannel(ctx context.Context, userID, workspaceID, channelID string) (*entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaveChannel", ctx, userID, workspaceID, channelID)
+	ret0, _ := ret[0].(*entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaveChannel indicates an expected call of LeaveChannel.
+func (mr *MockChannelUseCaseMockRecorder) LeaveChannel(ctx, userID, workspaceID, channelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveChannel", reflect.TypeOf((*MockChannelUseCase)(nil).LeaveChannel), ctx, userID, workspaceID, channelID)
+}
+
+// ListChannels mocks base method.
+func (m *MockChannelUseCase) ListChannels(ctx context.Context, userID, workspaceID string) ([]entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChannels", ctx, userID, workspaceID)
+	ret0, _ := ret[0].([]entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChannels indicates an expected call of ListChannels.
+func (mr *MockChannelUseCaseMockRecorder) ListChannels(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChannels", reflect.TypeOf((*MockChannelUseCase)(nil).ListChannels), ctx, userID, workspaceID)
+}
+
+// ListDirectMessages mocks base method.
+func (m *MockChannelUseCase) ListDirectMessages(ctx context.Context, userID, workspaceID string) ([]entity.DirectMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDirectMessages", ctx, userID, workspaceID)
+	ret0, _ := ret[0].([]entity.DirectMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDirectMessages indicates an expected call of ListDirectMessages.
+func (mr *MockChannelUseCaseMockRecorder) ListDirectMessages(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDirectMessages", reflect.TypeOf((*MockChannelUseCase)(nil).ListDirectMessages), ctx, userID, workspaceID)
+}
+
+// ListJoinedChannels mocks base method.
+func (m *MockChannelUseCase) ListJoinedChannels(ctx context.Context, userID, workspaceID string) ([]entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJoinedChannels", ctx, userID, workspaceID)
+	ret0, _ := ret[0].([]entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJoinedChannels indicates an expected call of ListJoinedChannels.
+func (mr *MockChannelUseCaseMockRecorder) ListJoinedChannels(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJoinedChannels", reflect.TypeOf((*MockChannelUseCase)(nil).ListJoinedChannels), ctx, userID, workspaceID)
+}
+
+// OpenDirectMessage mocks base method.
+func (m *MockChannelUseCase) OpenDirectMessage(ctx context.Context, userID, workspaceID string, userIDs []string) (*entity.DirectMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDirectMessage", ctx, userID, workspaceID, userIDs)
+	ret0, _ := ret[0].(*entity.DirectMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDirectMessage indicates an expected call of OpenDirectMessage.
+func (mr *MockChannelUseCaseMockRecorder) OpenDirectMessage(ctx, userID, workspaceID, userIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDirectMessage", reflect.TypeOf((*MockChannelUseCase)(nil).OpenDirectMessage), ctx, userID, workspaceID, userIDs)
+}
+
+// RemoveMember mocks base method.
+func (m *MockChannelUseCase) RemoveMember(ctx context.Context, userID, workspaceID, channelID, memberID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, userID, workspaceID, channelID, memberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockChannelUseCaseMockRecorder) RemoveMember(ctx, userID, workspaceID, channelID, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockChannelUseCase)(nil).RemoveMember), ctx, userID, workspaceID, channelID, memberID)
+}
+
+// UpdateChannel mocks base method.
+func (m *MockChannelUseCase) UpdateChannel(ctx context.Context, userID, workspaceID, channelID, name string) (*entity.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChannel", ctx, userID, workspaceID, channelID, name)
+	ret0, _ := ret[0].(*entity.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateChannel indicates an expected call of UpdateChannel.
+func (mr *MockChannelUseCaseMockRecorder) UpdateChannel(ctx, userID, workspaceID, channelID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChannel", reflect.TypeOf((*MockChannelUseCase)(nil).UpdateChannel), ctx, userID, workspaceID, channelID, name)
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: mention.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockMentionUseCase is a mock of MentionUseCase interface.
+type MockMentionUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockMentionUseCaseMockRecorder
+}
+
+// MockMentionUseCaseMockRecorder is the mock recorder for MockMentionUseCase.
+type MockMentionUseCaseMockRecorder struct {
+	mock *MockMentionUseCase
+}
+
+// NewMockMentionUseCase creates a new mock instance.
+func NewMockMentionUseCase(ctrl *gomock.Controller) *MockMentionUseCase {
+	mock := &MockMentionUseCase{ctrl: ctrl}
+	mock.recorder = &MockMentionUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMentionUseCase) EXPECT() *MockMentionUseCaseMockRecorder {
+	return m.recorder
+}
+
+// ListMentions mocks base method.
+func (m *MockMentionUseCase) ListMentions(ctx context.Context, userID, before string, limit int) ([]entity.MentionedMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMentions", ctx, userID, before, limit)
+	ret0, _ := ret[0].([]entity.MentionedMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMentions indicates an expected call of ListMentions.
+func (mr *MockMentionUseCaseMockRecorder) ListMentions(ctx, userID, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMentions", reflect.TypeOf((*MockMentionUseCase)(nil).ListMentions), ctx, userID, before, limit)
+}
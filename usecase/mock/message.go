@@ -64,6 +64,66 @@ func (mr *MockMessageUseCaseMockRecorder) DeleteMessage(ctx, message interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockMessageUseCase)(nil).DeleteMessage), ctx, message)
 }
 
+// ListMessageRevisions mocks base method.
+func (m *MockMessageUseCase) ListMessageRevisions(ctx context.Context, userID, workspaceID, messageID string) ([]entity.MessageRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessageRevisions", ctx, userID, workspaceID, messageID)
+	ret0, _ := ret[0].([]entity.MessageRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessageRevisions indicates an expected call of ListMessageRevisions.
+func (mr *MockMessageUseCaseMockRecorder) ListMessageRevisions(ctx, userID, workspaceID, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessageRevisions", reflect.TypeOf((*MockMessageUseCase)(nil).ListMessageRevisions), ctx, userID, workspaceID, messageID)
+}
+
+// ListMessages mocks base method.
+func (m *MockMessageUseCase) ListMessages(ctx context.Context, channelID, before, after string, limit int) (*entity.Messages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessages", ctx, channelID, before, after, limit)
+	ret0, _ := ret[0].(*entity.Messages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessages indicates an expected call of ListMessages.
+func (mr *MockMessageUseCaseMockRecorder) ListMessages(ctx, channelID, before, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessages", reflect.TypeOf((*MockMessageUseCase)(nil).ListMessages), ctx, channelID, before, after, limit)
+}
+
+// ListThreadMessages mocks base method.
+func (m *MockMessageUseCase) ListThreadMessages(ctx context.Context, channelID, parentID, before, after string, limit int) (*entity.Messages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListThreadMessages", ctx, channelID, parentID, before, after, limit)
+	ret0, _ := ret[0].(*entity.Messages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListThreadMessages indicates an expected call of ListThreadMessages.
+func (mr *MockMessageUseCaseMockRecorder) ListThreadMessages(ctx, channelID, parentID, before, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListThreadMessages", reflect.TypeOf((*MockMessageUseCase)(nil).ListThreadMessages), ctx, channelID, parentID, before, after, limit)
+}
+
+// ReplyMessage mocks base method.
+func (m *MockMessageUseCase) ReplyMessage(ctx context.Context, message *entity.Message) (*entity.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplyMessage", ctx, message)
+	ret0, _ := ret[0].(*entity.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplyMessage indicates an expected call of ReplyMessage.
+func (mr *MockMessageUseCaseMockRecorder) ReplyMessage(ctx, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplyMessage", reflect.TypeOf((*MockMessageUseCase)(nil).ReplyMessage), ctx, message)
+}
+
 // UpdateMessage mocks base method.
 func (m *MockMessageUseCase) UpdateMessage(ctx context.Context, message *entity.Message) error {
 	m.ctrl.T.Helper()
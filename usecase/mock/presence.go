@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: presence.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockPresenceUseCase is a mock of PresenceUseCase interface.
+type MockPresenceUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPresenceUseCaseMockRecorder
+}
+
+// MockPresenceUseCaseMockRecorder is the mock recorder for MockPresenceUseCase.
+type MockPresenceUseCaseMockRecorder struct {
+	mock *MockPresenceUseCase
+}
+
+// NewMockPresenceUseCase creates a new mock instance.
+func NewMockPresenceUseCase(ctrl *gomock.Controller) *MockPresenceUseCase {
+	mock := &MockPresenceUseCase{ctrl: ctrl}
+	mock.recorder = &MockPresenceUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPresenceUseCase) EXPECT() *MockPresenceUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Disconnect mocks base method.
+func (m *MockPresenceUseCase) Disconnect(ctx context.Context, workspaceID, userID, connectionID string) (*entity.Presence, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disconnect", ctx, workspaceID, userID, connectionID)
+	ret0, _ := ret[0].(*entity.Presence)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Disconnect indicates an expected call of Disconnect.
+func (mr *MockPresenceUseCaseMockRecorder) Disconnect(ctx, workspaceID, userID, connectionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnect", reflect.TypeOf((*MockPresenceUseCase)(nil).Disconnect), ctx, workspaceID, userID, connectionID)
+}
+
+// Heartbeat mocks base method.
+func (m *MockPresenceUseCase) Heartbeat(ctx context.Context, workspaceID, userID, connectionID, status string) (*entity.Presence, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Heartbeat", ctx, workspaceID, userID, connectionID, status)
+	ret0, _ := ret[0].(*entity.Presence)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Heartbeat indicates an expected call of Heartbeat.
+func (mr *MockPresenceUseCaseMockRecorder) Heartbeat(ctx, workspaceID, userID, connectionID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Heartbeat", reflect.TypeOf((*MockPresenceUseCase)(nil).Heartbeat), ctx, workspaceID, userID, connectionID, status)
+}
+
+// ListPresences mocks base method.
+func (m *MockPresenceUseCase) ListPresences(ctx context.Context, workspaceID string) ([]entity.Presence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPresences", ctx, workspaceID)
+	ret0, _ := ret[0].([]entity.Presence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPresences indicates an expected call of ListPresences.
+func (mr *MockPresenceUseCaseMockRecorder) ListPresences(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPresences", reflect.TypeOf((*MockPresenceUseCase)(nil).ListPresences), ctx, workspaceID)
+}
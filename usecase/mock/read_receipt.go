@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: read_receipt.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
+)
+
+// MockReadReceiptUseCase is a mock of ReadReceiptUseCase interface.
+type MockReadReceiptUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadReceiptUseCaseMockRecorder
+}
+
+// MockReadReceiptUseCaseMockRecorder is the mock recorder for MockReadReceiptUseCase.
+type MockReadReceiptUseCaseMockRecorder struct {
+	mock *MockReadReceiptUseCase
+}
+
+// NewMockReadReceiptUseCase creates a new mock instance.
+func NewMockReadReceiptUseCase(ctrl *gomock.Controller) *MockReadReceiptUseCase {
+	mock := &MockReadReceiptUseCase{ctrl: ctrl}
+	mock.recorder = &MockReadReceiptUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadReceiptUseCase) EXPECT() *MockReadReceiptUseCaseMockRecorder {
+	return m.recorder
+}
+
+// ListUnreadCounts mocks base method.
+func (m *MockReadReceiptUseCase) ListUnreadCounts(ctx context.Context, userID, workspaceID string, channelIDs []string) ([]entity.UnreadCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnreadCounts", ctx, userID, workspaceID, channelIDs)
+	ret0, _ := ret[0].([]entity.UnreadCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnreadCounts indicates an expected call of ListUnreadCounts.
+func (mr *MockReadReceiptUseCaseMockRecorder) ListUnreadCounts(ctx, userID, workspaceID, channelIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnreadCounts", reflect.TypeOf((*MockReadReceiptUseCase)(nil).ListUnreadCounts), ctx, userID, workspaceID, channelIDs)
+}
+
+// MarkRead mocks base method.
+func (m *MockReadReceiptUseCase) MarkRead(ctx context.Context, userID, workspaceID, channelID, messageID string) (*entity.ReadReceipt, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, userID, workspaceID, channelID, messageID)
+	ret0, _ := ret[0].(*entity.ReadReceipt)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockReadReceiptUseCaseMockRecorder) MarkRead(ctx, userID, workspaceID, channelID, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockReadReceiptUseCase)(nil).MarkRead), ctx, userID, workspaceID, channelID, messageID)
+}
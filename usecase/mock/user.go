@@ -9,6 +9,8 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+
+	entity "github.com/tusmasoma/go-chat-app/entity"
 )
 
 // MockUserUseCase is a mock of UserUseCase interface.
@@ -34,26 +36,203 @@ func (m *MockUserUseCase) EXPECT() *MockUserUseCaseMockRecorder {
 	return m.recorder
 }
 
-// LoginAndGenerateToken mocks base method.
-func (m *MockUserUseCase) LoginAndGenerateToken(ctx context.Context, email, password string) (string, error) {
+// ActivateTwoFactor mocks base method.
+func (m *MockUserUseCase) ActivateTwoFactor(ctx context.Context, userID, code string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoginAndGenerateToken", ctx, email, password)
+	ret := m.ctrl.Call(m, "ActivateTwoFactor", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivateTwoFactor indicates an expected call of ActivateTwoFactor.
+func (mr *MockUserUseCaseMockRecorder) ActivateTwoFactor(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateTwoFactor", reflect.TypeOf((*MockUserUseCase)(nil).ActivateTwoFactor), ctx, userID, code)
+}
+
+// BeginOIDCLogin mocks base method.
+func (m *MockUserUseCase) BeginOIDCLogin(ctx context.Context) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginOIDCLogin", ctx)
 	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginOIDCLogin indicates an expected call of BeginOIDCLogin.
+func (mr *MockUserUseCaseMockRecorder) BeginOIDCLogin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginOIDCLogin", reflect.TypeOf((*MockUserUseCase)(nil).BeginOIDCLogin), ctx)
+}
+
+// CompleteTwoFactorLogin mocks base method.
+func (m *MockUserUseCase) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (*entity.AuthTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactorLogin", ctx, challengeToken, code)
+	ret0, _ := ret[0].(*entity.AuthTokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// CompleteTwoFactorLogin indicates an expected call of CompleteTwoFactorLogin.
+func (mr *MockUserUseCaseMockRecorder) CompleteTwoFactorLogin(ctx, challengeToken, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactorLogin", reflect.TypeOf((*MockUserUseCase)(nil).CompleteTwoFactorLogin), ctx, challengeToken, code)
+}
+
+// DisableTwoFactor mocks base method.
+func (m *MockUserUseCase) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockUserUseCaseMockRecorder) DisableTwoFactor(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockUserUseCase)(nil).DisableTwoFactor), ctx, userID, code)
+}
+
+// EnrollTwoFactor mocks base method.
+func (m *MockUserUseCase) EnrollTwoFactor(ctx context.Context, userID string) (*entity.TwoFactorEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTwoFactor", ctx, userID)
+	ret0, _ := ret[0].(*entity.TwoFactorEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTwoFactor indicates an expected call of EnrollTwoFactor.
+func (mr *MockUserUseCaseMockRecorder) EnrollTwoFactor(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTwoFactor", reflect.TypeOf((*MockUserUseCase)(nil).EnrollTwoFactor), ctx, userID)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockUserUseCase) ForgotPassword(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockUserUseCaseMockRecorder) ForgotPassword(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockUserUseCase)(nil).ForgotPassword), ctx, email)
+}
+
+// GetJWKS mocks base method.
+func (m *MockUserUseCase) GetJWKS(ctx context.Context) entity.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS", ctx)
+	ret0, _ := ret[0].(entity.JWKSet)
+	return ret0
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockUserUseCaseMockRecorder) GetJWKS(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockUserUseCase)(nil).GetJWKS), ctx)
+}
+
+// LoginAndGenerateToken mocks base method.
+func (m *MockUserUseCase) LoginAndGenerateToken(ctx context.Context, email, password string) (*entity.AuthTokens, *entity.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginAndGenerateToken", ctx, email, password)
+	ret0, _ := ret[0].(*entity.AuthTokens)
+	ret1, _ := ret[1].(*entity.TwoFactorChallenge)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // LoginAndGenerateToken indicates an expected call of LoginAndGenerateToken.
 func (mr *MockUserUseCaseMockRecorder) LoginAndGenerateToken(ctx, email, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).LoginAndGenerateToken), ctx, email, password)
 }
 
+// Logout mocks base method.
+func (m *MockUserUseCase) Logout(ctx context.Context, userID, jti, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, userID, jti, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUserUseCaseMockRecorder) Logout(ctx, userID, jti, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUserUseCase)(nil).Logout), ctx, userID, jti, refreshToken)
+}
+
+// OIDCLoginAndGenerateToken mocks base method.
+func (m *MockUserUseCase) OIDCLoginAndGenerateToken(ctx context.Context, state, code string) (*entity.AuthTokens, *entity.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDCLoginAndGenerateToken", ctx, state, code)
+	ret0, _ := ret[0].(*entity.AuthTokens)
+	ret1, _ := ret[1].(*entity.TwoFactorChallenge)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OIDCLoginAndGenerateToken indicates an expected call of OIDCLoginAndGenerateToken.
+func (mr *MockUserUseCaseMockRecorder) OIDCLoginAndGenerateToken(ctx, state, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDCLoginAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).OIDCLoginAndGenerateToken), ctx, state, code)
+}
+
+// RefreshToken mocks base method.
+func (m *MockUserUseCase) RefreshToken(ctx context.Context, refreshToken string) (*entity.AuthTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(*entity.AuthTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockUserUseCaseMockRecorder) RefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockUserUseCase)(nil).RefreshToken), ctx, refreshToken)
+}
+
+// ResendVerificationEmail mocks base method.
+func (m *MockUserUseCase) ResendVerificationEmail(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerificationEmail", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendVerificationEmail indicates an expected call of ResendVerificationEmail.
+func (mr *MockUserUseCaseMockRecorder) ResendVerificationEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerificationEmail", reflect.TypeOf((*MockUserUseCase)(nil).ResendVerificationEmail), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserUseCase) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserUseCaseMockRecorder) ResetPassword(ctx, token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserUseCase)(nil).ResetPassword), ctx, token, password)
+}
+
 // SignUpAndGenerateToken mocks base method.
-func (m *MockUserUseCase) SignUpAndGenerateToken(ctx context.Context, email, passward string) (string, error) {
+func (m *MockUserUseCase) SignUpAndGenerateToken(ctx context.Context, email, passward string) (*entity.AuthTokens, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SignUpAndGenerateToken", ctx, email, passward)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(*entity.AuthTokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -63,3 +242,17 @@ func (mr *MockUserUseCaseMockRecorder) SignUpAndGenerateToken(ctx, email, passwa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpAndGenerateToken", reflect.TypeOf((*MockUserUseCase)(nil).SignUpAndGenerateToken), ctx, email, passward)
 }
+
+// VerifyEmail mocks base method.
+func (m *MockUserUseCase) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserUseCaseMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserUseCase)(nil).VerifyEmail), ctx, token)
+}
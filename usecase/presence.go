@@ -0,0 +1,78 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+var ErrInvalidPresenceStatus = errors.New("invalid presence status")
+
+type PresenceUseCase interface {
+	Heartbeat(ctx context.Context, workspaceID, userID, connectionID, status string) (*entity.Presence, bool, error)
+	Disconnect(ctx context.Context, workspaceID, userID, connectionID string) (*entity.Presence, bool, error)
+	ListPresences(ctx context.Context, workspaceID string) ([]entity.Presence, error)
+}
+
+type presenceUseCase struct {
+	pr repository.PresenceRepository
+}
+
+func NewPresenceUseCase(pr repository.PresenceRepository) PresenceUseCase {
+	return &presenceUseCase{
+		pr: pr,
+	}
+}
+
+// Heartbeat は接続の状態を記録してTTLを延長し、ユーザの状態とその状態が変化したかを返す
+func (puc *presenceUseCase) Heartbeat(ctx context.Context, workspaceID, userID, connectionID, status string) (*entity.Presence, bool, error) {
+	if !entity.IsValidPresenceStatus(status) {
+		log.Warn("Invalid presence status", log.Fstring("status", status))
+		return nil, false, ErrInvalidPresenceStatus
+	}
+	return puc.update(ctx, workspaceID, userID, func() error {
+		return puc.pr.Heartbeat(ctx, workspaceID, userID, connectionID, status, config.PresenceTTL)
+	})
+}
+
+// Disconnect は接続を取り除き、ユーザの状態とその状態が変化したかを返す
+func (puc *presenceUseCase) Disconnect(ctx context.Context, workspaceID, userID, connectionID string) (*entity.Presence, bool, error) {
+	return puc.update(ctx, workspaceID, userID, func() error {
+		return puc.pr.Remove(ctx, workspaceID, userID, connectionID)
+	})
+}
+
+// ListPresences はWorkspaceでonlineまたはawayのユーザの状態を返す
+// 含まれないユーザはofflineである
+func (puc *presenceUseCase) ListPresences(ctx context.Context, workspaceID string) ([]entity.Presence, error) {
+	presences, err := puc.pr.List(ctx, workspaceID)
+	if err != nil {
+		log.Error("Failed to list presences", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		return nil, err
+	}
+	return presences, nil
+}
+
+func (puc *presenceUseCase) update(ctx context.Context, workspaceID, userID string, fn func() error) (*entity.Presence, bool, error) {
+	before, err := puc.pr.Get(ctx, workspaceID, userID)
+	if err != nil {
+		log.Error("Failed to get presence", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, false, err
+	}
+	if err = fn(); err != nil {
+		log.Error("Failed to update presence", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, false, err
+	}
+	after, err := puc.pr.Get(ctx, workspaceID, userID)
+	if err != nil {
+		log.Error("Failed to get presence", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, false, err
+	}
+	return after, before.Status != after.Status, nil
+}
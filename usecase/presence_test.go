@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func TestPresenceUseCase_Heartbeat(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	connectionID := uuid.New().String()
+
+	presence := func(statuses ...string) *entity.Presence {
+		p, _ := entity.NewPresence(workspaceID, userID, statuses)
+		return p
+	}
+
+	patterns := []struct {
+		name        string
+		setup       func(mpr *mock.MockPresenceRepository)
+		status      string
+		wantChanged bool
+		wantErr     error
+	}{
+		{
+			name: "success: user comes online",
+			setup: func(mpr *mock.MockPresenceRepository) {
+				gomock.InOrder(
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(), nil),
+					mpr.EXPECT().Heartbeat(gomock.Any(), workspaceID, userID, connectionID, entity.PresenceOnline, config.PresenceTTL).Return(nil),
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(entity.PresenceOnline), nil),
+				)
+			},
+			status:      entity.PresenceOnline,
+			wantChanged: true,
+		},
+		{
+			name: "success: another connection keeps user online",
+			setup: func(mpr *mock.MockPresenceRepository) {
+				gomock.InOrder(
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(entity.PresenceOnline), nil),
+					mpr.EXPECT().Heartbeat(gomock.Any(), workspaceID, userID, connectionID, entity.PresenceAway, config.PresenceTTL).Return(nil),
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(entity.PresenceOnline, entity.PresenceAway), nil),
+				)
+			},
+			status:      entity.PresenceAway,
+			wantChanged: false,
+		},
+		{
+			name:    "Fail: invalid status",
+			status:  entity.PresenceOffline,
+			wantErr: ErrInvalidPresenceStatus,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			pr := mock.NewMockPresenceRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(pr)
+			}
+
+			usecase := NewPresenceUseCase(pr)
+
+			_, changed, err := usecase.Heartbeat(context.Background(), workspaceID, userID, connectionID, tt.status)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Heartbeat() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("Heartbeat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("Heartbeat() changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestPresenceUseCase_Disconnect(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	userID := uuid.New().String()
+	connectionID := uuid.New().String()
+
+	presence := func(statuses ...string) *entity.Presence {
+		p, _ := entity.NewPresence(workspaceID, userID, statuses)
+		return p
+	}
+
+	patterns := []struct {
+		name        string
+		setup       func(mpr *mock.MockPresenceRepository)
+		wantStatus  string
+		wantChanged bool
+	}{
+		{
+			name: "success: last connection goes offline",
+			setup: func(mpr *mock.MockPresenceRepository) {
+				gomock.InOrder(
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(entity.PresenceOnline), nil),
+					mpr.EXPECT().Remove(gomock.Any(), workspaceID, userID, connectionID).Return(nil),
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(), nil),
+				)
+			},
+			wantStatus:  entity.PresenceOffline,
+			wantChanged: true,
+		},
+		{
+			name: "success: other connections remain",
+			setup: func(mpr *mock.MockPresenceRepository) {
+				gomock.InOrder(
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(entity.PresenceOnline, entity.PresenceOnline), nil),
+					mpr.EXPECT().Remove(gomock.Any(), workspaceID, userID, connectionID).Return(nil),
+					mpr.EXPECT().Get(gomock.Any(), workspaceID, userID).Return(presence(entity.PresenceOnline), nil),
+				)
+			},
+			wantStatus:  entity.PresenceOnline,
+			wantChanged: false,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			pr := mock.NewMockPresenceRepository(ctrl)
+
+			tt.setup(pr)
+
+			usecase := NewPresenceUseCase(pr)
+
+			got, changed, err := usecase.Disconnect(context.Background(), workspaceID, userID, connectionID)
+			if err != nil {
+				t.Fatalf("Disconnect() error = %v", err)
+			}
+			if got.Status != tt.wantStatus || changed != tt.wantChanged {
+				t.Errorf("Disconnect() got = %v, %v, want %v, %v", got.Status, changed, tt.wantStatus, tt.wantChanged)
+			}
+		})
+	}
+}
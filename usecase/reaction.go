@@ -0,0 +1,90 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+var ErrInvalidReaction = errors.New("invalid reaction")
+
+type ReactionUseCase interface {
+	AddReaction(ctx context.Context, userID, workspaceID, channelID, messageID, emoji string) ([]entity.ReactionCount, error)
+	RemoveReaction(ctx context.Context, userID, workspaceID, channelID, messageID, emoji string) ([]entity.ReactionCount, error)
+}
+
+type reactionUseCase struct {
+	rr repository.ReactionRepository
+	mr repository.MessageRepository
+}
+
+func NewReactionUseCase(rr repository.ReactionRepository, mr repository.MessageRepository) ReactionUseCase {
+	return &reactionUseCase{
+		rr: rr,
+		mr: mr,
+	}
+}
+
+// AddReaction はメッセージにリアクションを付け、集計後のリアクションを返す
+func (ruc *reactionUseCase) AddReaction(ctx context.Context, userID, workspaceID, channelID, messageID, emoji string) ([]entity.ReactionCount, error) {
+	if err := ruc.checkMessage(ctx, workspaceID, channelID, messageID); err != nil {
+		return nil, err
+	}
+
+	reaction, err := entity.NewReaction(messageID, userID, emoji, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReaction, err)
+	}
+	if err = ruc.rr.Create(ctx, *reaction); err != nil {
+		log.Error("Failed to create reaction", log.Fstring("messageID", messageID), log.Ferror(err))
+		return nil, err
+	}
+	return ruc.countReactions(ctx, messageID)
+}
+
+// RemoveReaction はメッセージからリアクションを外し、集計後のリアクションを返す
+func (ruc *reactionUseCase) RemoveReaction(ctx context.Context, userID, workspaceID, channelID, messageID, emoji string) ([]entity.ReactionCount, error) {
+	if err := ruc.checkMessage(ctx, workspaceID, channelID, messageID); err != nil {
+		return nil, err
+	}
+
+	if err := ruc.rr.Delete(ctx, messageID, userID, emoji); err != nil {
+		log.Error("Failed to delete reaction", log.Fstring("messageID", messageID), log.Ferror(err))
+		return nil, err
+	}
+	return ruc.countReactions(ctx, messageID)
+}
+
+// checkMessage はリアクション対象のメッセージがChannelに属していることを確認する
+func (ruc *reactionUseCase) checkMessage(ctx context.Context, workspaceID, channelID, messageID string) error {
+	message, err := ruc.mr.Get(ctx, messageID)
+	if err != nil {
+		log.Warn("Failed to get message", log.Fstring("messageID", messageID), log.Ferror(err))
+		return ErrMessageNotFound
+	}
+	if message.TargetID != channelID || message.WorkspaceID != workspaceID || message.IsDeleted() {
+		log.Warn("Message does not belong to channel", log.Fstring("messageID", messageID), log.Fstring("channelID", channelID))
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
+func (ruc *reactionUseCase) countReactions(ctx context.Context, messageID string) ([]entity.ReactionCount, error) {
+	reactions, err := ruc.rr.ListByMessageIDs(ctx, []string{messageID})
+	if err != nil {
+		log.Error("Failed to list reactions", log.Fstring("messageID", messageID), log.Ferror(err))
+		return nil, err
+	}
+	counts := entity.NewReactionCounts(reactions)[messageID]
+	if counts == nil {
+		return []entity.ReactionCount{}, nil
+	}
+	return counts, nil
+}
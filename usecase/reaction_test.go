@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func TestReactionUseCase_AddReaction(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mrr *mock.MockReactionRepository,
+			mmr *mock.MockMessageRepository,
+		)
+		arg struct {
+			ctx       context.Context
+			channelID string
+			emoji     string
+		}
+		want    []entity.ReactionCount
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				mrr *mock.MockReactionRepository,
+				mmr *mock.MockMessageRepository,
+			) {
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, WorkspaceID: workspaceID, TargetID: channelID}, nil,
+				)
+				mrr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				mrr.EXPECT().ListByMessageIDs(gomock.Any(), []string{messageID}).Return(
+					[]entity.Reaction{{MessageID: messageID, UserID: userID, Emoji: "👍"}}, nil,
+				)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				emoji     string
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				emoji:     "👍",
+			},
+			want:    []entity.ReactionCount{{Emoji: "👍", Count: 1, UserIDs: []string{userID}}},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: message belongs to another channel",
+			setup: func(
+				_ *mock.MockReactionRepository,
+				mmr *mock.MockMessageRepository,
+			) {
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, WorkspaceID: workspaceID, TargetID: uuid.New().String()}, nil,
+				)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				emoji     string
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				emoji:     "👍",
+			},
+			wantErr: ErrMessageNotFound,
+		},
+		{
+			name: "Fail: emoji is empty",
+			setup: func(
+				_ *mock.MockReactionRepository,
+				mmr *mock.MockMessageRepository,
+			) {
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, WorkspaceID: workspaceID, TargetID: channelID}, nil,
+				)
+			},
+			arg: struct {
+				ctx       context.Context
+				channelID string
+				emoji     string
+			}{
+				ctx:       context.Background(),
+				channelID: channelID,
+				emoji:     "",
+			},
+			wantErr: fmt.Errorf("%w: emoji is required", ErrInvalidReaction),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			rr := mock.NewMockReactionRepository(ctrl)
+			mr := mock.NewMockMessageRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(rr, mr)
+			}
+
+			usecase := NewReactionUseCase(rr, mr)
+
+			got, err := usecase.AddReaction(tt.arg.ctx, userID, workspaceID, tt.arg.channelID, messageID, tt.arg.emoji)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("AddReaction() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("AddReaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (len(got) != len(tt.want) || got[0].Count != tt.want[0].Count) {
+				t.Errorf("AddReaction() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReactionUseCase_RemoveReaction(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+
+	ctrl := gomock.NewController(t)
+	rr := mock.NewMockReactionRepository(ctrl)
+	mr := mock.NewMockMessageRepository(ctrl)
+
+	mr.EXPECT().Get(gomock.Any(), messageID).Return(
+		&entity.Message{ID: messageID, WorkspaceID: workspaceID, TargetID: channelID}, nil,
+	)
+	rr.EXPECT().Delete(gomock.Any(), messageID, userID, "👍").Return(nil)
+	rr.EXPECT().ListByMessageIDs(gomock.Any(), []string{messageID}).Return([]entity.Reaction{}, nil)
+
+	usecase := NewReactionUseCase(rr, mr)
+
+	got, err := usecase.RemoveReaction(context.Background(), userID, workspaceID, channelID, messageID, "👍")
+	if err != nil {
+		t.Fatalf("RemoveReaction() error = %v, want nil", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("RemoveReaction() got = %v, want empty", got)
+	}
+}
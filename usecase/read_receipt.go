@@ -0,0 +1,101 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type ReadReceiptUseCase interface {
+	MarkRead(ctx context.Context, userID, workspaceID, channelID, messageID string) (*entity.ReadReceipt, bool, error)
+	ListUnreadCounts(ctx context.Context, userID, workspaceID string, channelIDs []string) ([]entity.UnreadCount, error)
+}
+
+type readReceiptUseCase struct {
+	rrr repository.ReadReceiptRepository
+	mr  repository.MessageRepository
+}
+
+func NewReadReceiptUseCase(rrr repository.ReadReceiptRepository, mr repository.MessageRepository) ReadReceiptUseCase {
+	return &readReceiptUseCase{
+		rrr: rrr,
+		mr:  mr,
+	}
+}
+
+// MarkRead はChannelの既読位置をメッセージまで進め、既読位置とそれが更新されたかを返す
+// 既に新しいメッセージまで読んでいる場合は既読位置を戻さない
+func (rruc *readReceiptUseCase) MarkRead(ctx context.Context, userID, workspaceID, channelID, messageID string) (*entity.ReadReceipt, bool, error) {
+	message, err := rruc.mr.Get(ctx, messageID)
+	if err != nil {
+		log.Warn("Failed to get message", log.Fstring("messageID", messageID), log.Ferror(err))
+		return nil, false, ErrMessageNotFound
+	}
+	if message.TargetID != channelID || message.WorkspaceID != workspaceID {
+		log.Warn("Message does not belong to channel", log.Fstring("messageID", messageID), log.Fstring("channelID", channelID))
+		return nil, false, ErrMessageNotFound
+	}
+
+	receipt, err := entity.NewReadReceipt(userID, workspaceID, channelID, messageID, message.CreatedAt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	receipts, err := rruc.rrr.ListByUserID(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to list read receipts", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, false, err
+	}
+	current := findReadReceipt(receipts, channelID)
+	if !receipt.IsAfter(current) {
+		return current, false, nil
+	}
+
+	if err = rruc.rrr.Upsert(ctx, *receipt); err != nil {
+		log.Error("Failed to save read receipt", log.Fstring("channelID", channelID), log.Ferror(err))
+		return nil, false, err
+	}
+	return receipt, true, nil
+}
+
+// ListUnreadCounts はChannel毎に既読位置より新しいメッセージの数と、そのうち自分がメンションされている数を返す
+func (rruc *readReceiptUseCase) ListUnreadCounts(ctx context.Context, userID, workspaceID string, channelIDs []string) ([]entity.UnreadCount, error) {
+	receipts, err := rruc.rrr.ListByUserID(ctx, userID, workspaceID)
+	if err != nil {
+		log.Error("Failed to list read receipts", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+
+	counts := make([]entity.UnreadCount, 0, len(channelIDs))
+	for _, channelID := range channelIDs {
+		var cursor *repository.MessageCursor
+		receipt := findReadReceipt(receipts, channelID)
+		if receipt != nil {
+			cursor = &repository.MessageCursor{CreatedAt: receipt.ReadAt, ID: receipt.MessageID}
+		}
+
+		count, err := rruc.mr.CountUnread(ctx, channelID, userID, cursor)
+		if err != nil {
+			log.Error("Failed to count unread messages", log.Fstring("channelID", channelID), log.Ferror(err))
+			return nil, err
+		}
+		if receipt != nil {
+			count.LastReadMessageID = receipt.MessageID
+		}
+		counts = append(counts, *count)
+	}
+	return counts, nil
+}
+
+func findReadReceipt(receipts []entity.ReadReceipt, channelID string) *entity.ReadReceipt {
+	for i := range receipts {
+		if receipts[i].ChannelID == channelID {
+			return &receipts[i]
+		}
+	}
+	return nil
+}
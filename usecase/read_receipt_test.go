@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func TestReadReceiptUseCase_MarkRead(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	messageID := uuid.New().String()
+	createdAt := time.Now()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mrrr *mock.MockReadReceiptRepository,
+			mmr *mock.MockMessageRepository,
+		)
+		wantMoved bool
+		wantErr   error
+	}{
+		{
+			name: "success: first receipt",
+			setup: func(mrrr *mock.MockReadReceiptRepository, mmr *mock.MockMessageRepository) {
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, WorkspaceID: workspaceID, TargetID: channelID, CreatedAt: createdAt}, nil,
+				)
+				mrrr.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return(nil, nil)
+				mrrr.EXPECT().Upsert(gomock.Any(), entity.ReadReceipt{
+					UserID:      userID,
+					WorkspaceID: workspaceID,
+					ChannelID:   channelID,
+					MessageID:   messageID,
+					ReadAt:      createdAt,
+				}).Return(nil)
+			},
+			wantMoved: true,
+		},
+		{
+			name: "success: already read a newer message",
+			setup: func(mrrr *mock.MockReadReceiptRepository, mmr *mock.MockMessageRepository) {
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, WorkspaceID: workspaceID, TargetID: channelID, CreatedAt: createdAt}, nil,
+				)
+				mrrr.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return([]entity.ReadReceipt{
+					{UserID: userID, WorkspaceID: workspaceID, ChannelID: channelID, MessageID: uuid.New().String(), ReadAt: createdAt.Add(time.Minute)},
+				}, nil)
+			},
+			wantMoved: false,
+		},
+		{
+			name: "Fail: message belongs to another channel",
+			setup: func(_ *mock.MockReadReceiptRepository, mmr *mock.MockMessageRepository) {
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, WorkspaceID: workspaceID, TargetID: uuid.New().String(), CreatedAt: createdAt}, nil,
+				)
+			},
+			wantErr: ErrMessageNotFound,
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			rrr := mock.NewMockReadReceiptRepository(ctrl)
+			mr := mock.NewMockMessageRepository(ctrl)
+
+			tt.setup(rrr, mr)
+
+			usecase := NewReadReceiptUseCase(rrr, mr)
+
+			_, moved, err := usecase.MarkRead(context.Background(), userID, workspaceID, channelID, messageID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("MarkRead() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("MarkRead() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if moved != tt.wantMoved {
+				t.Errorf("MarkRead() moved = %v, want %v", moved, tt.wantMoved)
+			}
+		})
+	}
+}
+
+func TestReadReceiptUseCase_ListUnreadCounts(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	channelID := uuid.New().String()
+	readChannelID := uuid.New().String()
+	lastReadID := uuid.New().String()
+	readAt := time.Now()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			mrrr *mock.MockReadReceiptRepository,
+			mmr *mock.MockMessageRepository,
+		)
+		want    []entity.UnreadCount
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(mrrr *mock.MockReadReceiptRepository, mmr *mock.MockMessageRepository) {
+				mrrr.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return([]entity.ReadReceipt{
+					{UserID: userID, WorkspaceID: workspaceID, ChannelID: readChannelID, MessageID: lastReadID, ReadAt: readAt},
+				}, nil)
+				mmr.EXPECT().CountUnread(gomock.Any(), channelID, userID, nil).Return(
+					&entity.UnreadCount{ChannelID: channelID, UnreadCount: 3, MentionCount: 1}, nil,
+				)
+				mmr.EXPECT().CountUnread(gomock.Any(), readChannelID, userID, &repository.MessageCursor{CreatedAt: readAt, ID: lastReadID}).Return(
+					&entity.UnreadCount{ChannelID: readChannelID, UnreadCount: 0}, nil,
+				)
+			},
+			want: []entity.UnreadCount{
+				{ChannelID: channelID, UnreadCount: 3, MentionCount: 1},
+				{ChannelID: readChannelID, LastReadMessageID: lastReadID},
+			},
+		},
+		{
+			name: "Fail: failed to list read receipts",
+			setup: func(mrrr *mock.MockReadReceiptRepository, _ *mock.MockMessageRepository) {
+				mrrr.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return(nil, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			rrr := mock.NewMockReadReceiptRepository(ctrl)
+			mr := mock.NewMockMessageRepository(ctrl)
+
+			tt.setup(rrr, mr)
+
+			usecase := NewReadReceiptUseCase(rrr, mr)
+
+			got, err := usecase.ListUnreadCounts(context.Background(), userID, workspaceID, []string{channelID, readChannelID})
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ListUnreadCounts() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ListUnreadCounts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ListUnreadCounts() got = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ListUnreadCounts() got = %v, want %v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
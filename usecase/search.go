@@ -0,0 +1,157 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/config"
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// SearchMessagesInput はメッセージ検索の条件
+// ChannelIDとFromは任意で、Before/Afterは投稿日時で絞り込む。Cursorには前のページの最後のメッセージのidを指定する
+type SearchMessagesInput struct {
+	Query     string
+	ChannelID string
+	From      string
+	Before    *time.Time
+	After     *time.Time
+	Cursor    string
+	Limit     int
+}
+
+type SearchUseCase interface {
+	SearchMessages(ctx context.Context, userID string, input SearchMessagesInput) (*entity.SearchResults, error)
+}
+
+type searchUseCase struct {
+	mr  repository.MessageRepository
+	wr  repository.WorkspaceRepository
+	cr  repository.ChannelRepository
+	mcr repository.MembershipChannelRepository
+}
+
+func NewSearchUseCase(
+	mr repository.MessageRepository,
+	wr repository.WorkspaceRepository,
+	cr repository.ChannelRepository,
+	mcr repository.MembershipChannelRepository,
+) SearchUseCase {
+	return &searchUseCase{
+		mr:  mr,
+		wr:  wr,
+		cr:  cr,
+		mcr: mcr,
+	}
+}
+
+// SearchMessages はユーザが閲覧可能なChannelのメッセージから、検索語を全て含むものを新しい順に返す
+func (suc *searchUseCase) SearchMessages(ctx context.Context, userID string, input SearchMessagesInput) (*entity.SearchResults, error) {
+	terms := entity.ParseSearchTerms(input.Query)
+	if len(terms) == 0 {
+		log.Warn("Search query is empty", log.Fstring("query", input.Query))
+		return nil, ErrInvalidSearchQuery
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = config.DefaultMessageListLimit
+	}
+	if limit > config.MaxMessageListLimit {
+		limit = config.MaxMessageListLimit
+	}
+
+	channelIDs, err := suc.listVisibleChannelIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.ChannelID != "" {
+		if !containsString(channelIDs, input.ChannelID) {
+			log.Warn("Channel is not visible", log.Fstring("channelID", input.ChannelID), log.Fstring("userID", userID))
+			return nil, ErrChannelNotFound
+		}
+		channelIDs = []string{input.ChannelID}
+	}
+
+	query := repository.SearchMessagesQuery{
+		Terms:      terms,
+		ChannelIDs: channelIDs,
+		UserID:     input.From,
+		Before:     input.Before,
+		After:      input.After,
+		Limit:      limit,
+	}
+	if input.Cursor != "" {
+		cursor, err := suc.mr.Get(ctx, input.Cursor) //nolint:govet // err shadowing
+		if err != nil || !containsString(channelIDs, cursor.TargetID) {
+			log.Warn("Failed to get cursor message", log.Fstring("messageID", input.Cursor))
+			return nil, ErrMessageNotFound
+		}
+		query.Cursor = &repository.MessageCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+	}
+
+	messages, err := suc.mr.Search(ctx, query)
+	if err != nil {
+		log.Error("Failed to search messages", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+
+	results := &entity.SearchResults{Results: make([]entity.SearchResult, len(messages))}
+	for i := range messages {
+		results.Results[i] = entity.NewSearchResult(&messages[i], terms)
+	}
+	if len(messages) == limit {
+		results.NextCursor = messages[len(messages)-1].ID
+	}
+	return results, nil
+}
+
+// listVisibleChannelIDs はユーザが所属する全てのWorkspaceで、閲覧可能なChannel(公開Channelと参加しているプライベートChannel・DM)のIDを返す
+func (suc *searchUseCase) listVisibleChannelIDs(ctx context.Context, userID string) ([]string, error) {
+	workspaces, err := suc.wr.ListByUserID(ctx, userID)
+	if err != nil {
+		log.Error("Failed to list workspaces", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+
+	var channelIDs []string
+	for _, workspace := range workspaces {
+		channels, err := suc.cr.List(ctx, workspace.ID) //nolint:govet // err shadowing
+		if err != nil {
+			log.Error("Failed to list channels", log.Fstring("workspaceID", workspace.ID), log.Ferror(err))
+			return nil, err
+		}
+		membershipChannels, err := suc.mcr.ListByUserID(ctx, userID, workspace.ID)
+		if err != nil {
+			log.Error("Failed to list membership channels", log.Fstring("userID", userID), log.Ferror(err))
+			return nil, err
+		}
+		joined := make(map[string]bool, len(membershipChannels))
+		for _, mc := range membershipChannels {
+			joined[mc.ChannelID] = true
+		}
+		for _, channel := range channels {
+			if channel.Private && !joined[channel.ID] {
+				continue
+			}
+			channelIDs = append(channelIDs, channel.ID)
+		}
+	}
+	return channelIDs, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func TestSearchUseCase_SearchMessages(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	workspaceID := uuid.New().String()
+	publicChannelID := uuid.New().String()
+	privateChannelID := uuid.New().String()
+	joinedChannelID := uuid.New().String()
+	messageID := uuid.New().String()
+	createdAt := time.Now()
+
+	setupChannels := func(mwr *mock.MockWorkspaceRepository, mcr *mock.MockChannelRepository, mmcr *mock.MockMembershipChannelRepository) {
+		mwr.EXPECT().ListByUserID(gomock.Any(), userID).Return([]entity.Workspace{{ID: workspaceID}}, nil)
+		mcr.EXPECT().List(gomock.Any(), workspaceID).Return([]entity.Channel{
+			{ID: publicChannelID, WorkspaceID: workspaceID},
+			{ID: privateChannelID, WorkspaceID: workspaceID, Private: true},
+			{ID: joinedChannelID, WorkspaceID: workspaceID, Private: true},
+		}, nil)
+		mmcr.EXPECT().ListByUserID(gomock.Any(), userID, workspaceID).Return([]entity.MembershipChannel{
+			{UserID: userID, WorkspaceID: workspaceID, ChannelID: joinedChannelID},
+		}, nil)
+	}
+
+	patterns := []struct {
+		name  string
+		input SearchMessagesInput
+		setup func(
+			mmr *mock.MockMessageRepository,
+			mwr *mock.MockWorkspaceRepository,
+			mcr *mock.MockChannelRepository,
+			mmcr *mock.MockMembershipChannelRepository,
+		)
+		wantCount      int
+		wantNextCursor string
+		wantErr        error
+	}{
+		{
+			name:  "success: searches only visible channels",
+			input: SearchMessagesInput{Query: "hello world", Limit: 1},
+			setup: func(mmr *mock.MockMessageRepository, mwr *mock.MockWorkspaceRepository, mcr *mock.MockChannelRepository, mmcr *mock.MockMembershipChannelRepository) {
+				setupChannels(mwr, mcr, mmcr)
+				mmr.EXPECT().Search(gomock.Any(), repository.SearchMessagesQuery{
+					Terms:      []string{"hello", "world"},
+					ChannelIDs: []string{publicChannelID, joinedChannelID},
+					Limit:      1,
+				}).Return([]entity.Message{
+					{ID: messageID, TargetID: publicChannelID, Text: "hello world", CreatedAt: createdAt},
+				}, nil)
+			},
+			wantCount:      1,
+			wantNextCursor: messageID,
+		},
+		{
+			name:  "success: filtered by channel and cursor",
+			input: SearchMessagesInput{Query: "hello", ChannelID: joinedChannelID, From: userID, Cursor: messageID},
+			setup: func(mmr *mock.MockMessageRepository, mwr *mock.MockWorkspaceRepository, mcr *mock.MockChannelRepository, mmcr *mock.MockMembershipChannelRepository) {
+				setupChannels(mwr, mcr, mmcr)
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, TargetID: joinedChannelID, CreatedAt: createdAt}, nil,
+				)
+				mmr.EXPECT().Search(gomock.Any(), repository.SearchMessagesQuery{
+					Terms:      []string{"hello"},
+					ChannelIDs: []string{joinedChannelID},
+					UserID:     userID,
+					Cursor:     &repository.MessageCursor{CreatedAt: createdAt, ID: messageID},
+					Limit:      50,
+				}).Return(nil, nil)
+			},
+		},
+		{
+			name:  "Fail: empty query",
+			input: SearchMessagesInput{Query: "  "},
+			setup: func(_ *mock.MockMessageRepository, _ *mock.MockWorkspaceRepository, _ *mock.MockChannelRepository, _ *mock.MockMembershipChannelRepository) {
+			},
+			wantErr: ErrInvalidSearchQuery,
+		},
+		{
+			name:  "Fail: private channel the user has not joined",
+			input: SearchMessagesInput{Query: "hello", ChannelID: privateChannelID},
+			setup: func(_ *mock.MockMessageRepository, mwr *mock.MockWorkspaceRepository, mcr *mock.MockChannelRepository, mmcr *mock.MockMembershipChannelRepository) {
+				setupChannels(mwr, mcr, mmcr)
+			},
+			wantErr: ErrChannelNotFound,
+		},
+		{
+			name:  "Fail: cursor message in an invisible channel",
+			input: SearchMessagesInput{Query: "hello", Cursor: messageID},
+			setup: func(mmr *mock.MockMessageRepository, mwr *mock.MockWorkspaceRepository, mcr *mock.MockChannelRepository, mmcr *mock.MockMembershipChannelRepository) {
+				setupChannels(mwr, mcr, mmcr)
+				mmr.EXPECT().Get(gomock.Any(), messageID).Return(
+					&entity.Message{ID: messageID, TargetID: privateChannelID, CreatedAt: createdAt}, nil,
+				)
+			},
+			wantErr: ErrMessageNotFound,
+		},
+		{
+			name:  "Fail: failed to search messages",
+			input: SearchMessagesInput{Query: "hello"},
+			setup: func(mmr *mock.MockMessageRepository, mwr *mock.MockWorkspaceRepository, mcr *mock.MockChannelRepository, mmcr *mock.MockMembershipChannelRepository) {
+				setupChannels(mwr, mcr, mmcr)
+				mmr.EXPECT().Search(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			mr := mock.NewMockMessageRepository(ctrl)
+			wr := mock.NewMockWorkspaceRepository(ctrl)
+			cr := mock.NewMockChannelRepository(ctrl)
+			mcr := mock.NewMockMembershipChannelRepository(ctrl)
+
+			tt.setup(mr, wr, cr, mcr)
+
+			usecase := NewSearchUseCase(mr, wr, cr, mcr)
+
+			got, err := usecase.SearchMessages(context.Background(), userID, tt.input)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("SearchMessages() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("SearchMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Results) != tt.wantCount {
+				t.Errorf("SearchMessages() got %d results, want %d", len(got.Results), tt.wantCount)
+			}
+			if got.NextCursor != tt.wantNextCursor {
+				t.Errorf("SearchMessages() next cursor = %q, want %q", got.NextCursor, tt.wantNextCursor)
+			}
+		})
+	}
+}
@@ -4,46 +4,128 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/tusmasoma/go-tech-dojo/pkg/log"
 
+	"github.com/tusmasoma/go-chat-app/config"
 	"github.com/tusmasoma/go-chat-app/entity"
 
 	"github.com/tusmasoma/go-chat-app/repository"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrInvalidOneTimeToken = errors.New("invalid or expired token")
+	ErrEmailNotVerified    = errors.New("email is not verified")
+
+	ErrOIDCNotConfigured        = errors.New("oidc login is not configured")
+	ErrInvalidOIDCState         = errors.New("invalid or expired oidc state")
+	ErrOIDCAuthenticationFailed = errors.New("oidc authentication failed")
+	ErrOIDCEmailConflict        = errors.New("email is already registered with another account")
+
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor authentication code")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+)
+
+const (
+	verificationMailSubject = "Verify your email address"
+	verificationMailBody    = "Please verify your email address by opening the link below.\n\n%s/verify-email?token=%s\n\nIf you did not sign up, you can ignore this email.\n"
+	resetMailSubject        = "Reset your password"
+	resetMailBody           = "A password reset was requested for your account. Open the link below to choose a new password.\n\n%s/reset-password?token=%s\n\nIf you did not request this, you can ignore this email.\n"
+)
+
 type UserUseCase interface {
-	SignUpAndGenerateToken(ctx context.Context, email string, passward string) (string, error)
-	LoginAndGenerateToken(ctx context.Context, email string, password string) (string, error)
-	// LogoutUser(ctx context.Context, userID string) error
+	// SignUpAndGenerateToken はユーザを作成し、確認メールを送信する
+	// メールアドレスの確認が必須の場合は、ユーザを作成した上でトークンを発行せずErrEmailNotVerifiedを返す
+	SignUpAndGenerateToken(ctx context.Context, email string, passward string) (*entity.AuthTokens, error)
+	// LoginAndGenerateToken はパスワードでログインする
+	// 二要素認証が有効なユーザの場合はトークンを発行せず、CompleteTwoFactorLoginに渡すチャレンジトークンを返す
+	LoginAndGenerateToken(ctx context.Context, email string, password string) (*entity.AuthTokens, *entity.TwoFactorChallenge, error)
+	// CompleteTwoFactorLogin はチャレンジトークンと認証アプリのコード(またはリカバリーコード)を検証してトークンを発行する
+	// チャレンジトークンは一度しか使えないため、コードが誤っていた場合はパスワードでのログインからやり直す
+	CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (*entity.AuthTokens, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*entity.AuthTokens, error)
+	Logout(ctx context.Context, userID, jti, refreshToken string) error
+	GetJWKS(ctx context.Context) entity.JWKSet
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerificationEmail(ctx context.Context, email string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	// BeginOIDCLogin は認可リクエストを保存し、OIDCプロバイダの認可エンドポイントのURLとstateを返す
+	BeginOIDCLogin(ctx context.Context) (authURL string, state string, err error)
+	// OIDCLoginAndGenerateToken は認可コードを交換し、外部アカウントに紐付くユーザでログインする
+	// 紐付くユーザがいない場合は、サインアップと同様にユーザ(とMembership)を作成して紐付ける
+	// 二要素認証が有効なユーザの場合は、LoginAndGenerateTokenと同様にチャレンジトークンを返す
+	OIDCLoginAndGenerateToken(ctx context.Context, state, code string) (*entity.AuthTokens, *entity.TwoFactorChallenge, error)
+	// EnrollTwoFactor は共有鍵を発行する。ActivateTwoFactorでコードを確認するまで二要素認証は有効にならない
+	EnrollTwoFactor(ctx context.Context, userID string) (*entity.TwoFactorEnrollment, error)
+	// ActivateTwoFactor は認証アプリのコードを確認して二要素認証を有効にし、リカバリーコードを返す
+	ActivateTwoFactor(ctx context.Context, userID, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID, code string) error
 }
 
 type userUseCase struct {
-	ur repository.UserRepository
-	mr repository.MembershipRepository
-	tr repository.TransactionRepository
-	ar repository.AuthRepository
+	ur       repository.UserRepository
+	mr       repository.MembershipRepository
+	cr       repository.ChannelRepository
+	mcr      repository.MembershipChannelRepository
+	uir      repository.UserIdentityRepository
+	rcr      repository.RecoveryCodeRepository
+	tr       repository.TransactionRepository
+	ar       repository.AuthRepository
+	tkr      repository.TokenRepository
+	ml       repository.Mailer
+	op       repository.OIDCProvider
+	conf     *config.AuthConfig
+	mailConf *config.MailConfig
+	oidcConf *config.OIDCConfig
+	wsConf   *config.WorkspaceConfig
 }
 
 func NewUserUseCase(
 	ur repository.UserRepository,
 	mr repository.MembershipRepository,
+	cr repository.ChannelRepository,
+	mcr repository.MembershipChannelRepository,
+	uir repository.UserIdentityRepository,
+	rcr repository.RecoveryCodeRepository,
 	tr repository.TransactionRepository,
 	ar repository.AuthRepository,
+	tkr repository.TokenRepository,
+	ml repository.Mailer,
+	op repository.OIDCProvider,
+	conf *config.AuthConfig,
+	mailConf *config.MailConfig,
+	oidcConf *config.OIDCConfig,
+	wsConf *config.WorkspaceConfig,
 ) UserUseCase {
 	return &userUseCase{
-		ur: ur,
-		mr: mr,
-		tr: tr,
-		ar: ar,
+		ur:       ur,
+		mr:       mr,
+		cr:       cr,
+		mcr:      mcr,
+		uir:      uir,
+		rcr:      rcr,
+		tr:       tr,
+		ar:       ar,
+		tkr:      tkr,
+		ml:       ml,
+		op:       op,
+		conf:     conf,
+		mailConf: mailConf,
+		oidcConf: oidcConf,
+		wsConf:   wsConf,
 	}
 }
 
-// 現時点では、User作成時にMembershipも作成する(workspaceIDは固定されています)
-
-func (uuc *userUseCase) SignUpAndGenerateToken(ctx context.Context, email string, password string) (string, error) {
+func (uuc *userUseCase) SignUpAndGenerateToken(ctx context.Context, email string, password string) (*entity.AuthTokens, error) {
 	var user *entity.User
 	if err := uuc.tr.Transaction(ctx, func(ctx context.Context) error {
 		exists, err := uuc.ur.LockByEmail(ctx, email)
@@ -61,50 +143,102 @@ func (uuc *userUseCase) SignUpAndGenerateToken(ctx context.Context, email string
 			log.Error("Error encrypting password", log.Fstring("email", email))
 			return err
 		}
-		user, err = entity.NewUser("", email, hashedPassword)
-		if err != nil {
-			log.Error("Error creating new user", log.Fstring("email", email))
-			return err
-		}
+		user, err = uuc.createUser(ctx, email, hashedPassword)
+		return err
+	}); err != nil {
+		return nil, err
+	}
 
-		if err = uuc.ur.Create(ctx, *user); err != nil {
-			log.Error("Error creating new user", log.Fstring("email", email))
-			return err
-		}
+	// 確認メールの送信に失敗してもユーザは作成済みなので、再送できるようにエラーにしない
+	if err := uuc.sendOneTimeTokenMail(ctx, *user, entity.OneTimeTokenPurposeEmailVerification); err != nil {
+		log.Error("Failed to send verification email", log.Fstring("userID", user.ID), log.Ferror(err))
+	}
+	if uuc.conf.RequireEmailVerification {
+		return nil, ErrEmailNotVerified
+	}
+
+	return uuc.generateTokens(ctx, *user)
+}
+
+// createUser はユーザを作成する。デフォルトWorkspaceが設定されている場合はそのWorkspaceのMembershipも作成し、
+// WebSocketで投稿できるようデフォルトの公開Channelにも参加させる
+func (uuc *userUseCase) createUser(ctx context.Context, email, hashedPassword string) (*entity.User, error) {
+	user, err := entity.NewUser("", email, hashedPassword)
+	if err != nil {
+		log.Error("Error creating new user", log.Fstring("email", email))
+		return nil, err
+	}
+
+	if err = uuc.ur.Create(ctx, *user); err != nil {
+		log.Error("Error creating new user", log.Fstring("email", email))
+		return nil, err
+	}
 
-		// Membership作成
-		parts := strings.Split(email, "@")
-		name := parts[0]
-		membership, err := entity.NewMembership(
-			user.ID,
-			os.Getenv("WORKSPACE_ID"),
-			name,
-			os.Getenv("PROFILE_IMAGE_URL"),
-			false,
-		)
+	// Membership作成
+	workspaceID := uuc.wsConf.DefaultID
+	if workspaceID == "" {
+		return user, nil
+	}
+	parts := strings.Split(email, "@")
+	name := parts[0]
+	membership, err := entity.NewMembership(
+		user.ID,
+		workspaceID,
+		name,
+		os.Getenv("PROFILE_IMAGE_URL"),
+		false,
+	)
+	if err != nil {
+		log.Error("Error creating new membership", log.Fstring("email", email))
+		return nil, err
+	}
+	if err = uuc.mr.Create(ctx, *membership); err != nil {
+		log.Error("Error creating new membership", log.Fstring("email", email))
+		return nil, err
+	}
+	if err = uuc.joinDefaultChannels(ctx, user.ID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// joinDefaultChannels はWorkspaceの公開Channelのうち、デフォルトに指定された名前のChannelにユーザを参加させる
+func (uuc *userUseCase) joinDefaultChannels(ctx context.Context, userID, workspaceID string) error {
+	if len(uuc.wsConf.DefaultChannels) == 0 {
+		return nil
+	}
+	names := make(map[string]bool, len(uuc.wsConf.DefaultChannels))
+	for _, name := range uuc.wsConf.DefaultChannels {
+		names[name] = true
+	}
+
+	channels, err := uuc.cr.List(ctx, workspaceID)
+	if err != nil {
+		log.Error("Failed to list channels", log.Fstring("workspaceID", workspaceID), log.Ferror(err))
+		return err
+	}
+	for _, channel := range channels {
+		if channel.Private || channel.IsDirectMessage() || !names[channel.Name] {
+			continue
+		}
+		membershipChannel, err := entity.NewMembershipChannel(userID, workspaceID, channel.ID, false) //nolint:govet // err shadowing
 		if err != nil {
-			log.Error("Error creating new membership", log.Fstring("email", email))
 			return err
 		}
-		if err = uuc.mr.Create(ctx, *membership); err != nil {
-			log.Error("Error creating new membership", log.Fstring("email", email))
+		if err = uuc.mcr.Create(ctx, *membershipChannel); err != nil {
+			log.Error("Failed to join default channel", log.Fstring("channelID", channel.ID), log.Ferror(err))
 			return err
 		}
-
-		return nil
-	}); err != nil {
-		return "", err
 	}
-
-	jwt, _ := uuc.ar.GenerateToken(user.ID, user.Email)
-	return jwt, nil
+	return nil
 }
 
-func (uuc *userUseCase) LoginAndGenerateToken(ctx context.Context, email string, password string) (string, error) {
+func (uuc *userUseCase) LoginAndGenerateToken(ctx context.Context, email string, password string) (*entity.AuthTokens, *entity.TwoFactorChallenge, error) {
 	user, err := uuc.ur.GetByEmail(ctx, email)
 	if err != nil {
 		log.Error("Error retrieving user by email", log.Fstring("email", email))
-		return "", err
+		return nil, nil, err
 	}
 	// 既にログイン済みかどうか確認する
 	// session, _ := uuc.cr.GetUserSession(ctx, user.ID)
@@ -116,13 +250,540 @@ func (uuc *userUseCase) LoginAndGenerateToken(ctx context.Context, email string,
 	// Clientから送られてきたpasswordをハッシュ化したものとMySQLから返されたハッシュ化されたpasswordを比較する
 	if err = user.CompareHashAndPassword(password); err != nil {
 		log.Info("Password does not match", log.Fstring("email", email))
-		return "", err
+		return nil, nil, err
+	}
+	if uuc.conf.RequireEmailVerification && !user.IsEmailVerified() {
+		log.Info("Email is not verified", log.Fstring("email", email))
+		return nil, nil, ErrEmailNotVerified
 	}
 
-	jwt, _ := uuc.ar.GenerateToken(user.ID, user.Email)
-	// if err = uuc.cr.SetUserSession(ctx, user.ID, jti); err != nil {
-	// 	log.Error("Failed to set access token in cache", log.Fstring("userID", user.ID), log.Fstring("jti", jti))
-	// 	return "", err
-	// }
-	return jwt, nil
+	return uuc.generateTokensOrChallenge(ctx, *user)
+}
+
+// generateTokensOrChallenge は二要素認証が有効なユーザの場合はチャレンジトークンを、それ以外の場合はトークンを発行する
+func (uuc *userUseCase) generateTokensOrChallenge(ctx context.Context, user entity.User) (*entity.AuthTokens, *entity.TwoFactorChallenge, error) {
+	if !user.IsTwoFactorEnabled() {
+		tokens, err := uuc.generateTokens(ctx, user)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tokens, nil, nil
+	}
+
+	challenge, err := entity.NewOneTimeToken(entity.OneTimeTokenPurposeTwoFactorChallenge, user.ID, uuc.conf.TwoFactorChallengeTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = uuc.tkr.SaveOneTimeToken(ctx, *challenge); err != nil {
+		log.Error("Failed to save two-factor challenge", log.Fstring("userID", user.ID))
+		return nil, nil, err
+	}
+	return nil, &entity.TwoFactorChallenge{
+		ChallengeToken: challenge.Token,
+		ExpiresIn:      int(uuc.conf.TwoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+func (uuc *userUseCase) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (*entity.AuthTokens, error) {
+	user, err := uuc.consumeOneTimeToken(ctx, entity.OneTimeTokenPurposeTwoFactorChallenge, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	// チャレンジトークンの発行後に二要素認証が無効にされた場合
+	if !user.IsTwoFactorEnabled() {
+		log.Info("Two-factor authentication is not enabled", log.Fstring("userID", user.ID))
+		return nil, ErrInvalidOneTimeToken
+	}
+	if err = uuc.verifyTwoFactorCode(ctx, *user, code); err != nil {
+		return nil, err
+	}
+
+	return uuc.generateTokens(ctx, *user)
+}
+
+// RefreshToken はリフレッシュトークンを新しいものに置き換え、アクセストークンを再発行する
+// 使用済みのリフレッシュトークンが使われた場合は漏洩したものとみなし、同じFamilyのトークンを全て無効にする
+func (uuc *userUseCase) RefreshToken(ctx context.Context, refreshToken string) (*entity.AuthTokens, error) {
+	current, err := uuc.tkr.GetRefreshToken(ctx, entity.HashToken(refreshToken))
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		log.Info("Refresh token not found")
+		return nil, ErrInvalidRefreshToken
+	} else if err != nil {
+		log.Error("Failed to get refresh token", log.Ferror(err))
+		return nil, err
+	}
+	if current.IsExpired(time.Now()) {
+		log.Info("Refresh token expired", log.Fstring("userID", current.UserID))
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := uuc.ur.Get(ctx, current.UserID)
+	if err != nil {
+		log.Error("Failed to get user", log.Fstring("userID", current.UserID))
+		return nil, err
+	}
+
+	next, err := entity.NewRefreshToken(user.ID, current.FamilyID, uuc.conf.RefreshTokenTTL)
+	if err != nil {
+		log.Error("Failed to create refresh token", log.Fstring("userID", user.ID))
+		return nil, err
+	}
+	if err = uuc.tkr.RotateRefreshToken(ctx, *current, *next); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRefreshTokenReused):
+			log.Warn("Refresh token reused", log.Fstring("userID", user.ID), log.Fstring("familyID", current.FamilyID))
+			if err = uuc.tkr.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				log.Error("Failed to revoke refresh token family", log.Fstring("familyID", current.FamilyID))
+				return nil, err
+			}
+			return nil, ErrInvalidRefreshToken
+		case errors.Is(err, repository.ErrRefreshTokenNotFound):
+			log.Info("Refresh token family revoked", log.Fstring("familyID", current.FamilyID))
+			return nil, ErrInvalidRefreshToken
+		default:
+			log.Error("Failed to rotate refresh token", log.Fstring("userID", user.ID))
+			return nil, err
+		}
+	}
+
+	jwt, _, err := uuc.ar.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		log.Error("Failed to generate token", log.Fstring("userID", user.ID), log.Ferror(err))
+		return nil, err
+	}
+	return entity.NewAuthTokens(jwt, next.Token, uuc.conf.AccessTokenTTL), nil
+}
+
+// Logout はアクセストークンを有効期限まで失効させる。リフレッシュトークンが指定された場合はそのFamilyも無効にする
+func (uuc *userUseCase) Logout(ctx context.Context, userID, jti, refreshToken string) error {
+	if err := uuc.tkr.RevokeAccessToken(ctx, jti, uuc.conf.AccessTokenTTL); err != nil {
+		log.Error("Failed to revoke access token", log.Fstring("userID", userID), log.Fstring("jti", jti))
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := uuc.tkr.GetRefreshToken(ctx, entity.HashToken(refreshToken))
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil
+	} else if err != nil {
+		log.Error("Failed to get refresh token", log.Ferror(err))
+		return err
+	}
+	if token.UserID != userID {
+		log.Warn("Refresh token belongs to another user", log.Fstring("userID", userID))
+		return ErrInvalidRefreshToken
+	}
+
+	if err = uuc.tkr.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+		log.Error("Failed to revoke refresh token family", log.Fstring("familyID", token.FamilyID))
+		return err
+	}
+	return nil
+}
+
+// generateTokens は新しいFamilyのリフレッシュトークンを保存し、アクセストークンと共に返す
+func (uuc *userUseCase) generateTokens(ctx context.Context, user entity.User) (*entity.AuthTokens, error) {
+	jwt, _, err := uuc.ar.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		log.Error("Failed to generate token", log.Fstring("userID", user.ID), log.Ferror(err))
+		return nil, err
+	}
+
+	refreshToken, err := entity.NewRefreshToken(user.ID, "", uuc.conf.RefreshTokenTTL)
+	if err != nil {
+		log.Error("Failed to create refresh token", log.Fstring("userID", user.ID))
+		return nil, err
+	}
+	if err = uuc.tkr.SaveRefreshToken(ctx, *refreshToken); err != nil {
+		log.Error("Failed to save refresh token", log.Fstring("userID", user.ID))
+		return nil, err
+	}
+	return entity.NewAuthTokens(jwt, refreshToken.Token, uuc.conf.AccessTokenTTL), nil
+}
+
+// GetJWKS はトークンの検証に使える公開鍵の一覧を返す
+func (uuc *userUseCase) GetJWKS(_ context.Context) entity.JWKSet {
+	return uuc.ar.JWKS()
+}
+
+func (uuc *userUseCase) VerifyEmail(ctx context.Context, token string) error {
+	user, err := uuc.consumeOneTimeToken(ctx, entity.OneTimeTokenPurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	user.VerifyEmail(time.Now())
+	if err = uuc.ur.Update(ctx, *user); err != nil {
+		log.Error("Failed to update user", log.Fstring("userID", user.ID))
+		return err
+	}
+	return nil
+}
+
+// ResendVerificationEmail は確認メールを再送する
+// 登録されているメールアドレスかどうかを知られないよう、未登録・確認済みの場合も成功として扱う
+func (uuc *userUseCase) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := uuc.ur.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		log.Info("User not found", log.Fstring("email", email))
+		return nil
+	} else if err != nil {
+		log.Error("Error retrieving user by email", log.Fstring("email", email))
+		return err
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+	return uuc.sendOneTimeTokenMail(ctx, *user, entity.OneTimeTokenPurposeEmailVerification)
+}
+
+// ForgotPassword はパスワード再設定のメールを送信する。未登録のメールアドレスの場合も成功として扱う
+func (uuc *userUseCase) ForgotPassword(ctx context.Context, email string) error {
+	user, err := uuc.ur.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		log.Info("User not found", log.Fstring("email", email))
+		return nil
+	} else if err != nil {
+		log.Error("Error retrieving user by email", log.Fstring("email", email))
+		return err
+	}
+	return uuc.sendOneTimeTokenMail(ctx, *user, entity.OneTimeTokenPurposePasswordReset)
+}
+
+// ResetPassword はパスワードを再設定する。メールを受け取れたので、メールアドレスも確認済みにする
+func (uuc *userUseCase) ResetPassword(ctx context.Context, token, password string) error {
+	user, err := uuc.consumeOneTimeToken(ctx, entity.OneTimeTokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := entity.PasswordEncrypt(password)
+	if err != nil {
+		log.Error("Error encrypting password", log.Fstring("userID", user.ID))
+		return err
+	}
+	user.Password = hashedPassword
+	user.VerifyEmail(time.Now())
+
+	if err = uuc.ur.Update(ctx, *user); err != nil {
+		log.Error("Failed to update user", log.Fstring("userID", user.ID))
+		return err
+	}
+	// 漏洩したセッションを使い続けられないよう、再設定前に発行したリフレッシュトークンを全て無効にする
+	if err = uuc.tkr.RevokeUserRefreshTokenFamilies(ctx, user.ID); err != nil {
+		log.Error("Failed to revoke refresh tokens", log.Fstring("userID", user.ID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (uuc *userUseCase) BeginOIDCLogin(ctx context.Context) (string, string, error) {
+	req, err := entity.NewOIDCAuthRequest(uuc.oidcConf.AuthRequestTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := uuc.op.AuthCodeURL(ctx, req.State, req.Nonce, req.CodeChallenge())
+	if errors.Is(err, repository.ErrOIDCNotConfigured) {
+		log.Info("OIDC login is not configured")
+		return "", "", ErrOIDCNotConfigured
+	} else if err != nil {
+		log.Error("Failed to build oidc authorization url", log.Ferror(err))
+		return "", "", err
+	}
+	if err = uuc.tkr.SaveOIDCAuthRequest(ctx, *req); err != nil {
+		log.Error("Failed to save oidc auth request", log.Ferror(err))
+		return "", "", err
+	}
+	return authURL, req.State, nil
+}
+
+func (uuc *userUseCase) OIDCLoginAndGenerateToken(ctx context.Context, state, code string) (*entity.AuthTokens, *entity.TwoFactorChallenge, error) {
+	req, err := uuc.tkr.ConsumeOIDCAuthRequest(ctx, state)
+	if errors.Is(err, repository.ErrOIDCAuthRequestNotFound) {
+		log.Info("OIDC auth request not found")
+		return nil, nil, ErrInvalidOIDCState
+	} else if err != nil {
+		log.Error("Failed to consume oidc auth request", log.Ferror(err))
+		return nil, nil, err
+	}
+
+	identity, err := uuc.op.Exchange(ctx, code, req.CodeVerifier, req.Nonce)
+	if errors.Is(err, repository.ErrOIDCNotConfigured) {
+		log.Info("OIDC login is not configured")
+		return nil, nil, ErrOIDCNotConfigured
+	} else if err != nil {
+		log.Warn("Failed to exchange oidc authorization code", log.Ferror(err))
+		return nil, nil, ErrOIDCAuthenticationFailed
+	}
+
+	var user *entity.User
+	if err = uuc.tr.Transaction(ctx, func(ctx context.Context) error {
+		user, err = uuc.findOrCreateOIDCUser(ctx, *identity)
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+	if uuc.conf.RequireEmailVerification && !user.IsEmailVerified() {
+		log.Info("Email is not verified", log.Fstring("userID", user.ID))
+		return nil, nil, ErrEmailNotVerified
+	}
+
+	log.Info("User login with oidc", log.Fstring("userID", user.ID), log.Fstring("issuer", identity.Issuer))
+	return uuc.generateTokensOrChallenge(ctx, *user)
+}
+
+// findOrCreateOIDCUser は外部アカウントに紐付くユーザを返す。紐付けがない場合は同じメールアドレスのユーザに紐付けるか、ユーザを作成する
+// 既存のユーザに紐付けるのは、プロバイダがメールアドレスを確認済みの場合のみ(他人のアカウントの乗っ取りを防ぐ)
+func (uuc *userUseCase) findOrCreateOIDCUser(ctx context.Context, identity entity.OIDCIdentity) (*entity.User, error) {
+	linked, err := uuc.uir.Get(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		return uuc.ur.Get(ctx, linked.UserID)
+	} else if !errors.Is(err, repository.ErrUserIdentityNotFound) {
+		log.Error("Failed to get user identity", log.Fstring("issuer", identity.Issuer), log.Ferror(err))
+		return nil, err
+	}
+
+	if identity.Email == "" {
+		log.Warn("Email claim is required to link oidc account", log.Fstring("issuer", identity.Issuer))
+		return nil, ErrOIDCAuthenticationFailed
+	}
+
+	exists, err := uuc.ur.LockByEmail(ctx, identity.Email)
+	if err != nil {
+		log.Error("Error retrieving user by email", log.Fstring("email", identity.Email))
+		return nil, err
+	}
+
+	var user *entity.User
+	if exists {
+		if !identity.EmailVerified {
+			log.Info("Email of oidc account is not verified", log.Fstring("email", identity.Email))
+			return nil, ErrOIDCEmailConflict
+		}
+		if user, err = uuc.ur.GetByEmail(ctx, identity.Email); err != nil {
+			log.Error("Error retrieving user by email", log.Fstring("email", identity.Email))
+			return nil, err
+		}
+	} else {
+		// パスワードではログインできないよう推測できない値を設定する。パスワードはForgotPasswordで設定できる
+		hashedPassword, err := entity.PasswordEncrypt(uuid.New().String()) //nolint:govet // err shadowing
+		if err != nil {
+			log.Error("Error encrypting password", log.Fstring("email", identity.Email))
+			return nil, err
+		}
+		if user, err = uuc.createUser(ctx, identity.Email, hashedPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	if identity.EmailVerified && !user.IsEmailVerified() {
+		user.VerifyEmail(time.Now())
+		if err = uuc.ur.Update(ctx, *user); err != nil {
+			log.Error("Failed to update user", log.Fstring("userID", user.ID))
+			return nil, err
+		}
+	}
+
+	link, err := entity.NewUserIdentity(identity.Issuer, identity.Subject, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = uuc.uir.Create(ctx, *link); err != nil {
+		log.Error("Failed to create user identity", log.Fstring("userID", user.ID), log.Ferror(err))
+		return nil, err
+	}
+	return user, nil
+}
+
+func (uuc *userUseCase) EnrollTwoFactor(ctx context.Context, userID string) (*entity.TwoFactorEnrollment, error) {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Error("Failed to get user", log.Fstring("userID", userID))
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		log.Info("Two-factor authentication is already enabled", log.Fstring("userID", userID))
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	// 登録をやり直した場合は以前の共有鍵を破棄する
+	enrollment, err := entity.NewTwoFactorEnrollment(uuc.conf.Issuer, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	user.EnrollTwoFactor(enrollment.Secret)
+	if err = uuc.ur.Update(ctx, *user); err != nil {
+		log.Error("Failed to update user", log.Fstring("userID", userID))
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+func (uuc *userUseCase) ActivateTwoFactor(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Error("Failed to get user", log.Fstring("userID", userID))
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		log.Info("Two-factor authentication is already enabled", log.Fstring("userID", userID))
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		log.Info("Two-factor authentication is not enrolled", log.Fstring("userID", userID))
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	// 認証アプリに正しく登録できたことを確認してから有効にする
+	step, ok := entity.ValidateTOTPCode(user.TOTPSecret, code, time.Now())
+	if !ok {
+		log.Info("Invalid two-factor authentication code", log.Fstring("userID", userID))
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, err := entity.NewRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = uuc.tr.Transaction(ctx, func(ctx context.Context) error {
+		user.EnableTwoFactor(time.Now())
+		if err = uuc.ur.Update(ctx, *user); err != nil {
+			log.Error("Failed to update user", log.Fstring("userID", userID))
+			return err
+		}
+		if err = uuc.rcr.Replace(ctx, user.ID, recoveryCodes); err != nil {
+			log.Error("Failed to save recovery codes", log.Fstring("userID", userID))
+			return err
+		}
+		// 有効化に使ったコードでログインできないよう、ステップを記録する
+		return uuc.consumeTOTPStep(ctx, user.ID, step)
+	}); err != nil {
+		return nil, err
+	}
+
+	// リカバリーコードはハッシュ値のみを保存しているため、平文を返せるのはこの時だけ
+	codes := make([]string, len(recoveryCodes))
+	for i, rc := range recoveryCodes {
+		codes[i] = rc.Code
+	}
+	log.Info("Two-factor authentication enabled", log.Fstring("userID", userID))
+	return codes, nil
+}
+
+// DisableTwoFactor は二要素認証を無効にする。端末を紛失した場合に備えてリカバリーコードでも無効にできる
+func (uuc *userUseCase) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	user, err := uuc.ur.Get(ctx, userID)
+	if err != nil {
+		log.Error("Failed to get user", log.Fstring("userID", userID))
+		return err
+	}
+	if !user.IsTwoFactorEnabled() {
+		log.Info("Two-factor authentication is not enabled", log.Fstring("userID", userID))
+		return ErrTwoFactorNotEnabled
+	}
+	if err = uuc.verifyTwoFactorCode(ctx, *user, code); err != nil {
+		return err
+	}
+
+	if err = uuc.tr.Transaction(ctx, func(ctx context.Context) error {
+		user.DisableTwoFactor()
+		if err = uuc.ur.Update(ctx, *user); err != nil {
+			log.Error("Failed to update user", log.Fstring("userID", userID))
+			return err
+		}
+		if err = uuc.rcr.DeleteByUserID(ctx, user.ID); err != nil {
+			log.Error("Failed to delete recovery codes", log.Fstring("userID", userID))
+			return err
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	log.Info("Two-factor authentication disabled", log.Fstring("userID", userID))
+	return nil
+}
+
+// verifyTwoFactorCode は認証アプリのコードを検証し、一致しない場合はリカバリーコードとして使用済みにする
+// 認証アプリのコードも一度しか使えないよう、受け付けたステップを記録する
+func (uuc *userUseCase) verifyTwoFactorCode(ctx context.Context, user entity.User, code string) error {
+	if step, ok := entity.ValidateTOTPCode(user.TOTPSecret, code, time.Now()); ok {
+		return uuc.consumeTOTPStep(ctx, user.ID, step)
+	}
+
+	err := uuc.rcr.Consume(ctx, user.ID, entity.HashRecoveryCode(code))
+	if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+		log.Info("Invalid two-factor authentication code", log.Fstring("userID", user.ID))
+		return ErrInvalidTwoFactorCode
+	} else if err != nil {
+		log.Error("Failed to consume recovery code", log.Fstring("userID", user.ID), log.Ferror(err))
+		return err
+	}
+	log.Info("Recovery code used", log.Fstring("userID", user.ID))
+	return nil
+}
+
+func (uuc *userUseCase) consumeTOTPStep(ctx context.Context, userID string, step int64) error {
+	err := uuc.ur.ConsumeTOTPStep(ctx, userID, step)
+	if errors.Is(err, repository.ErrTOTPStepAlreadyUsed) {
+		log.Warn("TOTP code reused", log.Fstring("userID", userID))
+		return ErrInvalidTwoFactorCode
+	} else if err != nil {
+		log.Error("Failed to consume totp step", log.Fstring("userID", userID), log.Ferror(err))
+		return err
+	}
+	return nil
+}
+
+func (uuc *userUseCase) consumeOneTimeToken(ctx context.Context, purpose, token string) (*entity.User, error) {
+	userID, err := uuc.tkr.ConsumeOneTimeToken(ctx, purpose, entity.HashToken(token))
+	if errors.Is(err, repository.ErrOneTimeTokenNotFound) {
+		log.Info("One-time token not found", log.Fstring("purpose", purpose))
+		return nil, ErrInvalidOneTimeToken
+	} else if err != nil {
+		log.Error("Failed to consume one-time token", log.Fstring("purpose", purpose), log.Ferror(err))
+		return nil, err
+	}
+
+	user, err := uuc.ur.Get(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		log.Info("User not found", log.Fstring("userID", userID))
+		return nil, ErrInvalidOneTimeToken
+	} else if err != nil {
+		log.Error("Failed to get user", log.Fstring("userID", userID))
+		return nil, err
+	}
+	return user, nil
+}
+
+// sendOneTimeTokenMail はワンタイムトークンを発行し、用途に応じたURLをメールで送る
+func (uuc *userUseCase) sendOneTimeTokenMail(ctx context.Context, user entity.User, purpose string) error {
+	ttl, subject, body := uuc.conf.EmailVerificationTokenTTL, verificationMailSubject, verificationMailBody
+	if purpose == entity.OneTimeTokenPurposePasswordReset {
+		ttl, subject, body = uuc.conf.PasswordResetTokenTTL, resetMailSubject, resetMailBody
+	}
+
+	token, err := entity.NewOneTimeToken(purpose, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+	if err = uuc.tkr.SaveOneTimeToken(ctx, *token); err != nil {
+		log.Error("Failed to save one-time token", log.Fstring("userID", user.ID), log.Fstring("purpose", purpose))
+		return err
+	}
+
+	mail, err := entity.NewMail(user.Email, subject, fmt.Sprintf(body, strings.TrimRight(uuc.mailConf.LinkBaseURL, "/"), token.Token))
+	if err != nil {
+		return err
+	}
+	if err = uuc.ml.Send(ctx, *mail); err != nil {
+		log.Error("Failed to send mail", log.Fstring("userID", user.ID), log.Fstring("purpose", purpose), log.Ferror(err))
+		return err
+	}
+	return nil
 }
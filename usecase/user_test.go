@@ -4,18 +4,44 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 
+	"github.com/tusmasoma/go-chat-app/config"
 	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
 	"github.com/tusmasoma/go-chat-app/repository/mock"
 )
 
+var (
+	authConf = &config.AuthConfig{
+		Issuer:                    "go-chat-app",
+		AccessTokenTTL:            15 * time.Minute,
+		RefreshTokenTTL:           24 * time.Hour,
+		EmailVerificationTokenTTL: 24 * time.Hour,
+		PasswordResetTokenTTL:     time.Hour,
+		TwoFactorChallengeTTL:     5 * time.Minute,
+	}
+	mailConf = &config.MailConfig{
+		LinkBaseURL: "https://chat.example.com",
+	}
+	workspaceConf = &config.WorkspaceConfig{
+		DefaultID: uuid.New().String(),
+	}
+	oidcConf = &config.OIDCConfig{
+		Issuer:         "https://sso.example.com",
+		AuthRequestTTL: 10 * time.Minute,
+	}
+)
+
+const totpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
 func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) { //nolint:gocognit // The number of lines is acceptable
 	t.Helper()
-	t.Setenv("WORKSPACE_ID", uuid.New().String())
 	t.Setenv("PROFILE_IMAGE_URL", "https://example.com")
 
 	patterns := []struct {
@@ -25,8 +51,11 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) { //nolint:gocognit //
 			m1 *mock.MockMembershipRepository,
 			m2 *mock.MockTransactionRepository,
 			m3 *mock.MockAuthRepository,
+			m4 *mock.MockTokenRepository,
+			m5 *mock.MockMailer,
 		)
-		arg struct {
+		requireEmailVerification bool
+		arg                      struct {
 			ctx      context.Context
 			email    string
 			password string
@@ -35,7 +64,7 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) { //nolint:gocognit //
 	}{
 		{
 			name: "success",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockTransactionRepository, m3 *mock.MockAuthRepository) {
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockTransactionRepository, m3 *mock.MockAuthRepository, m4 *mock.MockTokenRepository, m5 *mock.MockMailer) {
 				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
 					return fn(ctx)
 				})
@@ -71,7 +100,27 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) { //nolint:gocognit //
 				m3.EXPECT().GenerateToken(
 					gomock.Any(),
 					"test@gmail.com",
-				).Return("jwt", "jti")
+				).Return("jwt", "jti", nil)
+				m4.EXPECT().SaveRefreshToken(
+					gomock.Any(),
+					gomock.Any(),
+				).Return(nil)
+				m4.EXPECT().SaveOneTimeToken(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, token entity.OneTimeToken) {
+					if token.Purpose != entity.OneTimeTokenPurposeEmailVerification || token.TTL != authConf.EmailVerificationTokenTTL {
+						t.Errorf("unexpected OneTimeToken: %+v", token)
+					}
+				}).Return(nil)
+				m5.EXPECT().Send(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, mail entity.Mail) {
+					if mail.To != "test@gmail.com" || !strings.Contains(mail.Body, mailConf.LinkBaseURL+"/verify-email?token=") {
+						t.Errorf("unexpected Mail: %+v", mail)
+					}
+				}).Return(nil)
 			},
 			arg: struct {
 				ctx      context.Context
@@ -84,9 +133,33 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) { //nolint:gocognit //
 			},
 			wantErr: nil,
 		},
+		{
+			name: "Success: email verification required",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockTransactionRepository, m3 *mock.MockAuthRepository, m4 *mock.MockTokenRepository, m5 *mock.MockMailer) {
+				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().LockByEmail(gomock.Any(), "test@gmail.com").Return(false, nil)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m4.EXPECT().SaveOneTimeToken(gomock.Any(), gomock.Any()).Return(nil)
+				m5.EXPECT().Send(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+			},
+			requireEmailVerification: true,
+			arg: struct {
+				ctx      context.Context
+				email    string
+				password string
+			}{
+				ctx:      context.Background(),
+				email:    "test@gmail.com",
+				password: "password123",
+			},
+			wantErr: ErrEmailNotVerified,
+		},
 		{
 			name: "Fail: Username already exists",
-			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockTransactionRepository, m3 *mock.MockAuthRepository) {
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockTransactionRepository, m3 *mock.MockAuthRepository, m4 *mock.MockTokenRepository, m5 *mock.MockMailer) {
 				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
 					return fn(ctx)
 				})
@@ -115,13 +188,17 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) { //nolint:gocognit //
 			mr := mock.NewMockMembershipRepository(ctrl)
 			tr := mock.NewMockTransactionRepository(ctrl)
 			ar := mock.NewMockAuthRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+			ml := mock.NewMockMailer(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ur, mr, tr, ar)
+				tt.setup(ur, mr, tr, ar, tkr, ml)
 			}
 
-			usecase := NewUserUseCase(ur, mr, tr, ar)
-			jwt, err := usecase.SignUpAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.password)
+			conf := *authConf
+			conf.RequireEmailVerification = tt.requireEmailVerification
+			usecase := NewUserUseCase(ur, mr, nil, nil, nil, nil, tr, ar, tkr, ml, nil, &conf, mailConf, nil, workspaceConf)
+			tokens, err := usecase.SignUpAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.password)
 
 			if (err != nil) != (tt.wantErr != nil) {
 				t.Errorf("SignUpAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
@@ -129,14 +206,66 @@ func TestUserUseCase_SignUpAndGenerateToken(t *testing.T) { //nolint:gocognit //
 				t.Errorf("SignUpAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
-			if tt.wantErr == nil && jwt == "" {
-				t.Error("Failed to generate token")
+			if tt.wantErr == nil && (tokens == nil || tokens.AccessToken != "jwt" || tokens.RefreshToken == "") {
+				t.Errorf("Failed to generate token: %+v", tokens)
 			}
 		})
 	}
 }
 
-func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
+func TestUserUseCase_SignUpJoinsDefaultChannels(t *testing.T) {
+	t.Parallel()
+
+	workspaceID := uuid.New().String()
+	defaultChannel := entity.Channel{ID: uuid.New().String(), WorkspaceID: workspaceID, Name: "general"}
+	channels := []entity.Channel{
+		defaultChannel,
+		{ID: uuid.New().String(), WorkspaceID: workspaceID, Name: "random"},
+		{ID: uuid.New().String(), WorkspaceID: workspaceID, Name: "general-private", Private: true},
+	}
+
+	ctrl := gomock.NewController(t)
+	ur := mock.NewMockUserRepository(ctrl)
+	mr := mock.NewMockMembershipRepository(ctrl)
+	cr := mock.NewMockChannelRepository(ctrl)
+	mcr := mock.NewMockMembershipChannelRepository(ctrl)
+	tr := mock.NewMockTransactionRepository(ctrl)
+	ar := mock.NewMockAuthRepository(ctrl)
+	tkr := mock.NewMockTokenRepository(ctrl)
+	ml := mock.NewMockMailer(ctrl)
+
+	// MembershipとデフォルトChannelへの参加は、ユーザの作成と同じトランザクションで行う
+	var inTransaction bool
+	tr.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		inTransaction = true
+		defer func() { inTransaction = false }()
+		return fn(ctx)
+	})
+	ur.EXPECT().LockByEmail(gomock.Any(), "test@gmail.com").Return(false, nil)
+	ur.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	mr.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	cr.EXPECT().List(gomock.Any(), workspaceID).Return(channels, nil)
+	mcr.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, mc entity.MembershipChannel) {
+		if !inTransaction {
+			t.Error("default channel is joined outside the transaction")
+		}
+		if mc.ChannelID != defaultChannel.ID || mc.WorkspaceID != workspaceID || mc.IsOwner {
+			t.Errorf("unexpected MembershipChannel: %+v", mc)
+		}
+	}).Return(nil)
+	ar.EXPECT().GenerateToken(gomock.Any(), "test@gmail.com").Return("jwt", "jti", nil)
+	tkr.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+	tkr.EXPECT().SaveOneTimeToken(gomock.Any(), gomock.Any()).Return(nil)
+	ml.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+
+	wsConf := &config.WorkspaceConfig{DefaultID: workspaceID, DefaultChannels: []string{"general", "general-private"}}
+	usecase := NewUserUseCase(ur, mr, cr, mcr, nil, nil, tr, ar, tkr, ml, nil, authConf, mailConf, nil, wsConf)
+	if _, err := usecase.SignUpAndGenerateToken(context.Background(), "test@gmail.com", "password123"); err != nil {
+		t.Fatalf("SignUpAndGenerateToken() error = %v", err)
+	}
+}
+
+func TestUserUseCase_LoginAndGenerateToken(t *testing.T) { //nolint:gocognit // The number of lines is acceptable
 	t.Parallel()
 
 	userID := uuid.New().String()
@@ -148,13 +277,16 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 			m1 *mock.MockMembershipRepository,
 			m2 *mock.MockTransactionRepository,
 			m3 *mock.MockAuthRepository,
+			m4 *mock.MockTokenRepository,
 		)
-		arg struct {
+		requireEmailVerification bool
+		arg                      struct {
 			ctx      context.Context
 			email    string
 			passward string
 		}
-		wantErr error
+		wantChallenge bool
+		wantErr       error
 	}{
 		{
 			name: "success",
@@ -163,6 +295,7 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 				m1 *mock.MockMembershipRepository,
 				m2 *mock.MockTransactionRepository,
 				m3 *mock.MockAuthRepository,
+				m4 *mock.MockTokenRepository,
 			) {
 				hashPassword, _ := entity.PasswordEncrypt("password123")
 				m.EXPECT().GetByEmail(
@@ -176,8 +309,16 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 					}, nil,
 				)
 				m3.EXPECT().GenerateToken(userID, "test@gmail.com").Return(
-					"jwt", "jti",
+					"jwt", "jti", nil,
 				)
+				m4.EXPECT().SaveRefreshToken(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, token entity.RefreshToken) {
+					if token.UserID != userID || token.FamilyID == "" || token.TokenHash == "" {
+						t.Errorf("unexpected RefreshToken: %+v", token)
+					}
+				}).Return(nil)
 			},
 			arg: struct {
 				ctx      context.Context
@@ -190,6 +331,117 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "success: two factor enabled",
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockMembershipRepository,
+				m2 *mock.MockTransactionRepository,
+				m3 *mock.MockAuthRepository,
+				m4 *mock.MockTokenRepository,
+			) {
+				hashPassword, _ := entity.PasswordEncrypt("password123")
+				enabledAt := time.Now()
+				m.EXPECT().GetByEmail(
+					gomock.Any(),
+					"test@gmail.com",
+				).Return(
+					&entity.User{
+						ID:            userID,
+						Email:         "test@gmail.com",
+						Password:      hashPassword,
+						TOTPSecret:    totpSecret,
+						TOTPEnabledAt: &enabledAt,
+					}, nil,
+				)
+				m4.EXPECT().SaveOneTimeToken(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, token entity.OneTimeToken) {
+					if token.Purpose != entity.OneTimeTokenPurposeTwoFactorChallenge || token.UserID != userID || token.TTL != authConf.TwoFactorChallengeTTL {
+						t.Errorf("unexpected OneTimeToken: %+v", token)
+					}
+				}).Return(nil)
+			},
+			arg: struct {
+				ctx      context.Context
+				email    string
+				passward string
+			}{
+				ctx:      context.Background(),
+				email:    "test@gmail.com",
+				passward: "password123",
+			},
+			wantChallenge: true,
+		},
+		{
+			name: "Fail: failed to sign token",
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockMembershipRepository,
+				m2 *mock.MockTransactionRepository,
+				m3 *mock.MockAuthRepository,
+				m4 *mock.MockTokenRepository,
+			) {
+				hashPassword, _ := entity.PasswordEncrypt("password123")
+				m.EXPECT().GetByEmail(
+					gomock.Any(),
+					"test@gmail.com",
+				).Return(
+					&entity.User{
+						ID:       userID,
+						Email:    "test@gmail.com",
+						Password: hashPassword,
+					}, nil,
+				)
+				m3.EXPECT().GenerateToken(userID, "test@gmail.com").Return(
+					"", "", errors.New("failed to sign token"),
+				)
+			},
+			arg: struct {
+				ctx      context.Context
+				email    string
+				passward string
+			}{
+				ctx:      context.Background(),
+				email:    "test@gmail.com",
+				passward: "password123",
+			},
+			wantErr: errors.New("failed to sign token"),
+		},
+		{
+			name: "Fail: email is not verified",
+			setup: func(
+				m *mock.MockUserRepository,
+				m1 *mock.MockMembershipRepository,
+				m2 *mock.MockTransactionRepository,
+				m3 *mock.MockAuthRepository,
+				m4 *mock.MockTokenRepository,
+			) {
+				hashPassword, _ := entity.PasswordEncrypt("password123")
+				m.EXPECT().GetByEmail(
+					gomock.Any(),
+					"test@gmail.com",
+				).Return(
+					&entity.User{
+						ID:       userID,
+						Email:    "test@gmail.com",
+						Password: hashPassword,
+					}, nil,
+				)
+			},
+			requireEmailVerification: true,
+			arg: struct {
+				ctx      context.Context
+				email    string
+				passward string
+			}{
+				ctx:      context.Background(),
+				email:    "test@gmail.com",
+				passward: "password123",
+			},
+			wantErr: ErrEmailNotVerified,
+		},
 		{
 			name: "Fail: invalid passward",
 			setup: func(
@@ -197,6 +449,7 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 				m1 *mock.MockMembershipRepository,
 				m2 *mock.MockTransactionRepository,
 				m3 *mock.MockAuthRepository,
+				m4 *mock.MockTokenRepository,
 			) {
 				hashPassword, _ := entity.PasswordEncrypt("invalidPassword123")
 				m.EXPECT().GetByEmail(
@@ -233,23 +486,1151 @@ func TestUserUseCase_LoginAndGenerateToken(t *testing.T) {
 			mr := mock.NewMockMembershipRepository(ctrl)
 			tr := mock.NewMockTransactionRepository(ctrl)
 			ar := mock.NewMockAuthRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
 
 			if tt.setup != nil {
-				tt.setup(ur, mr, tr, ar)
+				tt.setup(ur, mr, tr, ar, tkr)
 			}
 
-			usecase := NewUserUseCase(ur, mr, tr, ar)
-			jwt, err := usecase.LoginAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward)
+			conf := *authConf
+			conf.RequireEmailVerification = tt.requireEmailVerification
+			usecase := NewUserUseCase(ur, mr, nil, nil, nil, nil, tr, ar, tkr, mock.NewMockMailer(ctrl), nil, &conf, mailConf, nil, workspaceConf)
+			tokens, challenge, err := usecase.LoginAndGenerateToken(tt.arg.ctx, tt.arg.email, tt.arg.passward)
 
 			if (err != nil) != (tt.wantErr != nil) {
 				t.Errorf("LoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
 				t.Errorf("LoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if tt.wantChallenge {
+				// 二要素認証が有効な場合はコードを確認するまでトークンを発行しない
+				if tokens != nil || challenge == nil || challenge.ChallengeToken == "" || challenge.ExpiresIn != 300 {
+					t.Errorf("LoginAndGenerateToken() tokens = %+v, challenge = %+v", tokens, challenge)
+				}
+			} else if challenge != nil || tokens == nil || tokens.AccessToken != "jwt" || tokens.RefreshToken == "" {
+				t.Errorf("Failed to generate token: %+v", tokens)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_RefreshToken(t *testing.T) { //nolint:gocognit // The number of lines is acceptable
+	t.Parallel()
+
+	userID := uuid.New().String()
+	familyID := uuid.New().String()
+	refreshToken := "refresh-token"
+	current := entity.RefreshToken{
+		TokenHash: entity.HashToken(refreshToken),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	user := &entity.User{
+		ID:    userID,
+		Email: "test@gmail.com",
+	}
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+			m1 *mock.MockAuthRepository,
+			m2 *mock.MockTokenRepository,
+		)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockAuthRepository, m2 *mock.MockTokenRepository) {
+				m2.EXPECT().GetRefreshToken(gomock.Any(), current.TokenHash).Return(&current, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				m2.EXPECT().RotateRefreshToken(
+					gomock.Any(),
+					current,
+					gomock.Any(),
+				).Do(func(_ context.Context, _, next entity.RefreshToken) {
+					if next.FamilyID != familyID || next.UserID != userID || next.TokenHash == current.TokenHash {
+						t.Errorf("unexpected RefreshToken: %+v", next)
+					}
+				}).Return(nil)
+				m1.EXPECT().GenerateToken(userID, "test@gmail.com").Return("jwt", "jti", nil)
+			},
+		},
+		{
+			name: "Fail: refresh token not found",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockAuthRepository, m2 *mock.MockTokenRepository) {
+				m2.EXPECT().GetRefreshToken(gomock.Any(), current.TokenHash).Return(nil, repository.ErrRefreshTokenNotFound)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: refresh token expired",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockAuthRepository, m2 *mock.MockTokenRepository) {
+				expired := current
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				m2.EXPECT().GetRefreshToken(gomock.Any(), current.TokenHash).Return(&expired, nil)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: reused refresh token revokes the family",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockAuthRepository, m2 *mock.MockTokenRepository) {
+				m2.EXPECT().GetRefreshToken(gomock.Any(), current.TokenHash).Return(&current, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				m2.EXPECT().RotateRefreshToken(gomock.Any(), current, gomock.Any()).Return(repository.ErrRefreshTokenReused)
+				m2.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), familyID).Return(nil)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Fail: refresh token family revoked",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockAuthRepository, m2 *mock.MockTokenRepository) {
+				m2.EXPECT().GetRefreshToken(gomock.Any(), current.TokenHash).Return(&current, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				m2.EXPECT().RotateRefreshToken(gomock.Any(), current, gomock.Any()).Return(repository.ErrRefreshTokenNotFound)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			ar := mock.NewMockAuthRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, ar, tkr)
+			}
+
+			usecase := NewUserUseCase(ur, mr, nil, nil, nil, nil, tr, ar, tkr, nil, nil, authConf, mailConf, nil, workspaceConf)
+			tokens, err := usecase.RefreshToken(context.Background(), refreshToken)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && (tokens == nil || tokens.AccessToken != "jwt" || tokens.RefreshToken == refreshToken) {
+				t.Errorf("Failed to refresh token: %+v", tokens)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_Logout(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	familyID := uuid.New().String()
+	jti := uuid.New().String()
+	refreshToken := "refresh-token"
+
+	patterns := []struct {
+		name         string
+		refreshToken string
+		setup        func(m *mock.MockTokenRepository)
+		wantErr      error
+	}{
+		{
+			name: "success: access token only",
+			setup: func(m *mock.MockTokenRepository) {
+				m.EXPECT().RevokeAccessToken(gomock.Any(), jti, authConf.AccessTokenTTL).Return(nil)
+			},
+		},
+		{
+			name:         "success: with refresh token",
+			refreshToken: refreshToken,
+			setup: func(m *mock.MockTokenRepository) {
+				m.EXPECT().RevokeAccessToken(gomock.Any(), jti, authConf.AccessTokenTTL).Return(nil)
+				m.EXPECT().GetRefreshToken(gomock.Any(), entity.HashToken(refreshToken)).Return(
+					&entity.RefreshToken{UserID: userID, FamilyID: familyID}, nil,
+				)
+				m.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), familyID).Return(nil)
+			},
+		},
+		{
+			name:         "success: refresh token already revoked",
+			refreshToken: refreshToken,
+			setup: func(m *mock.MockTokenRepository) {
+				m.EXPECT().RevokeAccessToken(gomock.Any(), jti, authConf.AccessTokenTTL).Return(nil)
+				m.EXPECT().GetRefreshToken(gomock.Any(), entity.HashToken(refreshToken)).Return(
+					nil, repository.ErrRefreshTokenNotFound,
+				)
+			},
+		},
+		{
+			name:         "Fail: refresh token of another user",
+			refreshToken: refreshToken,
+			setup: func(m *mock.MockTokenRepository) {
+				m.EXPECT().RevokeAccessToken(gomock.Any(), jti, authConf.AccessTokenTTL).Return(nil)
+				m.EXPECT().GetRefreshToken(gomock.Any(), entity.HashToken(refreshToken)).Return(
+					&entity.RefreshToken{UserID: uuid.New().String(), FamilyID: familyID}, nil,
+				)
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			tkr := mock.NewMockTokenRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(tkr)
+			}
 
-			if tt.wantErr == nil && jwt == "" {
-				t.Error("Failed to generate token")
+			usecase := NewUserUseCase(nil, nil, nil, nil, nil, nil, nil, nil, tkr, nil, nil, authConf, mailConf, nil, workspaceConf)
+			err := usecase.Logout(context.Background(), userID, jti, tt.refreshToken)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Logout() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("Logout() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestUserUseCase_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	token := "verification-token"
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository) {
+				m1.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeEmailVerification, entity.HashToken(token)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					if !user.IsEmailVerified() {
+						t.Error("EmailVerifiedAt is nil")
+					}
+				}).Return(nil)
+			},
+		},
+		{
+			name: "success: already verified",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository) {
+				m1.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeEmailVerification, entity.HashToken(token)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+			},
+		},
+		{
+			name: "Fail: token already used or expired",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository) {
+				m1.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeEmailVerification, entity.HashToken(token)).Return("", repository.ErrOneTimeTokenNotFound)
+			},
+			wantErr: ErrInvalidOneTimeToken,
+		},
+		{
+			name: "Fail: user deleted",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository) {
+				m1.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeEmailVerification, entity.HashToken(token)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(nil, repository.ErrUserNotFound)
+			},
+			wantErr: ErrInvalidOneTimeToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, tkr)
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, nil, tkr, nil, nil, authConf, mailConf, nil, workspaceConf)
+			err := usecase.VerifyEmail(context.Background(), token)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("VerifyEmail() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("VerifyEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_ResendVerificationEmail(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer) {
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+				m1.EXPECT().SaveOneTimeToken(gomock.Any(), gomock.Any()).Return(nil)
+				m2.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "success: already verified",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer) {
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(&entity.User{ID: userID, Email: "test@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+			},
+		},
+		{
+			name: "success: user not found",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer) {
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(nil, repository.ErrUserNotFound)
+			},
+		},
+		{
+			name: "Fail: failed to send mail",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer) {
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+				m1.EXPECT().SaveOneTimeToken(gomock.Any(), gomock.Any()).Return(nil)
+				m2.EXPECT().Send(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+			},
+			wantErr: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+			ml := mock.NewMockMailer(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, tkr, ml)
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, nil, tkr, ml, nil, authConf, mailConf, nil, workspaceConf)
+			err := usecase.ResendVerificationEmail(context.Background(), "test@gmail.com")
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ResendVerificationEmail() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ResendVerificationEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_ForgotPassword(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer) {
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+				m1.EXPECT().SaveOneTimeToken(gomock.Any(), gomock.Any()).Do(func(_ context.Context, token entity.OneTimeToken) {
+					if token.Purpose != entity.OneTimeTokenPurposePasswordReset || token.UserID != userID || token.TTL != authConf.PasswordResetTokenTTL {
+						t.Errorf("unexpected OneTimeToken: %+v", token)
+					}
+				}).Return(nil)
+				m2.EXPECT().Send(gomock.Any(), gomock.Any()).Do(func(_ context.Context, mail entity.Mail) {
+					if mail.To != "test@gmail.com" || !strings.Contains(mail.Body, mailConf.LinkBaseURL+"/reset-password?token=") {
+						t.Errorf("unexpected Mail: %+v", mail)
+					}
+				}).Return(nil)
+			},
+		},
+		{
+			name: "success: user not found",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer) {
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(nil, repository.ErrUserNotFound)
+			},
+		},
+		{
+			name: "Fail: failed to get user",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository, m2 *mock.MockMailer) {
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(nil, errors.New("connection refused"))
+			},
+			wantErr: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+			ml := mock.NewMockMailer(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, tkr, ml)
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, nil, tkr, ml, nil, authConf, mailConf, nil, workspaceConf)
+			err := usecase.ForgotPassword(context.Background(), "test@gmail.com")
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ForgotPassword() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ForgotPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	token := "reset-token"
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository) {
+				hashPassword, _ := entity.PasswordEncrypt("oldpassword")
+				m1.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposePasswordReset, entity.HashToken(token)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", Password: hashPassword}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					if err := user.CompareHashAndPassword("newpassword"); err != nil {
+						t.Errorf("Password is not updated: %v", err)
+					}
+					if !user.IsEmailVerified() {
+						t.Error("EmailVerifiedAt is nil")
+					}
+				}).Return(nil)
+				m1.EXPECT().RevokeUserRefreshTokenFamilies(gomock.Any(), userID).Return(nil)
+			},
+		},
+		{
+			name: "Fail: failed to revoke refresh tokens",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository) {
+				hashPassword, _ := entity.PasswordEncrypt("oldpassword")
+				m1.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposePasswordReset, entity.HashToken(token)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", Password: hashPassword}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+				m1.EXPECT().RevokeUserRefreshTokenFamilies(gomock.Any(), userID).Return(fmt.Errorf("failed to revoke refresh tokens"))
+			},
+			wantErr: fmt.Errorf("failed to revoke refresh tokens"),
+		},
+		{
+			name: "Fail: token already used or expired",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockTokenRepository) {
+				m1.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposePasswordReset, entity.HashToken(token)).Return("", repository.ErrOneTimeTokenNotFound)
+			},
+			wantErr: ErrInvalidOneTimeToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, tkr)
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, nil, tkr, nil, nil, authConf, mailConf, nil, workspaceConf)
+			err := usecase.ResetPassword(context.Background(), token, "newpassword")
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ResetPassword() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ResetPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_BeginOIDCLogin(t *testing.T) {
+	t.Parallel()
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockTokenRepository, m1 *mock.MockOIDCProvider)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockTokenRepository, m1 *mock.MockOIDCProvider) {
+				var challenge string
+				m1.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, state, _, codeChallenge string) (string, error) {
+						challenge = codeChallenge
+						return "https://sso.example.com/authorize?state=" + state, nil
+					},
+				)
+				m.EXPECT().SaveOIDCAuthRequest(gomock.Any(), gomock.Any()).Do(func(_ context.Context, req entity.OIDCAuthRequest) {
+					if req.CodeChallenge() != challenge || req.TTL != oidcConf.AuthRequestTTL {
+						t.Errorf("unexpected OIDCAuthRequest: %+v", req)
+					}
+				}).Return(nil)
+			},
+		},
+		{
+			name: "Fail: oidc is not configured",
+			setup: func(m *mock.MockTokenRepository, m1 *mock.MockOIDCProvider) {
+				m1.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("", repository.ErrOIDCNotConfigured)
+			},
+			wantErr: ErrOIDCNotConfigured,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			tkr := mock.NewMockTokenRepository(ctrl)
+			op := mock.NewMockOIDCProvider(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(tkr, op)
+			}
+
+			usecase := NewUserUseCase(nil, nil, nil, nil, nil, nil, nil, nil, tkr, nil, op, authConf, mailConf, oidcConf, workspaceConf)
+			authURL, state, err := usecase.BeginOIDCLogin(context.Background())
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("BeginOIDCLogin() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("BeginOIDCLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (state == "" || authURL != "https://sso.example.com/authorize?state="+state) {
+				t.Errorf("BeginOIDCLogin() = %q, %q", authURL, state)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_OIDCLoginAndGenerateToken(t *testing.T) { //nolint:gocognit // The number of lines is acceptable
+
+	userID := uuid.New().String()
+	verifiedAt := time.Now().Add(-time.Hour)
+	authRequest := &entity.OIDCAuthRequest{State: "state", Nonce: "nonce", CodeVerifier: "verifier"}
+	identity := &entity.OIDCIdentity{
+		Issuer:        oidcConf.Issuer,
+		Subject:       "248289761001",
+		Email:         "test@gmail.com",
+		EmailVerified: true,
+	}
+	unverifiedIdentity := *identity
+	unverifiedIdentity.EmailVerified = false
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockUserRepository,
+			m1 *mock.MockMembershipRepository,
+			m2 *mock.MockUserIdentityRepository,
+			m3 *mock.MockTransactionRepository,
+			m4 *mock.MockAuthRepository,
+			m5 *mock.MockTokenRepository,
+			m6 *mock.MockOIDCProvider,
+		)
+		wantChallenge bool
+		wantErr       error
+	}{
+		{
+			name: "success: linked user",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockUserIdentityRepository, m3 *mock.MockTransactionRepository, m4 *mock.MockAuthRepository, m5 *mock.MockTokenRepository, m6 *mock.MockOIDCProvider) {
+				m5.EXPECT().ConsumeOIDCAuthRequest(gomock.Any(), "state").Return(authRequest, nil)
+				m6.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m2.EXPECT().Get(gomock.Any(), identity.Issuer, identity.Subject).Return(&entity.UserIdentity{UserID: userID}, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+				m4.EXPECT().GenerateToken(userID, "test@gmail.com").Return("jwt", "jti", nil)
+				m5.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "success: two factor enabled",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockUserIdentityRepository, m3 *mock.MockTransactionRepository, m4 *mock.MockAuthRepository, m5 *mock.MockTokenRepository, m6 *mock.MockOIDCProvider) {
+				m5.EXPECT().ConsumeOIDCAuthRequest(gomock.Any(), "state").Return(authRequest, nil)
+				m6.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m2.EXPECT().Get(gomock.Any(), identity.Issuer, identity.Subject).Return(&entity.UserIdentity{UserID: userID}, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{
+					ID:              userID,
+					Email:           "test@gmail.com",
+					EmailVerifiedAt: &verifiedAt,
+					TOTPSecret:      totpSecret,
+					TOTPEnabledAt:   &verifiedAt,
+				}, nil)
+				// 二要素認証が有効な場合はトークンを発行せずチャレンジトークンを保存する
+				m5.EXPECT().SaveOneTimeToken(gomock.Any(), gomock.Any()).Do(func(_ context.Context, token entity.OneTimeToken) {
+					if token.Purpose != entity.OneTimeTokenPurposeTwoFactorChallenge || token.UserID != userID {
+						t.Errorf("unexpected OneTimeToken: %+v", token)
+					}
+				}).Return(nil)
+			},
+			wantChallenge: true,
+		},
+		{
+			name: "success: create user",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockUserIdentityRepository, m3 *mock.MockTransactionRepository, m4 *mock.MockAuthRepository, m5 *mock.MockTokenRepository, m6 *mock.MockOIDCProvider) {
+				var createdUserID string
+				m5.EXPECT().ConsumeOIDCAuthRequest(gomock.Any(), "state").Return(authRequest, nil)
+				m6.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m2.EXPECT().Get(gomock.Any(), identity.Issuer, identity.Subject).Return(nil, repository.ErrUserIdentityNotFound)
+				m.EXPECT().LockByEmail(gomock.Any(), "test@gmail.com").Return(false, nil)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					createdUserID = user.ID
+					if user.Email != "test@gmail.com" || user.Password == "" {
+						t.Errorf("unexpected User: %+v", user)
+					}
+				}).Return(nil)
+				m1.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, membership entity.Membership) {
+					if membership.UserID != createdUserID || membership.Name != "test" {
+						t.Errorf("unexpected Membership: %+v", membership)
+					}
+				}).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					if !user.IsEmailVerified() {
+						t.Error("EmailVerifiedAt is nil")
+					}
+				}).Return(nil)
+				m2.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, link entity.UserIdentity) {
+					if link.Issuer != identity.Issuer || link.Subject != identity.Subject || link.UserID != createdUserID {
+						t.Errorf("unexpected UserIdentity: %+v", link)
+					}
+				}).Return(nil)
+				m4.EXPECT().GenerateToken(gomock.Any(), "test@gmail.com").Return("jwt", "jti", nil)
+				m5.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "success: link to user with the same verified email",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockUserIdentityRepository, m3 *mock.MockTransactionRepository, m4 *mock.MockAuthRepository, m5 *mock.MockTokenRepository, m6 *mock.MockOIDCProvider) {
+				m5.EXPECT().ConsumeOIDCAuthRequest(gomock.Any(), "state").Return(authRequest, nil)
+				m6.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m2.EXPECT().Get(gomock.Any(), identity.Issuer, identity.Subject).Return(nil, repository.ErrUserIdentityNotFound)
+				m.EXPECT().LockByEmail(gomock.Any(), "test@gmail.com").Return(true, nil)
+				m.EXPECT().GetByEmail(gomock.Any(), "test@gmail.com").Return(&entity.User{ID: userID, Email: "test@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+				m2.EXPECT().Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, link entity.UserIdentity) {
+					if link.UserID != userID {
+						t.Errorf("unexpected UserIdentity: %+v", link)
+					}
+				}).Return(nil)
+				m4.EXPECT().GenerateToken(userID, "test@gmail.com").Return("jwt", "jti", nil)
+				m5.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Fail: email of existing user is not verified by provider",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockUserIdentityRepository, m3 *mock.MockTransactionRepository, m4 *mock.MockAuthRepository, m5 *mock.MockTokenRepository, m6 *mock.MockOIDCProvider) {
+				m5.EXPECT().ConsumeOIDCAuthRequest(gomock.Any(), "state").Return(authRequest, nil)
+				m6.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(&unverifiedIdentity, nil)
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m2.EXPECT().Get(gomock.Any(), identity.Issuer, identity.Subject).Return(nil, repository.ErrUserIdentityNotFound)
+				m.EXPECT().LockByEmail(gomock.Any(), "test@gmail.com").Return(true, nil)
+			},
+			wantErr: ErrOIDCEmailConflict,
+		},
+		{
+			name: "Fail: state is already used or expired",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockUserIdentityRepository, m3 *mock.MockTransactionRepository, m4 *mock.MockAuthRepository, m5 *mock.MockTokenRepository, m6 *mock.MockOIDCProvider) {
+				m5.EXPECT().ConsumeOIDCAuthRequest(gomock.Any(), "state").Return(nil, repository.ErrOIDCAuthRequestNotFound)
+			},
+			wantErr: ErrInvalidOIDCState,
+		},
+		{
+			name: "Fail: failed to exchange authorization code",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockMembershipRepository, m2 *mock.MockUserIdentityRepository, m3 *mock.MockTransactionRepository, m4 *mock.MockAuthRepository, m5 *mock.MockTokenRepository, m6 *mock.MockOIDCProvider) {
+				m5.EXPECT().ConsumeOIDCAuthRequest(gomock.Any(), "state").Return(authRequest, nil)
+				m6.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(nil, errors.New("nonce mismatch"))
+			},
+			wantErr: ErrOIDCAuthenticationFailed,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			uir := mock.NewMockUserIdentityRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+			ar := mock.NewMockAuthRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+			op := mock.NewMockOIDCProvider(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, mr, uir, tr, ar, tkr, op)
+			}
+
+			usecase := NewUserUseCase(ur, mr, nil, nil, uir, nil, tr, ar, tkr, nil, op, authConf, mailConf, oidcConf, workspaceConf)
+			tokens, challenge, err := usecase.OIDCLoginAndGenerateToken(context.Background(), "state", "code")
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("OIDCLoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("OIDCLoginAndGenerateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if tt.wantChallenge {
+				if tokens != nil || challenge == nil || challenge.ChallengeToken == "" {
+					t.Errorf("OIDCLoginAndGenerateToken() tokens = %+v, challenge = %+v", tokens, challenge)
+				}
+			} else if challenge != nil || tokens == nil || tokens.AccessToken != "jwt" || tokens.RefreshToken == "" {
+				t.Errorf("OIDCLoginAndGenerateToken() tokens = %+v", tokens)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_CompleteTwoFactorLogin(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	challengeToken := "challenge-token"
+	recoveryCode := "abcd-efgh-ijkl-mnop"
+	enabledAt := time.Now().Add(-time.Hour)
+	newUser := func() *entity.User {
+		return &entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret, TOTPEnabledAt: &enabledAt}
+	}
+
+	patterns := []struct {
+		name    string
+		code    string
+		setup   func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockAuthRepository, m3 *mock.MockTokenRepository)
+		wantErr error
+	}{
+		{
+			name: "success: totp code",
+			code: "totp",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockAuthRepository, m3 *mock.MockTokenRepository) {
+				m3.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeTwoFactorChallenge, entity.HashToken(challengeToken)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(newUser(), nil)
+				m.EXPECT().ConsumeTOTPStep(gomock.Any(), userID, gomock.Any()).Return(nil)
+				m2.EXPECT().GenerateToken(userID, "test@gmail.com").Return("jwt", "jti", nil)
+				m3.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "success: recovery code",
+			code: strings.ToUpper(recoveryCode),
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockAuthRepository, m3 *mock.MockTokenRepository) {
+				m3.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeTwoFactorChallenge, entity.HashToken(challengeToken)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(newUser(), nil)
+				m1.EXPECT().Consume(gomock.Any(), userID, entity.HashRecoveryCode(recoveryCode)).Return(nil)
+				m2.EXPECT().GenerateToken(userID, "test@gmail.com").Return("jwt", "jti", nil)
+				m3.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Fail: totp code already used",
+			code: "totp",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockAuthRepository, m3 *mock.MockTokenRepository) {
+				m3.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeTwoFactorChallenge, entity.HashToken(challengeToken)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(newUser(), nil)
+				m.EXPECT().ConsumeTOTPStep(gomock.Any(), userID, gomock.Any()).Return(repository.ErrTOTPStepAlreadyUsed)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: invalid code",
+			code: recoveryCode,
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockAuthRepository, m3 *mock.MockTokenRepository) {
+				m3.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeTwoFactorChallenge, entity.HashToken(challengeToken)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(newUser(), nil)
+				m1.EXPECT().Consume(gomock.Any(), userID, entity.HashRecoveryCode(recoveryCode)).Return(repository.ErrRecoveryCodeNotFound)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: challenge token is already used or expired",
+			code: "totp",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockAuthRepository, m3 *mock.MockTokenRepository) {
+				m3.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeTwoFactorChallenge, entity.HashToken(challengeToken)).Return("", repository.ErrOneTimeTokenNotFound)
+			},
+			wantErr: ErrInvalidOneTimeToken,
+		},
+		{
+			name: "Fail: two factor disabled after login",
+			code: "totp",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockAuthRepository, m3 *mock.MockTokenRepository) {
+				m3.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeTwoFactorChallenge, entity.HashToken(challengeToken)).Return(userID, nil)
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+			},
+			wantErr: ErrInvalidOneTimeToken,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			rcr := mock.NewMockRecoveryCodeRepository(ctrl)
+			ar := mock.NewMockAuthRepository(ctrl)
+			tkr := mock.NewMockTokenRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, rcr, ar, tkr)
+			}
+
+			code := tt.code
+			if code == "totp" {
+				code, _ = entity.GenerateTOTPCode(totpSecret, time.Now())
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, rcr, nil, ar, tkr, nil, nil, authConf, mailConf, nil, workspaceConf)
+			tokens, err := usecase.CompleteTwoFactorLogin(context.Background(), challengeToken, code)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("CompleteTwoFactorLogin() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("CompleteTwoFactorLogin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (tokens == nil || tokens.AccessToken != "jwt" || tokens.RefreshToken == "") {
+				t.Errorf("CompleteTwoFactorLogin() tokens = %+v", tokens)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_EnrollTwoFactor(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	enabledAt := time.Now().Add(-time.Hour)
+
+	patterns := []struct {
+		name    string
+		setup   func(m *mock.MockUserRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					if user.TOTPSecret == "" || user.IsTwoFactorEnabled() {
+						t.Errorf("unexpected User: %+v", user)
+					}
+				}).Return(nil)
+			},
+		},
+		{
+			name: "success: re-enroll before activation",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret}, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					if user.TOTPSecret == totpSecret {
+						t.Error("TOTPSecret is not regenerated")
+					}
+				}).Return(nil)
+			},
+		},
+		{
+			name: "Fail: already enabled",
+			setup: func(m *mock.MockUserRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret, TOTPEnabledAt: &enabledAt}, nil)
+			},
+			wantErr: ErrTwoFactorAlreadyEnabled,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur)
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, authConf, mailConf, nil, workspaceConf)
+			enrollment, err := usecase.EnrollTwoFactor(context.Background(), userID)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("EnrollTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("EnrollTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (enrollment == nil || !strings.HasPrefix(enrollment.OTPAuthURI, "otpauth://totp/go-chat-app:test@gmail.com?")) {
+				t.Errorf("EnrollTwoFactor() enrollment = %+v", enrollment)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_ActivateTwoFactor(t *testing.T) { //nolint:gocognit // The number of lines is acceptable
+	t.Parallel()
+
+	userID := uuid.New().String()
+	enabledAt := time.Now().Add(-time.Hour)
+
+	patterns := []struct {
+		name    string
+		code    string
+		setup   func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository)
+		wantErr error
+	}{
+		{
+			name: "success",
+			code: "totp",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret}, nil)
+				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					if !user.IsTwoFactorEnabled() {
+						t.Errorf("unexpected User: %+v", user)
+					}
+				}).Return(nil)
+				m1.EXPECT().Replace(gomock.Any(), userID, gomock.Any()).Do(func(_ context.Context, _ string, codes []entity.RecoveryCode) {
+					if len(codes) != entity.RecoveryCodeCount {
+						t.Errorf("unexpected RecoveryCodes: %+v", codes)
+					}
+				}).Return(nil)
+				m.EXPECT().ConsumeTOTPStep(gomock.Any(), userID, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Fail: invalid code",
+			code: "abcdef",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret}, nil)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: not enrolled",
+			code: "totp",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com"}, nil)
+			},
+			wantErr: ErrTwoFactorNotEnrolled,
+		},
+		{
+			name: "Fail: already enabled",
+			code: "totp",
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret, TOTPEnabledAt: &enabledAt}, nil)
+			},
+			wantErr: ErrTwoFactorAlreadyEnabled,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			rcr := mock.NewMockRecoveryCodeRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, rcr, tr)
+			}
+
+			code := tt.code
+			if code == "totp" {
+				code, _ = entity.GenerateTOTPCode(totpSecret, time.Now())
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, rcr, tr, nil, nil, nil, nil, authConf, mailConf, nil, workspaceConf)
+			codes, err := usecase.ActivateTwoFactor(context.Background(), userID, code)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("ActivateTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("ActivateTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && len(codes) != entity.RecoveryCodeCount {
+				t.Errorf("ActivateTwoFactor() codes = %v", codes)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_DisableTwoFactor(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	recoveryCode := "abcd-efgh-ijkl-mnop"
+	enabledAt := time.Now().Add(-time.Hour)
+	newUser := func() *entity.User {
+		return &entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret, TOTPEnabledAt: &enabledAt}
+	}
+
+	patterns := []struct {
+		name    string
+		code    string
+		setup   func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository)
+		wantErr error
+	}{
+		{
+			name: "success: recovery code",
+			code: recoveryCode,
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(newUser(), nil)
+				m1.EXPECT().Consume(gomock.Any(), userID, entity.HashRecoveryCode(recoveryCode)).Return(nil)
+				m2.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user entity.User) {
+					if user.IsTwoFactorEnabled() || user.TOTPSecret != "" {
+						t.Errorf("unexpected User: %+v", user)
+					}
+				}).Return(nil)
+				m1.EXPECT().DeleteByUserID(gomock.Any(), userID).Return(nil)
+			},
+		},
+		{
+			name: "Fail: invalid code",
+			code: recoveryCode,
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(newUser(), nil)
+				m1.EXPECT().Consume(gomock.Any(), userID, entity.HashRecoveryCode(recoveryCode)).Return(repository.ErrRecoveryCodeNotFound)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "Fail: not enabled",
+			code: recoveryCode,
+			setup: func(m *mock.MockUserRepository, m1 *mock.MockRecoveryCodeRepository, m2 *mock.MockTransactionRepository) {
+				m.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret}, nil)
+			},
+			wantErr: ErrTwoFactorNotEnabled,
+		},
+	}
+
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			ur := mock.NewMockUserRepository(ctrl)
+			rcr := mock.NewMockRecoveryCodeRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(ur, rcr, tr)
+			}
+
+			usecase := NewUserUseCase(ur, nil, nil, nil, nil, rcr, tr, nil, nil, nil, nil, authConf, mailConf, nil, workspaceConf)
+			err := usecase.DisableTwoFactor(context.Background(), userID, tt.code)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("DisableTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("DisableTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 一度受け付けた認証アプリのコードは、有効期間内でもログインや二要素認証の無効化に再利用できない
+func TestUserUseCase_TwoFactorCodeReplay(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+	enabledAt := time.Now().Add(-time.Hour)
+
+	ctrl := gomock.NewController(t)
+	ur := mock.NewMockUserRepository(ctrl)
+	ar := mock.NewMockAuthRepository(ctrl)
+	tkr := mock.NewMockTokenRepository(ctrl)
+
+	// ConsumeTOTPStep はMySQLの実装と同様に、記録済みのステップ以前を拒否する
+	var lastUsedStep int64
+	ur.EXPECT().ConsumeTOTPStep(gomock.Any(), userID, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, step int64) error {
+		if step <= lastUsedStep {
+			return repository.ErrTOTPStepAlreadyUsed
+		}
+		lastUsedStep = step
+		return nil
+	}).Times(3)
+	ur.EXPECT().Get(gomock.Any(), userID).Return(&entity.User{ID: userID, Email: "test@gmail.com", TOTPSecret: totpSecret, TOTPEnabledAt: &enabledAt}, nil).Times(3)
+	tkr.EXPECT().ConsumeOneTimeToken(gomock.Any(), entity.OneTimeTokenPurposeTwoFactorChallenge, gomock.Any()).Return(userID, nil).Times(2)
+	ar.EXPECT().GenerateToken(userID, "test@gmail.com").Return("jwt", "jti", nil)
+	tkr.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+	usecase := NewUserUseCase(ur, nil, nil, nil, nil, nil, nil, ar, tkr, nil, nil, authConf, mailConf, nil, workspaceConf)
+	code, _ := entity.GenerateTOTPCode(totpSecret, time.Now())
+
+	if _, err := usecase.CompleteTwoFactorLogin(context.Background(), "challenge-token", code); err != nil {
+		t.Fatalf("CompleteTwoFactorLogin() error = %v", err)
+	}
+	if _, err := usecase.CompleteTwoFactorLogin(context.Background(), "another-challenge-token", code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("CompleteTwoFactorLogin() with replayed code error = %v, wantErr %v", err, ErrInvalidTwoFactorCode)
+	}
+	if err := usecase.DisableTwoFactor(context.Background(), userID, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("DisableTwoFactor() with replayed code error = %v, wantErr %v", err, ErrInvalidTwoFactorCode)
+	}
+}
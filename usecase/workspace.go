@@ -0,0 +1,91 @@
+//go:generate mockgen -source=$GOFILE -package=mock -destination=./mock/$GOFILE
+package usecase
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/tusmasoma/go-tech-dojo/pkg/log"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository"
+)
+
+type WorkspaceUseCase interface {
+	ListWorkspaces(ctx context.Context, userID string) ([]entity.Workspace, error)
+	CreateWorkspace(ctx context.Context, userID, name string) (*entity.Workspace, error)
+}
+
+type workspaceUseCase struct {
+	wr repository.WorkspaceRepository
+	ur repository.UserRepository
+	mr repository.MembershipRepository
+	tr repository.TransactionRepository
+}
+
+func NewWorkspaceUseCase(
+	wr repository.WorkspaceRepository,
+	ur repository.UserRepository,
+	mr repository.MembershipRepository,
+	tr repository.TransactionRepository,
+) WorkspaceUseCase {
+	return &workspaceUseCase{
+		wr: wr,
+		ur: ur,
+		mr: mr,
+		tr: tr,
+	}
+}
+
+func (wuc *workspaceUseCase) ListWorkspaces(ctx context.Context, userID string) ([]entity.Workspace, error) {
+	workspaces, err := wuc.wr.ListByUserID(ctx, userID)
+	if err != nil {
+		log.Error("Failed to list workspaces", log.Fstring("userID", userID), log.Ferror(err))
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// Workspace作成時に、作成者を管理者としてMembershipに登録する
+func (wuc *workspaceUseCase) CreateWorkspace(ctx context.Context, userID, name string) (*entity.Workspace, error) {
+	workspace, err := entity.NewWorkspace("", name)
+	if err != nil {
+		log.Error("Failed to create new workspace", log.Fstring("name", name), log.Ferror(err))
+		return nil, err
+	}
+
+	if err = wuc.tr.Transaction(ctx, func(ctx context.Context) error {
+		if err = wuc.wr.Create(ctx, *workspace); err != nil {
+			log.Error("Failed to create workspace", log.Fstring("name", name), log.Ferror(err))
+			return err
+		}
+
+		user, err := wuc.ur.Get(ctx, userID) //nolint:govet // err shadowing
+		if err != nil {
+			log.Error("Failed to get user", log.Fstring("userID", userID), log.Ferror(err))
+			return err
+		}
+
+		membership, err := entity.NewMembership(
+			user.ID,
+			workspace.ID,
+			strings.Split(user.Email, "@")[0],
+			os.Getenv("PROFILE_IMAGE_URL"),
+			true,
+		)
+		if err != nil {
+			log.Error("Failed to create new membership", log.Fstring("userID", userID), log.Ferror(err))
+			return err
+		}
+		if err = wuc.mr.Create(ctx, *membership); err != nil {
+			log.Error("Failed to create membership", log.Fstring("userID", userID), log.Ferror(err))
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+
+	"github.com/tusmasoma/go-chat-app/entity"
+	"github.com/tusmasoma/go-chat-app/repository/mock"
+)
+
+func TestWorkspaceUseCase_CreateWorkspace(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New().String()
+
+	patterns := []struct {
+		name  string
+		setup func(
+			m *mock.MockWorkspaceRepository,
+			m1 *mock.MockUserRepository,
+			m2 *mock.MockMembershipRepository,
+			m3 *mock.MockTransactionRepository,
+		)
+		arg struct {
+			ctx    context.Context
+			userID string
+			name   string
+		}
+		wantErr error
+	}{
+		{
+			name: "success",
+			setup: func(
+				m *mock.MockWorkspaceRepository,
+				m1 *mock.MockUserRepository,
+				m2 *mock.MockMembershipRepository,
+				m3 *mock.MockTransactionRepository,
+			) {
+				m3.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				m.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, workspace entity.Workspace) {
+					if workspace.Name != "workspace" {
+						t.Errorf("unexpected Name: got %v, want %v", workspace.Name, "workspace")
+					}
+				}).Return(nil)
+				m1.EXPECT().Get(gomock.Any(), userID).Return(
+					&entity.User{
+						ID:    userID,
+						Email: "test@gmail.com",
+					}, nil,
+				)
+				m2.EXPECT().Create(
+					gomock.Any(),
+					gomock.Any(),
+				).Do(func(_ context.Context, membership entity.Membership) {
+					if membership.UserID != userID {
+						t.Errorf("unexpected UserID: got %v, want %v", membership.UserID, userID)
+					}
+					if membership.Name != "test" {
+						t.Errorf("unexpected Name: got %v, want %v", membership.Name, "test")
+					}
+					if !membership.IsAdmin {
+						t.Error("creator should be admin")
+					}
+				}).Return(nil)
+			},
+			arg: struct {
+				ctx    context.Context
+				userID string
+				name   string
+			}{
+				ctx:    context.Background(),
+				userID: userID,
+				name:   "workspace",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail: name is empty",
+			arg: struct {
+				ctx    context.Context
+				userID string
+				name   string
+			}{
+				ctx:    context.Background(),
+				userID: userID,
+				name:   "",
+			},
+			wantErr: errors.New("name is required"),
+		},
+	}
+	for _, tt := range patterns {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			wr := mock.NewMockWorkspaceRepository(ctrl)
+			ur := mock.NewMockUserRepository(ctrl)
+			mr := mock.NewMockMembershipRepository(ctrl)
+			tr := mock.NewMockTransactionRepository(ctrl)
+
+			if tt.setup != nil {
+				tt.setup(wr, ur, mr, tr)
+			}
+
+			usecase := NewWorkspaceUseCase(wr, ur, mr, tr)
+
+			workspace, err := usecase.CreateWorkspace(
+				tt.arg.ctx,
+				tt.arg.userID,
+				tt.arg.name,
+			)
+
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("CreateWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && tt.wantErr != nil && err.Error() != tt.wantErr.Error() {
+				t.Errorf("CreateWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && workspace == nil {
+				t.Error("Failed to create workspace")
+			}
+		})
+	}
+}